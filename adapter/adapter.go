@@ -18,7 +18,9 @@ import (
 	"github.com/wowucco/go-admin/plugins/admin/models"
 	"github.com/wowucco/go-admin/template"
 	"github.com/wowucco/go-admin/template/types"
+	"net/http"
 	"net/url"
+	"strings"
 )
 
 // WebFrameWork is an interface which is used as an adapter of
@@ -66,9 +68,81 @@ type WebFrameWork interface {
 	HTMLContentType() string
 }
 
-// BaseAdapter is a base adapter contains some helper functions.
+// HandlerCreator is a WebFrameWork which serves the routes itself, its
+// NewHandler return the app given to Use, which is a http.Handler.
+type HandlerCreator interface {
+	NewHandler() http.Handler
+}
+
+// BaseAdapter is a base adapter contains some helper functions. The routes
+// of the adapter are matched by its router, and the web framework serves
+// them with a few catch-all routes.
 type BaseAdapter struct {
-	db db.Connection
+	db     db.Connection
+	router *context.App
+	mounts map[string]bool
+}
+
+// MountFn mount a route to the web framework which serves the requests
+// by the router of the adapter. If catchAll is true, the route matches all
+// the paths under the path, such as "/admin/*path", and the path is empty
+// for all the paths of the site.
+type MountFn func(path string, catchAll bool)
+
+// MountMethods is the methods of the mounted routes, for the web frameworks
+// which can not mount a route of all the methods.
+var MountMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+// AddRoute add the route to the router of the adapter, and mount the routes
+// serving it, which are mounted only once:
+//
+//	/admin/info/:__prefix => /admin, /admin/*path
+//	/admin                => /admin, /admin/*path
+//	/:id                  => /*path
+//	/                     => /
+func (base *BaseAdapter) AddRoute(method, path string, handlers context.Handlers, mount MountFn) {
+	if base.router == nil {
+		base.resetRouter()
+	}
+	base.router.AppendReqAndResp(path, strings.ToLower(method), handlers)
+
+	segment := strings.TrimLeft(path, "/")
+	if i := strings.IndexByte(segment, '/'); i != -1 {
+		segment = segment[:i]
+	}
+	switch {
+	case segment == "":
+		base.mount("/", false, mount)
+	case segment[0] == ':' || segment[0] == '*':
+		base.mount("", true, mount)
+	default:
+		base.mount("/"+segment, false, mount)
+		base.mount("/"+segment, true, mount)
+	}
+}
+
+func (base *BaseAdapter) mount(path string, catchAll bool, mount MountFn) {
+	key := path
+	if catchAll {
+		key += "/*"
+	}
+	if !base.mounts[key] {
+		base.mounts[key] = true
+		mount(path, catchAll)
+	}
+}
+
+// Serve run the handlers of the route matching the request, and return the
+// context of the response.
+func (base *BaseAdapter) Serve(req *http.Request) *context.Context {
+	ctx := context.NewContext(req)
+	base.router.Serve(ctx)
+	return ctx
+}
+
+func (base *BaseAdapter) resetRouter() {
+	base.router = context.NewApp()
+	base.mounts = make(map[string]bool)
 }
 
 // SetConnection set the db connection.
@@ -109,6 +183,8 @@ func (base *BaseAdapter) GetUse(app interface{}, plugin []plugins.Plugin, wf Web
 		return err
 	}
 
+	base.resetRouter()
+
 	for _, plug := range plugin {
 		for path, handlers := range plug.GetHandler() {
 			wf.AddHandler(path.Method, path.URL, handlers)
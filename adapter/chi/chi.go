@@ -0,0 +1,174 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package chi
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi"
+	"github.com/wowucco/go-admin/adapter"
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/engine"
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/plugins"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+	"github.com/wowucco/go-admin/plugins/admin/modules/constant"
+	"github.com/wowucco/go-admin/template/types"
+)
+
+// Chi structure value is a Chi GoAdmin adapter.
+type Chi struct {
+	adapter.BaseAdapter
+	ctx Context
+	app chi.Router
+}
+
+func init() {
+	engine.Register(new(Chi))
+}
+
+// User implements the method Adapter.User.
+func (ch *Chi) User(ctx interface{}) (models.UserModel, bool) {
+	return ch.GetUser(ctx, ch)
+}
+
+// Use implements the method Adapter.Use.
+func (ch *Chi) Use(app interface{}, plugs []plugins.Plugin) error {
+	return ch.GetUse(app, plugs, ch)
+}
+
+// Content implements the method Adapter.Content.
+func (ch *Chi) Content(ctx interface{}, getPanelFn types.GetPanelFn, btns ...types.Button) {
+	ch.GetContent(ctx, getPanelFn, ch, btns)
+}
+
+// Context is the request and the response writer of a chi handler.
+type Context struct {
+	Request  *http.Request
+	Response http.ResponseWriter
+}
+
+type HandlerFunc func(ctx Context) (types.Panel, error)
+
+func Content(handler HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := Context{
+			Request:  request,
+			Response: writer,
+		}
+		engine.Content(ctx, func(ctx interface{}) (types.Panel, error) {
+			return handler(ctx.(Context))
+		})
+	}
+}
+
+func (ch *Chi) DisableLog()                {}
+func (ch *Chi) Static(prefix, path string) {}
+
+// SetApp implements the method Adapter.SetApp.
+func (ch *Chi) SetApp(app interface{}) error {
+	var (
+		eng chi.Router
+		ok  bool
+	)
+	if eng, ok = app.(chi.Router); !ok {
+		return errors.New("chi adapter SetApp: wrong parameter")
+	}
+	ch.app = eng
+	return nil
+}
+
+// AddHandler implements the method Adapter.AddHandler.
+func (ch *Chi) AddHandler(method, path string, handlers context.Handlers) {
+	ch.AddRoute(method, path, handlers, func(path string, catchAll bool) {
+		if catchAll {
+			path += "/*"
+		}
+		ch.app.HandleFunc(path, ch.serve)
+	})
+}
+
+func (ch *Chi) serve(w http.ResponseWriter, r *http.Request) {
+	ctx := ch.Serve(r)
+	for key, head := range ctx.Response.Header {
+		for _, value := range head {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(ctx.Response.StatusCode)
+	if ctx.Response.Body != nil {
+		buf := new(bytes.Buffer)
+		_, _ = buf.ReadFrom(ctx.Response.Body)
+		_, _ = w.Write(buf.Bytes())
+	}
+}
+
+// Name implements the method Adapter.Name.
+func (ch *Chi) Name() string {
+	return "chi"
+}
+
+// SetContext implements the method Adapter.SetContext.
+func (ch *Chi) SetContext(contextInterface interface{}) adapter.WebFrameWork {
+	var (
+		ctx Context
+		ok  bool
+	)
+
+	if ctx, ok = contextInterface.(Context); !ok {
+		panic("chi adapter SetContext: wrong parameter")
+	}
+
+	return &Chi{ctx: ctx}
+}
+
+// Redirect implements the method Adapter.Redirect.
+func (ch *Chi) Redirect() {
+	http.Redirect(ch.ctx.Response, ch.ctx.Request, config.Url(config.GetLoginUrl()), http.StatusFound)
+}
+
+// SetContentType implements the method Adapter.SetContentType.
+func (ch *Chi) SetContentType() {
+	ch.ctx.Response.Header().Set("Content-Type", ch.HTMLContentType())
+}
+
+// Write implements the method Adapter.Write.
+func (ch *Chi) Write(body []byte) {
+	ch.ctx.Response.WriteHeader(http.StatusOK)
+	_, _ = ch.ctx.Response.Write(body)
+}
+
+// GetCookie implements the method Adapter.GetCookie.
+func (ch *Chi) GetCookie() (string, error) {
+	cookie, err := ch.ctx.Request.Cookie(ch.CookieKey())
+	if err != nil {
+		return "", err
+	}
+	return cookie.Value, nil
+}
+
+// Path implements the method Adapter.Path.
+func (ch *Chi) Path() string {
+	return ch.ctx.Request.URL.Path
+}
+
+// Method implements the method Adapter.Method.
+func (ch *Chi) Method() string {
+	return ch.ctx.Request.Method
+}
+
+// FormParam implements the method Adapter.FormParam.
+func (ch *Chi) FormParam() url.Values {
+	_ = ch.ctx.Request.ParseMultipartForm(32 << 20)
+	return ch.ctx.Request.PostForm
+}
+
+// IsPjax implements the method Adapter.IsPjax.
+func (ch *Chi) IsPjax() bool {
+	return ch.ctx.Request.Header.Get(constant.PjaxHeader) == "true"
+}
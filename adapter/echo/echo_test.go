@@ -0,0 +1,128 @@
+package echo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/auth"
+)
+
+func TestHandler(t *testing.T) {
+	app := echo.New()
+	a := new(Echo)
+	assert.NoError(t, a.SetApp(app))
+	assert.Error(t, a.SetApp(http.NewServeMux()))
+
+	handle := func(name string) context.Handlers {
+		return context.Handlers{func(ctx *context.Context) {
+			ctx.WriteString(name + " " + ctx.Param("__prefix") + " " + ctx.Query("__prefix"))
+		}}
+	}
+
+	a.AddHandler("GET", "/admin/info/:__prefix", handle("info"))
+	a.AddHandler("post", "/admin/edit/:__prefix", handle("update"))
+	a.AddHandler("GET", "/admin", handle("index"))
+
+	cases := []struct {
+		method string
+		path   string
+		code   int
+		body   string
+	}{
+		{"GET", "/admin/info/users", 200, "info users users"},
+		{"GET", "/admin/info/users?__prefix=manager", 200, "info users users"},
+		{"POST", "/admin/edit/users", 200, "update users users"},
+		{"GET", "/admin", 200, "index  "},
+		{"GET", "/admin/edit/users", 405, ""},
+		{"GET", "/admin/info", 404, ""},
+		{"GET", "/other", 404, ""},
+	}
+
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(c.method, c.path, nil))
+		assert.Equal(t, c.code, w.Code, c.method+" "+c.path)
+		if c.code == 200 {
+			assert.Equal(t, c.body, w.Body.String(), c.method+" "+c.path)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/admin/edit/users", nil))
+	assert.Equal(t, "POST", w.Header().Get("Allow"))
+}
+
+func TestCookies(t *testing.T) {
+	app := echo.New()
+	a := new(Echo)
+	assert.NoError(t, a.SetApp(app))
+
+	a.AddHandler("GET", "/admin/login", context.Handlers{func(ctx *context.Context) {
+		ctx.SetCookie(&http.Cookie{Name: "a", Value: "1"})
+		ctx.SetCookie(&http.Cookie{Name: "b", Value: "2"})
+		cookie, _ := ctx.Request.Cookie(auth.DefaultCookieKey)
+		ctx.WriteString(cookie.Value)
+	}})
+
+	req := httptest.NewRequest("GET", "/admin/login", nil)
+	req.AddCookie(&http.Cookie{Name: auth.DefaultCookieKey, Value: "session"})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	assert.Equal(t, "session", w.Body.String())
+	assert.Equal(t, []string{"a=1", "b=2"}, w.Header()["Set-Cookie"])
+
+	cookie, err := a.SetContext(app.NewContext(req, httptest.NewRecorder())).GetCookie()
+	assert.NoError(t, err)
+	assert.Equal(t, "session", cookie)
+
+	_, err = a.SetContext(app.NewContext(httptest.NewRequest("GET", "/", nil), httptest.NewRecorder())).GetCookie()
+	assert.Error(t, err)
+}
+
+func TestMultipart(t *testing.T) {
+	app := echo.New()
+	a := new(Echo)
+	assert.NoError(t, a.SetApp(app))
+
+	a.AddHandler("POST", "/admin/upload", context.Handlers{func(ctx *context.Context) {
+		file, header, err := ctx.Request.FormFile("file")
+		if err != nil {
+			ctx.SetStatusCode(http.StatusBadRequest)
+			return
+		}
+		content, _ := ioutil.ReadAll(file)
+		ctx.WriteString(ctx.FormValue("name") + " " + header.Filename + " " + string(content))
+	}})
+
+	req := multipartRequest(t)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "avatar a.txt content", w.Body.String())
+
+	ctx := app.NewContext(multipartRequest(t), httptest.NewRecorder())
+	assert.Equal(t, "avatar", a.SetContext(ctx).FormParam().Get("name"))
+}
+
+func multipartRequest(t *testing.T) *http.Request {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	assert.NoError(t, mw.WriteField("name", "avatar"))
+	fw, err := mw.CreateFormFile("file", "a.txt")
+	assert.NoError(t, err)
+	_, _ = fw.Write([]byte("content"))
+	assert.NoError(t, mw.Close())
+
+	req := httptest.NewRequest("POST", "/admin/upload", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
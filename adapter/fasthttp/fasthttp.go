@@ -0,0 +1,238 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package fasthttp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/buaazp/fasthttprouter"
+	"github.com/valyala/fasthttp"
+	"github.com/wowucco/go-admin/adapter"
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/engine"
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/plugins"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+	"github.com/wowucco/go-admin/plugins/admin/modules/constant"
+	"github.com/wowucco/go-admin/template/types"
+)
+
+// Fasthttp structure value is a Fasthttp GoAdmin adapter.
+type Fasthttp struct {
+	adapter.BaseAdapter
+	ctx *fasthttp.RequestCtx
+	app *fasthttprouter.Router
+}
+
+func init() {
+	engine.Register(new(Fasthttp))
+}
+
+// User implements the method Adapter.User.
+func (fast *Fasthttp) User(ctx interface{}) (models.UserModel, bool) {
+	return fast.GetUser(ctx, fast)
+}
+
+// Use implements the method Adapter.Use.
+func (fast *Fasthttp) Use(app interface{}, plugs []plugins.Plugin) error {
+	return fast.GetUse(app, plugs, fast)
+}
+
+// Content implements the method Adapter.Content.
+func (fast *Fasthttp) Content(ctx interface{}, getPanelFn types.GetPanelFn, btns ...types.Button) {
+	fast.GetContent(ctx, getPanelFn, fast, btns)
+}
+
+type HandlerFunc func(ctx *fasthttp.RequestCtx) (types.Panel, error)
+
+func Content(handler HandlerFunc) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		engine.Content(ctx, func(ctx interface{}) (types.Panel, error) {
+			return handler(ctx.(*fasthttp.RequestCtx))
+		})
+	}
+}
+
+func (fast *Fasthttp) DisableLog()                {}
+func (fast *Fasthttp) Static(prefix, path string) {}
+
+// SetApp implements the method Adapter.SetApp.
+func (fast *Fasthttp) SetApp(app interface{}) error {
+	var (
+		eng *fasthttprouter.Router
+		ok  bool
+	)
+	if eng, ok = app.(*fasthttprouter.Router); !ok {
+		return errors.New("fasthttp adapter SetApp: wrong parameter")
+	}
+	fast.app = eng
+	return nil
+}
+
+// AddHandler implements the method Adapter.AddHandler.
+func (fast *Fasthttp) AddHandler(method, path string, handlers context.Handlers) {
+	fast.AddRoute(method, path, handlers, func(path string, catchAll bool) {
+		if catchAll {
+			path += "/*path"
+		}
+		for _, method := range adapter.MountMethods {
+			fast.app.Handle(method, path, fast.serve)
+		}
+	})
+}
+
+func (fast *Fasthttp) serve(c *fasthttp.RequestCtx) {
+	req, err := convertRequest(c)
+	if err != nil {
+		c.Error(err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+	writeResponse(c, fast.Serve(req).Response)
+}
+
+// convertRequest convert the fasthttp request to a net/http one. The body
+// is read from the buffer of fasthttp without copying, so the multipart
+// forms are parsed only once by the handlers.
+func convertRequest(c *fasthttp.RequestCtx) (*http.Request, error) {
+	u, err := url.ParseRequestURI(string(c.RequestURI()))
+	if err != nil {
+		return nil, err
+	}
+
+	body := c.PostBody()
+	req := &http.Request{
+		Method:        string(c.Method()),
+		URL:           u,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Host:          string(c.Host()),
+		RemoteAddr:    c.RemoteAddr().String(),
+		RequestURI:    string(c.RequestURI()),
+	}
+	if c.IsTLS() {
+		req.TLS = c.TLSConnectionState()
+	}
+
+	// the cookies are visited as a single Cookie header
+	c.Request.Header.VisitAll(func(key, value []byte) {
+		req.Header.Add(string(key), string(value))
+	})
+
+	return req, nil
+}
+
+// writeResponse write the response of the handlers to fasthttp. The body
+// of the attachments, such as the exported files, is streamed, and the
+// others are copied to the buffer of fasthttp.
+func writeResponse(c *fasthttp.RequestCtx, res *http.Response) {
+	for key, head := range res.Header {
+		for i, value := range head {
+			// Set of fasthttp keeps every Set-Cookie as a cookie
+			if i == 0 || key == "Set-Cookie" {
+				c.Response.Header.Set(key, value)
+			} else {
+				c.Response.Header.Add(key, value)
+			}
+		}
+	}
+	c.SetStatusCode(res.StatusCode)
+
+	if res.Body == nil {
+		return
+	}
+	if strings.HasPrefix(res.Header.Get("Content-Disposition"), "attachment") {
+		size := -1
+		if res.ContentLength > 0 {
+			size = int(res.ContentLength)
+		}
+		// fasthttp closes the body after it is sent
+		c.SetBodyStream(res.Body, size)
+		return
+	}
+	_, _ = io.Copy(c, res.Body)
+	_ = res.Body.Close()
+}
+
+// Name implements the method Adapter.Name.
+func (fast *Fasthttp) Name() string {
+	return "fasthttp"
+}
+
+// SetContext implements the method Adapter.SetContext.
+func (fast *Fasthttp) SetContext(contextInterface interface{}) adapter.WebFrameWork {
+	var (
+		ctx *fasthttp.RequestCtx
+		ok  bool
+	)
+
+	if ctx, ok = contextInterface.(*fasthttp.RequestCtx); !ok {
+		panic("fasthttp adapter SetContext: wrong parameter")
+	}
+
+	return &Fasthttp{ctx: ctx}
+}
+
+// Redirect implements the method Adapter.Redirect.
+func (fast *Fasthttp) Redirect() {
+	fast.ctx.Redirect(config.Url(config.GetLoginUrl()), http.StatusFound)
+}
+
+// SetContentType implements the method Adapter.SetContentType.
+func (fast *Fasthttp) SetContentType() {
+	fast.ctx.Response.Header.Set("Content-Type", fast.HTMLContentType())
+}
+
+// Write implements the method Adapter.Write.
+func (fast *Fasthttp) Write(body []byte) {
+	fast.ctx.SetStatusCode(http.StatusOK)
+	fast.ctx.SetBody(body)
+}
+
+// GetCookie implements the method Adapter.GetCookie.
+func (fast *Fasthttp) GetCookie() (string, error) {
+	cookie := fast.ctx.Request.Header.Cookie(fast.CookieKey())
+	if len(cookie) == 0 {
+		return "", http.ErrNoCookie
+	}
+	return string(cookie), nil
+}
+
+// Path implements the method Adapter.Path.
+func (fast *Fasthttp) Path() string {
+	return string(fast.ctx.Path())
+}
+
+// Method implements the method Adapter.Method.
+func (fast *Fasthttp) Method() string {
+	return string(fast.ctx.Method())
+}
+
+// FormParam implements the method Adapter.FormParam.
+func (fast *Fasthttp) FormParam() url.Values {
+	if form, err := fast.ctx.MultipartForm(); err == nil {
+		return form.Value
+	}
+
+	values := make(url.Values)
+	fast.ctx.PostArgs().VisitAll(func(key, value []byte) {
+		values.Add(string(key), string(value))
+	})
+	return values
+}
+
+// IsPjax implements the method Adapter.IsPjax.
+func (fast *Fasthttp) IsPjax() bool {
+	return string(fast.ctx.Request.Header.Peek(constant.PjaxHeader)) == "true"
+}
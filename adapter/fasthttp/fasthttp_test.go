@@ -0,0 +1,125 @@
+package fasthttp
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func newRequestCtx(method, uri, contentType string, body []byte) *fasthttp.RequestCtx {
+	var req fasthttp.Request
+	req.Header.SetMethod(method)
+	req.SetRequestURI(uri)
+	req.Header.SetHost("example.com")
+	if contentType != "" {
+		req.Header.SetContentType(contentType)
+	}
+	req.SetBody(body)
+
+	c := new(fasthttp.RequestCtx)
+	c.Init(&req, &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080}, nil)
+	return c
+}
+
+func TestConvertRequest(t *testing.T) {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	assert.NoError(t, mw.WriteField("name", "avatar"))
+	fw, err := mw.CreateFormFile("file", "a.txt")
+	assert.NoError(t, err)
+	_, _ = fw.Write([]byte("content"))
+	assert.NoError(t, mw.Close())
+
+	c := newRequestCtx("POST", "/admin/upload?__prefix=users", mw.FormDataContentType(), body.Bytes())
+	c.Request.Header.Set("Cookie", "go_admin_session=session; other=1")
+
+	req, err := convertRequest(c)
+	assert.NoError(t, err)
+	assert.Equal(t, "POST", req.Method)
+	assert.Equal(t, "/admin/upload", req.URL.Path)
+	assert.Equal(t, "users", req.URL.Query().Get("__prefix"))
+	assert.Equal(t, "example.com", req.Host)
+	assert.Equal(t, "127.0.0.1:8080", req.RemoteAddr)
+	assert.Equal(t, int64(body.Len()), req.ContentLength)
+
+	cookie, err := req.Cookie("go_admin_session")
+	assert.NoError(t, err)
+	assert.Equal(t, "session", cookie.Value)
+	assert.Len(t, req.Cookies(), 2)
+
+	assert.NoError(t, req.ParseMultipartForm(32<<20))
+	assert.Equal(t, "avatar", req.PostForm.Get("name"))
+	file, header, err := req.FormFile("file")
+	assert.NoError(t, err)
+	content, _ := ioutil.ReadAll(file)
+	assert.Equal(t, "a.txt", header.Filename)
+	assert.Equal(t, "content", string(content))
+}
+
+func TestWriteResponse(t *testing.T) {
+	c := newRequestCtx("GET", "/admin/login", "", nil)
+
+	writeResponse(c, &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Content-Type": {"text/html; charset=utf-8"},
+			"Set-Cookie":   {"a=1; Path=/", "b=2; Path=/"},
+			"Vary":         {"Accept", "Cookie"},
+		},
+		Body: ioutil.NopCloser(strings.NewReader("page")),
+	})
+
+	res := readResponse(t, c)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, []string{"Accept", "Cookie"}, res.Header["Vary"])
+	assert.Len(t, res.Cookies(), 2)
+	assert.Equal(t, "a", res.Cookies()[0].Name)
+	assert.Equal(t, "b", res.Cookies()[1].Name)
+	assert.False(t, c.Response.IsBodyStream())
+	assert.Equal(t, "page", readBody(t, res))
+}
+
+func TestWriteResponseAttachment(t *testing.T) {
+	c := newRequestCtx("GET", "/admin/export/users", "", nil)
+
+	writeResponse(c, &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Content-Disposition": {`attachment; filename="users.csv"`},
+		},
+		ContentLength: 8,
+		Body:          ioutil.NopCloser(strings.NewReader("id,name\n")),
+	})
+
+	// the attachment is streamed instead of copied to the buffer
+	assert.True(t, c.Response.IsBodyStream())
+
+	res := readResponse(t, c)
+	assert.Equal(t, int64(8), res.ContentLength)
+	assert.Equal(t, "id,name\n", readBody(t, res))
+}
+
+func readResponse(t *testing.T, c *fasthttp.RequestCtx) *http.Response {
+	buf := new(bytes.Buffer)
+	w := bufio.NewWriter(buf)
+	assert.NoError(t, c.Response.Write(w))
+	assert.NoError(t, w.Flush())
+
+	res, err := http.ReadResponse(bufio.NewReader(buf), nil)
+	assert.NoError(t, err)
+	return res
+}
+
+func readBody(t *testing.T, res *http.Response) string {
+	b, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+	return string(b)
+}
@@ -18,7 +18,6 @@ import (
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"net/url"
-	"strings"
 )
 
 // Gin structure value is a Gin GoAdmin adapter.
@@ -75,29 +74,28 @@ func (gins *Gin) SetApp(app interface{}) error {
 
 // AddHandler implements the method Adapter.AddHandler.
 func (gins *Gin) AddHandler(method, path string, handlers context.Handlers) {
-	gins.app.Handle(strings.ToUpper(method), path, func(c *gin.Context) {
-		ctx := context.NewContext(c.Request)
-
-		for _, param := range c.Params {
-			if c.Request.URL.RawQuery == "" {
-				c.Request.URL.RawQuery += strings.Replace(param.Key, ":", "", -1) + "=" + param.Value
-			} else {
-				c.Request.URL.RawQuery += "&" + strings.Replace(param.Key, ":", "", -1) + "=" + param.Value
-			}
+	gins.AddRoute(method, path, handlers, func(path string, catchAll bool) {
+		if catchAll {
+			path += "/*path"
 		}
+		gins.app.Any(path, gins.serve)
+	})
+}
 
-		ctx.SetHandlers(handlers).Next()
-		for key, head := range ctx.Response.Header {
-			c.Header(key, head[0])
-		}
-		if ctx.Response.Body != nil {
-			buf := new(bytes.Buffer)
-			_, _ = buf.ReadFrom(ctx.Response.Body)
-			c.String(ctx.Response.StatusCode, buf.String())
-		} else {
-			c.Status(ctx.Response.StatusCode)
+func (gins *Gin) serve(c *gin.Context) {
+	ctx := gins.Serve(c.Request)
+	for key, head := range ctx.Response.Header {
+		for _, value := range head {
+			c.Writer.Header().Add(key, value)
 		}
-	})
+	}
+	if ctx.Response.Body != nil {
+		buf := new(bytes.Buffer)
+		_, _ = buf.ReadFrom(ctx.Response.Body)
+		c.String(ctx.Response.StatusCode, buf.String())
+	} else {
+		c.Status(ctx.Response.StatusCode)
+	}
 }
 
 // Name implements the method Adapter.Name.
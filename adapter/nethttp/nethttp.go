@@ -0,0 +1,195 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package nethttp
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/wowucco/go-admin/adapter"
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/engine"
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/plugins"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+	"github.com/wowucco/go-admin/plugins/admin/modules/constant"
+	"github.com/wowucco/go-admin/template/types"
+)
+
+// NetHTTP structure value is a net/http GoAdmin adapter. The routes are
+// served by a Handler, which can be mounted to a http.ServeMux or any router
+// accepting a http.Handler:
+//
+//	h, err := engine.Default().AddConfig(cfg).Handler()
+//	mux.Handle("/admin/", h)
+type NetHTTP struct {
+	adapter.BaseAdapter
+	ctx Context
+	app *Handler
+}
+
+// Handler is the http.Handler of the adapter, the requests are routed by
+// the router of GoAdmin only, which responds 404 and 405 too.
+type Handler struct {
+	nh *NetHTTP
+}
+
+// ServeHTTP implements the http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.nh == nil {
+		http.NotFound(w, r)
+		return
+	}
+	h.nh.serve(w, r)
+}
+
+func init() {
+	engine.Register(new(NetHTTP))
+}
+
+// User implements the method Adapter.User.
+func (nh *NetHTTP) User(ctx interface{}) (models.UserModel, bool) {
+	return nh.GetUser(ctx, nh)
+}
+
+// Use implements the method Adapter.Use.
+func (nh *NetHTTP) Use(app interface{}, plugs []plugins.Plugin) error {
+	return nh.GetUse(app, plugs, nh)
+}
+
+// Content implements the method Adapter.Content.
+func (nh *NetHTTP) Content(ctx interface{}, getPanelFn types.GetPanelFn, btns ...types.Button) {
+	nh.GetContent(ctx, getPanelFn, nh, btns)
+}
+
+// NewHandler implements the method adapter.HandlerCreator.
+func (nh *NetHTTP) NewHandler() http.Handler {
+	return new(Handler)
+}
+
+// Context is the request and the response writer of a net/http handler.
+type Context struct {
+	Request  *http.Request
+	Response http.ResponseWriter
+}
+
+type HandlerFunc func(ctx Context) (types.Panel, error)
+
+func Content(handler HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := Context{
+			Request:  request,
+			Response: writer,
+		}
+		engine.Content(ctx, func(ctx interface{}) (types.Panel, error) {
+			return handler(ctx.(Context))
+		})
+	}
+}
+
+func (nh *NetHTTP) DisableLog()                {}
+func (nh *NetHTTP) Static(prefix, path string) {}
+
+// SetApp implements the method Adapter.SetApp.
+func (nh *NetHTTP) SetApp(app interface{}) error {
+	var (
+		eng *Handler
+		ok  bool
+	)
+	if eng, ok = app.(*Handler); !ok {
+		return errors.New("net/http adapter SetApp: wrong parameter")
+	}
+	eng.nh = nh
+	nh.app = eng
+	return nil
+}
+
+// AddHandler implements the method Adapter.AddHandler. The Handler serves
+// all the paths, so nothing is mounted.
+func (nh *NetHTTP) AddHandler(method, path string, handlers context.Handlers) {
+	nh.AddRoute(method, path, handlers, func(path string, catchAll bool) {})
+}
+
+func (nh *NetHTTP) serve(w http.ResponseWriter, r *http.Request) {
+	ctx := nh.Serve(r)
+	for key, head := range ctx.Response.Header {
+		for _, value := range head {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(ctx.Response.StatusCode)
+	if ctx.Response.Body != nil {
+		buf := new(bytes.Buffer)
+		_, _ = buf.ReadFrom(ctx.Response.Body)
+		_, _ = w.Write(buf.Bytes())
+	}
+}
+
+// Name implements the method Adapter.Name.
+func (nh *NetHTTP) Name() string {
+	return "net/http"
+}
+
+// SetContext implements the method Adapter.SetContext.
+func (nh *NetHTTP) SetContext(contextInterface interface{}) adapter.WebFrameWork {
+	var (
+		ctx Context
+		ok  bool
+	)
+
+	if ctx, ok = contextInterface.(Context); !ok {
+		panic("net/http adapter SetContext: wrong parameter")
+	}
+
+	return &NetHTTP{ctx: ctx}
+}
+
+// Redirect implements the method Adapter.Redirect.
+func (nh *NetHTTP) Redirect() {
+	http.Redirect(nh.ctx.Response, nh.ctx.Request, config.Url(config.GetLoginUrl()), http.StatusFound)
+}
+
+// SetContentType implements the method Adapter.SetContentType.
+func (nh *NetHTTP) SetContentType() {
+	nh.ctx.Response.Header().Set("Content-Type", nh.HTMLContentType())
+}
+
+// Write implements the method Adapter.Write.
+func (nh *NetHTTP) Write(body []byte) {
+	nh.ctx.Response.WriteHeader(http.StatusOK)
+	_, _ = nh.ctx.Response.Write(body)
+}
+
+// GetCookie implements the method Adapter.GetCookie.
+func (nh *NetHTTP) GetCookie() (string, error) {
+	cookie, err := nh.ctx.Request.Cookie(nh.CookieKey())
+	if err != nil {
+		return "", err
+	}
+	return cookie.Value, nil
+}
+
+// Path implements the method Adapter.Path.
+func (nh *NetHTTP) Path() string {
+	return nh.ctx.Request.URL.Path
+}
+
+// Method implements the method Adapter.Method.
+func (nh *NetHTTP) Method() string {
+	return nh.ctx.Request.Method
+}
+
+// FormParam implements the method Adapter.FormParam.
+func (nh *NetHTTP) FormParam() url.Values {
+	_ = nh.ctx.Request.ParseMultipartForm(32 << 20)
+	return nh.ctx.Request.PostForm
+}
+
+// IsPjax implements the method Adapter.IsPjax.
+func (nh *NetHTTP) IsPjax() bool {
+	return nh.ctx.Request.Header.Get(constant.PjaxHeader) == "true"
+}
@@ -0,0 +1,63 @@
+package nethttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wowucco/go-admin/context"
+)
+
+func TestHandler(t *testing.T) {
+	nh := new(NetHTTP)
+	h := nh.NewHandler()
+	assert.NoError(t, nh.SetApp(h))
+	assert.Error(t, nh.SetApp(http.NewServeMux()))
+
+	handle := func(name string) context.Handlers {
+		return context.Handlers{func(ctx *context.Context) {
+			ctx.WriteString(name + " " + ctx.Param("__prefix") + " " + ctx.Query("__prefix"))
+		}}
+	}
+
+	nh.AddHandler("GET", "/admin/info/:__prefix", handle("info"))
+	nh.AddHandler("GET", "/admin/info/site/edit", handle("site"))
+	nh.AddHandler("post", "/admin/edit/:__prefix", handle("update"))
+	nh.AddHandler("GET", "/admin", handle("index"))
+
+	cases := []struct {
+		method string
+		path   string
+		code   int
+		body   string
+	}{
+		{"GET", "/admin/info/users", 200, "info users users"},
+		{"GET", "/admin/info/users?__prefix=manager", 200, "info users users"},
+		{"GET", "/admin/info/site/edit", 200, "site  "},
+		{"POST", "/admin/edit/users", 200, "update users users"},
+		{"GET", "/admin", 200, "index  "},
+		{"GET", "/admin/edit/users", 405, ""},
+		{"GET", "/admin/info", 404, ""},
+		{"GET", "/other", 404, ""},
+	}
+
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(c.method, c.path, nil))
+		assert.Equal(t, c.code, w.Code, c.method+" "+c.path)
+		if c.code == 200 {
+			assert.Equal(t, c.body, w.Body.String(), c.method+" "+c.path)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/admin/edit/users", nil))
+	assert.Equal(t, "POST", w.Header().Get("Allow"))
+
+	// the routes added after the handler is created are served too
+	nh.AddHandler("GET", "/admin/new/:__prefix", handle("new"))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/admin/new/users", nil))
+	assert.Equal(t, "new users users", w.Body.String())
+}
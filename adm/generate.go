@@ -24,6 +24,17 @@ import (
 var systemGoAdminTables = []string{
 	"goadmin_menu",
 	"goadmin_operation_log",
+	"goadmin_media",
+	"goadmin_user_two_factor",
+	"goadmin_user_identities",
+	"goadmin_login_throttles",
+	"goadmin_password_histories",
+	"goadmin_password_resets",
+	"goadmin_api_tokens",
+	"goadmin_user_sessions",
+	"goadmin_permission_denies",
+	"goadmin_sync_records",
+	"goadmin_change_requests",
 	"goadmin_permissions",
 	"goadmin_role_menu",
 	"goadmin_roles",
@@ -18,6 +18,7 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"sort"
 	"strings"
 	"time"
 )
@@ -36,6 +37,7 @@ type Context struct {
 	UserValue map[string]interface{}
 	index     int8
 	handlers  Handlers
+	params    Params
 }
 
 // Path is used in the matching of request and response. Url stores the
@@ -81,6 +83,33 @@ func (ctx *Context) SetUserValue(key string, value interface{}) {
 	ctx.UserValue[key] = value
 }
 
+// Param return the value of the route param of the name, such as
+// "__prefix" of the route "/info/:__prefix".
+func (ctx *Context) Param(name string) string {
+	return ctx.params.Get(name)
+}
+
+// Params return the route params.
+func (ctx *Context) Params() Params {
+	return ctx.params
+}
+
+// SetParams set the route params, the params are also set to the url query
+// of the request, replacing the query values of the same keys, so that the
+// client can not override them.
+func (ctx *Context) SetParams(params Params) *Context {
+	ctx.params = params
+	if len(params) == 0 {
+		return ctx
+	}
+	query := ctx.Request.URL.Query()
+	for _, param := range params {
+		query.Set(param.Key, param.Value)
+	}
+	ctx.Request.URL.RawQuery = query.Encode()
+	return ctx
+}
+
 // Path return the url path.
 func (ctx *Context) Path() string {
 	return ctx.Request.URL.Path
@@ -435,7 +464,9 @@ type HandlerMap map[Path]Handlers
 
 // App is the key struct of the package. App as a member of plugin
 // entity contains the request and the corresponding handler. Prefix
-// is the url prefix and MiddlewareList is for control flow.
+// is the url prefix and MiddlewareList is for control flow. The routes
+// are also stored in the radix trees of the methods, which are used to
+// match the incoming requests.
 type App struct {
 	Requests    []Path
 	Handlers    HandlerMap
@@ -445,6 +476,7 @@ type App struct {
 	Routers    RouterMap
 	routeIndex int
 	routeANY   bool
+	trees      map[string]*node
 }
 
 // NewApp return an empty app.
@@ -456,6 +488,7 @@ func NewApp() *App {
 		Middlewares: make([]Handler, 0),
 		routeIndex:  -1,
 		Routers:     make(RouterMap),
+		trees:       make(map[string]*node),
 	}
 }
 
@@ -466,32 +499,91 @@ type Handler func(ctx *Context)
 type Handlers []Handler
 
 // AppendReqAndResp stores the request info and handle into app.
-// support the route parameter. The route parameter will be recognized as
-// wildcard store into the RegUrl of Path struct. For example:
+// support the route parameters. The named parameters match a segment of
+// the path, and the catch-all parameter matches the rest of it:
 //
-//         /user/:id      => /user/(.*)
-//         /user/:id/info => /user/(.*?)/info
+//	/user/:id          => /user/1
+//	/user/:id/info     => /user/1/info
+//	/assets/*filepath  => /assets/dist/js/app.js
 //
-// The RegUrl will be used to recognize the incoming path and find
-// the handler.
+// The static segments are matched before the parameters. It panics if the
+// route conflicts with a registered one, such as "/user/:name" after the
+// "/user/:id", or the same route is registered twice.
 func (app *App) AppendReqAndResp(url, method string, handler []Handler) {
+	app.addRoute(join(app.Prefix, url), method, append(app.Middlewares, handler...))
+}
+
+func (app *App) addRoute(url, method string, handlers Handlers) {
+	if app.trees == nil {
+		app.trees = make(map[string]*node)
+	}
+	key := strings.ToUpper(method)
+	if app.trees[key] == nil {
+		app.trees[key] = tree()
+	}
+	app.trees[key].addRoute(url, handlers)
 
 	app.Requests = append(app.Requests, Path{
-		URL:    join(app.Prefix, url),
+		URL:    url,
 		Method: method,
 	})
 	app.routeIndex++
 
 	app.Handlers[Path{
-		URL:    join(app.Prefix, url),
+		URL:    url,
 		Method: method,
-	}] = append(app.Middlewares, handler...)
+	}] = handlers
 }
 
-// Find is public helper method for findPath of tree.
+// Find return the handlers of the route matching the url and the method.
 func (app *App) Find(url, method string) []Handler {
 	app.routeANY = false
-	return app.Handlers[Path{URL: url, Method: method}]
+	handlers, _ := app.Lookup(url, method)
+	return handlers
+}
+
+// Lookup return the handlers and the params of the route matching the url
+// and the method.
+func (app *App) Lookup(url, method string) (Handlers, Params) {
+	if root := app.trees[strings.ToUpper(method)]; root != nil {
+		return root.find(url)
+	}
+	return nil, nil
+}
+
+// Allowed return the methods of the routes matching the url.
+func (app *App) Allowed(url string) []string {
+	methods := make([]string, 0)
+	for method, root := range app.trees {
+		if handlers, _ := root.find(url); handlers != nil {
+			methods = append(methods, method)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// Serve run the handlers of the route matching the request of the context
+// with the route params set. If no routes match, it responds the status
+// 405 with the allowed methods, or 404.
+func (app *App) Serve(ctx *Context) {
+	handlers, params := app.Lookup(ctx.Path(), ctx.Method())
+	if handlers != nil {
+		ctx.SetParams(params).SetHandlers(handlers).Next()
+		return
+	}
+
+	if allowed := app.Allowed(ctx.Path()); len(allowed) > 0 {
+		ctx.SetHeader("Allow", strings.Join(allowed, ", "))
+		ctx.SetContentType("text/plain; charset=utf-8")
+		ctx.SetStatusCode(http.StatusMethodNotAllowed)
+		ctx.WriteString(http.StatusText(http.StatusMethodNotAllowed))
+		return
+	}
+
+	ctx.SetContentType("text/plain; charset=utf-8")
+	ctx.SetStatusCode(http.StatusNotFound)
+	ctx.WriteString("404 page not found")
 }
 
 // POST is a shortcut for app.AppendReqAndResp(url, "post", handler).
@@ -579,30 +671,13 @@ type RouterGroup struct {
 	Prefix      string
 }
 
-// AppendReqAndResp stores the request info and handle into app.
-// support the route parameter. The route parameter will be recognized as
-// wildcard store into the RegUrl of Path struct. For example:
-//
-//         /user/:id      => /user/(.*)
-//         /user/:id/info => /user/(.*?)/info
-//
-// The RegUrl will be used to recognize the incoming path and find
-// the handler.
+// AppendReqAndResp stores the request info and handle into app, the route
+// parameters are the same as the ones of App.AppendReqAndResp.
 func (g *RouterGroup) AppendReqAndResp(url, method string, handler []Handler) {
-
-	g.app.Requests = append(g.app.Requests, Path{
-		URL:    join(g.Prefix, url),
-		Method: method,
-	})
-	g.app.routeIndex++
-
 	var h = make([]Handler, len(g.Middlewares))
 	copy(h, g.Middlewares)
 
-	g.app.Handlers[Path{
-		URL:    join(g.Prefix, url),
-		Method: method,
-	}] = append(h, handler...)
+	g.app.addRoute(join(g.Prefix, url), method, append(h, handler...))
 }
 
 // POST is a shortcut for app.AppendReqAndResp(url, "post", handler).
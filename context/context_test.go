@@ -3,6 +3,9 @@ package context
 import (
 	"fmt"
 	"github.com/magiconair/properties/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -25,43 +28,111 @@ func TestJoin(t *testing.T) {
 
 func TestTree(t *testing.T) {
 	tree := tree()
-	tree.addPath(stringToArr("/adm"), "GET", []Handler{func(ctx *Context) { fmt.Println(1) }})
-	tree.addPath(stringToArr("/admi"), "GET", []Handler{func(ctx *Context) { fmt.Println(1) }})
-	tree.addPath(stringToArr("/admin"), "GET", []Handler{func(ctx *Context) { fmt.Println(1) }})
-	tree.addPath(stringToArr("/admin/menu/new"), "POST", []Handler{func(ctx *Context) { fmt.Println(1) }})
-	tree.addPath(stringToArr("/admin/menu/new"), "GET", []Handler{func(ctx *Context) { fmt.Println(1) }})
-	tree.addPath(stringToArr("/admin/info/:__prefix"), "GET", []Handler{
+	tree.addRoute("/adm", []Handler{func(ctx *Context) { fmt.Println(1) }})
+	tree.addRoute("/admi", []Handler{func(ctx *Context) { fmt.Println(1) }})
+	tree.addRoute("/admin", []Handler{func(ctx *Context) { fmt.Println(1) }})
+	tree.addRoute("/admin/menu/new", []Handler{func(ctx *Context) { fmt.Println(1) }})
+	tree.addRoute("/admin/info/:__prefix", []Handler{
 		func(ctx *Context) { fmt.Println("auth") },
 		func(ctx *Context) { fmt.Println("init") },
 		func(ctx *Context) { fmt.Println("info") },
 	})
-	tree.addPath(stringToArr("/admin/info/:__prefix/detail"), "GET", []Handler{
+	tree.addRoute("/admin/info/:__prefix/detail", []Handler{
 		func(ctx *Context) { fmt.Println("auth") },
 		func(ctx *Context) { fmt.Println("detail") },
 	})
+	tree.addRoute("/admin/info/site/edit", []Handler{func(ctx *Context) { fmt.Println("site") }})
+	tree.addRoute("/admin/assets/*filepath", []Handler{func(ctx *Context) { fmt.Println("assets") }})
 
-	fmt.Println("/admin/menu/new", "GET")
-	h := tree.findPath(stringToArr("/admin/menu/new"), "GET")
-	assert.Equal(t, h != nil, true)
+	fmt.Println("/admin/menu/new")
+	h, _ := tree.find("/admin/menu/new")
+	assert.Equal(t, len(h), 1)
 	printHandler(h)
-	fmt.Println("/admin/menu/new", "POST")
-	h = tree.findPath(stringToArr("/admin/menu/new"), "POST")
-	assert.Equal(t, h != nil, true)
-	printHandler(h)
-	fmt.Println("/admin/me/new", "POST")
-	h = tree.findPath(stringToArr("/admin/me/new"), "POST")
+	fmt.Println("/admin/me/new")
+	h, _ = tree.find("/admin/me/new")
 	assert.Equal(t, h == nil, true)
+	fmt.Println("/admin/info/user")
+	h, ps := tree.find("/admin/info/user")
+	assert.Equal(t, len(h), 3)
+	assert.Equal(t, ps, Params{{Key: "__prefix", Value: "user"}})
 	printHandler(h)
-	fmt.Println("/admin/info/user", "GET")
-	h = tree.findPath(stringToArr("/admin/info/user"), "GET")
-	assert.Equal(t, h != nil, true)
-	printHandler(h)
-	fmt.Println("/admin/info/user/detail", "GET")
-	h = tree.findPath(stringToArr("/admin/info/user/detail"), "GET")
-	assert.Equal(t, h != nil, true)
+	fmt.Println("/admin/info/user/detail")
+	h, ps = tree.find("/admin/info/user/detail/")
+	assert.Equal(t, len(h), 2)
+	assert.Equal(t, ps.Get("__prefix"), "user")
 	printHandler(h)
-	fmt.Println("=========== printChildren ===========")
-	tree.printChildren()
+
+	h, ps = tree.find("/admin/info/site/edit")
+	assert.Equal(t, len(h), 1)
+	assert.Equal(t, len(ps), 0)
+	h, ps = tree.find("/admin/info/site/detail")
+	assert.Equal(t, len(h), 2)
+	assert.Equal(t, ps.Get("__prefix"), "site")
+	h, ps = tree.find("/admin/assets/dist/js/app.js")
+	assert.Equal(t, len(h), 1)
+	assert.Equal(t, ps.Get("filepath"), "dist/js/app.js")
+
+	for _, path := range []string{"/", "/ad", "/admin/info", "/admin/info/user/edit", "/admin/menu"} {
+		h, _ = tree.find(path)
+		assert.Equal(t, h == nil, true, path)
+	}
+}
+
+func TestTreeConflict(t *testing.T) {
+	tree := tree()
+	tree.addRoute("/admin/info/:__prefix", nil)
+	tree.addRoute("/admin/assets/*filepath", nil)
+
+	for _, pattern := range []string{
+		"/admin/info/:__prefix",
+		"/admin/info/:id/edit",
+		"/admin/assets/*path",
+		"/admin/*path/edit",
+		"/admin/:",
+		"admin",
+	} {
+		assert.Equal(t, panics(func() { tree.addRoute(pattern, nil) }), true, pattern)
+	}
+}
+
+func TestAppServe(t *testing.T) {
+	app := NewApp()
+	app.GET("/info/:__prefix", func(ctx *Context) {
+		ctx.WriteString(ctx.Param("__prefix") + " " + ctx.Query("__prefix"))
+	})
+	app.POST("/edit/:__prefix", func(ctx *Context) {})
+
+	ctx := NewContext(httptest.NewRequest("GET", "/info/a%20b?page=1", nil))
+	app.Serve(ctx)
+	assert.Equal(t, ctx.Response.StatusCode, http.StatusOK)
+	body, _ := ioutil.ReadAll(ctx.Response.Body)
+	assert.Equal(t, string(body), "a b a b")
+	assert.Equal(t, ctx.Query("page"), "1")
+
+	// the route param wins over the query of the client
+	ctx = NewContext(httptest.NewRequest("GET", "/info/users?__prefix=manager&page=2", nil))
+	app.Serve(ctx)
+	body, _ = ioutil.ReadAll(ctx.Response.Body)
+	assert.Equal(t, string(body), "users users")
+	assert.Equal(t, ctx.Request.URL.Query()["__prefix"], []string{"users"})
+	assert.Equal(t, ctx.Query("page"), "2")
+
+	ctx = NewContext(httptest.NewRequest("GET", "/edit/users", nil))
+	app.Serve(ctx)
+	assert.Equal(t, ctx.Response.StatusCode, http.StatusMethodNotAllowed)
+	assert.Equal(t, ctx.Response.Header.Get("Allow"), "POST")
+
+	ctx = NewContext(httptest.NewRequest("GET", "/new/users", nil))
+	app.Serve(ctx)
+	assert.Equal(t, ctx.Response.StatusCode, http.StatusNotFound)
+}
+
+func panics(fn func()) (panicked bool) {
+	defer func() {
+		panicked = recover() != nil
+	}()
+	fn()
+	return
 }
 
 func printHandler(h []Handler) {
@@ -4,140 +4,208 @@
 
 package context
 
-import "fmt"
+import (
+	"strings"
+)
+
+// Param is a named param of the route, such as ":__prefix" or "*filepath".
+type Param struct {
+	Key   string
+	Value string
+}
+
+// Params is the params of the matched route in the order of the pattern.
+type Params []Param
+
+// Get return the value of the param of the name, or empty string.
+func (ps Params) Get(name string) string {
+	for _, p := range ps {
+		if p.Key == name {
+			return p.Value
+		}
+	}
+	return ""
+}
 
+type nodeKind uint8
+
+const (
+	staticNode nodeKind = iota
+	paramNode
+	catchAllNode
+)
+
+// node is a node of the radix tree of the routes of a method. The static
+// nodes hold a compressed part of the path, the param nodes match a path
+// segment and the catch-all nodes match the rest of the path. When a path
+// is searched, the static children are tried before the param child, and
+// the param child before the catch-all one.
 type node struct {
-	children []*node
-	value    string
-	method   []string
-	handle   [][]Handler
+	kind     nodeKind
+	prefix   string
+	name     string
+	statics  []*node
+	param    *node
+	catchAll *node
+
+	// pattern is the registered url of the route of the node, empty if
+	// the node is not a route.
+	pattern  string
+	handlers Handlers
 }
 
 func tree() *node {
-	return &node{
-		children: make([]*node, 0),
-		value:    "/",
-		handle:   nil,
-	}
+	return &node{kind: staticNode}
 }
 
-func (n *node) hasMethod(method string) int {
-	for k, m := range n.method {
-		if m == method {
-			return k
-		}
+// addRoute add the route of the pattern to the tree. It panics if the
+// pattern is invalid or conflicts with a registered one.
+func (n *node) addRoute(pattern string, handlers Handlers) {
+	if pattern == "" || pattern[0] != '/' {
+		panic("context: route pattern must begin with '/': " + pattern)
+	}
+	leaf := n.insert(pattern, pattern)
+	if leaf.pattern != "" {
+		panic("context: route " + pattern + " conflicts with the registered route " + leaf.pattern)
 	}
-	return -1
+	leaf.pattern = pattern
+	leaf.handlers = handlers
 }
 
-func (n *node) addMethodAndHandler(method string, handler []Handler) {
-	n.method = append(n.method, method)
-	n.handle = append(n.handle, handler)
-}
+// insert add the rest path of the pattern under the node, and return the
+// node of the end of the path.
+func (n *node) insert(path, pattern string) *node {
+	if path == "" {
+		return n
+	}
 
-func (n *node) addChild(child *node) {
-	n.children = append(n.children, child)
-}
+	switch path[0] {
+	case ':':
+		end := strings.IndexByte(path, '/')
+		if end == -1 {
+			end = len(path)
+		}
+		name := path[1:end]
+		if name == "" || strings.ContainsAny(name, ":*") {
+			panic("context: invalid param name in route " + pattern)
+		}
+		if n.param == nil {
+			n.param = &node{kind: paramNode, name: name}
+		} else if n.param.name != name {
+			panic("context: param :" + name + " of route " + pattern +
+				" conflicts with the param :" + n.param.name + " of the registered routes")
+		}
+		return n.param.insert(path[end:], pattern)
+	case '*':
+		name := path[1:]
+		if name == "" || strings.ContainsAny(name, "/:*") {
+			panic("context: catch-all param must be named and at the end of route " + pattern)
+		}
+		if n.catchAll == nil {
+			n.catchAll = &node{kind: catchAllNode, name: name}
+		} else if n.catchAll.name != name {
+			panic("context: catch-all param *" + name + " of route " + pattern +
+				" conflicts with the param *" + n.catchAll.name + " of the registered routes")
+		}
+		return n.catchAll
+	}
 
-func (n *node) addContent(value string) *node {
-	var child = n.search(value)
-	if child == nil {
-		child = &node{
-			children: make([]*node, 0),
-			value:    value,
+	// the static part ends before the next param beginning a segment
+	end := len(path)
+	for i := 1; i < len(path); i++ {
+		if path[i-1] == '/' && (path[i] == ':' || path[i] == '*') {
+			end = i
+			break
 		}
-		n.addChild(child)
 	}
-	return child
-}
+	static := path[:end]
 
-func (n *node) search(value string) *node {
-	for _, child := range n.children {
-		if child.value == value || child.value == "*" {
-			return child
+	for i, child := range n.statics {
+		common := commonPrefix(child.prefix, static)
+		if common == 0 {
+			continue
 		}
+		if common < len(child.prefix) {
+			// split the child at the common prefix
+			parent := &node{kind: staticNode, prefix: child.prefix[:common]}
+			child.prefix = child.prefix[common:]
+			parent.statics = []*node{child}
+			n.statics[i] = parent
+			child = parent
+		}
+		return child.insert(path[common:], pattern)
 	}
-	return nil
+
+	child := &node{kind: staticNode, prefix: static}
+	n.statics = append(n.statics, child)
+	return child.insert(path[end:], pattern)
 }
 
-func (n *node) addPath(paths []string, method string, handler []Handler) {
-	child := n
-	for i := 0; i < len(paths); i++ {
-		child = child.addContent(paths[i])
+// search find the route node of the rest path under the node, the params
+// of the route are appended to ps.
+func (n *node) search(path string, ps Params) (*node, Params) {
+	if path == "" {
+		if n.pattern != "" {
+			return n, ps
+		}
+		if n.catchAll != nil && n.catchAll.pattern != "" {
+			return n.catchAll, append(ps, Param{Key: n.catchAll.name})
+		}
+		return nil, ps
 	}
-	child.addMethodAndHandler(method, handler)
-}
 
-func (n *node) findPath(paths []string, method string) []Handler {
-	child := n
-	for i := 0; i < len(paths); i++ {
-		child = child.search(paths[i])
-		if child == nil {
-			return nil
+	for _, child := range n.statics {
+		if child.prefix[0] != path[0] {
+			continue
 		}
+		if strings.HasPrefix(path, child.prefix) {
+			if found, params := child.search(path[len(child.prefix):], ps); found != nil {
+				return found, params
+			}
+		}
+		break
 	}
 
-	methodIndex := child.hasMethod(method)
-	if methodIndex == -1 {
-		return nil
+	if n.param != nil {
+		end := strings.IndexByte(path, '/')
+		if end == -1 {
+			end = len(path)
+		}
+		if end > 0 {
+			if found, params := n.param.search(path[end:], append(ps, Param{Key: n.param.name, Value: path[:end]})); found != nil {
+				return found, params
+			}
+		}
 	}
 
-	return child.handle[methodIndex]
-}
+	if n.catchAll != nil && n.catchAll.pattern != "" {
+		return n.catchAll, append(ps, Param{Key: n.catchAll.name, Value: path})
+	}
 
-func (n *node) print() {
-	fmt.Println(n)
+	return nil, ps
 }
 
-func (n *node) printChildren() {
-	n.print()
-	for _, child := range n.children {
-		child.printChildren()
+// find return the handlers and the params of the route matching the path.
+// The trailing slash of the path is ignored if no routes match it.
+func (n *node) find(path string) (Handlers, Params) {
+	if found, ps := n.search(path, nil); found != nil {
+		return found.handlers, ps
 	}
-}
-
-func (n *node) printLeafChildren() {
-	if len(n.handle) > 0 {
-		n.print()
+	if len(path) > 1 && path[len(path)-1] == '/' {
+		path = path[:len(path)-1]
+	} else {
+		path += "/"
 	}
-	for _, child := range n.children {
-		child.printLeafChildren()
+	if found, ps := n.search(path, nil); found != nil {
+		return found.handlers, ps
 	}
+	return nil, nil
 }
 
-func stringToArr(path string) []string {
-	var (
-		paths      = make([]string, 0)
-		start      = 0
-		end        int
-		isWildcard = false
-	)
-	for i := 0; i < len(path); i++ {
-		if i == 0 && path[0] == '/' {
-			start = 1
-			continue
-		}
-		if path[i] == ':' {
-			isWildcard = true
-		}
-		if i == len(path)-1 {
-			end = i + 1
-			if isWildcard {
-				paths = append(paths, "*")
-			} else {
-				paths = append(paths, path[start:end])
-			}
-		}
-		if path[i] == '/' {
-			end = i
-			if isWildcard {
-				paths = append(paths, "*")
-			} else {
-				paths = append(paths, path[start:end])
-			}
-			start = i + 1
-			isWildcard = false
-		}
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
 	}
-	return paths
+	return i
 }
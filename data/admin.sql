@@ -64,14 +64,46 @@ DROP TABLE IF EXISTS `goadmin_operation_log`;
 CREATE TABLE `goadmin_operation_log` (
   `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
   `user_id` int(11) unsigned NOT NULL,
+  `impersonator_id` int(11) unsigned NOT NULL DEFAULT '0',
+  `prefix` varchar(100) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `record_id` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `action` varchar(20) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
   `path` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,
   `method` varchar(10) COLLATE utf8mb4_unicode_ci NOT NULL,
   `ip` varchar(15) COLLATE utf8mb4_unicode_ci NOT NULL,
   `input` text COLLATE utf8mb4_unicode_ci NOT NULL,
+  `status` int(11) NOT NULL DEFAULT '0',
   `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
-  KEY `admin_operation_log_user_id_index` (`user_id`)
+  KEY `admin_operation_log_user_id_index` (`user_id`),
+  KEY `admin_operation_log_prefix_index` (`prefix`),
+  KEY `admin_operation_log_created_at_index` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+
+# Dump of table goadmin_media
+# ------------------------------------------------------------
+
+DROP TABLE IF EXISTS `goadmin_media`;
+
+CREATE TABLE `goadmin_media` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` int(11) unsigned NOT NULL DEFAULT '0',
+  `engine` varchar(50) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `prefix` varchar(100) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `field` varchar(100) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `path` varchar(500) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `name` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `size` bigint(20) unsigned NOT NULL DEFAULT '0',
+  `mime` varchar(100) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `variants` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `refs` int(11) unsigned NOT NULL DEFAULT '0',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `admin_media_path_index` (`path`(191)),
+  KEY `admin_media_user_id_index` (`user_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
 
@@ -209,6 +241,8 @@ CREATE TABLE `goadmin_roles` (
   `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
   `name` varchar(50) COLLATE utf8mb4_unicode_ci NOT NULL,
   `slug` varchar(50) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `parent_id` int(11) unsigned NOT NULL DEFAULT '0',
+  `two_factor` tinyint(4) unsigned NOT NULL DEFAULT '0',
   `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
@@ -268,6 +302,208 @@ VALUES
 UNLOCK TABLES;
 
 
+# Dump of table goadmin_password_histories
+# ------------------------------------------------------------
+
+DROP TABLE IF EXISTS `goadmin_password_histories`;
+
+CREATE TABLE `goadmin_password_histories` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` int(11) unsigned NOT NULL,
+  `password` varchar(100) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `admin_password_histories_user_id_index` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+
+
+# Dump of table goadmin_password_resets
+# ------------------------------------------------------------
+
+DROP TABLE IF EXISTS `goadmin_password_resets`;
+
+CREATE TABLE `goadmin_password_resets` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` int(11) unsigned NOT NULL,
+  `token` varchar(64) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `expires_at` bigint(20) unsigned NOT NULL DEFAULT '0',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `admin_password_resets_token_unique` (`token`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+
+
+# Dump of table goadmin_api_tokens
+# ------------------------------------------------------------
+
+DROP TABLE IF EXISTS `goadmin_api_tokens`;
+
+CREATE TABLE `goadmin_api_tokens` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` int(11) unsigned NOT NULL,
+  `name` varchar(100) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `token` varchar(64) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `scopes` text COLLATE utf8mb4_unicode_ci,
+  `expires_at` bigint(20) unsigned NOT NULL DEFAULT '0',
+  `last_used_at` bigint(20) unsigned NOT NULL DEFAULT '0',
+  `last_used_ip` varchar(50) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `revoked_at` bigint(20) unsigned NOT NULL DEFAULT '0',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `admin_api_tokens_token_unique` (`token`),
+  KEY `admin_api_tokens_user_id_index` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+
+
+# Dump of table goadmin_user_sessions
+# ------------------------------------------------------------
+
+DROP TABLE IF EXISTS `goadmin_user_sessions`;
+
+CREATE TABLE `goadmin_user_sessions` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` int(11) unsigned NOT NULL,
+  `session_id` varchar(64) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `ip` varchar(50) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `user_agent` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `last_seen_at` bigint(20) unsigned NOT NULL DEFAULT '0',
+  `expires_at` bigint(20) unsigned NOT NULL DEFAULT '0',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `admin_user_sessions_session_id_unique` (`session_id`),
+  KEY `admin_user_sessions_user_id_index` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+
+
+# Dump of table goadmin_permission_denies
+# ------------------------------------------------------------
+
+DROP TABLE IF EXISTS `goadmin_permission_denies`;
+
+CREATE TABLE `goadmin_permission_denies` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `role_id` int(11) unsigned NOT NULL DEFAULT '0',
+  `user_id` int(11) unsigned NOT NULL DEFAULT '0',
+  `permission_id` int(11) unsigned NOT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `admin_permission_denies_role_id_index` (`role_id`),
+  KEY `admin_permission_denies_user_id_index` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+
+
+# Dump of table goadmin_sync_records
+# ------------------------------------------------------------
+
+DROP TABLE IF EXISTS `goadmin_sync_records`;
+
+CREATE TABLE `goadmin_sync_records` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `kind` varchar(20) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `record_id` int(11) unsigned NOT NULL,
+  `checksum` varchar(64) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `admin_sync_records_kind_record_id_unique` (`kind`,`record_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+
+
+# Dump of table goadmin_change_requests
+# ------------------------------------------------------------
+
+DROP TABLE IF EXISTS `goadmin_change_requests`;
+
+CREATE TABLE `goadmin_change_requests` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `prefix` varchar(100) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `kind` varchar(10) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `record_id` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `permission` varchar(100) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `data` text COLLATE utf8mb4_unicode_ci NOT NULL,
+  `diff` text COLLATE utf8mb4_unicode_ci NOT NULL,
+  `status` varchar(20) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'pending',
+  `requester_id` int(11) unsigned NOT NULL DEFAULT '0',
+  `approver_id` int(11) unsigned NOT NULL DEFAULT '0',
+  `comment` varchar(1000) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `admin_change_requests_status_index` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+
+
+# Dump of table goadmin_login_throttles
+# ------------------------------------------------------------
+
+DROP TABLE IF EXISTS `goadmin_login_throttles`;
+
+CREATE TABLE `goadmin_login_throttles` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `kind` varchar(20) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `value` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `failures` int(11) unsigned NOT NULL DEFAULT '0',
+  `locked_until` bigint(20) unsigned NOT NULL DEFAULT '0',
+  `last_failed_at` bigint(20) unsigned NOT NULL DEFAULT '0',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `admin_login_throttles_kind_value_unique` (`kind`,`value`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+
+
+# Dump of table goadmin_user_identities
+# ------------------------------------------------------------
+
+DROP TABLE IF EXISTS `goadmin_user_identities`;
+
+CREATE TABLE `goadmin_user_identities` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` int(11) unsigned NOT NULL,
+  `provider` varchar(50) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `subject` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `admin_user_identities_provider_subject_unique` (`provider`,`subject`),
+  KEY `admin_user_identities_user_id_index` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+
+
+# Dump of table goadmin_user_two_factor
+# ------------------------------------------------------------
+
+DROP TABLE IF EXISTS `goadmin_user_two_factor`;
+
+CREATE TABLE `goadmin_user_two_factor` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` int(11) unsigned NOT NULL,
+  `secret` varchar(100) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `enabled` tinyint(4) unsigned NOT NULL DEFAULT '0',
+  `recovery_codes` text COLLATE utf8mb4_unicode_ci,
+  `last_step` bigint(20) unsigned NOT NULL DEFAULT '0',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `admin_user_two_factor_user_id_unique` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+
+
 # Dump of table goadmin_users
 # ------------------------------------------------------------
 
@@ -279,6 +515,7 @@ CREATE TABLE `goadmin_users` (
   `password` varchar(100) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
   `name` varchar(100) COLLATE utf8mb4_unicode_ci NOT NULL,
   `avatar` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `email` varchar(100) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
   `remember_token` varchar(100) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
   `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
@@ -0,0 +1,13 @@
+CREATE TABLE[goadmin_media] (
+ [id] int   identity(1,1) ,
+ [user_id] int   NOT NULL DEFAULT 0,
+ [engine] varchar(50)   NOT NULL DEFAULT '',
+ [path] varchar(500)   NOT NULL,
+ [name] varchar(255)   NOT NULL DEFAULT '',
+ [size] bigint   NOT NULL DEFAULT 0,
+ [mime] varchar(100)   NOT NULL DEFAULT '',
+ [refs] int   NOT NULL DEFAULT 0,
+ [created_at] datetime NULL DEFAULT GETDATE(),
+ [updated_at] datetime NULL DEFAULT GETDATE(),
+  PRIMARY KEY ([id]),
+)
@@ -0,0 +1,46 @@
+--
+-- Name: goadmin_media_myid_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+CREATE SEQUENCE public.goadmin_media_myid_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    MAXVALUE 99999999
+    CACHE 1;
+
+
+ALTER TABLE public.goadmin_media_myid_seq OWNER TO postgres;
+
+--
+-- Name: goadmin_media; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.goadmin_media (
+    id integer DEFAULT nextval('public.goadmin_media_myid_seq'::regclass) NOT NULL,
+    user_id integer DEFAULT 0 NOT NULL,
+    engine character varying(50) DEFAULT ''::character varying NOT NULL,
+    path character varying(500) NOT NULL,
+    name character varying(255) DEFAULT ''::character varying NOT NULL,
+    size bigint DEFAULT 0 NOT NULL,
+    mime character varying(100) DEFAULT ''::character varying NOT NULL,
+    refs integer DEFAULT 0 NOT NULL,
+    created_at timestamp without time zone DEFAULT now(),
+    updated_at timestamp without time zone DEFAULT now()
+);
+
+
+ALTER TABLE public.goadmin_media OWNER TO postgres;
+
+--
+-- Name: goadmin_media goadmin_media_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.goadmin_media
+    ADD CONSTRAINT goadmin_media_pkey PRIMARY KEY (id);
+
+--
+-- Name: admin_media_path_index; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX admin_media_path_index ON public.goadmin_media USING btree (path);
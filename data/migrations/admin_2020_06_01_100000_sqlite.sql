@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "goadmin_media" (
+`id` integer PRIMARY KEY autoincrement,
+`user_id` INT NOT NULL DEFAULT '0',
+`engine` CHAR(50) COLLATE NOCASE NOT NULL DEFAULT '',
+`path` CHAR(500) COLLATE NOCASE NOT NULL,
+`name` CHAR(255) COLLATE NOCASE NOT NULL DEFAULT '',
+`size` INTEGER NOT NULL DEFAULT '0',
+`mime` CHAR(100) COLLATE NOCASE NOT NULL DEFAULT '',
+`refs` INT NOT NULL DEFAULT '0',
+`created_at` TIMESTAMP default CURRENT_TIMESTAMP,
+`updated_at` TIMESTAMP default CURRENT_TIMESTAMP
+);
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE[goadmin_user_two_factor] (
+ [id] int   identity(1,1) ,
+ [user_id] int   NOT NULL,
+ [secret] varchar(100)   NOT NULL DEFAULT '',
+ [enabled] tinyint   NOT NULL DEFAULT 0,
+ [recovery_codes] text NULL,
+ [last_step] bigint   NOT NULL DEFAULT 0,
+ [created_at] datetime NULL DEFAULT GETDATE(),
+ [updated_at] datetime NULL DEFAULT GETDATE(),
+  PRIMARY KEY ([id]),
+)
+
+ALTER TABLE [goadmin_roles] ADD [two_factor] tinyint NOT NULL DEFAULT 0
@@ -0,0 +1,14 @@
+CREATE TABLE `goadmin_user_two_factor` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` int(11) unsigned NOT NULL,
+  `secret` varchar(100) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '',
+  `enabled` tinyint(4) unsigned NOT NULL DEFAULT '0',
+  `recovery_codes` text COLLATE utf8mb4_unicode_ci,
+  `last_step` bigint(20) unsigned NOT NULL DEFAULT '0',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `admin_user_two_factor_user_id_unique` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE `goadmin_roles` ADD COLUMN `two_factor` tinyint(4) unsigned NOT NULL DEFAULT '0' AFTER `slug`;
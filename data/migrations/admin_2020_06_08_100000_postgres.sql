@@ -0,0 +1,50 @@
+--
+-- Name: goadmin_user_two_factor_myid_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+CREATE SEQUENCE public.goadmin_user_two_factor_myid_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    MAXVALUE 99999999
+    CACHE 1;
+
+
+ALTER TABLE public.goadmin_user_two_factor_myid_seq OWNER TO postgres;
+
+--
+-- Name: goadmin_user_two_factor; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.goadmin_user_two_factor (
+    id integer DEFAULT nextval('public.goadmin_user_two_factor_myid_seq'::regclass) NOT NULL,
+    user_id integer NOT NULL,
+    secret character varying(100) DEFAULT ''::character varying NOT NULL,
+    enabled smallint DEFAULT 0 NOT NULL,
+    recovery_codes text,
+    last_step bigint DEFAULT 0 NOT NULL,
+    created_at timestamp without time zone DEFAULT now(),
+    updated_at timestamp without time zone DEFAULT now()
+);
+
+
+ALTER TABLE public.goadmin_user_two_factor OWNER TO postgres;
+
+--
+-- Name: goadmin_user_two_factor goadmin_user_two_factor_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.goadmin_user_two_factor
+    ADD CONSTRAINT goadmin_user_two_factor_pkey PRIMARY KEY (id);
+
+--
+-- Name: admin_user_two_factor_user_id_unique; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE UNIQUE INDEX admin_user_two_factor_user_id_unique ON public.goadmin_user_two_factor USING btree (user_id);
+
+--
+-- Name: goadmin_roles two_factor; Type: COLUMN; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.goadmin_roles ADD COLUMN two_factor smallint DEFAULT 0 NOT NULL;
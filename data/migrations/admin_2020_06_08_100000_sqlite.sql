@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS "goadmin_user_two_factor" (
+`id` integer PRIMARY KEY autoincrement,
+`user_id` INT NOT NULL,
+`secret` CHAR(100) NOT NULL DEFAULT '',
+`enabled` INT NOT NULL DEFAULT '0',
+`recovery_codes` TEXT,
+`last_step` INTEGER NOT NULL DEFAULT '0',
+`created_at` TIMESTAMP default CURRENT_TIMESTAMP,
+`updated_at` TIMESTAMP default CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "admin_user_two_factor_user_id_unique" ON "goadmin_user_two_factor" ("user_id");
+
+ALTER TABLE "goadmin_roles" ADD COLUMN `two_factor` INT NOT NULL DEFAULT '0';
@@ -0,0 +1,9 @@
+CREATE TABLE[goadmin_user_identities] (
+ [id] int   identity(1,1) ,
+ [user_id] int   NOT NULL,
+ [provider] varchar(50)   NOT NULL,
+ [subject] varchar(255)   NOT NULL,
+ [created_at] datetime NULL DEFAULT GETDATE(),
+ [updated_at] datetime NULL DEFAULT GETDATE(),
+  PRIMARY KEY ([id]),
+)
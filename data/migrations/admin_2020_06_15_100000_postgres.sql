@@ -0,0 +1,48 @@
+--
+-- Name: goadmin_user_identities_myid_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+CREATE SEQUENCE public.goadmin_user_identities_myid_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    MAXVALUE 99999999
+    CACHE 1;
+
+
+ALTER TABLE public.goadmin_user_identities_myid_seq OWNER TO postgres;
+
+--
+-- Name: goadmin_user_identities; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.goadmin_user_identities (
+    id integer DEFAULT nextval('public.goadmin_user_identities_myid_seq'::regclass) NOT NULL,
+    user_id integer NOT NULL,
+    provider character varying(50) NOT NULL,
+    subject character varying(255) NOT NULL,
+    created_at timestamp without time zone DEFAULT now(),
+    updated_at timestamp without time zone DEFAULT now()
+);
+
+
+ALTER TABLE public.goadmin_user_identities OWNER TO postgres;
+
+--
+-- Name: goadmin_user_identities goadmin_user_identities_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.goadmin_user_identities
+    ADD CONSTRAINT goadmin_user_identities_pkey PRIMARY KEY (id);
+
+--
+-- Name: admin_user_identities_provider_subject_unique; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE UNIQUE INDEX admin_user_identities_provider_subject_unique ON public.goadmin_user_identities USING btree (provider, subject);
+
+--
+-- Name: admin_user_identities_user_id_index; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX admin_user_identities_user_id_index ON public.goadmin_user_identities USING btree (user_id);
@@ -0,0 +1,11 @@
+CREATE TABLE[goadmin_login_throttles] (
+ [id] int   identity(1,1) ,
+ [kind] varchar(20)   NOT NULL,
+ [value] varchar(255)   NOT NULL,
+ [failures] int   NOT NULL DEFAULT 0,
+ [locked_until] bigint   NOT NULL DEFAULT 0,
+ [last_failed_at] bigint   NOT NULL DEFAULT 0,
+ [created_at] datetime NULL DEFAULT GETDATE(),
+ [updated_at] datetime NULL DEFAULT GETDATE(),
+  PRIMARY KEY ([id]),
+)
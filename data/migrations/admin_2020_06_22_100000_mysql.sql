@@ -0,0 +1,12 @@
+CREATE TABLE `goadmin_login_throttles` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `kind` varchar(20) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `value` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  `failures` int(11) unsigned NOT NULL DEFAULT '0',
+  `locked_until` bigint(20) unsigned NOT NULL DEFAULT '0',
+  `last_failed_at` bigint(20) unsigned NOT NULL DEFAULT '0',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `admin_login_throttles_kind_value_unique` (`kind`,`value`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
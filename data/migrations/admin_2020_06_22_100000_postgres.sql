@@ -0,0 +1,44 @@
+--
+-- Name: goadmin_login_throttles_myid_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+CREATE SEQUENCE public.goadmin_login_throttles_myid_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    MAXVALUE 99999999
+    CACHE 1;
+
+
+ALTER TABLE public.goadmin_login_throttles_myid_seq OWNER TO postgres;
+
+--
+-- Name: goadmin_login_throttles; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.goadmin_login_throttles (
+    id integer DEFAULT nextval('public.goadmin_login_throttles_myid_seq'::regclass) NOT NULL,
+    kind character varying(20) NOT NULL,
+    value character varying(255) NOT NULL,
+    failures integer DEFAULT 0 NOT NULL,
+    locked_until bigint DEFAULT 0 NOT NULL,
+    last_failed_at bigint DEFAULT 0 NOT NULL,
+    created_at timestamp without time zone DEFAULT now(),
+    updated_at timestamp without time zone DEFAULT now()
+);
+
+
+ALTER TABLE public.goadmin_login_throttles OWNER TO postgres;
+
+--
+-- Name: goadmin_login_throttles goadmin_login_throttles_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.goadmin_login_throttles
+    ADD CONSTRAINT goadmin_login_throttles_pkey PRIMARY KEY (id);
+
+--
+-- Name: admin_login_throttles_kind_value_unique; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE UNIQUE INDEX admin_login_throttles_kind_value_unique ON public.goadmin_login_throttles USING btree (kind, value);
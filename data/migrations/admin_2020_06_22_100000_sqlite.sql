@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "goadmin_login_throttles" (
+`id` integer PRIMARY KEY autoincrement,
+`kind` CHAR(20) NOT NULL,
+`value` CHAR(255) NOT NULL,
+`failures` INT NOT NULL DEFAULT '0',
+`locked_until` INTEGER NOT NULL DEFAULT '0',
+`last_failed_at` INTEGER NOT NULL DEFAULT '0',
+`created_at` TIMESTAMP default CURRENT_TIMESTAMP,
+`updated_at` TIMESTAMP default CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "admin_login_throttles_kind_value_unique" ON "goadmin_login_throttles" ("kind", "value");
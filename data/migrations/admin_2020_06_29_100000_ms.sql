@@ -0,0 +1,20 @@
+CREATE TABLE[goadmin_password_histories] (
+ [id] int   identity(1,1) ,
+ [user_id] int   NOT NULL,
+ [password] varchar(100)   NOT NULL DEFAULT '',
+ [created_at] datetime NULL DEFAULT GETDATE(),
+ [updated_at] datetime NULL DEFAULT GETDATE(),
+  PRIMARY KEY ([id]),
+)
+
+CREATE TABLE[goadmin_password_resets] (
+ [id] int   identity(1,1) ,
+ [user_id] int   NOT NULL,
+ [token] varchar(64)   NOT NULL,
+ [expires_at] bigint   NOT NULL DEFAULT 0,
+ [created_at] datetime NULL DEFAULT GETDATE(),
+ [updated_at] datetime NULL DEFAULT GETDATE(),
+  PRIMARY KEY ([id]),
+)
+
+ALTER TABLE [goadmin_users] ADD [email] varchar(100) NOT NULL DEFAULT ''
@@ -0,0 +1,90 @@
+--
+-- Name: goadmin_password_histories_myid_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+CREATE SEQUENCE public.goadmin_password_histories_myid_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    MAXVALUE 99999999
+    CACHE 1;
+
+
+ALTER TABLE public.goadmin_password_histories_myid_seq OWNER TO postgres;
+
+--
+-- Name: goadmin_password_histories; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.goadmin_password_histories (
+    id integer DEFAULT nextval('public.goadmin_password_histories_myid_seq'::regclass) NOT NULL,
+    user_id integer NOT NULL,
+    password character varying(100) DEFAULT ''::character varying NOT NULL,
+    created_at timestamp without time zone DEFAULT now(),
+    updated_at timestamp without time zone DEFAULT now()
+);
+
+
+ALTER TABLE public.goadmin_password_histories OWNER TO postgres;
+
+--
+-- Name: goadmin_password_histories goadmin_password_histories_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.goadmin_password_histories
+    ADD CONSTRAINT goadmin_password_histories_pkey PRIMARY KEY (id);
+
+--
+-- Name: admin_password_histories_user_id_index; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX admin_password_histories_user_id_index ON public.goadmin_password_histories USING btree (user_id);
+
+--
+-- Name: goadmin_password_resets_myid_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+CREATE SEQUENCE public.goadmin_password_resets_myid_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    MAXVALUE 99999999
+    CACHE 1;
+
+
+ALTER TABLE public.goadmin_password_resets_myid_seq OWNER TO postgres;
+
+--
+-- Name: goadmin_password_resets; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.goadmin_password_resets (
+    id integer DEFAULT nextval('public.goadmin_password_resets_myid_seq'::regclass) NOT NULL,
+    user_id integer NOT NULL,
+    token character varying(64) NOT NULL,
+    expires_at bigint DEFAULT 0 NOT NULL,
+    created_at timestamp without time zone DEFAULT now(),
+    updated_at timestamp without time zone DEFAULT now()
+);
+
+
+ALTER TABLE public.goadmin_password_resets OWNER TO postgres;
+
+--
+-- Name: goadmin_password_resets goadmin_password_resets_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.goadmin_password_resets
+    ADD CONSTRAINT goadmin_password_resets_pkey PRIMARY KEY (id);
+
+--
+-- Name: admin_password_resets_token_unique; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE UNIQUE INDEX admin_password_resets_token_unique ON public.goadmin_password_resets USING btree (token);
+
+--
+-- Name: goadmin_users email; Type: COLUMN; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.goadmin_users ADD COLUMN email character varying(100) DEFAULT ''::character varying NOT NULL;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS "goadmin_password_histories" (
+`id` integer PRIMARY KEY autoincrement,
+`user_id` INT NOT NULL,
+`password` CHAR(100) NOT NULL DEFAULT '',
+`created_at` TIMESTAMP default CURRENT_TIMESTAMP,
+`updated_at` TIMESTAMP default CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "admin_password_histories_user_id_index" ON "goadmin_password_histories" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "goadmin_password_resets" (
+`id` integer PRIMARY KEY autoincrement,
+`user_id` INT NOT NULL,
+`token` CHAR(64) NOT NULL,
+`expires_at` INTEGER NOT NULL DEFAULT '0',
+`created_at` TIMESTAMP default CURRENT_TIMESTAMP,
+`updated_at` TIMESTAMP default CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "admin_password_resets_token_unique" ON "goadmin_password_resets" ("token");
+
+ALTER TABLE "goadmin_users" ADD COLUMN `email` CHAR(100) NOT NULL DEFAULT '';
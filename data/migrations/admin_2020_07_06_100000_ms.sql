@@ -0,0 +1,14 @@
+CREATE TABLE[goadmin_api_tokens] (
+ [id] int   identity(1,1) ,
+ [user_id] int   NOT NULL,
+ [name] varchar(100)   NOT NULL DEFAULT '',
+ [token] varchar(64)   NOT NULL,
+ [scopes] text NULL,
+ [expires_at] bigint   NOT NULL DEFAULT 0,
+ [last_used_at] bigint   NOT NULL DEFAULT 0,
+ [last_used_ip] varchar(50)   NOT NULL DEFAULT '',
+ [revoked_at] bigint   NOT NULL DEFAULT 0,
+ [created_at] datetime NULL DEFAULT GETDATE(),
+ [updated_at] datetime NULL DEFAULT GETDATE(),
+  PRIMARY KEY ([id]),
+)
@@ -0,0 +1,53 @@
+--
+-- Name: goadmin_api_tokens_myid_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+CREATE SEQUENCE public.goadmin_api_tokens_myid_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    MAXVALUE 99999999
+    CACHE 1;
+
+
+ALTER TABLE public.goadmin_api_tokens_myid_seq OWNER TO postgres;
+
+--
+-- Name: goadmin_api_tokens; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.goadmin_api_tokens (
+    id integer DEFAULT nextval('public.goadmin_api_tokens_myid_seq'::regclass) NOT NULL,
+    user_id integer NOT NULL,
+    name character varying(100) DEFAULT ''::character varying NOT NULL,
+    token character varying(64) NOT NULL,
+    scopes text,
+    expires_at bigint DEFAULT 0 NOT NULL,
+    last_used_at bigint DEFAULT 0 NOT NULL,
+    last_used_ip character varying(50) DEFAULT ''::character varying NOT NULL,
+    revoked_at bigint DEFAULT 0 NOT NULL,
+    created_at timestamp without time zone DEFAULT now(),
+    updated_at timestamp without time zone DEFAULT now()
+);
+
+
+ALTER TABLE public.goadmin_api_tokens OWNER TO postgres;
+
+--
+-- Name: goadmin_api_tokens goadmin_api_tokens_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.goadmin_api_tokens
+    ADD CONSTRAINT goadmin_api_tokens_pkey PRIMARY KEY (id);
+
+--
+-- Name: admin_api_tokens_token_unique; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE UNIQUE INDEX admin_api_tokens_token_unique ON public.goadmin_api_tokens USING btree (token);
+
+--
+-- Name: admin_api_tokens_user_id_index; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX admin_api_tokens_user_id_index ON public.goadmin_api_tokens USING btree (user_id);
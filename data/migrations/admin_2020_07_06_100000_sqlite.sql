@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS "goadmin_api_tokens" (
+`id` integer PRIMARY KEY autoincrement,
+`user_id` INT NOT NULL,
+`name` CHAR(100) NOT NULL DEFAULT '',
+`token` CHAR(64) NOT NULL,
+`scopes` TEXT,
+`expires_at` INTEGER NOT NULL DEFAULT '0',
+`last_used_at` INTEGER NOT NULL DEFAULT '0',
+`last_used_ip` CHAR(50) NOT NULL DEFAULT '',
+`revoked_at` INTEGER NOT NULL DEFAULT '0',
+`created_at` TIMESTAMP default CURRENT_TIMESTAMP,
+`updated_at` TIMESTAMP default CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "admin_api_tokens_token_unique" ON "goadmin_api_tokens" ("token");
+
+CREATE INDEX IF NOT EXISTS "admin_api_tokens_user_id_index" ON "goadmin_api_tokens" ("user_id");
@@ -0,0 +1,12 @@
+CREATE TABLE[goadmin_user_sessions] (
+ [id] int   identity(1,1) ,
+ [user_id] int   NOT NULL,
+ [session_id] varchar(64)   NOT NULL,
+ [ip] varchar(50)   NOT NULL DEFAULT '',
+ [user_agent] varchar(255)   NOT NULL DEFAULT '',
+ [last_seen_at] bigint   NOT NULL DEFAULT 0,
+ [expires_at] bigint   NOT NULL DEFAULT 0,
+ [created_at] datetime NULL DEFAULT GETDATE(),
+ [updated_at] datetime NULL DEFAULT GETDATE(),
+  PRIMARY KEY ([id]),
+)
@@ -0,0 +1,51 @@
+--
+-- Name: goadmin_user_sessions_myid_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+CREATE SEQUENCE public.goadmin_user_sessions_myid_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    MAXVALUE 99999999
+    CACHE 1;
+
+
+ALTER TABLE public.goadmin_user_sessions_myid_seq OWNER TO postgres;
+
+--
+-- Name: goadmin_user_sessions; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.goadmin_user_sessions (
+    id integer DEFAULT nextval('public.goadmin_user_sessions_myid_seq'::regclass) NOT NULL,
+    user_id integer NOT NULL,
+    session_id character varying(64) NOT NULL,
+    ip character varying(50) DEFAULT ''::character varying NOT NULL,
+    user_agent character varying(255) DEFAULT ''::character varying NOT NULL,
+    last_seen_at bigint DEFAULT 0 NOT NULL,
+    expires_at bigint DEFAULT 0 NOT NULL,
+    created_at timestamp without time zone DEFAULT now(),
+    updated_at timestamp without time zone DEFAULT now()
+);
+
+
+ALTER TABLE public.goadmin_user_sessions OWNER TO postgres;
+
+--
+-- Name: goadmin_user_sessions goadmin_user_sessions_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.goadmin_user_sessions
+    ADD CONSTRAINT goadmin_user_sessions_pkey PRIMARY KEY (id);
+
+--
+-- Name: admin_user_sessions_session_id_unique; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE UNIQUE INDEX admin_user_sessions_session_id_unique ON public.goadmin_user_sessions USING btree (session_id);
+
+--
+-- Name: admin_user_sessions_user_id_index; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX admin_user_sessions_user_id_index ON public.goadmin_user_sessions USING btree (user_id);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS "goadmin_user_sessions" (
+`id` integer PRIMARY KEY autoincrement,
+`user_id` INT NOT NULL,
+`session_id` CHAR(64) NOT NULL,
+`ip` CHAR(50) NOT NULL DEFAULT '',
+`user_agent` CHAR(255) NOT NULL DEFAULT '',
+`last_seen_at` INTEGER NOT NULL DEFAULT '0',
+`expires_at` INTEGER NOT NULL DEFAULT '0',
+`created_at` TIMESTAMP default CURRENT_TIMESTAMP,
+`updated_at` TIMESTAMP default CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "admin_user_sessions_session_id_unique" ON "goadmin_user_sessions" ("session_id");
+
+CREATE INDEX IF NOT EXISTS "admin_user_sessions_user_id_index" ON "goadmin_user_sessions" ("user_id");
@@ -0,0 +1,5 @@
+--
+-- Name: goadmin_operation_log impersonator_id; Type: COLUMN; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.goadmin_operation_log ADD COLUMN impersonator_id integer DEFAULT 0 NOT NULL;
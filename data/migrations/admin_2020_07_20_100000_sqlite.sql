@@ -0,0 +1 @@
+ALTER TABLE "goadmin_operation_log" ADD COLUMN `impersonator_id` INT NOT NULL DEFAULT '0';
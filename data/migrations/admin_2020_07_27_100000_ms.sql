@@ -0,0 +1,11 @@
+CREATE TABLE[goadmin_permission_denies] (
+ [id] int   identity(1,1) ,
+ [role_id] int   NOT NULL DEFAULT 0,
+ [user_id] int   NOT NULL DEFAULT 0,
+ [permission_id] int   NOT NULL,
+ [created_at] datetime NULL DEFAULT GETDATE(),
+ [updated_at] datetime NULL DEFAULT GETDATE(),
+  PRIMARY KEY ([id]),
+)
+
+ALTER TABLE [goadmin_roles] ADD [parent_id] int NOT NULL DEFAULT 0
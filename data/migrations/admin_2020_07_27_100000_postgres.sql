@@ -0,0 +1,54 @@
+--
+-- Name: goadmin_permission_denies_myid_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+CREATE SEQUENCE public.goadmin_permission_denies_myid_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    MAXVALUE 99999999
+    CACHE 1;
+
+
+ALTER TABLE public.goadmin_permission_denies_myid_seq OWNER TO postgres;
+
+--
+-- Name: goadmin_permission_denies; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.goadmin_permission_denies (
+    id integer DEFAULT nextval('public.goadmin_permission_denies_myid_seq'::regclass) NOT NULL,
+    role_id integer DEFAULT 0 NOT NULL,
+    user_id integer DEFAULT 0 NOT NULL,
+    permission_id integer NOT NULL,
+    created_at timestamp without time zone DEFAULT now(),
+    updated_at timestamp without time zone DEFAULT now()
+);
+
+
+ALTER TABLE public.goadmin_permission_denies OWNER TO postgres;
+
+--
+-- Name: goadmin_permission_denies goadmin_permission_denies_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.goadmin_permission_denies
+    ADD CONSTRAINT goadmin_permission_denies_pkey PRIMARY KEY (id);
+
+--
+-- Name: admin_permission_denies_role_id_index; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX admin_permission_denies_role_id_index ON public.goadmin_permission_denies USING btree (role_id);
+
+--
+-- Name: admin_permission_denies_user_id_index; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX admin_permission_denies_user_id_index ON public.goadmin_permission_denies USING btree (user_id);
+
+--
+-- Name: goadmin_roles parent_id; Type: COLUMN; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.goadmin_roles ADD COLUMN parent_id integer DEFAULT 0 NOT NULL;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS "goadmin_permission_denies" (
+`id` integer PRIMARY KEY autoincrement,
+`role_id` INT NOT NULL DEFAULT '0',
+`user_id` INT NOT NULL DEFAULT '0',
+`permission_id` INT NOT NULL,
+`created_at` TIMESTAMP default CURRENT_TIMESTAMP,
+`updated_at` TIMESTAMP default CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "admin_permission_denies_role_id_index" ON "goadmin_permission_denies" ("role_id");
+
+CREATE INDEX IF NOT EXISTS "admin_permission_denies_user_id_index" ON "goadmin_permission_denies" ("user_id");
+
+ALTER TABLE "goadmin_roles" ADD COLUMN `parent_id` INT NOT NULL DEFAULT '0';
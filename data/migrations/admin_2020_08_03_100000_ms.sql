@@ -0,0 +1,10 @@
+CREATE TABLE[goadmin_sync_records] (
+ [id] int   identity(1,1) ,
+ [kind] varchar(20)   NOT NULL,
+ [record_id] int   NOT NULL,
+ [checksum] varchar(64)   NOT NULL,
+ [created_at] datetime NULL DEFAULT GETDATE(),
+ [updated_at] datetime NULL DEFAULT GETDATE(),
+  PRIMARY KEY ([id]),
+  CONSTRAINT [admin_sync_records_kind_record_id_unique] UNIQUE ([kind], [record_id]),
+)
@@ -0,0 +1,42 @@
+--
+-- Name: goadmin_sync_records_myid_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+CREATE SEQUENCE public.goadmin_sync_records_myid_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    MAXVALUE 99999999
+    CACHE 1;
+
+
+ALTER TABLE public.goadmin_sync_records_myid_seq OWNER TO postgres;
+
+--
+-- Name: goadmin_sync_records; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.goadmin_sync_records (
+    id integer DEFAULT nextval('public.goadmin_sync_records_myid_seq'::regclass) NOT NULL,
+    kind character varying(20) NOT NULL,
+    record_id integer NOT NULL,
+    checksum character varying(64) NOT NULL,
+    created_at timestamp without time zone DEFAULT now(),
+    updated_at timestamp without time zone DEFAULT now()
+);
+
+
+ALTER TABLE public.goadmin_sync_records OWNER TO postgres;
+
+--
+-- Name: goadmin_sync_records goadmin_sync_records_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.goadmin_sync_records
+    ADD CONSTRAINT goadmin_sync_records_pkey PRIMARY KEY (id);
+
+--
+-- Name: admin_sync_records_kind_record_id_unique; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE UNIQUE INDEX admin_sync_records_kind_record_id_unique ON public.goadmin_sync_records USING btree (kind, record_id);
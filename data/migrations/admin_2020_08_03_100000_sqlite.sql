@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS "goadmin_sync_records" (
+`id` integer PRIMARY KEY autoincrement,
+`kind` CHAR(20) NOT NULL,
+`record_id` INT NOT NULL,
+`checksum` CHAR(64) NOT NULL,
+`created_at` TIMESTAMP default CURRENT_TIMESTAMP,
+`updated_at` TIMESTAMP default CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "admin_sync_records_kind_record_id_unique" ON "goadmin_sync_records" ("kind", "record_id");
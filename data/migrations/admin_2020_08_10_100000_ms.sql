@@ -0,0 +1,16 @@
+CREATE TABLE[goadmin_change_requests] (
+ [id] int   identity(1,1) ,
+ [prefix] varchar(100)   NOT NULL,
+ [kind] varchar(10)   NOT NULL,
+ [record_id] varchar(255)   NOT NULL DEFAULT '',
+ [permission] varchar(100)   NOT NULL DEFAULT '',
+ [data] text   NOT NULL,
+ [diff] text   NOT NULL,
+ [status] varchar(20)   NOT NULL DEFAULT 'pending',
+ [requester_id] int   NOT NULL DEFAULT 0,
+ [approver_id] int   NOT NULL DEFAULT 0,
+ [comment] varchar(1000)   NOT NULL DEFAULT '',
+ [created_at] datetime NULL DEFAULT GETDATE(),
+ [updated_at] datetime NULL DEFAULT GETDATE(),
+  PRIMARY KEY ([id]),
+)
@@ -0,0 +1,49 @@
+--
+-- Name: goadmin_change_requests_myid_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+CREATE SEQUENCE public.goadmin_change_requests_myid_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    MAXVALUE 99999999
+    CACHE 1;
+
+
+ALTER TABLE public.goadmin_change_requests_myid_seq OWNER TO postgres;
+
+--
+-- Name: goadmin_change_requests; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.goadmin_change_requests (
+    id integer DEFAULT nextval('public.goadmin_change_requests_myid_seq'::regclass) NOT NULL,
+    prefix character varying(100) NOT NULL,
+    kind character varying(10) NOT NULL,
+    record_id character varying(255) DEFAULT ''::character varying NOT NULL,
+    permission character varying(100) DEFAULT ''::character varying NOT NULL,
+    data text NOT NULL,
+    diff text NOT NULL,
+    status character varying(20) DEFAULT 'pending'::character varying NOT NULL,
+    requester_id integer DEFAULT 0 NOT NULL,
+    approver_id integer DEFAULT 0 NOT NULL,
+    comment character varying(1000) DEFAULT ''::character varying NOT NULL,
+    created_at timestamp without time zone DEFAULT now(),
+    updated_at timestamp without time zone DEFAULT now()
+);
+
+
+ALTER TABLE public.goadmin_change_requests OWNER TO postgres;
+
+--
+-- Name: goadmin_change_requests goadmin_change_requests_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.goadmin_change_requests
+    ADD CONSTRAINT goadmin_change_requests_pkey PRIMARY KEY (id);
+
+--
+-- Name: admin_change_requests_status_index; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX admin_change_requests_status_index ON public.goadmin_change_requests USING btree (status);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS "goadmin_change_requests" (
+`id` integer PRIMARY KEY autoincrement,
+`prefix` CHAR(100) NOT NULL,
+`kind` CHAR(10) NOT NULL,
+`record_id` CHAR(255) NOT NULL DEFAULT '',
+`permission` CHAR(100) NOT NULL DEFAULT '',
+`data` TEXT NOT NULL,
+`diff` TEXT NOT NULL,
+`status` CHAR(20) NOT NULL DEFAULT 'pending',
+`requester_id` INT NOT NULL DEFAULT '0',
+`approver_id` INT NOT NULL DEFAULT '0',
+`comment` CHAR(1000) NOT NULL DEFAULT '',
+`created_at` TIMESTAMP default CURRENT_TIMESTAMP,
+`updated_at` TIMESTAMP default CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "admin_change_requests_status_index" ON "goadmin_change_requests" ("status");
@@ -0,0 +1,6 @@
+ALTER TABLE [goadmin_operation_log] ADD [prefix] varchar(100) NOT NULL DEFAULT ''
+ALTER TABLE [goadmin_operation_log] ADD [record_id] varchar(255) NOT NULL DEFAULT ''
+ALTER TABLE [goadmin_operation_log] ADD [action] varchar(20) NOT NULL DEFAULT ''
+ALTER TABLE [goadmin_operation_log] ADD [status] int NOT NULL DEFAULT 0
+CREATE INDEX [admin_operation_log_prefix_index] ON [goadmin_operation_log] ([prefix])
+CREATE INDEX [admin_operation_log_created_at_index] ON [goadmin_operation_log] ([created_at])
@@ -0,0 +1,6 @@
+ALTER TABLE `goadmin_operation_log` ADD COLUMN `prefix` varchar(100) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' AFTER `impersonator_id`;
+ALTER TABLE `goadmin_operation_log` ADD COLUMN `record_id` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' AFTER `prefix`;
+ALTER TABLE `goadmin_operation_log` ADD COLUMN `action` varchar(20) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' AFTER `record_id`;
+ALTER TABLE `goadmin_operation_log` ADD COLUMN `status` int(11) NOT NULL DEFAULT '0' AFTER `input`;
+ALTER TABLE `goadmin_operation_log` ADD KEY `admin_operation_log_prefix_index` (`prefix`);
+ALTER TABLE `goadmin_operation_log` ADD KEY `admin_operation_log_created_at_index` (`created_at`);
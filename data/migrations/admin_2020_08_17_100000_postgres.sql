@@ -0,0 +1,20 @@
+--
+-- Name: goadmin_operation_log prefix; Type: COLUMN; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.goadmin_operation_log ADD COLUMN prefix character varying(100) DEFAULT ''::character varying NOT NULL;
+ALTER TABLE public.goadmin_operation_log ADD COLUMN record_id character varying(255) DEFAULT ''::character varying NOT NULL;
+ALTER TABLE public.goadmin_operation_log ADD COLUMN action character varying(20) DEFAULT ''::character varying NOT NULL;
+ALTER TABLE public.goadmin_operation_log ADD COLUMN status integer DEFAULT 0 NOT NULL;
+
+--
+-- Name: admin_operation_log_prefix_index; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX admin_operation_log_prefix_index ON public.goadmin_operation_log USING btree (prefix);
+
+--
+-- Name: admin_operation_log_created_at_index; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX admin_operation_log_created_at_index ON public.goadmin_operation_log USING btree (created_at);
@@ -0,0 +1,7 @@
+ALTER TABLE "goadmin_operation_log" ADD COLUMN `prefix` VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE "goadmin_operation_log" ADD COLUMN `record_id` VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE "goadmin_operation_log" ADD COLUMN `action` VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE "goadmin_operation_log" ADD COLUMN `status` INT NOT NULL DEFAULT '0';
+
+CREATE INDEX IF NOT EXISTS "admin_operation_log_prefix_index" ON "goadmin_operation_log" ("prefix");
+CREATE INDEX IF NOT EXISTS "admin_operation_log_created_at_index" ON "goadmin_operation_log" ("created_at");
@@ -0,0 +1,2 @@
+ALTER TABLE [goadmin_media] ADD [prefix] varchar(100) NOT NULL DEFAULT ''
+ALTER TABLE [goadmin_media] ADD [field] varchar(100) NOT NULL DEFAULT ''
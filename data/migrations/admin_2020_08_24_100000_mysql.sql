@@ -0,0 +1,2 @@
+ALTER TABLE `goadmin_media` ADD COLUMN `prefix` varchar(100) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' AFTER `engine`;
+ALTER TABLE `goadmin_media` ADD COLUMN `field` varchar(100) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' AFTER `prefix`;
@@ -0,0 +1,11 @@
+--
+-- Name: goadmin_media prefix; Type: COLUMN; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.goadmin_media ADD COLUMN prefix character varying(100) DEFAULT ''::character varying NOT NULL;
+
+--
+-- Name: goadmin_media field; Type: COLUMN; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.goadmin_media ADD COLUMN field character varying(100) DEFAULT ''::character varying NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE "goadmin_media" ADD COLUMN `prefix` CHAR(100) COLLATE NOCASE NOT NULL DEFAULT '';
+ALTER TABLE "goadmin_media" ADD COLUMN `field` CHAR(100) COLLATE NOCASE NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE [goadmin_media] ADD [variants] varchar(255) NOT NULL DEFAULT ''
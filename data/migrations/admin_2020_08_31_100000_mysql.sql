@@ -0,0 +1 @@
+ALTER TABLE `goadmin_media` ADD COLUMN `variants` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' AFTER `mime`;
@@ -0,0 +1,5 @@
+--
+-- Name: goadmin_media variants; Type: COLUMN; Schema: public; Owner: postgres
+--
+
+ALTER TABLE public.goadmin_media ADD COLUMN variants character varying(255) DEFAULT ''::character varying NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE "goadmin_media" ADD COLUMN `variants` CHAR(255) COLLATE NOCASE NOT NULL DEFAULT '';
@@ -6,7 +6,6 @@ package engine
 
 import (
 	"bytes"
-	"encoding/json"
 	errors2 "errors"
 	"fmt"
 	template2 "html/template"
@@ -20,14 +19,17 @@ import (
 	"github.com/wowucco/go-admin/modules/config"
 	"github.com/wowucco/go-admin/modules/db"
 	"github.com/wowucco/go-admin/modules/errors"
+	"github.com/wowucco/go-admin/modules/file"
 	"github.com/wowucco/go-admin/modules/logger"
 	"github.com/wowucco/go-admin/modules/menu"
+	"github.com/wowucco/go-admin/modules/oidc"
 	"github.com/wowucco/go-admin/modules/service"
 	"github.com/wowucco/go-admin/modules/system"
 	"github.com/wowucco/go-admin/modules/ui"
 	"github.com/wowucco/go-admin/plugins"
 	"github.com/wowucco/go-admin/plugins/admin"
 	"github.com/wowucco/go-admin/plugins/admin/models"
+	"github.com/wowucco/go-admin/plugins/admin/modules/oplog"
 	"github.com/wowucco/go-admin/plugins/admin/modules/response"
 	"github.com/wowucco/go-admin/plugins/admin/modules/table"
 	"github.com/wowucco/go-admin/template"
@@ -58,6 +60,23 @@ func Default() *Engine {
 	}
 }
 
+// Handler enable the adapter and return the http.Handler serving the routes,
+// the adapter must be a adapter.HandlerCreator, such as the net/http one.
+func (eng *Engine) Handler() (http.Handler, error) {
+	if eng.Adapter == nil {
+		panic("adapter is nil, import the default adapter or use AddAdapter method add the adapter")
+	}
+	creator, ok := eng.Adapter.(adapter.HandlerCreator)
+	if !ok {
+		return nil, errors2.New("the adapter " + eng.Adapter.Name() + " can not create a http.Handler")
+	}
+	h := creator.NewHandler()
+	if err := eng.Use(h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
 // Use enable the adapter.
 func (eng *Engine) Use(router interface{}) error {
 	if eng.Adapter == nil {
@@ -73,6 +92,8 @@ func (eng *Engine) Use(router interface{}) error {
 	eng.Services.Add("config", config.SrvWithConfig(eng.config))
 	errors.Init()
 
+	file.SetImagePipeline(file.NewImagePipeline(eng.config.FileUploadEngine.Image))
+
 	if !eng.config.HideConfigCenterEntrance {
 		btn := types.GetNavButton("", icon.Gear, action.Jump(eng.config.Url("/info/site/edit")))
 		eng.NavButtons = append(eng.NavButtons, btn)
@@ -127,6 +148,15 @@ func (eng *Engine) AddAuthService(processor auth.Processor) *Engine {
 	return eng
 }
 
+// AddOIDCProvider add the OpenID Connect providers of the single sign-on,
+// each of them has a sign in button on the login page.
+func (eng *Engine) AddOIDCProvider(cfg ...oidc.Config) *Engine {
+	for _, c := range cfg {
+		oidc.Add(oidc.New(c))
+	}
+	return eng
+}
+
 // ============================
 // Config APIs
 // ============================
@@ -291,15 +321,7 @@ func (eng *Engine) ClonedBySetter(setter Setter) *Engine {
 func (eng *Engine) deferHandler(conn db.Connection) context.Handler {
 	return func(ctx *context.Context) {
 		defer func(ctx *context.Context) {
-			if user, ok := ctx.UserValue["user"].(models.UserModel); ok {
-				var input []byte
-				form := ctx.Request.MultipartForm
-				if form != nil {
-					input, _ = json.Marshal((*form).Value)
-				}
-
-				models.OperationLog().SetConn(conn).New(user.Id, ctx.Path(), ctx.Method(), ctx.LocalIP(), string(input))
-			}
+			defer oplog.Record(ctx, conn)
 
 			if err := recover(); err != nil {
 				logger.Error(err)
@@ -558,6 +580,13 @@ func (eng *Engine) AddGenerators(list ...table.GeneratorList) *Engine {
 	return eng
 }
 
+// SyncGenerators enable the sync of the admin generators with the permissions
+// and the menus on startup.
+func (eng *Engine) SyncGenerators(cfg ...table.SyncConfig) *Engine {
+	eng.AdminPlugin().SyncGenerators(cfg...)
+	return eng
+}
+
 // AdminPlugin get the admin plugin. if not exist, create one.
 func (eng *Engine) AdminPlugin() *admin.Admin {
 	plug, exist := eng.FindPluginByName("admin")
@@ -6,15 +6,18 @@ require (
 	github.com/360EntSecGroup-Skylar/excelize v1.4.1
 	github.com/GoAdminGroup/html v0.0.1
 	github.com/NebulousLabs/fastrand v0.0.0-20181203155948-6fb6489aac4e
+	github.com/buaazp/fasthttprouter v0.1.1
 	github.com/denisenkom/go-mssqldb v0.0.0-20200206145737-bbfc9a55622e
 	github.com/gin-gonic/gin v1.5.0
+	github.com/go-chi/chi v4.1.2+incompatible
 	github.com/go-sql-driver/mysql v1.5.0
 	github.com/gobuffalo/packr/v2 v2.8.0 // indirect
 	github.com/gogf/gf v1.11.5
+	github.com/gorilla/mux v1.7.4
 	github.com/kataras/iris/v12 v12.1.8
+	github.com/labstack/echo/v4 v4.1.17
 	github.com/lib/pq v1.3.0
 	github.com/magiconair/properties v1.8.1
-	github.com/mattn/go-colorable v0.1.6 // indirect
 	github.com/mattn/go-sqlite3 v2.0.3+incompatible
 	github.com/mgutz/ansi v0.0.0-20170206155736-9520e82c474b
 	github.com/natefinch/lumberjack v2.0.0+incompatible
@@ -22,10 +25,11 @@ require (
 	github.com/shirou/gopsutil v2.20.4+incompatible
 	github.com/sirupsen/logrus v1.4.2
 	github.com/stretchr/testify v1.5.1
+	github.com/valyala/fasthttp v1.15.1
 	github.com/wowucco/themes v0.1.6 // indirect
 	go.uber.org/zap v1.15.0
-	golang.org/x/crypto v0.0.0-20200221231518-2aa609cf4a9d
-	golang.org/x/text v0.3.2
+	golang.org/x/crypto v0.0.0-20200820211705-5c72a883971a
+	golang.org/x/text v0.3.3
 	gopkg.in/ini.v1 v1.51.1
 	gopkg.in/yaml.v2 v2.2.8
 )
@@ -0,0 +1,247 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/db"
+	errors2 "github.com/wowucco/go-admin/modules/errors"
+	"github.com/wowucco/go-admin/modules/language"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+	"github.com/wowucco/go-admin/plugins/admin/modules/constant"
+)
+
+const (
+	apiTokenPrefix = "ga_"
+	apiTokenKey    = "api_token"
+
+	// the last use of a token is written at most once in the interval
+	apiTokenTouchInterval = int64(60)
+)
+
+var (
+	// ErrInvalidAPIToken is returned when the api token is unknown, revoked
+	// or expired.
+	ErrInvalidAPIToken = errors.New("invalid api token")
+	// ErrAPITokenScope is returned when the scopes of the api token do not
+	// allow the request.
+	ErrAPITokenScope = errors.New("the api token scopes do not allow the request")
+)
+
+var apiMethods = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true,
+}
+
+// APIScope allows the methods on the table of the prefix. The prefix "*" is
+// every table and empty Methods are all the methods.
+type APIScope struct {
+	Prefix  string
+	Methods []string
+}
+
+// Allow check the scope allows the method on the table of the prefix.
+func (s APIScope) Allow(prefix, method string) bool {
+	if s.Prefix != "*" && s.Prefix != prefix {
+		return false
+	}
+	if len(s.Methods) == 0 {
+		return true
+	}
+	for _, m := range s.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s APIScope) String() string {
+	if len(s.Methods) == 0 {
+		return s.Prefix
+	}
+	return s.Prefix + ":" + strings.Join(s.Methods, ",")
+}
+
+// ParseAPIScopes parse the scopes of a token, one per line, such as:
+//
+//	posts:GET,POST
+//	users:GET
+//	*:GET
+//
+// A prefix without methods allows all the methods.
+func ParseAPIScopes(text string) ([]APIScope, error) {
+	var scopes []APIScope
+	for _, line := range strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		scope := APIScope{Prefix: line}
+		if i := strings.Index(line, ":"); i >= 0 {
+			scope.Prefix = strings.TrimSpace(line[:i])
+			for _, m := range strings.Split(line[i+1:], ",") {
+				m = strings.ToUpper(strings.TrimSpace(m))
+				if m == "*" {
+					scope.Methods = nil
+					break
+				}
+				if !apiMethods[m] {
+					return nil, fmt.Errorf(language.Get("wrong api token scope: %s"), line)
+				}
+				scope.Methods = append(scope.Methods, m)
+			}
+		}
+		if scope.Prefix == "" || strings.ContainsAny(scope.Prefix, " \t,") {
+			return nil, fmt.Errorf(language.Get("wrong api token scope: %s"), line)
+		}
+
+		scopes = append(scopes, scope)
+	}
+
+	if len(scopes) == 0 {
+		return nil, errors.New(language.Get("the api token needs a scope"))
+	}
+
+	return scopes, nil
+}
+
+// FormatAPIScopes return the stored form of the scopes.
+func FormatAPIScopes(scopes []APIScope) string {
+	lines := make([]string, len(scopes))
+	for i, s := range scopes {
+		lines[i] = s.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// APIScopesAllow check one of the scopes allows the method on the table of
+// the prefix.
+func APIScopesAllow(scopes []APIScope, prefix, method string) bool {
+	for _, s := range scopes {
+		if s.Allow(prefix, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewAPIToken create a personal api token of the user. The token is returned
+// only here, as the hash of it is stored.
+func NewAPIToken(userId int64, name string, scopes []APIScope, expiresAt int64, conn db.Connection) (string, models.APITokenModel, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", models.APITokenModel{}, err
+	}
+	token := apiTokenPrefix + hex.EncodeToString(b)
+
+	t, err := models.APIToken().SetConn(conn).New(userId, name, hashToken(token), FormatAPIScopes(scopes), expiresAt)
+	if db.CheckError(err, db.INSERT) {
+		return "", t, err
+	}
+
+	return token, t, nil
+}
+
+// CheckAPIToken return the token model if the token is active and allows the
+// method on the table of the prefix.
+func CheckAPIToken(token, prefix, method string, conn db.Connection) (models.APITokenModel, error) {
+	if !strings.HasPrefix(token, apiTokenPrefix) {
+		return models.APITokenModel{}, ErrInvalidAPIToken
+	}
+
+	t := models.APIToken().SetConn(conn).FindByToken(hashToken(token))
+	if !t.IsActive(time.Now()) {
+		return t, ErrInvalidAPIToken
+	}
+
+	scopes, err := ParseAPIScopes(t.Scopes)
+	if err != nil || !APIScopesAllow(scopes, prefix, method) {
+		return t, ErrAPITokenScope
+	}
+
+	return t, nil
+}
+
+// APIMiddleware is the auth middleware of the admin api. A request with a
+// bearer token is authenticated by the personal api token, the others by
+// the session as the Middleware.
+func APIMiddleware(conn db.Connection) context.Handler {
+	session := Middleware(conn)
+	return func(ctx *context.Context) {
+		token, ok := bearerToken(ctx.Headers("Authorization"))
+		if !ok {
+			session(ctx)
+			return
+		}
+
+		t, err := CheckAPIToken(token, ctx.Param(constant.PrefixKey), ctx.Method(), conn)
+		if err == ErrAPITokenScope {
+			apiTokenFail(ctx, http.StatusForbidden, err.Error())
+			return
+		}
+		if err != nil {
+			apiTokenFail(ctx, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		user, ok := GetCurUserByID(t.UserId, conn)
+		if !ok {
+			apiTokenFail(ctx, http.StatusUnauthorized, ErrInvalidAPIToken.Error())
+			return
+		}
+
+		if !CheckPermissions(user, ctx.Request.URL.String(), ctx.Method(), ctx.PostForm()) {
+			apiTokenFail(ctx, http.StatusForbidden, errors2.PermissionDenied)
+			return
+		}
+
+		if now := time.Now().Unix(); now-t.LastUsedAt >= apiTokenTouchInterval {
+			if _, err := t.UpdateLastUsed(now, ctx.LocalIP()); db.CheckError(err, db.UPDATE) {
+				logger.Error("update api token last used error: ", err)
+			}
+		}
+
+		ctx.SetUserValue("user", user)
+		ctx.SetUserValue(apiTokenKey, t)
+		ctx.Next()
+	}
+}
+
+// IsAPITokenRequest report if the request is authenticated by an api token.
+// The csrf token of the forms is not needed by these requests, as the bearer
+// token is not sent by the browser on its own.
+func IsAPITokenRequest(ctx *context.Context) bool {
+	_, ok := ctx.UserValue[apiTokenKey].(models.APITokenModel)
+	return ok
+}
+
+func bearerToken(header string) (string, bool) {
+	const scheme = "bearer "
+	if len(header) <= len(scheme) || !strings.EqualFold(header[:len(scheme)], scheme) {
+		return "", false
+	}
+	return strings.TrimSpace(header[len(scheme):]), true
+}
+
+func apiTokenFail(ctx *context.Context, code int, msg string) {
+	if code == http.StatusUnauthorized {
+		ctx.AddHeader("WWW-Authenticate", `Bearer realm="goadmin"`)
+	}
+	ctx.JSON(code, map[string]interface{}{
+		"code": code,
+		"msg":  language.Get(msg),
+	})
+	ctx.Abort()
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAPIScopes(t *testing.T) {
+	scopes, err := ParseAPIScopes("posts: get, post\r\n\nusers\n*:GET\n")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, FormatAPIScopes(scopes), "posts:GET,POST\nusers\n*:GET")
+
+	assert.Equal(t, APIScopesAllow(scopes, "posts", "POST"), true)
+	assert.Equal(t, APIScopesAllow(scopes, "posts", "DELETE"), false)
+	assert.Equal(t, APIScopesAllow(scopes, "users", "DELETE"), true)
+	assert.Equal(t, APIScopesAllow(scopes, "manager", "GET"), true)
+	assert.Equal(t, APIScopesAllow(scopes, "manager", "POST"), false)
+
+	scopes, err = ParseAPIScopes("posts:*")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, APIScopesAllow(scopes, "posts", "DELETE"), true)
+
+	for _, text := range []string{"", " \n ", "posts:FETCH", ":GET", "posts users:GET"} {
+		_, err := ParseAPIScopes(text)
+		assert.NotEqual(t, err, nil, text)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	token, ok := bearerToken("Bearer ga_abc")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, token, "ga_abc")
+
+	token, ok = bearerToken("bearer  ga_abc ")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, token, "ga_abc")
+
+	_, ok = bearerToken("Basic YWRtaW46YWRtaW4=")
+	assert.Equal(t, ok, false)
+	_, ok = bearerToken("Bearer ")
+	assert.Equal(t, ok, false)
+}
@@ -6,10 +6,11 @@ package auth
 
 import (
 	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/config"
 	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/logger"
 	"github.com/wowucco/go-admin/modules/service"
 	"github.com/wowucco/go-admin/plugins/admin/models"
-	"github.com/wowucco/go-admin/plugins/admin/modules"
 	"golang.org/x/crypto/bcrypt"
 	"sync"
 )
@@ -19,8 +20,17 @@ func Auth(ctx *context.Context) models.UserModel {
 	return ctx.User().(models.UserModel)
 }
 
-// Check check the password and username and return the user model.
+// Check check the password and username and return the user model. The
+// password is checked against the directory when the LDAP is enabled.
 func Check(password string, username string, conn db.Connection) (user models.UserModel, ok bool) {
+	if cfg := config.GetLdap(); cfg.Enable {
+		return checkLDAP(cfg, password, username, conn)
+	}
+	return checkLocal(password, username, conn)
+}
+
+// checkLocal check the password with the hash of the users table.
+func checkLocal(password string, username string, conn db.Connection) (user models.UserModel, ok bool) {
 
 	user = models.User().SetConn(conn).FindByUserName(username)
 
@@ -60,7 +70,7 @@ func SetCookie(ctx *context.Context, user models.UserModel, conn db.Connection)
 		return err
 	}
 
-	return ses.Add("user_id", user.Id)
+	return login(ctx, ses, user, conn)
 }
 
 // DelCookie delete the cookie from Context.
@@ -71,12 +81,22 @@ func DelCookie(ctx *context.Context, conn db.Connection) error {
 		return err
 	}
 
+	if sessionId, ok := ses.Get(sessionIdSesKey).(string); ok && sessionId != "" {
+		record := models.UserSession().SetConn(conn).FindBySessionId(sessionId)
+		if err := record.Delete(); !record.IsEmpty() && db.CheckError(err, db.DELETE) {
+			return err
+		}
+	}
+
 	return ses.Clear()
 }
 
+// TokenService issues and checks the csrf tokens of the forms. The store is
+// chosen by the csrf config at the first use.
 type TokenService struct {
-	tokens CSRFToken
-	lock   sync.Mutex
+	once  sync.Once
+	store CSRFStore
+	err   error
 }
 
 func (s *TokenService) Name() string {
@@ -85,9 +105,7 @@ func (s *TokenService) Name() string {
 
 func init() {
 	service.Register(TokenServiceKey, func() (service.Service, error) {
-		return &TokenService{
-			tokens: make(CSRFToken, 0),
-		}, nil
+		return &TokenService{}, nil
 	})
 }
 
@@ -103,29 +121,51 @@ func GetTokenService(s interface{}) *TokenService {
 	panic("wrong service")
 }
 
-// AddToken add the token to the CSRFToken.
+// InitStore create the store of the csrf config, and return the error of a
+// wrong config. The memory store is used instead of a wrong one.
+func (s *TokenService) InitStore() error {
+	s.once.Do(func() {
+		s.store, s.err = NewCSRFStore(config.GetCsrf())
+		if s.err != nil {
+			logger.Error("init csrf store error: ", s.err)
+			s.store = NewMemoryCSRFStore(0, 0)
+		}
+	})
+	return s.err
+}
+
+// AddToken return a new token which is not bound to a session.
 func (s *TokenService) AddToken() string {
-	s.lock.Lock()
-	defer s.lock.Unlock()
-	tokenStr := modules.Uuid()
-	s.tokens = append(s.tokens, tokenStr)
-	return tokenStr
+	return s.issue("")
 }
 
-// CheckToken check the given token with tokens in the CSRFToken, if exist
-// return true.
+// CheckToken check the given token which is not bound to a session, if it is
+// valid return true.
 func (s *TokenService) CheckToken(toCheckToken string) bool {
-	for i := 0; i < len(s.tokens); i++ {
-		if (s.tokens)[i] == toCheckToken {
-			s.tokens = append((s.tokens)[:i], (s.tokens)[i+1:]...)
-			return true
-		}
-	}
-	return false
+	_ = s.InitStore()
+	return s.store.Check(toCheckToken, "")
 }
 
-// CSRFToken is type of a csrf token list.
-type CSRFToken []string
+// AddSessionToken return a new token bound to the session of the request.
+func (s *TokenService) AddSessionToken(ctx *context.Context) string {
+	return s.issue(CurrentSessionId(ctx))
+}
+
+// CheckSessionToken check the given token with the session of the request,
+// if it is valid return true.
+func (s *TokenService) CheckSessionToken(ctx *context.Context, toCheckToken string) bool {
+	_ = s.InitStore()
+	return s.store.Check(toCheckToken, CurrentSessionId(ctx))
+}
+
+func (s *TokenService) issue(binding string) string {
+	_ = s.InitStore()
+	token, err := s.store.Issue(binding)
+	if err != nil {
+		logger.Error("issue csrf token error: ", err)
+	}
+	return token
+}
 
 type Processor func(ctx *context.Context) (model models.UserModel, exist bool, msg string)
 
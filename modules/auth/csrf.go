@@ -0,0 +1,217 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"container/list"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/wowucco/go-admin/modules/config"
+)
+
+const (
+	defaultCSRFExpire    = 2 * time.Hour
+	defaultCSRFMaxTokens = 10000
+	csrfSecretMinLength  = 16
+
+	// csrfMaxTokensPerBinding is the limit of the tokens of a session, so
+	// one session can not push out the tokens of the others.
+	csrfMaxTokensPerBinding = 256
+)
+
+// CSRFStore issues and checks the csrf tokens. The binding is the session
+// which the token is issued to, a token is only valid with the same binding.
+type CSRFStore interface {
+	Issue(binding string) (string, error)
+	Check(token, binding string) bool
+}
+
+// NewCSRFStore return the CSRFStore of the config.
+func NewCSRFStore(cfg config.Csrf) (CSRFStore, error) {
+	expire := time.Duration(cfg.ExpireSeconds) * time.Second
+	switch cfg.Store {
+	case "", "memory":
+		return NewMemoryCSRFStore(expire, cfg.MaxTokens), nil
+	case "hmac":
+		return NewHMACCSRFStore(cfg.Secret, expire)
+	}
+	return nil, errors.New("wrong csrf store: " + cfg.Store)
+}
+
+// MemoryCSRFStore keeps the tokens in the process. A token is deleted when
+// it is checked, so it can be used once. The tokens have the same lifetime,
+// so the lists in the issue order are also in the expiry order, and the
+// expired or the oldest tokens are dropped from their fronts.
+type MemoryCSRFStore struct {
+	lock      sync.Mutex
+	expire    time.Duration
+	maxTokens int
+	tokens    map[string]*csrfEntry
+	// order is all the tokens and bindings is the tokens of every session,
+	// the oldest first.
+	order    *list.List
+	bindings map[string]*list.List
+}
+
+type csrfEntry struct {
+	token     string
+	binding   string
+	expiresAt time.Time
+
+	inOrder   *list.Element
+	inBinding *list.Element
+}
+
+// NewMemoryCSRFStore return an empty MemoryCSRFStore, which keeps at most
+// maxTokens tokens.
+func NewMemoryCSRFStore(expire time.Duration, maxTokens int) *MemoryCSRFStore {
+	if expire <= 0 {
+		expire = defaultCSRFExpire
+	}
+	if maxTokens <= 0 {
+		maxTokens = defaultCSRFMaxTokens
+	}
+	return &MemoryCSRFStore{
+		expire:    expire,
+		maxTokens: maxTokens,
+		tokens:    make(map[string]*csrfEntry),
+		order:     list.New(),
+		bindings:  make(map[string]*list.List),
+	}
+}
+
+// Issue implements the CSRFStore.Issue.
+func (s *MemoryCSRFStore) Issue(binding string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	now := time.Now()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for s.order.Len() > 0 {
+		oldest := s.order.Front().Value.(*csrfEntry)
+		if oldest.expiresAt.After(now) {
+			break
+		}
+		s.remove(oldest)
+	}
+
+	// the abandoned forms leave tokens, the oldest one of the session is
+	// dropped first, then the oldest one of all
+	own := s.bindings[binding]
+	if binding != "" && own != nil && own.Len() >= csrfMaxTokensPerBinding {
+		s.remove(own.Front().Value.(*csrfEntry))
+	}
+	for len(s.tokens) >= s.maxTokens {
+		s.remove(s.order.Front().Value.(*csrfEntry))
+	}
+
+	entry := &csrfEntry{token: token, binding: binding, expiresAt: now.Add(s.expire)}
+	if own = s.bindings[binding]; own == nil {
+		own = list.New()
+		s.bindings[binding] = own
+	}
+	entry.inOrder = s.order.PushBack(entry)
+	entry.inBinding = own.PushBack(entry)
+	s.tokens[token] = entry
+	return token, nil
+}
+
+// Check implements the CSRFStore.Check.
+func (s *MemoryCSRFStore) Check(token, binding string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	entry, ok := s.tokens[token]
+	if !ok {
+		return false
+	}
+	s.remove(entry)
+	return entry.binding == binding && entry.expiresAt.After(time.Now())
+}
+
+// Len return the count of the kept tokens.
+func (s *MemoryCSRFStore) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.tokens)
+}
+
+func (s *MemoryCSRFStore) remove(entry *csrfEntry) {
+	delete(s.tokens, entry.token)
+	s.order.Remove(entry.inOrder)
+	if own := s.bindings[entry.binding]; own != nil {
+		own.Remove(entry.inBinding)
+		if own.Len() == 0 {
+			delete(s.bindings, entry.binding)
+		}
+	}
+}
+
+// HMACCSRFStore keeps nothing, a token carries its expiry and a signature of
+// it and the binding. The instances sharing the secret accept the tokens of
+// each other, and a token can be used until it expires.
+type HMACCSRFStore struct {
+	key    []byte
+	expire time.Duration
+}
+
+const (
+	csrfNonceSize = 8
+	csrfTokenSize = 8 + csrfNonceSize + sha256.Size
+)
+
+// NewHMACCSRFStore return a HMACCSRFStore of the secret.
+func NewHMACCSRFStore(secret string, expire time.Duration) (*HMACCSRFStore, error) {
+	if len(secret) < csrfSecretMinLength {
+		return nil, errors.New("the secret of the hmac csrf store needs at least 16 characters")
+	}
+	if expire <= 0 {
+		expire = defaultCSRFExpire
+	}
+	return &HMACCSRFStore{key: []byte(secret), expire: expire}, nil
+}
+
+// Issue implements the CSRFStore.Issue.
+func (s *HMACCSRFStore) Issue(binding string) (string, error) {
+	data := make([]byte, 8+csrfNonceSize, csrfTokenSize)
+	binary.BigEndian.PutUint64(data, uint64(time.Now().Add(s.expire).Unix()))
+	if _, err := rand.Read(data[8:]); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(append(data, s.sign(data, binding)...)), nil
+}
+
+// Check implements the CSRFStore.Check.
+func (s *HMACCSRFStore) Check(token, binding string) bool {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(data) != csrfTokenSize {
+		return false
+	}
+	payload, sig := data[:8+csrfNonceSize], data[8+csrfNonceSize:]
+	if !hmac.Equal(sig, s.sign(payload, binding)) {
+		return false
+	}
+	return int64(binary.BigEndian.Uint64(payload)) > time.Now().Unix()
+}
+
+func (s *HMACCSRFStore) sign(payload []byte, binding string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(payload)
+	mac.Write([]byte(binding))
+	return mac.Sum(nil)
+}
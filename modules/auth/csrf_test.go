@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wowucco/go-admin/modules/config"
+)
+
+func TestMemoryCSRFStore(t *testing.T) {
+	store := NewMemoryCSRFStore(time.Hour, 10)
+
+	token, err := store.Issue("ses1")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, store.Check(token, "ses2"), false)
+
+	token, _ = store.Issue("ses1")
+	assert.Equal(t, store.Check(token, "ses1"), true)
+	// a token is used once
+	assert.Equal(t, store.Check(token, "ses1"), false)
+	assert.Equal(t, store.Check("unknown", "ses1"), false)
+}
+
+func TestMemoryCSRFStoreExpire(t *testing.T) {
+	store := NewMemoryCSRFStore(time.Millisecond, 10)
+
+	token, _ := store.Issue("")
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, store.Check(token, ""), false)
+}
+
+func TestMemoryCSRFStoreMaxTokens(t *testing.T) {
+	store := NewMemoryCSRFStore(time.Hour, 3)
+
+	first, _ := store.Issue("")
+	time.Sleep(time.Millisecond)
+	for i := 0; i < 5; i++ {
+		_, _ = store.Issue("")
+	}
+	assert.Equal(t, store.Len(), 3)
+	assert.Equal(t, store.Check(first, ""), false)
+}
+
+func TestMemoryCSRFStoreMaxTokensPerBinding(t *testing.T) {
+	store := NewMemoryCSRFStore(time.Hour, 1000)
+
+	other, _ := store.Issue("ses1")
+	first, _ := store.Issue("ses2")
+	for i := 0; i < csrfMaxTokensPerBinding; i++ {
+		_, _ = store.Issue("ses2")
+	}
+
+	assert.Equal(t, store.Len(), csrfMaxTokensPerBinding+1)
+	// the session drops its own oldest token, not the ones of the others
+	assert.Equal(t, store.Check(first, "ses2"), false)
+	assert.Equal(t, store.Check(other, "ses1"), true)
+	assert.Equal(t, store.Len(), csrfMaxTokensPerBinding)
+}
+
+func TestHMACCSRFStore(t *testing.T) {
+	_, err := NewHMACCSRFStore("short", time.Hour)
+	assert.Equal(t, err != nil, true)
+
+	store, err := NewHMACCSRFStore("0123456789abcdef", time.Hour)
+	assert.Equal(t, err, nil)
+
+	token, _ := store.Issue("ses1")
+	assert.Equal(t, store.Check(token, "ses1"), true)
+	assert.Equal(t, store.Check(token, "ses2"), false)
+
+	tampered := []byte(token)
+	if tampered[0] == 'A' {
+		tampered[0] = 'B'
+	} else {
+		tampered[0] = 'A'
+	}
+	assert.Equal(t, store.Check(string(tampered), "ses1"), false)
+	assert.Equal(t, store.Check("not a token", "ses1"), false)
+
+	// another instance with the same secret accepts the token
+	other, _ := NewHMACCSRFStore("0123456789abcdef", time.Hour)
+	assert.Equal(t, other.Check(token, "ses1"), true)
+
+	wrong, _ := NewHMACCSRFStore("fedcba9876543210", time.Hour)
+	assert.Equal(t, wrong.Check(token, "ses1"), false)
+}
+
+func TestHMACCSRFStoreExpire(t *testing.T) {
+	store, _ := NewHMACCSRFStore("0123456789abcdef", time.Second)
+
+	token, _ := store.Issue("")
+	time.Sleep(1100 * time.Millisecond)
+	assert.Equal(t, store.Check(token, ""), false)
+}
+
+func TestNewCSRFStore(t *testing.T) {
+	store, err := NewCSRFStore(config.Csrf{})
+	assert.Equal(t, err, nil)
+	_, ok := store.(*MemoryCSRFStore)
+	assert.Equal(t, ok, true)
+
+	store, err = NewCSRFStore(config.Csrf{Store: "hmac", Secret: "0123456789abcdef"})
+	assert.Equal(t, err, nil)
+	_, ok = store.(*HMACCSRFStore)
+	assert.Equal(t, ok, true)
+
+	_, err = NewCSRFStore(config.Csrf{Store: "unknown"})
+	assert.Equal(t, err != nil, true)
+}
@@ -0,0 +1,187 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+	"github.com/wowucco/go-admin/plugins/admin/modules"
+)
+
+// ErrNoSSOUser is returned when the external account is not linked to a user
+// and the backend does not create one.
+var ErrNoSSOUser = errors.New("no user of the single sign-on account")
+
+const maxUsernameLength = 100
+
+// externalAccount is an account authenticated by an identity provider or a
+// directory.
+type externalAccount struct {
+	provider string
+	subject  string
+	username string
+	name     string
+	email    string
+
+	// emailVerified is set when the provider verified the email.
+	emailVerified bool
+
+	// linkExisting links the first login to the user of the same verified
+	// email, or of the same username when linkUsername is set. The super
+	// administrators are never linked.
+	linkExisting bool
+	linkUsername bool
+	autoCreate   bool
+
+	// roles are given to a created user, and replace the roles of the user
+	// on every login when syncRoles is set. The roles of the super
+	// administrators are never replaced.
+	roles     []string
+	syncRoles bool
+}
+
+// externalUser return the user of the account. It is found by its linked
+// identity, then by the verified email or the username when linkExisting is
+// set, and is created when autoCreate is set.
+func externalUser(account externalAccount, conn db.Connection) (models.UserModel, error) {
+
+	identity := models.UserIdentity().SetConn(conn).Find(account.provider, account.subject)
+
+	var user models.UserModel
+
+	if !identity.IsEmpty() {
+		user = models.User().SetConn(conn).Find(identity.UserId)
+		if user.IsEmpty() {
+			// the user was deleted, the identity is linked again below
+			if err := identity.Delete(); db.CheckError(err, db.DELETE) {
+				return user, err
+			}
+			identity = models.UserIdentity().SetConn(conn)
+		}
+	}
+
+	if user.IsEmpty() && account.linkExisting {
+		user = linkedUser(account, conn)
+	}
+
+	created := false
+
+	if user.IsEmpty() {
+		if !account.autoCreate {
+			return user, ErrNoSSOUser
+		}
+
+		name := account.name
+		if name == "" {
+			name = account.username
+		}
+
+		var err error
+		user, err = models.User().SetConn(conn).New(externalUsername(account, conn),
+			EncodePassword([]byte(modules.Uuid())), name, "")
+		if db.CheckError(err, db.INSERT) {
+			return user, err
+		}
+		created = true
+	}
+
+	if identity.IsEmpty() {
+		if _, err := models.UserIdentity().SetConn(conn).New(user.Id, account.provider, account.subject); db.CheckError(err, db.INSERT) {
+			return user, err
+		}
+	}
+
+	if created || account.syncRoles {
+		if isSuperAdmin(user) {
+			logger.Warn("roles of the superadmin are not synced with the external account: ", user.UserName)
+		} else if err := syncRoles(user, account.roles, conn); err != nil {
+			return user, err
+		}
+	}
+
+	return user.WithRoles().WithPermissions().WithMenus(), nil
+}
+
+// linkedUser return the existing user of the account. It is the user of the
+// same email when the provider verified it, or of the same username when the
+// usernames are trusted. A super administrator is never linked, as taking it
+// over gives everything.
+func linkedUser(account externalAccount, conn db.Connection) models.UserModel {
+	var user models.UserModel
+
+	switch {
+	case account.linkUsername && account.username != "":
+		user = models.User().SetConn(conn).FindByUserName(account.username)
+	case account.emailVerified && account.email != "":
+		user = models.User().SetConn(conn).FindByEmail(account.email)
+		// the email column may be case insensitive
+		if user.Email != account.email {
+			return models.User().SetConn(conn)
+		}
+	default:
+		return models.User().SetConn(conn)
+	}
+
+	if !user.IsEmpty() && isSuperAdmin(user) {
+		logger.Warn("external account is not linked to the superadmin: ", account.provider, " ", account.subject)
+		return models.User().SetConn(conn)
+	}
+
+	return user
+}
+
+func isSuperAdmin(user models.UserModel) bool {
+	return user.WithRoles().WithPermissions().IsSuperAdmin()
+}
+
+// externalUsername return the username of a created user, a taken username
+// is replaced by the provider and the subject.
+func externalUsername(account externalAccount, conn db.Connection) string {
+	if account.username != "" && len(account.username) <= maxUsernameLength &&
+		models.User().SetConn(conn).FindByUserName(account.username).IsEmpty() {
+		return account.username
+	}
+	name := account.provider + ":" + account.subject
+	if len(name) > maxUsernameLength {
+		name = name[:maxUsernameLength]
+	}
+	return name
+}
+
+func syncRoles(user models.UserModel, slugs []string, conn db.Connection) error {
+	roleIds := make([]string, 0, len(slugs))
+	for _, slug := range slugs {
+		role := models.Role().SetConn(conn).FindBySlug(slug)
+		if role.IsEmpty() {
+			logger.Warn("role of the external account not found: ", slug)
+			continue
+		}
+		roleIds = append(roleIds, strconv.FormatInt(role.Id, 10))
+	}
+
+	// the roles are replaced at once, a failed insert keeps the old roles
+	_, err := db.WithDriver(conn).WithTransaction(func(tx *sql.Tx) (error, map[string]interface{}) {
+		if err := user.WithTx(tx).DeleteRoles(); db.CheckError(err, db.DELETE) {
+			return err, nil
+		}
+		for _, id := range roleIds {
+			if _, err := user.WithTx(tx).AddRole(id); db.CheckError(err, db.INSERT) {
+				return err, nil
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	InvalidateUserCache(user.Id)
+	return nil
+}
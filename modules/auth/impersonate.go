@@ -0,0 +1,143 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"errors"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+)
+
+const (
+	impersonatorSesKey = "impersonator_id"
+
+	// ImpersonatePermission is the slug of the permission to impersonate the
+	// other users, the super administrators do not need it. The path of the
+	// permission should allow the impersonate action of the managers, which
+	// is "/operation/manager_impersonate".
+	ImpersonatePermission = "impersonate"
+)
+
+// ErrCannotImpersonate is returned when the login user is not allowed to
+// impersonate the target user.
+var ErrCannotImpersonate = errors.New("the user cannot be impersonated")
+
+// ErrImpersonated is returned when an impersonated user makes a lasting
+// change of the account, such as the password, the two-factor settings, the
+// api tokens or the approvals, which only the real user can make.
+var ErrImpersonated = errors.New("not allowed while impersonating")
+
+// CanImpersonate check if the actor can impersonate the target. The super
+// administrators and the actor himself cannot be impersonated, and the
+// impersonation is not nested. An actor who is not a super administrator
+// can only impersonate the targets whose permissions he has too, so that
+// he does not get more rights by the impersonation.
+func CanImpersonate(actor, target models.UserModel) bool {
+	if target.IsEmpty() || target.IsSuperAdmin() || target.Id == actor.Id || actor.IsImpersonated() {
+		return false
+	}
+	if actor.IsSuperAdmin() {
+		return true
+	}
+	return actor.CheckPermission(ImpersonatePermission) && coversPermissions(actor, target)
+}
+
+// coversPermissions report if the permissions of the target are all of the
+// actor, and the denied permissions of the actor are all of the target.
+func coversPermissions(actor, target models.UserModel) bool {
+	return permissionSubset(target.Permissions, actor.Permissions) &&
+		permissionSubset(actor.DeniedPermissions, target.DeniedPermissions)
+}
+
+func permissionSubset(sub, set []models.PermissionModel) bool {
+	slugs := make(map[string]bool, len(set))
+	for _, p := range set {
+		slugs[p.Slug] = true
+	}
+	for _, p := range sub {
+		if !slugs[p.Slug] {
+			return false
+		}
+	}
+	return true
+}
+
+// StartImpersonate switch the session of the login user to the target user.
+// The session record stays of the real user, so that the logout and the
+// revoke of the real user end the impersonation too.
+func StartImpersonate(ctx *context.Context, targetId int64, conn db.Connection) error {
+	target := models.User().SetConn(conn).Find(targetId)
+	if !target.IsEmpty() {
+		target = target.WithRoles().WithPermissions()
+	}
+
+	actor := Auth(ctx)
+	if !CanImpersonate(actor, target) {
+		return ErrCannotImpersonate
+	}
+
+	ses, err := InitSession(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	ses.Values[impersonatorSesKey] = actor.Id
+	return ses.Add("user_id", target.Id)
+}
+
+// StopImpersonate switch the session back to the real user, it does nothing
+// if the session is not impersonated.
+func StopImpersonate(ctx *context.Context, conn db.Connection) error {
+	ses, err := InitSession(ctx, conn)
+	if err != nil {
+		return err
+	}
+	return stopImpersonate(ses)
+}
+
+func stopImpersonate(ses *Session) error {
+	id, ok := ses.Get(impersonatorSesKey).(float64)
+	if !ok {
+		return nil
+	}
+	delete(ses.Values, impersonatorSesKey)
+	return ses.Add("user_id", int64(id))
+}
+
+// sessionActorId return the id of the real user of the session values.
+func sessionActorId(values map[string]interface{}, userId int64) int64 {
+	if id, ok := values[impersonatorSesKey].(float64); ok {
+		return int64(id)
+	}
+	return userId
+}
+
+// impersonated return the impersonated user with the real user of the
+// session. The session goes back to the real user when the impersonation is
+// not allowed any more.
+func impersonated(ses *Session, user models.UserModel, actorId int64, conn db.Connection) (models.UserModel, bool) {
+	if actorId == user.Id {
+		return user, true
+	}
+
+	actor := models.User().SetConn(conn).Find(actorId)
+	if !actor.IsEmpty() {
+		actor = actor.WithRoles().WithPermissions()
+	}
+
+	if !CanImpersonate(actor, user) {
+		if err := stopImpersonate(ses); err != nil {
+			logger.Error("stop impersonate error: ", err)
+		}
+		return GetCurUserByID(actorId, conn)
+	}
+
+	user.ImpersonatorId = actor.Id
+	user.ImpersonatorName = actor.Name
+	return user, true
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+)
+
+func TestCanImpersonate(t *testing.T) {
+	super := models.UserModel{Id: 1, Permissions: []models.PermissionModel{{Slug: "*", HttpPath: []string{"*"}}}}
+	dashboard := models.PermissionModel{Slug: "dashboard", HttpPath: []string{"/"}}
+	roles := models.PermissionModel{Slug: "roles", HttpPath: []string{"/info/roles", "/edit/roles"}}
+	payouts := models.PermissionModel{Slug: "payouts", HttpPath: []string{"/info/payouts"}}
+	support := models.UserModel{Id: 2, Permissions: []models.PermissionModel{
+		{Slug: ImpersonatePermission, HttpPath: []string{"/operation/manager_impersonate"}}, dashboard,
+	}}
+	operator := models.UserModel{Id: 3, Permissions: []models.PermissionModel{dashboard}}
+
+	assert.Equal(t, CanImpersonate(super, operator), true)
+	assert.Equal(t, CanImpersonate(support, operator), true)
+	assert.Equal(t, CanImpersonate(operator, support), false)
+	assert.Equal(t, CanImpersonate(support, super), false)
+	assert.Equal(t, CanImpersonate(support, support), false)
+	assert.Equal(t, CanImpersonate(support, models.UserModel{}), false)
+
+	// the target with more rights than the actor, such as editing the
+	// roles, can not be impersonated
+	admin := models.UserModel{Id: 4, Permissions: []models.PermissionModel{dashboard, roles}}
+	assert.Equal(t, CanImpersonate(support, admin), false)
+	assert.Equal(t, CanImpersonate(super, admin), true)
+
+	// nor the target who is not denied what the actor is denied
+	limited := support
+	limited.DeniedPermissions = []models.PermissionModel{payouts}
+	assert.Equal(t, CanImpersonate(limited, operator), false)
+	operator.DeniedPermissions = []models.PermissionModel{payouts}
+	assert.Equal(t, CanImpersonate(limited, operator), true)
+
+	impersonated := support
+	impersonated.ImpersonatorId = super.Id
+	assert.Equal(t, CanImpersonate(impersonated, operator), false)
+}
@@ -0,0 +1,95 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"strings"
+	"time"
+
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/ldap"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+)
+
+// ldapProvider is the provider of the identities of the directory users.
+const ldapProvider = "ldap"
+
+// checkLDAP check the password against the directory. The local password of
+// a superadmin is checked when the directory rejects the login and the local
+// fallback is on.
+func checkLDAP(cfg config.Ldap, password, username string, conn db.Connection) (models.UserModel, bool) {
+
+	user, err := ldapUser(cfg, password, username, conn)
+	if err == nil {
+		return user, true
+	}
+
+	if !ldap.IsInvalidCredentials(err) && err != ldap.ErrUserNotFound {
+		logger.Error("ldap authentication error: ", err)
+	}
+
+	if cfg.LocalFallback {
+		if user, ok := checkLocal(password, username, conn); ok && user.IsSuperAdmin() {
+			logger.Warn("local fallback login of superadmin: ", username)
+			return user, true
+		}
+	}
+
+	return models.UserModel{}, false
+}
+
+func ldapUser(cfg config.Ldap, password, username string, conn db.Connection) (models.UserModel, error) {
+
+	entry, err := ldap.Authenticate(ldap.Config{
+		URL:                cfg.Url,
+		StartTLS:           cfg.StartTLS,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		BindDN:             cfg.BindDN,
+		BindPassword:       cfg.BindPassword,
+		BaseDN:             cfg.BaseDN,
+		UserFilter:         cfg.UserFilter,
+		Attributes:         []string{cfg.NameAttribute, cfg.GroupAttribute},
+		Timeout:            time.Duration(cfg.Timeout) * time.Second,
+	}, username, password)
+
+	if err != nil {
+		return models.UserModel{}, err
+	}
+
+	return externalUser(externalAccount{
+		provider: ldapProvider,
+		subject:  strings.ToLower(entry.DN),
+		username: username,
+		name:     entry.Value(cfg.NameAttribute),
+		// the usernames of the directory are managed by the company, the
+		// local user of the same name is the same person
+		linkExisting: true,
+		linkUsername: true,
+		autoCreate:   cfg.AutoCreate,
+		roles:        mapLDAPGroups(cfg, entry.Values(cfg.GroupAttribute)),
+		syncRoles:    len(cfg.GroupMapping) > 0,
+	}, conn)
+}
+
+// mapLDAPGroups return the role slugs of the group dns, or the default roles
+// when no group is mapped. The dns are compared case-insensitively.
+func mapLDAPGroups(cfg config.Ldap, groups []string) []string {
+	roles := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		for dn, slug := range cfg.GroupMapping {
+			if strings.EqualFold(strings.TrimSpace(dn), group) && !seen[slug] {
+				seen[slug] = true
+				roles = append(roles, slug)
+			}
+		}
+	}
+	if len(roles) == 0 {
+		return cfg.DefaultRoles
+	}
+	return roles
+}
@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wowucco/go-admin/modules/config"
+)
+
+func TestMapLDAPGroups(t *testing.T) {
+	cfg := config.Ldap{
+		GroupMapping: map[string]string{
+			"CN=Ops,OU=Groups,DC=example,DC=com":    "operator",
+			"cn=admins,ou=groups,dc=example,dc=com": "administrator",
+		},
+		DefaultRoles: []string{"viewer"},
+	}
+
+	assert.Equal(t, mapLDAPGroups(cfg, []string{"cn=ops,ou=groups,dc=example,dc=com", "cn=other"}), []string{"operator"})
+	assert.Equal(t, len(mapLDAPGroups(cfg, []string{"cn=ops,ou=groups,dc=example,dc=com", "cn=admins,ou=groups,dc=example,dc=com"})), 2)
+	assert.Equal(t, mapLDAPGroups(cfg, nil), []string{"viewer"})
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/wowucco/go-admin/modules/constant"
 	"github.com/wowucco/go-admin/modules/db"
 	"github.com/wowucco/go-admin/modules/errors"
+	"github.com/wowucco/go-admin/modules/file"
 	"github.com/wowucco/go-admin/modules/language"
 	"github.com/wowucco/go-admin/modules/logger"
 	"github.com/wowucco/go-admin/modules/page"
@@ -18,6 +19,7 @@ import (
 	"github.com/wowucco/go-admin/template/types"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
 // Invoker contains the callback functions which are used
@@ -117,7 +119,15 @@ type MiddlewareCallback func(ctx *context.Context)
 // Middleware get the auth middleware from Invoker.
 func (invoker *Invoker) Middleware() context.Handler {
 	return func(ctx *context.Context) {
-		user, authOk, permissionOk := Filter(ctx, invoker.conn)
+		user, authOk, permissionOk, ses := filter(ctx, invoker.conn)
+
+		if authOk && !isTwoFactorSetupPath(ctx.Request.URL.Path) && isTwoFactorSetupRequired(ses) {
+			ctx.Write(302, map[string]string{
+				"Location": config.Url("/2fa"),
+			}, ``)
+			ctx.Abort()
+			return
+		}
 
 		if authOk && permissionOk {
 			ctx.SetUserValue("user", user)
@@ -140,9 +150,21 @@ func (invoker *Invoker) Middleware() context.Handler {
 	}
 }
 
+// isTwoFactorSetupPath report if the path is allowed before the user enrolled
+// the two-factor authentication which a role requires.
+func isTwoFactorSetupPath(path string) bool {
+	return path == config.Url("/logout") || path == config.Url("/2fa") ||
+		strings.HasPrefix(path, config.Url("/2fa/"))
+}
+
 // Filter retrieve the user model from Context and check the permission
 // at the same time.
 func Filter(ctx *context.Context, conn db.Connection) (models.UserModel, bool, bool) {
+	user, authOk, permissionOk, _ := filter(ctx, conn)
+	return user, authOk, permissionOk
+}
+
+func filter(ctx *context.Context, conn db.Connection) (models.UserModel, bool, bool, *Session) {
 	var (
 		id   float64
 		ok   bool
@@ -153,20 +175,31 @@ func Filter(ctx *context.Context, conn db.Connection) (models.UserModel, bool, b
 
 	if err != nil {
 		logger.Error("retrieve auth user failed", err)
-		return user, false, false
+		return user, false, false, nil
 	}
 
 	if id, ok = ses.Get("user_id").(float64); !ok {
-		return user, false, false
+		return user, false, false, ses
 	}
 
+	// the session record is of the real user when it is impersonated
+	actorId := sessionActorId(ses.Values, int64(id))
+
 	user, ok = GetCurUserByID(int64(id), conn)
 
 	if !ok {
-		return user, false, false
+		return user, false, false, ses
 	}
 
-	return user, true, CheckPermissions(user, ctx.Request.URL.String(), ctx.Method(), ctx.PostForm())
+	if !checkSession(ctx, ses, actorId, conn) {
+		return user, false, false, ses
+	}
+
+	if user, ok = impersonated(ses, user, actorId, conn); !ok {
+		return user, false, false, ses
+	}
+
+	return user, true, CheckPermissions(user, ctx.Request.URL.String(), ctx.Method(), ctx.PostForm()), ses
 }
 
 const defaultUserIDSesKey = "user_id"
@@ -192,16 +225,53 @@ func GetCurUser(sesKey string, conn db.Connection) (user models.UserModel, ok bo
 		return
 	}
 
-	id := GetUserID(sesKey, conn)
-	if id == -1 {
+	values, err := sessionDriver(conn).Load(sesKey)
+	if err != nil {
+		logger.Error("retrieve auth user failed", err)
+		ok = false
+		return
+	}
+
+	id, isFloat := values[defaultUserIDSesKey].(float64)
+	if !isFloat {
 		ok = false
 		return
 	}
-	return GetCurUserByID(id, conn)
+
+	actorId := sessionActorId(values, int64(id))
+	if !sessionRecordValid(values, actorId, conn) {
+		ok = false
+		return
+	}
+
+	if user, ok = GetCurUserByID(int64(id), conn); ok && actorId != user.Id {
+		user.ImpersonatorId = actorId
+	}
+	return
 }
 
-// GetCurUserByID return the user model of given user id.
+// GetCurUserByID return the user model of given user id, which is cached
+// until the user, the roles, the permissions or the menus are edited. The
+// avatar is linked on every call, as the links may expire.
 func GetCurUserByID(id int64, conn db.Connection) (user models.UserModel, ok bool) {
+	if user, ok = loadCurUser(id, conn); ok {
+		user.Avatar = file.StoredURL(user.Avatar)
+	}
+	return
+}
+
+func loadCurUser(id int64, conn db.Connection) (user models.UserModel, ok bool) {
+
+	if user, ok = loadCachedUser(id); ok {
+		return
+	}
+
+	generation := userCacheGeneration()
+	defer func() {
+		if ok {
+			storeCachedUser(user, generation)
+		}
+	}()
 
 	user = models.User().SetConn(conn).Find(id)
 
@@ -210,12 +280,6 @@ func GetCurUserByID(id int64, conn db.Connection) (user models.UserModel, ok boo
 		return
 	}
 
-	if user.Avatar == "" || config.GetStore().Prefix == "" {
-		user.Avatar = ""
-	} else {
-		user.Avatar = config.GetStore().URL(user.Avatar)
-	}
-
 	user = user.WithRoles().WithPermissions().WithMenus()
 
 	ok = user.HasMenu()
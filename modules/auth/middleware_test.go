@@ -80,3 +80,66 @@ func TestCheckPermissions(t *testing.T) {
 	assert.Equal(t, CheckPermissions(user, "/admin/info/user_list?__goadmin_edit_pk=3&user_type=20", "get", param), true)
 	assert.Equal(t, CheckPermissions(user, "/admin/delete/user", "post", param), true)
 }
+
+func TestCheckPermissionsPattern(t *testing.T) {
+
+	user := models.UserModel{
+		Permissions: []models.PermissionModel{
+			{
+				HttpMethod: []string{"GET"},
+				HttpPath:   []string{"/info/.*/edit", "/detail/(user|role)"},
+			}, {
+				HttpMethod: []string{""},
+				HttpPath:   []string{"/update/(x"},
+			},
+		},
+	}
+
+	param := make(url.Values)
+
+	assert.Equal(t, CheckPermissions(user, config.Url("/info/user/edit"), "GET", param), true)
+	assert.Equal(t, CheckPermissions(user, config.Url("/info/user/edit"), "POST", param), false)
+	assert.Equal(t, CheckPermissions(user, config.Url("/info/user/edit/more"), "GET", param), false)
+	assert.Equal(t, CheckPermissions(user, config.Url("/detail/role"), "GET", param), true)
+	assert.Equal(t, CheckPermissions(user, config.Url("/detail/menu"), "GET", param), false)
+	assert.Equal(t, CheckPermissions(user, config.Url("/update/(x"), "POST", param), true)
+}
+
+func TestCheckPermissionsDenied(t *testing.T) {
+
+	// the allowed permission is inherited from the ancestor role of the
+	// user, and the denied ones from the assigned role
+	inherited := []models.PermissionModel{
+		{HttpMethod: []string{""}, HttpPath: []string{"/info/payouts", "/info/payouts/edit", "/edit/payouts"}},
+	}
+	denied := []models.PermissionModel{
+		{HttpMethod: []string{"POST"}, HttpPath: []string{"/edit/payouts"}},
+		{HttpMethod: []string{"GET"}, HttpPath: []string{"/info/payouts/edit?id=1"}},
+	}
+	super := []models.PermissionModel{{HttpMethod: []string{""}, HttpPath: []string{"*"}}}
+
+	cases := []struct {
+		name        string
+		permissions []models.PermissionModel
+		denied      []models.PermissionModel
+		path        string
+		method      string
+		want        bool
+	}{
+		{"inherited allow", inherited, nil, "/edit/payouts", "POST", true},
+		{"deny overrides inherited allow", inherited, denied, "/edit/payouts", "POST", false},
+		{"deny of another method", inherited, denied, "/edit/payouts", "GET", true},
+		{"deny of the params", inherited, denied, "/info/payouts/edit?id=1", "GET", false},
+		{"deny of other params", inherited, denied, "/info/payouts/edit?id=2", "GET", true},
+		{"not denied", inherited, denied, "/info/payouts", "GET", true},
+		{"logout is not denied", inherited, denied, "/logout", "POST", true},
+		{"super admin", super, nil, "/edit/payouts", "POST", true},
+		{"deny overrides super admin", super, denied, "/edit/payouts", "POST", false},
+		{"super admin not denied", super, denied, "/info/users", "GET", true},
+	}
+
+	for _, c := range cases {
+		user := models.UserModel{Permissions: c.permissions, DeniedPermissions: c.denied}
+		assert.Equal(t, c.want, CheckPermissions(user, config.Url(c.path), c.method, make(url.Values)), c.name)
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/oidc"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+)
+
+// CheckOIDC return the user of the validated claims of the provider. The
+// account is found by its linked identity, then by the verified email when
+// the provider links existing users, and is created when the provider allows
+// it.
+// The roles are synced with the role mapping of the provider.
+func CheckOIDC(p *oidc.Provider, claims oidc.Claims, conn db.Connection) (models.UserModel, error) {
+	return externalUser(externalAccount{
+		provider:      p.Name,
+		subject:       claims.Subject(),
+		username:      claims.Username(p.UsernameClaim),
+		name:          claims.String("name"),
+		email:         claims.String("email"),
+		emailVerified: claims.Bool("email_verified"),
+		linkExisting:  p.LinkExisting,
+		autoCreate:    p.AutoCreate,
+		roles:         p.MapRoles(claims),
+		syncRoles:     len(p.RoleMapping) > 0,
+	}, conn)
+}
@@ -0,0 +1,230 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/language"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+)
+
+// ErrInvalidResetToken is returned when the password reset token is unknown,
+// used or expired.
+var ErrInvalidResetToken = errors.New("the password reset link is invalid or expired")
+
+// CheckPasswordPolicy check the password against the rules of the policy. The
+// error message is translated.
+func CheckPasswordPolicy(cfg config.PasswordPolicy, username, password string) error {
+	if cfg.MinLength > 0 && len([]rune(password)) < cfg.MinLength {
+		return fmt.Errorf(language.Get("the password must have at least %d characters"), cfg.MinLength)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	switch {
+	case cfg.RequireUpper && !upper:
+		return errors.New(language.Get("the password must have an uppercase letter"))
+	case cfg.RequireLower && !lower:
+		return errors.New(language.Get("the password must have a lowercase letter"))
+	case cfg.RequireDigit && !digit:
+		return errors.New(language.Get("the password must have a digit"))
+	case cfg.RequireSymbol && !symbol:
+		return errors.New(language.Get("the password must have a symbol"))
+	case cfg.NotUsername && username != "" && strings.EqualFold(password, username):
+		return errors.New(language.Get("the password can not be the username"))
+	}
+
+	return nil
+}
+
+// CheckPassword check the new password of the user against the policy and
+// the password history. The history is skipped for a new user, whose id is
+// zero.
+func CheckPassword(userId int64, username, password string, conn db.Connection) error {
+	cfg := config.GetPasswordPolicy()
+
+	if err := CheckPasswordPolicy(cfg, username, password); err != nil {
+		return err
+	}
+
+	if userId == 0 || cfg.HistorySize <= 0 {
+		return nil
+	}
+
+	for _, h := range models.PasswordHistory().SetConn(conn).Latest(userId, cfg.HistorySize) {
+		if comparePassword(password, h.Password) {
+			return fmt.Errorf(language.Get("the password can not be one of the last %d passwords"), cfg.HistorySize)
+		}
+	}
+
+	return nil
+}
+
+// RecordPassword add the password hash to the history of the user, which
+// also starts the expiry of the password.
+func RecordPassword(userId int64, hash string, conn db.Connection) {
+	history := models.PasswordHistory().SetConn(conn)
+	if _, err := history.New(userId, hash); db.CheckError(err, db.INSERT) {
+		logger.Error("add password history error: ", err)
+		return
+	}
+
+	// the last one is kept for the expiry
+	keep := config.GetPasswordPolicy().HistorySize
+	if keep < 1 {
+		keep = 1
+	}
+	if err := history.Prune(userId, keep); db.CheckError(err, db.DELETE) {
+		logger.Error("prune password history error: ", err)
+	}
+}
+
+// PasswordExpired check the password of the user is older than the expire
+// days. The age of a password without history starts at the creation of the
+// user.
+func PasswordExpired(user models.UserModel, conn db.Connection) bool {
+	days := config.GetPasswordPolicy().ExpireDays
+	if days <= 0 {
+		return false
+	}
+
+	changedAt := user.CreatedAt
+	if latest := models.PasswordHistory().SetConn(conn).Latest(user.Id, 1); len(latest) > 0 {
+		changedAt = latest[0].CreatedAt
+	}
+
+	t, ok := parseTime(changedAt)
+	if !ok {
+		return false
+	}
+	return time.Since(t) > time.Duration(days)*24*time.Hour
+}
+
+// NewPasswordReset create a single-use token to set the password of the
+// user. Only the hash of the token is stored.
+func NewPasswordReset(userId int64, conn db.Connection) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+
+	reset := models.PasswordReset().SetConn(conn)
+
+	// the expired tokens of all the users are cleaned here, as the tokens
+	// are rarely created
+	now := time.Now()
+	if err := reset.DeleteExpired(now); db.CheckError(err, db.DELETE) {
+		logger.Error("delete expired password resets error: ", err)
+	}
+
+	expires := time.Duration(config.GetPasswordReset().ExpireMinutes) * time.Minute
+	if _, err := reset.New(userId, hashToken(token), now.Add(expires).Unix()); db.CheckError(err, db.INSERT) {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// PasswordResetUser return the user of the valid token.
+func PasswordResetUser(token string, conn db.Connection) (models.UserModel, error) {
+	if token == "" {
+		return models.UserModel{}, ErrInvalidResetToken
+	}
+	reset := models.PasswordReset().SetConn(conn).FindByToken(hashToken(token))
+	if reset.IsEmpty() || reset.IsExpired(time.Now()) {
+		return models.UserModel{}, ErrInvalidResetToken
+	}
+	user := models.User().SetConn(conn).Find(reset.UserId)
+	if user.IsEmpty() {
+		return user, ErrInvalidResetToken
+	}
+	return user, nil
+}
+
+// ResetPassword set the password of the user of the token. The tokens of the
+// user are deleted, so that the link can not be used again, and the sessions
+// of the user are ended.
+func ResetPassword(token, password string, conn db.Connection) (models.UserModel, error) {
+	user, err := PasswordResetUser(token, conn)
+	if err != nil {
+		return user, err
+	}
+
+	if err := CheckPassword(user.Id, user.UserName, password, conn); err != nil {
+		return user, err
+	}
+
+	// the token is consumed by one conditional delete, so that the requests
+	// of the same token can not both succeed
+	reset := models.PasswordReset().SetConn(conn)
+	consumed, err := reset.Consume(hashToken(token), time.Now())
+	if err != nil {
+		return user, err
+	}
+	if !consumed {
+		return user, ErrInvalidResetToken
+	}
+
+	if err := reset.DeleteByUserId(user.Id); db.CheckError(err, db.DELETE) {
+		return user, err
+	}
+
+	hash := EncodePassword([]byte(password))
+	user = user.UpdatePwd(hash)
+	InvalidateUserCache(user.Id)
+	RecordPassword(user.Id, hash, conn)
+
+	if err := RevokeUserSessions(user.Id, "", conn); err != nil {
+		logger.Error("revoke user sessions error: ", err)
+	}
+
+	return user, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// timeLayouts are the formats of the timestamps returned by the drivers.
+var timeLayouts = []string{
+	"2006-01-02 15:04:05",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05.999999999",
+}
+
+func parseTime(value string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
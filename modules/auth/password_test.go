@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wowucco/go-admin/modules/config"
+)
+
+func TestCheckPasswordPolicy(t *testing.T) {
+	assert.Equal(t, CheckPasswordPolicy(config.PasswordPolicy{}, "admin", "admin"), nil)
+
+	cfg := config.PasswordPolicy{
+		MinLength:     8,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		NotUsername:   true,
+	}
+
+	assert.NotEqual(t, CheckPasswordPolicy(cfg, "admin", "Ab1!"), nil)
+	assert.NotEqual(t, CheckPasswordPolicy(cfg, "admin", "abcdefg1!"), nil)
+	assert.NotEqual(t, CheckPasswordPolicy(cfg, "admin", "ABCDEFG1!"), nil)
+	assert.NotEqual(t, CheckPasswordPolicy(cfg, "admin", "Abcdefgh!"), nil)
+	assert.NotEqual(t, CheckPasswordPolicy(cfg, "admin", "Abcdefgh1"), nil)
+	assert.NotEqual(t, CheckPasswordPolicy(cfg, "Admin_1234", "admin_1234"), nil)
+	assert.Equal(t, CheckPasswordPolicy(cfg, "admin", "Abcdefg1!"), nil)
+
+	// the length counts the characters instead of the bytes
+	assert.Equal(t, CheckPasswordPolicy(config.PasswordPolicy{MinLength: 4}, "", "密码密码"), nil)
+	assert.NotEqual(t, CheckPasswordPolicy(config.PasswordPolicy{MinLength: 4}, "", "密码"), nil)
+}
+
+func TestParseTime(t *testing.T) {
+	for _, value := range []string{"2020-06-29 10:00:00", "2020-06-29T10:00:00Z", "2020-06-29T10:00:00+08:00"} {
+		tm, ok := parseTime(value)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, tm.Year(), 2020)
+	}
+	_, ok := parseTime("")
+	assert.Equal(t, ok, false)
+}
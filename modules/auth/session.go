@@ -10,7 +10,6 @@ import (
 	"github.com/wowucco/go-admin/modules/config"
 	"github.com/wowucco/go-admin/modules/db"
 	"github.com/wowucco/go-admin/modules/db/dialect"
-	"github.com/wowucco/go-admin/modules/logger"
 	"github.com/wowucco/go-admin/plugins/admin/modules"
 	"net/http"
 	"strconv"
@@ -33,9 +32,21 @@ type PersistenceDriver interface {
 	Update(sid string, values map[string]interface{}) error
 }
 
+// Sweeper is implemented by the drivers which have to delete the expired
+// sessions. The sweeps of the session store run in a single background task.
+type Sweeper interface {
+	Sweep() error
+}
+
+// CookieEncoder is implemented by the drivers which keep the values in the
+// cookie instead of a session id.
+type CookieEncoder interface {
+	Encode(values map[string]interface{}) (string, error)
+}
+
 // GetSessionByKey get the session value by key.
 func GetSessionByKey(sesKey, key string, conn db.Connection) (interface{}, error) {
-	m, err := newDBDriver(conn).Load(sesKey)
+	m, err := sessionDriver(conn).Load(sesKey)
 	return m[key], err
 }
 
@@ -69,17 +80,44 @@ func (ses *Session) Get(key string) interface{} {
 // Add add the session value of key.
 func (ses *Session) Add(key string, value interface{}) error {
 	ses.Values[key] = value
+	return ses.Save()
+}
+
+// Clear clear a Session.
+func (ses *Session) Clear() error {
+	ses.Values = map[string]interface{}{}
+	return ses.Save()
+}
+
+// Save store the values by the driver and set the cookie. The cookie is
+// removed when the values are empty.
+func (ses *Session) Save() error {
 	if err := ses.Driver.Update(ses.Sid, ses.Values); err != nil {
 		return err
 	}
+
+	value := ses.Sid
+	if encoder, ok := ses.Driver.(CookieEncoder); ok && len(ses.Values) > 0 {
+		var err error
+		if value, err = encoder.Encode(ses.Values); err != nil {
+			return err
+		}
+		ses.Sid = value
+	}
+
 	cookie := http.Cookie{
 		Name:     ses.Cookie,
-		Value:    ses.Sid,
+		Value:    value,
 		MaxAge:   config.GetSessionLifeTime(),
 		Expires:  time.Now().Add(ses.Expires),
 		HttpOnly: true,
 		Path:     "/",
 	}
+	if len(ses.Values) == 0 {
+		cookie.Value = ""
+		cookie.MaxAge = -1
+		cookie.Expires = time.Unix(0, 0)
+	}
 	if config.GetDomain() != "" {
 		cookie.Domain = config.GetDomain()
 	}
@@ -87,12 +125,6 @@ func (ses *Session) Add(key string, value interface{}) error {
 	return nil
 }
 
-// Clear clear a Session.
-func (ses *Session) Clear() error {
-	ses.Values = map[string]interface{}{}
-	return ses.Driver.Update(ses.Sid, ses.Values)
-}
-
 // UseDriver set the driver of the Session.
 func (ses *Session) UseDriver(driver PersistenceDriver) {
 	ses.Driver = driver
@@ -125,7 +157,7 @@ func InitSession(ctx *context.Context, conn db.Connection) (*Session, error) {
 		Cookie:  DefaultCookieKey,
 	})
 
-	sessions.UseDriver(newDBDriver(conn))
+	sessions.UseDriver(sessionDriver(conn))
 	sessions.Values = make(map[string]interface{})
 
 	return sessions.StartCtx(ctx)
@@ -154,14 +186,8 @@ func (driver *DBDriver) Load(sid string) (map[string]interface{}, error) {
 	return values, err
 }
 
-func (driver *DBDriver) deleteOverdueSession() {
-
-	defer func() {
-		if err := recover(); err != nil {
-			logger.Error(err)
-			panic(err)
-		}
-	}()
+// Sweep implements the Sweeper.Sweep.
+func (driver *DBDriver) Sweep() error {
 
 	var (
 		duration   = strconv.Itoa(config.GetSessionLifeTime() + 1000)
@@ -179,16 +205,19 @@ func (driver *DBDriver) deleteOverdueSession() {
 		raw = `DATEDIFF(second, [created_at], GETDATE()) > ` + duration
 	}
 
-	if raw != "" {
-		_ = driver.table().WhereRaw(raw).Delete()
+	if raw == "" {
+		return nil
 	}
+
+	if err := driver.table().WhereRaw(raw).Delete(); db.CheckError(err, db.DELETE) {
+		return err
+	}
+	return nil
 }
 
 // Update implements the PersistenceDriver.Update.
 func (driver *DBDriver) Update(sid string, values map[string]interface{}) error {
 
-	go driver.deleteOverdueSession()
-
 	if sid != "" {
 		if len(values) == 0 {
 			err := driver.table().Where("sid", "=", sid).Delete()
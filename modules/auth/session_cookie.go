@@ -0,0 +1,98 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const cookieSecretMinLength = 16
+
+// CookieDriver is a driver which keeps the values in the cookie, encrypted
+// and signed by AES-GCM with a key derived from the secret. Nothing is stored
+// on the server, so a session can not be ended before the expiry except by
+// changing the secret.
+type CookieDriver struct {
+	aead cipher.AEAD
+}
+
+type cookiePayload struct {
+	Values    map[string]interface{} `json:"v"`
+	ExpiresAt int64                  `json:"e"`
+}
+
+// NewCookieDriver return a CookieDriver of the secret, which is shared by all
+// the instances.
+func NewCookieDriver(secret string) (*CookieDriver, error) {
+	if len(secret) < cookieSecretMinLength {
+		return nil, errors.New("the secret of the cookie session store needs at least 16 characters")
+	}
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &CookieDriver{aead: aead}, nil
+}
+
+// Load implements the PersistenceDriver.Load, the sid is the cookie value. A
+// cookie which can not be opened or is expired is an empty session.
+func (driver *CookieDriver) Load(sid string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+
+	data, err := base64.RawURLEncoding.DecodeString(sid)
+	if err != nil || len(data) < driver.aead.NonceSize() {
+		return values, nil
+	}
+	nonce, sealed := data[:driver.aead.NonceSize()], data[driver.aead.NonceSize():]
+	plain, err := driver.aead.Open(nil, nonce, sealed, []byte(DefaultCookieKey))
+	if err != nil {
+		return values, nil
+	}
+
+	var payload cookiePayload
+	if err := json.Unmarshal(plain, &payload); err != nil || payload.ExpiresAt <= time.Now().Unix() {
+		return values, nil
+	}
+	if payload.Values != nil {
+		values = payload.Values
+	}
+	return values, nil
+}
+
+// Update implements the PersistenceDriver.Update, the values are written by
+// Encode to the cookie.
+func (driver *CookieDriver) Update(sid string, values map[string]interface{}) error {
+	return nil
+}
+
+// Encode implements the CookieEncoder.Encode.
+func (driver *CookieDriver) Encode(values map[string]interface{}) (string, error) {
+	plain, err := json.Marshal(cookiePayload{
+		Values:    values,
+		ExpiresAt: time.Now().Add(sessionLifeTime()).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, driver.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(driver.aead.Seal(nonce, nonce, plain, []byte(DefaultCookieKey))), nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRedisPrefix   = "goadmin:session:"
+	defaultRedisTimeout  = 5 * time.Second
+	defaultRedisPoolSize = 10
+)
+
+// RedisConfig is the config of the redis session store, for example:
+//
+//	{
+//	    "name": "redis",
+//	    "config": {
+//	        "addr": "127.0.0.1:6379",
+//	        "password": "...",
+//	        "db": 0,
+//	        "prefix": "goadmin:session:"
+//	    }
+//	}
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+	// Prefix of the keys, default is "goadmin:session:".
+	Prefix   string
+	Timeout  time.Duration
+	PoolSize int
+}
+
+// RedisConfigFromMap parse the session store config.
+func RedisConfigFromMap(m map[string]interface{}) RedisConfig {
+	cfg := RedisConfig{
+		Addr:     sessionConfigString(m, "addr"),
+		Password: sessionConfigString(m, "password"),
+		Prefix:   sessionConfigString(m, "prefix"),
+	}
+	cfg.DB, _ = strconv.Atoi(sessionConfigString(m, "db"))
+	cfg.PoolSize, _ = strconv.Atoi(sessionConfigString(m, "pool_size"))
+	if timeout, err := strconv.Atoi(sessionConfigString(m, "timeout")); err == nil {
+		cfg.Timeout = time.Duration(timeout) * time.Second
+	}
+	return cfg
+}
+
+// RedisDriver is a driver which stores the sessions in a server speaking the
+// redis protocol. The keys expire with the sessions, so it needs no sweep.
+type RedisDriver struct {
+	cfg  RedisConfig
+	pool chan *redisConn
+}
+
+// NewRedisDriver return a RedisDriver of the config. The connections are
+// opened on demand.
+func NewRedisDriver(cfg RedisConfig) (*RedisDriver, error) {
+	if cfg.Addr == "" {
+		cfg.Addr = "127.0.0.1:6379"
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultRedisPrefix
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultRedisTimeout
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = defaultRedisPoolSize
+	}
+	return &RedisDriver{cfg: cfg, pool: make(chan *redisConn, cfg.PoolSize)}, nil
+}
+
+// Load implements the PersistenceDriver.Load.
+func (driver *RedisDriver) Load(sid string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if sid == "" {
+		return values, nil
+	}
+
+	reply, err := driver.do("GET", driver.cfg.Prefix+sid)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := reply.(string)
+	if !ok {
+		return values, nil
+	}
+
+	err = json.Unmarshal([]byte(data), &values)
+	return values, err
+}
+
+// Update implements the PersistenceDriver.Update.
+func (driver *RedisDriver) Update(sid string, values map[string]interface{}) error {
+	if sid == "" {
+		return nil
+	}
+
+	key := driver.cfg.Prefix + sid
+
+	if len(values) == 0 {
+		_, err := driver.do("DEL", key)
+		return err
+	}
+
+	b, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	ttl := strconv.Itoa(int(sessionLifeTime() / time.Second))
+
+	_, err = driver.do("SET", key, string(b), "EX", ttl)
+	return err
+}
+
+// redisError is an error reply of the server, the connection is still usable.
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (driver *RedisDriver) do(args ...string) (interface{}, error) {
+	c, err := driver.get()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := c.do(driver.cfg.Timeout, args...)
+	if _, ok := err.(redisError); err != nil && !ok {
+		_ = c.conn.Close()
+		return nil, err
+	}
+
+	select {
+	case driver.pool <- c:
+	default:
+		_ = c.conn.Close()
+	}
+	return reply, err
+}
+
+func (driver *RedisDriver) get() (*redisConn, error) {
+	select {
+	case c := <-driver.pool:
+		return c, nil
+	default:
+	}
+
+	conn, err := net.DialTimeout("tcp", driver.cfg.Addr, driver.cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+
+	if driver.cfg.Password != "" {
+		if _, err := c.do(driver.cfg.Timeout, "AUTH", driver.cfg.Password); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	if driver.cfg.DB != 0 {
+		if _, err := c.do(driver.cfg.Timeout, "SELECT", strconv.Itoa(driver.cfg.DB)); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *redisConn) do(timeout time.Duration, args ...string) (interface{}, error) {
+	_ = c.conn.SetDeadline(time.Now().Add(timeout))
+
+	cmd := "*" + strconv.Itoa(len(args)) + "\r\n"
+	for _, arg := range args {
+		cmd += "$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n"
+	}
+	if _, err := io.WriteString(c.conn, cmd); err != nil {
+		return nil, err
+	}
+
+	return c.read()
+}
+
+// read return a reply of the redis protocol: a string, an int64, nil or a
+// []interface{}.
+func (c *redisConn) read() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.New("redis: wrong reply " + strconv.Quote(line))
+	}
+	line = line[:len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: wrong reply type %q", line[0])
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRedis serves the commands of the redis driver from a map.
+func fakeRedis(t *testing.T, password string) (net.Listener, map[string]string, *sync.Mutex) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		lock sync.Mutex
+		data = make(map[string]string)
+	)
+
+	serve := func(conn net.Conn) {
+		defer func() {
+			_ = conn.Close()
+		}()
+		r := bufio.NewReader(conn)
+		authed := password == ""
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+			args := make([]string, n)
+			for i := range args {
+				l, _ := r.ReadString('\n')
+				size, _ := strconv.Atoi(strings.TrimSpace(l[1:]))
+				buf := make([]byte, size+2)
+				_, _ = io.ReadFull(r, buf)
+				args[i] = string(buf[:size])
+			}
+
+			reply := "+OK\r\n"
+			lock.Lock()
+			switch cmd := strings.ToUpper(args[0]); {
+			case cmd == "AUTH":
+				if args[1] == password {
+					authed = true
+				} else {
+					reply = "-WRONGPASS invalid password\r\n"
+				}
+			case !authed:
+				reply = "-NOAUTH Authentication required.\r\n"
+			case cmd == "GET":
+				if v, ok := data[args[1]]; ok {
+					reply = "$" + strconv.Itoa(len(v)) + "\r\n" + v + "\r\n"
+				} else {
+					reply = "$-1\r\n"
+				}
+			case cmd == "SET":
+				data[args[1]] = args[2]
+			case cmd == "DEL":
+				_, ok := data[args[1]]
+				delete(data, args[1])
+				reply = ":0\r\n"
+				if ok {
+					reply = ":1\r\n"
+				}
+			default:
+				reply = "-ERR unknown command\r\n"
+			}
+			lock.Unlock()
+			_, _ = conn.Write([]byte(reply))
+		}
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn)
+		}
+	}()
+
+	return ln, data, &lock
+}
+
+func TestRedisDriver(t *testing.T) {
+	ln, data, lock := fakeRedis(t, "secret")
+	defer func() {
+		_ = ln.Close()
+	}()
+	addr := ln.Addr().String()
+
+	driver, _ := NewRedisDriver(RedisConfigFromMap(map[string]interface{}{
+		"addr":     addr,
+		"password": "secret",
+	}))
+
+	assert.Equal(t, driver.Update("a", map[string]interface{}{"user_id": 1}), nil)
+	values, err := driver.Load("a")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["user_id"], float64(1))
+
+	lock.Lock()
+	assert.Equal(t, data[defaultRedisPrefix+"a"], `{"user_id":1}`)
+	lock.Unlock()
+
+	values, err = driver.Load("b")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(values), 0)
+
+	assert.Equal(t, driver.Update("a", map[string]interface{}{}), nil)
+	values, _ = driver.Load("a")
+	assert.Equal(t, len(values), 0)
+
+	wrong, _ := NewRedisDriver(RedisConfig{Addr: addr, Password: "wrong"})
+	_, err = wrong.Load("a")
+	assert.NotEqual(t, err, nil)
+}
@@ -0,0 +1,195 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/logger"
+)
+
+// SessionDriverGenerator is a function return the PersistenceDriver of the
+// session store config.
+type SessionDriverGenerator func(cfg map[string]interface{}, conn db.Connection) (PersistenceDriver, error)
+
+var sessionDriverList = map[string]SessionDriverGenerator{
+	"database": func(cfg map[string]interface{}, conn db.Connection) (PersistenceDriver, error) {
+		return newDBDriver(conn), nil
+	},
+	"memory": func(cfg map[string]interface{}, conn db.Connection) (PersistenceDriver, error) {
+		return NewMemoryDriver(), nil
+	},
+	"cookie": func(cfg map[string]interface{}, conn db.Connection) (PersistenceDriver, error) {
+		return NewCookieDriver(sessionConfigString(cfg, "secret"))
+	},
+	"redis": func(cfg map[string]interface{}, conn db.Connection) (PersistenceDriver, error) {
+		return NewRedisDriver(RedisConfigFromMap(cfg))
+	},
+}
+
+var sessionStore = struct {
+	sync.Mutex
+	driver PersistenceDriver
+}{}
+
+// AddSessionDriver makes a session driver generator available by the provided
+// name. If Add is called twice with the same name or if the generator is nil,
+// it panics.
+func AddSessionDriver(name string, gen SessionDriverGenerator) {
+	sessionStore.Lock()
+	defer sessionStore.Unlock()
+	if gen == nil {
+		panic("session driver generator is nil")
+	}
+	if _, dup := sessionDriverList[name]; dup {
+		panic("add session driver generator twice " + name)
+	}
+	sessionDriverList[name] = gen
+}
+
+// InitSessionStore create the driver of the session store config and start
+// the sweeper of it. The database driver is used until it is called, later
+// calls do nothing.
+func InitSessionStore(conn db.Connection) error {
+	sessionStore.Lock()
+	defer sessionStore.Unlock()
+
+	if sessionStore.driver != nil {
+		return nil
+	}
+
+	cfg := config.GetSessionStore()
+	gen, ok := sessionDriverList[cfg.Name]
+	if !ok {
+		return errors.New("wrong session store name: " + cfg.Name)
+	}
+	driver, err := gen(cfg.Config, conn)
+	if err != nil {
+		return err
+	}
+	sessionStore.driver = driver
+
+	if sweeper, ok := driver.(Sweeper); ok {
+		go sweepSessions(sweeper, time.Duration(cfg.SweepInterval)*time.Second)
+	}
+
+	return nil
+}
+
+func sessionDriver(conn db.Connection) PersistenceDriver {
+	sessionStore.Lock()
+	defer sessionStore.Unlock()
+	if sessionStore.driver == nil {
+		return newDBDriver(conn)
+	}
+	return sessionStore.driver
+}
+
+func sweepSessions(sweeper Sweeper, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := sweeper.Sweep(); err != nil {
+			logger.Error("sweep sessions error: ", err)
+		}
+		<-ticker.C
+	}
+}
+
+func sessionLifeTime() time.Duration {
+	if config.GetSessionLifeTime() <= 0 {
+		// the default of the config
+		return 2 * time.Hour
+	}
+	return time.Duration(config.GetSessionLifeTime()) * time.Second
+}
+
+func sessionConfigString(m map[string]interface{}, key string) string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// MemoryDriver is a driver which keeps the sessions in the process, they are
+// lost at the restart and not shared by the instances.
+type MemoryDriver struct {
+	lock     sync.Mutex
+	sessions map[string]memorySession
+}
+
+type memorySession struct {
+	values    []byte
+	expiresAt time.Time
+}
+
+// NewMemoryDriver return an empty MemoryDriver.
+func NewMemoryDriver() *MemoryDriver {
+	return &MemoryDriver{sessions: make(map[string]memorySession)}
+}
+
+// Load implements the PersistenceDriver.Load.
+func (driver *MemoryDriver) Load(sid string) (map[string]interface{}, error) {
+	driver.lock.Lock()
+	ses, ok := driver.sessions[sid]
+	driver.lock.Unlock()
+
+	values := make(map[string]interface{})
+	if !ok || !ses.expiresAt.After(time.Now()) {
+		return values, nil
+	}
+	// the values are decoded as the ones of the database
+	err := json.Unmarshal(ses.values, &values)
+	return values, err
+}
+
+// Update implements the PersistenceDriver.Update.
+func (driver *MemoryDriver) Update(sid string, values map[string]interface{}) error {
+	if sid == "" {
+		return nil
+	}
+
+	driver.lock.Lock()
+	defer driver.lock.Unlock()
+
+	if len(values) == 0 {
+		delete(driver.sessions, sid)
+		return nil
+	}
+
+	b, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	driver.sessions[sid] = memorySession{values: b, expiresAt: time.Now().Add(sessionLifeTime())}
+	return nil
+}
+
+// Sweep implements the Sweeper.Sweep.
+func (driver *MemoryDriver) Sweep() error {
+	now := time.Now()
+	driver.lock.Lock()
+	defer driver.lock.Unlock()
+	for id, ses := range driver.sessions {
+		if !ses.expiresAt.After(now) {
+			delete(driver.sessions, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wowucco/go-admin/context"
+)
+
+func TestMemoryDriver(t *testing.T) {
+	driver := NewMemoryDriver()
+
+	assert.Equal(t, driver.Update("a", map[string]interface{}{"user_id": 1}), nil)
+	values, err := driver.Load("a")
+	assert.Equal(t, err, nil)
+	// the numbers are float64 as the ones of the database driver
+	assert.Equal(t, values["user_id"], float64(1))
+
+	values, _ = driver.Load("b")
+	assert.Equal(t, len(values), 0)
+
+	assert.Equal(t, driver.Update("a", map[string]interface{}{}), nil)
+	values, _ = driver.Load("a")
+	assert.Equal(t, len(values), 0)
+
+	_ = driver.Update("c", map[string]interface{}{"user_id": 2})
+	driver.sessions["c"] = memorySession{values: driver.sessions["c"].values, expiresAt: time.Now().Add(-time.Second)}
+	values, _ = driver.Load("c")
+	assert.Equal(t, len(values), 0)
+	assert.Equal(t, driver.Sweep(), nil)
+	assert.Equal(t, len(driver.sessions), 0)
+}
+
+func TestCookieDriver(t *testing.T) {
+	_, err := NewCookieDriver("short")
+	assert.NotEqual(t, err, nil)
+
+	driver, err := NewCookieDriver("0123456789abcdef0123456789abcdef")
+	assert.Equal(t, err, nil)
+
+	value, err := driver.Encode(map[string]interface{}{"user_id": 1})
+	assert.Equal(t, err, nil)
+
+	values, err := driver.Load(value)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values["user_id"], float64(1))
+
+	// a changed cookie or another secret is an empty session
+	tampered := []byte(value)
+	tampered[len(tampered)/2] ^= 1
+	values, _ = driver.Load(string(tampered))
+	assert.Equal(t, len(values), 0)
+
+	other, _ := NewCookieDriver("fedcba9876543210fedcba9876543210")
+	values, _ = other.Load(value)
+	assert.Equal(t, len(values), 0)
+
+	values, _ = driver.Load("not-a-cookie")
+	assert.Equal(t, len(values), 0)
+}
+
+func TestSessionSaveCookieDriver(t *testing.T) {
+	driver, _ := NewCookieDriver("0123456789abcdef0123456789abcdef")
+
+	ctx := context.NewContext(httptest.NewRequest("GET", "/", nil))
+	ses := &Session{Cookie: DefaultCookieKey, Driver: driver, Values: map[string]interface{}{}, Sid: "sid", Context: ctx}
+	assert.Equal(t, ses.Add("user_id", 1), nil)
+
+	cookie := ctx.Response.Header.Get("Set-Cookie")
+	assert.Equal(t, strings.HasPrefix(cookie, DefaultCookieKey+"="+ses.Sid+";"), true)
+
+	values, _ := driver.Load(ses.Sid)
+	assert.Equal(t, values["user_id"], float64(1))
+
+	ctx = context.NewContext(httptest.NewRequest("GET", "/", nil))
+	ses.Context = ctx
+	assert.Equal(t, ses.Clear(), nil)
+	assert.Equal(t, strings.Contains(ctx.Response.Header.Get("Set-Cookie"), "Max-Age=0"), true)
+}
@@ -0,0 +1,204 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/modules/utils"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+)
+
+const maxThrottleValueLength = 255
+
+type throttleKey struct {
+	kind  string
+	value string
+	limit int
+}
+
+func loginThrottleKeys(cfg config.LoginLimit, username, ip string) []throttleKey {
+	keys := make([]throttleKey, 0, 2)
+	// the usernames are compared case-insensitively by some databases
+	if username = strings.ToLower(strings.TrimSpace(username)); username != "" {
+		if len(username) > maxThrottleValueLength {
+			username = username[:maxThrottleValueLength]
+		}
+		keys = append(keys, throttleKey{models.LoginThrottleUsername, username, cfg.MaxAttempts})
+	}
+	if ip != "" {
+		keys = append(keys, throttleKey{models.LoginThrottleIP, ip, cfg.IPMaxAttempts})
+	}
+	return keys
+}
+
+// LoginIP return the ip of the client of the login request. The forwarded
+// headers can be sent by anyone, so they are only read when the request comes
+// from a trusted proxy of the login limit config.
+func LoginIP(ctx *context.Context) string {
+	return loginIP(ctx.Request, config.GetLoginLimit().TrustedProxies)
+}
+
+func loginIP(req *http.Request, proxies []string) string {
+	remote := strings.TrimSpace(req.RemoteAddr)
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+
+	if !isTrustedProxy(remote, proxies) {
+		return remote
+	}
+
+	// the proxies append the ip of their client, the first untrusted one
+	// from the right is the client
+	ips := strings.Split(req.Header.Get("X-Forwarded-For"), ",")
+	for i := len(ips) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(ips[i])
+		if ip != "" && !isTrustedProxy(ip, proxies) {
+			return ip
+		}
+	}
+
+	if ip := strings.TrimSpace(req.Header.Get("X-Real-Ip")); ip != "" {
+		return ip
+	}
+
+	return remote
+}
+
+func isTrustedProxy(ip string, proxies []string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, proxy := range proxies {
+		if strings.Contains(proxy, "/") {
+			if _, network, err := net.ParseCIDR(proxy); err == nil && network.Contains(addr) {
+				return true
+			}
+		} else if trusted := net.ParseIP(proxy); trusted != nil && trusted.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoginLockout return how long the logins of the username or the ip are
+// locked, it is zero when they are not.
+func LoginLockout(username, ip string, conn db.Connection) time.Duration {
+	cfg := config.GetLoginLimit()
+	if cfg.Off {
+		return 0
+	}
+
+	now := time.Now()
+	var wait time.Duration
+
+	for _, key := range loginThrottleKeys(cfg, username, ip) {
+		t := models.LoginThrottle().SetConn(conn).Find(key.kind, key.value)
+		if t.IsLocked(now) {
+			if d := time.Unix(t.LockedUntil, 0).Sub(now); d > wait {
+				wait = d
+			}
+		}
+	}
+
+	return wait
+}
+
+// LoginFailed count a failed login of the username and the ip. The lockouts
+// are recorded in the operation log.
+func LoginFailed(username, ip string, conn db.Connection) {
+	cfg := config.GetLoginLimit()
+	if cfg.Off {
+		return
+	}
+
+	now := time.Now()
+
+	for _, key := range loginThrottleKeys(cfg, username, ip) {
+		t := models.LoginThrottle().SetConn(conn)
+		t.Kind, t.Value = key.kind, key.value
+
+		t, err := t.Fail(now.Unix(), resetBefore(cfg, now))
+		if err != nil {
+			logger.Error("save login throttle error: ", err)
+			continue
+		}
+
+		lockout := lockoutDuration(cfg, t.Failures, int64(key.limit))
+		if lockout <= 0 {
+			continue
+		}
+
+		if t, err = t.Lock(now.Add(lockout).Unix()); err != nil {
+			logger.Error("save login throttle error: ", err)
+			continue
+		}
+
+		recordLockout(t, username, ip, conn)
+	}
+}
+
+// LoginSucceeded reset the failures of the username.
+func LoginSucceeded(username string, conn db.Connection) {
+	cfg := config.GetLoginLimit()
+	if cfg.Off {
+		return
+	}
+
+	for _, key := range loginThrottleKeys(cfg, username, "") {
+		t := models.LoginThrottle().SetConn(conn).Find(key.kind, key.value)
+		if !t.IsEmpty() {
+			if err := t.Delete(); db.CheckError(err, db.DELETE) {
+				logger.Error("delete login throttle error: ", err)
+			}
+		}
+	}
+}
+
+// resetBefore return the time before which the failures are forgotten, they
+// are counted again after a quiet period since the last failure or lockout.
+func resetBefore(cfg config.LoginLimit, now time.Time) int64 {
+	return now.Unix() - int64(cfg.ResetSeconds)
+}
+
+// lockoutDuration return the lockout after the failures, which is doubled on
+// every failure beyond the max attempts.
+func lockoutDuration(cfg config.LoginLimit, failures, maxAttempts int64) time.Duration {
+	if maxAttempts <= 0 || failures < maxAttempts {
+		return 0
+	}
+	seconds := int64(cfg.LockoutSeconds)
+	for i := maxAttempts; i < failures && seconds < int64(cfg.MaxLockoutSeconds); i++ {
+		seconds *= 2
+	}
+	if seconds > int64(cfg.MaxLockoutSeconds) {
+		seconds = int64(cfg.MaxLockoutSeconds)
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func recordLockout(t models.LoginThrottleModel, username, ip string, conn db.Connection) {
+	var userId int64
+	if t.Kind == models.LoginThrottleUsername {
+		userId = models.User().SetConn(conn).FindByUserName(username).Id
+	}
+
+	models.OperationLog().SetConn(conn).New(userId, config.Url("/signin"), "POST", ip, utils.JSON(map[string]interface{}{
+		"event":        "login lockout",
+		"kind":         t.Kind,
+		"value":        t.Value,
+		"failures":     t.Failures,
+		"locked_until": time.Unix(t.LockedUntil, 0).Format("2006-01-02 15:04:05"),
+	}))
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wowucco/go-admin/modules/config"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	cfg := config.LoginLimit{LockoutSeconds: 60, MaxLockoutSeconds: 300}
+
+	assert.Equal(t, lockoutDuration(cfg, 4, 5), time.Duration(0))
+	assert.Equal(t, lockoutDuration(cfg, 5, 5), time.Minute)
+	assert.Equal(t, lockoutDuration(cfg, 6, 5), 2*time.Minute)
+	assert.Equal(t, lockoutDuration(cfg, 7, 5), 4*time.Minute)
+	assert.Equal(t, lockoutDuration(cfg, 8, 5), 5*time.Minute)
+	assert.Equal(t, lockoutDuration(cfg, 100, 5), 5*time.Minute)
+	assert.Equal(t, lockoutDuration(cfg, 100, 0), time.Duration(0))
+}
+
+func TestLoginIP(t *testing.T) {
+	proxies := []string{"10.0.0.0/8", "192.168.1.1"}
+
+	req := httptest.NewRequest("POST", "/admin/signin", nil)
+	req.RemoteAddr = "203.0.113.7:5000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	req.Header.Set("X-Real-Ip", "198.51.100.2")
+
+	// the headers of a client are not trusted
+	assert.Equal(t, "203.0.113.7", loginIP(req, proxies))
+	assert.Equal(t, "203.0.113.7", loginIP(req, nil))
+
+	// the client can prepend forged ips to the header of the proxies
+	req.RemoteAddr = "10.1.2.3:5000"
+	req.Header.Set("X-Forwarded-For", "1.1.1.1, 198.51.100.1, 192.168.1.1")
+	assert.Equal(t, "198.51.100.1", loginIP(req, proxies))
+
+	req.Header.Del("X-Forwarded-For")
+	assert.Equal(t, "198.51.100.2", loginIP(req, proxies))
+
+	req.Header.Del("X-Real-Ip")
+	assert.Equal(t, "10.1.2.3", loginIP(req, proxies))
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the sha1 secret of the test vectors of RFC 6238
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestHOTP(t *testing.T) {
+	key, err := decodeTOTPSecret(rfcSecret)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, string(key), "12345678901234567890")
+
+	for unix, code := range map[int64]string{
+		59:         "94287082",
+		1111111109: "07081804",
+		1234567890: "89005924",
+		2000000000: "69279037",
+	} {
+		assert.Equal(t, hotp(key, uint64(unix/TOTPPeriod), 8), code)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+
+	code, err := TOTPCode(rfcSecret, now)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, code, "005924")
+
+	step, ok := ValidateTOTP(rfcSecret, code, now, 0)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, step, TOTPStep(now))
+
+	// the clock of the phone may drift one step
+	_, ok = ValidateTOTP(rfcSecret, code, now.Add(TOTPPeriod*time.Second), 0)
+	assert.Equal(t, ok, true)
+	_, ok = ValidateTOTP(rfcSecret, code, now.Add(2*TOTPPeriod*time.Second), 0)
+	assert.Equal(t, ok, false)
+
+	// a used step can not be used again
+	_, ok = ValidateTOTP(rfcSecret, code, now, step)
+	assert.Equal(t, ok, false)
+
+	_, ok = ValidateTOTP(rfcSecret, "000000", now, 0)
+	assert.Equal(t, ok, false)
+	_, ok = ValidateTOTP("not base32!", code, now, 0)
+	assert.Equal(t, ok, false)
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(secret), 32)
+
+	_, err = TOTPCode(secret, time.Now())
+	assert.Equal(t, err, nil)
+
+	uri := TOTPURI("Go Admin", "admin", secret)
+	assert.Equal(t, strings.HasPrefix(uri, "otpauth://totp/Go%20Admin:admin?"), true)
+	assert.Equal(t, strings.Contains(uri, "secret="+secret), true)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(codes), 10)
+	assert.Equal(t, len(codes[0]), 11)
+	assert.Equal(t, HashRecoveryCode(codes[0]), HashRecoveryCode(" "+strings.ToUpper(codes[0])))
+	assert.NotEqual(t, HashRecoveryCode(codes[0]), HashRecoveryCode(codes[1]))
+
+	b, err := randomString("abc", 300)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(b), 300)
+	assert.Equal(t, strings.Trim(string(b), "abc"), "")
+}
+
+func TestTwoFactorChallenges(t *testing.T) {
+	c := NewTwoFactorChallenges()
+
+	token := c.Add(3)
+
+	userId, ok := c.User(token)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, userId, int64(3))
+	assert.Equal(t, c.PasswordExpired(token), false)
+	assert.Equal(t, c.PasswordExpired(c.AddPasswordExpired(3)), true)
+
+	_, ok = c.Verify(token, func(int64) bool { return false })
+	assert.Equal(t, ok, false)
+
+	userId, ok = c.Verify(token, func(id int64) bool { return id == 3 })
+	assert.Equal(t, ok, true)
+	assert.Equal(t, userId, int64(3))
+
+	// a challenge is passed once
+	_, ok = c.Verify(token, func(int64) bool { return true })
+	assert.Equal(t, ok, false)
+
+	token = c.Add(3)
+	for i := 0; i < TwoFactorMaxAttempts; i++ {
+		c.Verify(token, func(int64) bool { return false })
+	}
+	_, ok = c.User(token)
+	assert.Equal(t, ok, false)
+	_, ok = c.Verify(token, func(int64) bool { return true })
+	assert.Equal(t, ok, false)
+}
@@ -0,0 +1,186 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+	"github.com/wowucco/go-admin/plugins/admin/modules"
+)
+
+const (
+	// TwoFactorChallengeExpires is how long the second login step waits for
+	// the code after the password is checked.
+	TwoFactorChallengeExpires = 5 * time.Minute
+	// TwoFactorMaxAttempts is the number of wrong codes a challenge allows.
+	TwoFactorMaxAttempts = 5
+
+	// twoFactorSetupSesKey marks the session of a user whose role requires
+	// the two-factor authentication but who has not enrolled yet.
+	twoFactorSetupSesKey = "two_factor_setup"
+)
+
+type twoFactorChallenge struct {
+	userId   int64
+	expires  time.Time
+	attempts int
+
+	// passwordExpired is true if the password of the user has to be
+	// changed after the code is checked.
+	passwordExpired bool
+}
+
+// TwoFactorChallenges keeps the users who passed the password check and have
+// to send a code before the session cookie is set.
+type TwoFactorChallenges struct {
+	mu         sync.Mutex
+	challenges map[string]*twoFactorChallenge
+}
+
+// NewTwoFactorChallenges return an empty TwoFactorChallenges.
+func NewTwoFactorChallenges() *TwoFactorChallenges {
+	return &TwoFactorChallenges{challenges: make(map[string]*twoFactorChallenge)}
+}
+
+var defaultChallenges = NewTwoFactorChallenges()
+
+// GetTwoFactorChallenges return the global TwoFactorChallenges.
+func GetTwoFactorChallenges() *TwoFactorChallenges {
+	return defaultChallenges
+}
+
+// Add start a challenge of the user and return its token.
+func (c *TwoFactorChallenges) Add(userId int64) string {
+	return c.add(userId, false)
+}
+
+// AddPasswordExpired start a challenge of the user whose password is
+// expired, the password is changed only after the code is checked.
+func (c *TwoFactorChallenges) AddPasswordExpired(userId int64) string {
+	return c.add(userId, true)
+}
+
+func (c *TwoFactorChallenges) add(userId int64, passwordExpired bool) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for token, ch := range c.challenges {
+		if now.After(ch.expires) {
+			delete(c.challenges, token)
+		}
+	}
+
+	token := modules.Uuid()
+	c.challenges[token] = &twoFactorChallenge{
+		userId:          userId,
+		expires:         now.Add(TwoFactorChallengeExpires),
+		passwordExpired: passwordExpired,
+	}
+	return token
+}
+
+// PasswordExpired report if the challenge is of a user whose password is
+// expired.
+func (c *TwoFactorChallenges) PasswordExpired(token string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch, ok := c.challenges[token]
+	return ok && ch.passwordExpired
+}
+
+// User return the user of the challenge, false if it is expired or has no
+// attempts left.
+func (c *TwoFactorChallenges) User(token string) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch, ok := c.challenges[token]
+	if !ok || time.Now().After(ch.expires) || ch.attempts >= TwoFactorMaxAttempts {
+		return 0, false
+	}
+	return ch.userId, true
+}
+
+// Verify check the code of the challenge with check, which is given the user
+// id. The challenge is removed when the code is right, expired or attempted
+// too many times.
+func (c *TwoFactorChallenges) Verify(token string, check func(userId int64) bool) (int64, bool) {
+	c.mu.Lock()
+	ch, ok := c.challenges[token]
+	if !ok || time.Now().After(ch.expires) || ch.attempts >= TwoFactorMaxAttempts {
+		delete(c.challenges, token)
+		c.mu.Unlock()
+		return 0, false
+	}
+	ch.attempts++
+	c.mu.Unlock()
+
+	if !check(ch.userId) {
+		return 0, false
+	}
+
+	c.mu.Lock()
+	delete(c.challenges, token)
+	c.mu.Unlock()
+	return ch.userId, true
+}
+
+// CheckTwoFactorCode check a totp code or an unused recovery code of the user.
+// The code is consumed, it can not be used again.
+func CheckTwoFactorCode(userId int64, code string, conn db.Connection) bool {
+	tf := models.TwoFactor().SetConn(conn).FindByUserId(userId)
+	if tf.IsEmpty() || !tf.Enabled {
+		return false
+	}
+
+	code = strings.TrimSpace(code)
+
+	if step, ok := ValidateTOTP(tf.Secret, code, time.Now(), tf.LastStep); ok {
+		return tf.UpdateLastStep(step)
+	}
+
+	return tf.UseRecoveryCode(HashRecoveryCode(code))
+}
+
+// SetTwoFactorSetupCookie set the cookie of a user who has to enroll the
+// two-factor authentication before using the admin.
+func SetTwoFactorSetupCookie(ctx *context.Context, user models.UserModel, conn db.Connection) error {
+	ses, err := InitSession(ctx, conn)
+
+	if err != nil {
+		return err
+	}
+
+	ses.Values[twoFactorSetupSesKey] = true
+	return login(ctx, ses, user, conn)
+}
+
+// ClearTwoFactorSetup remove the enrollment mark of the session.
+func ClearTwoFactorSetup(ctx *context.Context, conn db.Connection) error {
+	ses, err := InitSession(ctx, conn)
+
+	if err != nil {
+		return err
+	}
+
+	if _, ok := ses.Values[twoFactorSetupSesKey]; !ok {
+		return nil
+	}
+	delete(ses.Values, twoFactorSetupSesKey)
+	return ses.Save()
+}
+
+func isTwoFactorSetupRequired(ses *Session) bool {
+	if ses == nil {
+		return false
+	}
+	required, _ := ses.Get(twoFactorSetupSesKey).(bool)
+	return required
+}
@@ -0,0 +1,78 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/wowucco/go-admin/plugins/admin/models"
+)
+
+// userCacheTTL bounds how long a user edited by another instance is served
+// from the cache, the edits of this instance invalidate it at once.
+const userCacheTTL = time.Minute
+
+type cachedUser struct {
+	user      models.UserModel
+	expiresAt time.Time
+}
+
+// userCache keeps the users with their roles, permissions and menus, which
+// are otherwise loaded from the database on every request. The generation
+// is increased by every invalidation, so that a user loaded before it is not
+// stored after it.
+var userCache = struct {
+	sync.RWMutex
+	users      map[int64]cachedUser
+	generation uint64
+}{users: make(map[int64]cachedUser)}
+
+// InvalidateUserCache drop the cached users of given ids, all the cached
+// users are dropped when no id is given, as the edits of the roles, the
+// permissions and the menus concern many users.
+func InvalidateUserCache(ids ...int64) {
+	userCache.Lock()
+	defer userCache.Unlock()
+
+	userCache.generation++
+	if len(ids) == 0 {
+		userCache.users = make(map[int64]cachedUser)
+		return
+	}
+	for _, id := range ids {
+		delete(userCache.users, id)
+	}
+}
+
+func loadCachedUser(id int64) (models.UserModel, bool) {
+	userCache.RLock()
+	defer userCache.RUnlock()
+
+	item, ok := userCache.users[id]
+	if !ok || time.Now().After(item.expiresAt) {
+		return models.UserModel{}, false
+	}
+	return item.user, true
+}
+
+func userCacheGeneration() uint64 {
+	userCache.RLock()
+	defer userCache.RUnlock()
+	return userCache.generation
+}
+
+func storeCachedUser(user models.UserModel, generation uint64) {
+	userCache.Lock()
+	defer userCache.Unlock()
+
+	if generation != userCache.generation {
+		return
+	}
+	userCache.users[user.Id] = cachedUser{
+		user:      user,
+		expiresAt: time.Now().Add(userCacheTTL),
+	}
+}
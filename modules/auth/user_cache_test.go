@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+)
+
+func TestUserCache(t *testing.T) {
+	InvalidateUserCache()
+
+	storeCachedUser(models.UserModel{Id: 1, Name: "admin"}, userCacheGeneration())
+	storeCachedUser(models.UserModel{Id: 2, Name: "operator"}, userCacheGeneration())
+
+	user, ok := loadCachedUser(1)
+	assert.True(t, ok)
+	assert.Equal(t, "admin", user.Name)
+
+	InvalidateUserCache(1)
+	_, ok = loadCachedUser(1)
+	assert.False(t, ok)
+	_, ok = loadCachedUser(2)
+	assert.True(t, ok)
+
+	InvalidateUserCache()
+	_, ok = loadCachedUser(2)
+	assert.False(t, ok)
+}
+
+func TestUserCacheStaleLoad(t *testing.T) {
+	InvalidateUserCache()
+
+	// the user is loaded before an edit and stored after it
+	generation := userCacheGeneration()
+	InvalidateUserCache(3)
+	storeCachedUser(models.UserModel{Id: 3}, generation)
+
+	_, ok := loadCachedUser(3)
+	assert.False(t, ok)
+}
@@ -0,0 +1,153 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+)
+
+const (
+	sessionIdSesKey = "session_id"
+
+	// the last request of a session is written at most once in the interval
+	sessionTouchInterval = int64(60)
+
+	userAgentMaxLength = 255
+)
+
+// login set the user of the session with a new session record, which is
+// independent of the session store. A session ends when its record is
+// deleted.
+func login(ctx *context.Context, ses *Session, user models.UserModel, conn db.Connection) error {
+	record := models.UserSession().SetConn(conn)
+
+	now := time.Now()
+	// the expired records of all the users are cleaned here
+	if err := record.DeleteExpired(now); db.CheckError(err, db.DELETE) {
+		logger.Error("delete expired user sessions error: ", err)
+	}
+
+	if old, ok := ses.Values[sessionIdSesKey].(string); ok && old != "" {
+		if err := record.FindBySessionId(old).Delete(); db.CheckError(err, db.DELETE) {
+			logger.Error("delete user session error: ", err)
+		}
+	}
+
+	// a login ends the other sessions of the user unless the logins from
+	// several places are allowed
+	if !config.GetNoLimitLoginIP() {
+		if err := RevokeUserSessions(user.Id, "", conn); err != nil {
+			return err
+		}
+	}
+
+	sessionId, err := newSessionId()
+	if err != nil {
+		return err
+	}
+
+	if _, err := record.New(user.Id, sessionId, ctx.LocalIP(), userAgent(ctx),
+		now.Add(sessionLifeTime()).Unix()); db.CheckError(err, db.INSERT) {
+		return err
+	}
+
+	ses.Values[sessionIdSesKey] = sessionId
+	return ses.Add("user_id", user.Id)
+}
+
+// checkSession check the record of the session of the user, and record the
+// last request of it. The sessions older than the records get one here.
+func checkSession(ctx *context.Context, ses *Session, userId int64, conn db.Connection) bool {
+	sessionId, _ := ses.Get(sessionIdSesKey).(string)
+
+	if sessionId == "" {
+		var err error
+		if sessionId, err = newSessionId(); err == nil {
+			_, err = models.UserSession().SetConn(conn).New(userId, sessionId, ctx.LocalIP(), userAgent(ctx),
+				time.Now().Add(sessionLifeTime()).Unix())
+		}
+		if db.CheckError(err, db.INSERT) {
+			logger.Error("add user session error: ", err)
+			return true
+		}
+		ses.Values[sessionIdSesKey] = sessionId
+		if err := ses.Save(); err != nil {
+			logger.Error("save session error: ", err)
+		}
+		ctx.SetUserValue(sessionIdSesKey, sessionId)
+		return true
+	}
+
+	record := models.UserSession().SetConn(conn).FindBySessionId(sessionId)
+	now := time.Now()
+
+	if record.IsEmpty() || record.UserId != userId || record.IsExpired(now) {
+		if err := ses.Clear(); err != nil {
+			logger.Error("clear session error: ", err)
+		}
+		return false
+	}
+
+	if now.Unix()-record.LastSeenAt >= sessionTouchInterval {
+		if _, err := record.Touch(now.Unix(), ctx.LocalIP()); db.CheckError(err, db.UPDATE) {
+			logger.Error("update user session error: ", err)
+		}
+	}
+
+	ctx.SetUserValue(sessionIdSesKey, sessionId)
+	return true
+}
+
+// sessionRecordValid check the record of the session values, the values
+// without a record id are valid until the next request of the middleware.
+func sessionRecordValid(values map[string]interface{}, userId int64, conn db.Connection) bool {
+	sessionId, _ := values[sessionIdSesKey].(string)
+	if sessionId == "" {
+		return true
+	}
+	record := models.UserSession().SetConn(conn).FindBySessionId(sessionId)
+	return !record.IsEmpty() && record.UserId == userId && !record.IsExpired(time.Now())
+}
+
+// CurrentSessionId return the record id of the session of the request, it is
+// empty for the requests without a session, such as the api token ones.
+func CurrentSessionId(ctx *context.Context) string {
+	id, _ := ctx.UserValue[sessionIdSesKey].(string)
+	return id
+}
+
+// RevokeUserSessions end the sessions of the user, except the one of given
+// session id when it is not empty.
+func RevokeUserSessions(userId int64, exceptSessionId string, conn db.Connection) error {
+	err := models.UserSession().SetConn(conn).DeleteByUserId(userId, exceptSessionId)
+	if db.CheckError(err, db.DELETE) {
+		return err
+	}
+	return nil
+}
+
+func newSessionId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func userAgent(ctx *context.Context) string {
+	ua := []rune(ctx.Headers("User-Agent"))
+	if len(ua) > userAgentMaxLength {
+		ua = ua[:userAgentMaxLength]
+	}
+	return string(ua)
+}
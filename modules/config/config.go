@@ -279,6 +279,30 @@ type Config struct {
 
 	ExcludeThemeComponents []string `json:"exclude_theme_components",yaml:"exclude_theme_components",ini:"exclude_theme_components"`
 
+	// Ldap authenticates the logins against a directory, such as the Active Directory
+	Ldap Ldap `json:"ldap",yaml:"ldap",ini:"ldap"`
+
+	// Throttle the failed logins of a username or an ip
+	LoginLimit LoginLimit `json:"login_limit",yaml:"login_limit",ini:"login_limit"`
+
+	// The rules and the expiry of the passwords of the users
+	PasswordPolicy PasswordPolicy `json:"password_policy",yaml:"password_policy",ini:"password_policy"`
+
+	// Self-service reset of the forgotten passwords
+	PasswordReset PasswordReset `json:"password_reset",yaml:"password_reset",ini:"password_reset"`
+
+	// Notifier sends the messages to the users, such as the password reset links
+	Notifier Notifier `json:"notifier",yaml:"notifier",ini:"notifier"`
+
+	// The store of the login sessions
+	SessionStore SessionStore `json:"session_store",yaml:"session_store",ini:"session_store"`
+
+	// The csrf tokens of the forms
+	Csrf Csrf `json:"csrf",yaml:"csrf",ini:"csrf"`
+
+	// The masking and the retention of the operation logs
+	OperationLog OperationLog `json:"operation_log",yaml:"operation_log",ini:"operation_log"`
+
 	prefix string
 }
 
@@ -340,6 +364,175 @@ func GetPageAnimationFromJSON(m string) PageAnimation {
 type FileUploadEngine struct {
 	Name   string                 `json:"name",yaml:"name",ini:"name"`
 	Config map[string]interface{} `json:"config",yaml:"config",ini:"config"`
+	// Image is the pipeline of the uploaded images, nil turns it off.
+	Image *ImagePipeline `json:"image,omitempty",yaml:"image,omitempty",ini:"image,omitempty"`
+}
+
+// ImagePipeline is the config of the processing of the uploaded jpeg and png
+// images, see file.ImagePipeline.
+type ImagePipeline struct {
+	// Variants empty means the default thumbnail and medium variants.
+	Variants  []ImageVariant `json:"variants,omitempty",yaml:"variants,omitempty",ini:"variants,omitempty"`
+	Format    string         `json:"format,omitempty",yaml:"format,omitempty",ini:"format,omitempty"`
+	Quality   int            `json:"quality,omitempty",yaml:"quality,omitempty",ini:"quality,omitempty"`
+	MaxPixels int            `json:"max_pixels,omitempty",yaml:"max_pixels,omitempty",ini:"max_pixels,omitempty"`
+}
+
+// ImageVariant is the config of a resized copy of the uploaded images.
+type ImageVariant struct {
+	Name   string `json:"name",yaml:"name",ini:"name"`
+	Width  int    `json:"width",yaml:"width",ini:"width"`
+	Height int    `json:"height",yaml:"height",ini:"height"`
+	Crop   bool   `json:"crop",yaml:"crop",ini:"crop"`
+}
+
+// Ldap is the config of the LDAP authentication. It is only read from the
+// config file, so that the password of the bind account is not kept in the
+// site table.
+type Ldap struct {
+	Enable bool `json:"enable",yaml:"enable",ini:"enable"`
+	// Url is ldap://host:389 or ldaps://host:636.
+	Url                string `json:"url",yaml:"url",ini:"url"`
+	StartTLS           bool   `json:"start_tls",yaml:"start_tls",ini:"start_tls"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify",yaml:"insecure_skip_verify",ini:"insecure_skip_verify"`
+	// The account to search the users, the search is anonymous when it is empty.
+	BindDN       string `json:"bind_dn",yaml:"bind_dn",ini:"bind_dn"`
+	BindPassword string `json:"bind_password",yaml:"bind_password",ini:"bind_password"`
+	BaseDN       string `json:"base_dn",yaml:"base_dn",ini:"base_dn"`
+	// UserFilter finds the user, %s is the username. Default is (uid=%s), use
+	// (sAMAccountName=%s) for the Active Directory.
+	UserFilter     string `json:"user_filter",yaml:"user_filter",ini:"user_filter"`
+	NameAttribute  string `json:"name_attribute",yaml:"name_attribute",ini:"name_attribute"`
+	GroupAttribute string `json:"group_attribute",yaml:"group_attribute",ini:"group_attribute"`
+	// GroupMapping maps the group dn to the role slug, the roles of the user
+	// are synced with the groups on every login when it is set.
+	GroupMapping map[string]string `json:"group_mapping",yaml:"group_mapping",ini:"group_mapping"`
+	DefaultRoles []string          `json:"default_roles",yaml:"default_roles",ini:"default_roles"`
+	// AutoCreate creates the user at the first login.
+	AutoCreate bool `json:"auto_create",yaml:"auto_create",ini:"auto_create"`
+	// LocalFallback lets the superadmins log in with the local password, in
+	// case the directory is unreachable.
+	LocalFallback bool `json:"local_fallback",yaml:"local_fallback",ini:"local_fallback"`
+	// Timeout in seconds, default is 10.
+	Timeout int `json:"timeout",yaml:"timeout",ini:"timeout"`
+}
+
+// LoginLimit is the config of the login throttling. A username or an ip is
+// locked after the max attempts, and every further failure doubles the
+// lockout up to the max lockout.
+type LoginLimit struct {
+	Off bool `json:"off",yaml:"off",ini:"off"`
+	// MaxAttempts of a username, default is 5.
+	MaxAttempts int `json:"max_attempts",yaml:"max_attempts",ini:"max_attempts"`
+	// IPMaxAttempts of an ip, default is 20.
+	IPMaxAttempts int `json:"ip_max_attempts",yaml:"ip_max_attempts",ini:"ip_max_attempts"`
+	// LockoutSeconds is the first lockout, default is 60.
+	LockoutSeconds int `json:"lockout_seconds",yaml:"lockout_seconds",ini:"lockout_seconds"`
+	// MaxLockoutSeconds default is 3600.
+	MaxLockoutSeconds int `json:"max_lockout_seconds",yaml:"max_lockout_seconds",ini:"max_lockout_seconds"`
+	// ResetSeconds forgets the failures after a quiet period, default is 900.
+	ResetSeconds int `json:"reset_seconds",yaml:"reset_seconds",ini:"reset_seconds"`
+	// TrustedProxies are the ips or cidrs of the proxies in front of the
+	// admin. The ip of a login is read from the X-Forwarded-For header of
+	// their requests, and is the remote address of the others.
+	TrustedProxies []string `json:"trusted_proxies",yaml:"trusted_proxies",ini:"trusted_proxies"`
+}
+
+// WithDefault return the LoginLimit with the defaults of the zero values.
+func (l LoginLimit) WithDefault() LoginLimit {
+	if l.MaxAttempts == 0 {
+		l.MaxAttempts = 5
+	}
+	if l.IPMaxAttempts == 0 {
+		l.IPMaxAttempts = 20
+	}
+	if l.LockoutSeconds == 0 {
+		l.LockoutSeconds = 60
+	}
+	if l.MaxLockoutSeconds == 0 {
+		l.MaxLockoutSeconds = 3600
+	}
+	if l.ResetSeconds == 0 {
+		l.ResetSeconds = 900
+	}
+	return l
+}
+
+// PasswordPolicy is the rules of the passwords set in the manager forms, the
+// user settings and the password reset. The zero value allows any password.
+type PasswordPolicy struct {
+	MinLength     int  `json:"min_length",yaml:"min_length",ini:"min_length"`
+	RequireUpper  bool `json:"require_upper",yaml:"require_upper",ini:"require_upper"`
+	RequireLower  bool `json:"require_lower",yaml:"require_lower",ini:"require_lower"`
+	RequireDigit  bool `json:"require_digit",yaml:"require_digit",ini:"require_digit"`
+	RequireSymbol bool `json:"require_symbol",yaml:"require_symbol",ini:"require_symbol"`
+	// NotUsername rejects the password equal to the username.
+	NotUsername bool `json:"not_username",yaml:"not_username",ini:"not_username"`
+	// HistorySize is the number of the last passwords which can not be reused.
+	HistorySize int `json:"history_size",yaml:"history_size",ini:"history_size"`
+	// ExpireDays forces the users to change the password at the login after
+	// the days, zero never expires.
+	ExpireDays int `json:"expire_days",yaml:"expire_days",ini:"expire_days"`
+}
+
+// PasswordReset is the config of the "forgot password" flow, the reset links
+// are sent by the notifier to the email of the user.
+type PasswordReset struct {
+	Enable bool `json:"enable",yaml:"enable",ini:"enable"`
+	// ExpireMinutes of the reset links, default is 30.
+	ExpireMinutes int `json:"expire_minutes",yaml:"expire_minutes",ini:"expire_minutes"`
+	// Url is the site url of the reset links, such as https://admin.example.com.
+	// It is required, the links are not sent without it.
+	Url string `json:"url",yaml:"url",ini:"url"`
+}
+
+// Notifier is a notifier of the users. The "smtp" notifier sends emails and
+// the "local" one, which is the default, writes them to the info log or to
+// the files of a directory.
+type Notifier struct {
+	Name   string                 `json:"name",yaml:"name",ini:"name"`
+	Config map[string]interface{} `json:"config",yaml:"config",ini:"config"`
+}
+
+// SessionStore is the store of the login sessions. The "database" store,
+// which is the default, uses the goadmin_session table, "memory" keeps the
+// sessions in the process, "cookie" keeps them encrypted in the cookie and
+// "redis" uses a redis server.
+type SessionStore struct {
+	Name   string                 `json:"name",yaml:"name",ini:"name"`
+	Config map[string]interface{} `json:"config",yaml:"config",ini:"config"`
+	// SweepInterval is the seconds between the deletes of the expired
+	// sessions, default is 60.
+	SweepInterval int `json:"sweep_interval",yaml:"sweep_interval",ini:"sweep_interval"`
+}
+
+// Csrf is the config of the csrf tokens of the forms. The "memory" store,
+// which is the default, keeps the single-use tokens in the process. The
+// "hmac" store signs the tokens with the Secret instead of keeping them, so
+// that the instances behind a load balancer accept the tokens of each other.
+type Csrf struct {
+	Store  string `json:"store",yaml:"store",ini:"store"`
+	Secret string `json:"secret",yaml:"secret",ini:"secret"`
+	// ExpireSeconds of the tokens, default is 7200.
+	ExpireSeconds int `json:"expire_seconds",yaml:"expire_seconds",ini:"expire_seconds"`
+	// MaxTokens kept by the memory store, the oldest ones are dropped
+	// above it. Default is 10000.
+	MaxTokens int `json:"max_tokens",yaml:"max_tokens",ini:"max_tokens"`
+}
+
+// OperationLog is the config of the operation logs. The values of the form
+// fields named in MaskFields, or whose names contain "password", "secret" or
+// "token", are masked before the input is stored.
+type OperationLog struct {
+	MaskFields []string `json:"mask_fields",yaml:"mask_fields",ini:"mask_fields"`
+	// RetentionDays is the days the logs are kept, zero keeps them forever.
+	RetentionDays int `json:"retention_days",yaml:"retention_days",ini:"retention_days"`
+	// ArchivePath is the directory which the expired logs are written to as
+	// gzipped json lines before they are deleted.
+	ArchivePath string `json:"archive_path",yaml:"archive_path",ini:"archive_path"`
+	// CleanInterval is the seconds between the cleans of the expired logs,
+	// default is 3600.
+	CleanInterval int `json:"clean_interval",yaml:"clean_interval",ini:"clean_interval"`
 }
 
 func (f FileUploadEngine) JSON() string {
@@ -491,6 +684,14 @@ func (c *Config) Copy() *Config {
 		OpenAdminApi:                  c.OpenAdminApi,
 		HideVisitorUserCenterEntrance: c.HideVisitorUserCenterEntrance,
 		ExcludeThemeComponents:        c.ExcludeThemeComponents,
+		Ldap:                          c.Ldap,
+		LoginLimit:                    c.LoginLimit,
+		PasswordPolicy:                c.PasswordPolicy,
+		PasswordReset:                 c.PasswordReset,
+		Notifier:                      c.Notifier,
+		SessionStore:                  c.SessionStore,
+		Csrf:                          c.Csrf,
+		OperationLog:                  c.OperationLog,
 		prefix:                        c.prefix,
 	}
 }
@@ -749,6 +950,31 @@ func Set(cfg Config) *Config {
 	cfg.ColorScheme = utils.SetDefault(cfg.ColorScheme, "", "skin-black")
 	cfg.FileUploadEngine.Name = utils.SetDefault(cfg.FileUploadEngine.Name, "", "local")
 	cfg.Env = utils.SetDefault(cfg.Env, "", EnvProd)
+	cfg.Ldap.UserFilter = utils.SetDefault(cfg.Ldap.UserFilter, "", "(uid=%s)")
+	cfg.Ldap.NameAttribute = utils.SetDefault(cfg.Ldap.NameAttribute, "", "cn")
+	cfg.Ldap.GroupAttribute = utils.SetDefault(cfg.Ldap.GroupAttribute, "", "memberOf")
+	if cfg.Ldap.Timeout == 0 {
+		cfg.Ldap.Timeout = 10
+	}
+	cfg.LoginLimit = cfg.LoginLimit.WithDefault()
+	if cfg.PasswordReset.ExpireMinutes == 0 {
+		cfg.PasswordReset.ExpireMinutes = 30
+	}
+	cfg.Notifier.Name = utils.SetDefault(cfg.Notifier.Name, "", "local")
+	cfg.SessionStore.Name = utils.SetDefault(cfg.SessionStore.Name, "", "database")
+	if cfg.SessionStore.SweepInterval == 0 {
+		cfg.SessionStore.SweepInterval = 60
+	}
+	cfg.Csrf.Store = utils.SetDefault(cfg.Csrf.Store, "", "memory")
+	if cfg.Csrf.ExpireSeconds == 0 {
+		cfg.Csrf.ExpireSeconds = 7200
+	}
+	if cfg.Csrf.MaxTokens == 0 {
+		cfg.Csrf.MaxTokens = 10000
+	}
+	if cfg.OperationLog.CleanInterval == 0 {
+		cfg.OperationLog.CleanInterval = 3600
+	}
 	if cfg.SessionLifeTime == 0 {
 		// default two hours
 		cfg.SessionLifeTime = 7200
@@ -993,6 +1219,38 @@ func GetExtra() map[string]interface{} {
 	return globalCfg.Extra
 }
 
+func GetLdap() Ldap {
+	return globalCfg.Ldap
+}
+
+func GetLoginLimit() LoginLimit {
+	return globalCfg.LoginLimit
+}
+
+func GetPasswordPolicy() PasswordPolicy {
+	return globalCfg.PasswordPolicy
+}
+
+func GetPasswordReset() PasswordReset {
+	return globalCfg.PasswordReset
+}
+
+func GetNotifier() Notifier {
+	return globalCfg.Notifier
+}
+
+func GetSessionStore() SessionStore {
+	return globalCfg.SessionStore
+}
+
+func GetCsrf() Csrf {
+	return globalCfg.Csrf
+}
+
+func GetOperationLog() OperationLog {
+	return globalCfg.OperationLog
+}
+
 func GetAnimation() PageAnimation {
 	return globalCfg.Animation
 }
@@ -0,0 +1,442 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package file
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/plugins/admin/modules"
+)
+
+const (
+	// DefaultMaxChunkSize is the max size of a chunk, keep it under the body
+	// limit of the proxy in front of the admin.
+	DefaultMaxChunkSize int64 = 32 << 20
+	// DefaultChunkExpires is how long an unfinished upload session is kept.
+	DefaultChunkExpires = 24 * time.Hour
+
+	// ChunkPathSuffix is appended to the name of a file field to post the
+	// paths of the finished chunked uploads with the form.
+	ChunkPathSuffix = "__chunk_path"
+	// MediaPathSuffix is appended to the name of a file field to post the
+	// paths of the files picked from the media library with the form.
+	MediaPathSuffix = "__media_path"
+
+	chunkMetaFile = "session.json"
+	chunkSuffix   = ".part"
+
+	uploadedDir    = ".uploaded"
+	uploadedSuffix = ".json"
+	claimedSuffix  = ".claimed"
+)
+
+var (
+	ErrChunkSessionNotFound = errors.New("upload session not found")
+	ErrChunkChecksum        = errors.New("chunk checksum mismatch")
+	ErrChunkIndex           = errors.New("wrong chunk index")
+	ErrChunkSize            = errors.New("wrong chunk size")
+	ErrChunkIncomplete      = errors.New("upload is not complete")
+	ErrFileChecksum         = errors.New("file checksum mismatch")
+
+	chunkSessionIDReg = regexp.MustCompile(`^[a-zA-Z0-9\-]+$`)
+)
+
+// ChunkSession is a resumable upload of one file which is sent in chunks.
+// It belongs to the user who started it, and the file is checked with the
+// upload rules of the field of the table of the prefix.
+type ChunkSession struct {
+	ID        string    `json:"id"`
+	Prefix    string    `json:"prefix"`
+	Field     string    `json:"field"`
+	UserId    int64     `json:"user_id"`
+	Filename  string    `json:"filename"`
+	Size      int64     `json:"size"`
+	ChunkSize int64     `json:"chunk_size"`
+	Checksum  string    `json:"checksum"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ChunkCount return the number of chunks of the file.
+func (s ChunkSession) ChunkCount() int {
+	if s.Size == 0 {
+		return 1
+	}
+	return int((s.Size + s.ChunkSize - 1) / s.ChunkSize)
+}
+
+// chunkLength return the expected length of the chunk of given index.
+func (s ChunkSession) chunkLength(index int) int64 {
+	if index == s.ChunkCount()-1 {
+		return s.Size - int64(index)*s.ChunkSize
+	}
+	return s.ChunkSize
+}
+
+// ChunkStore keeps the chunks of resumable uploads in a local directory until
+// the file is complete and assembled for the Uploader. The finished uploads
+// which a form can claim are kept in the directory too. When several admin
+// instances run behind a load balancer, the directory must be shared.
+type ChunkStore struct {
+	Dir          string
+	MaxChunkSize int64
+	Expires      time.Duration
+}
+
+type uploadedChunkFile struct {
+	Path   string    `json:"path"`
+	Prefix string    `json:"prefix"`
+	Field  string    `json:"field"`
+	UserId int64     `json:"user_id"`
+	At     time.Time `json:"at"`
+}
+
+var (
+	chunkStore     *ChunkStore
+	chunkStoreOnce sync.Once
+)
+
+// GetChunkStore return the global ChunkStore, which lives in the store path,
+// or the temp directory when no store path is set.
+func GetChunkStore() *ChunkStore {
+	chunkStoreOnce.Do(func() {
+		if chunkStore != nil {
+			return
+		}
+		dir := config.GetStore().Path
+		if dir == "" {
+			dir = os.TempDir()
+		}
+		chunkStore = NewChunkStore(filepath.Join(dir, ".chunks"))
+	})
+	return chunkStore
+}
+
+// SetChunkStore replace the global ChunkStore.
+func SetChunkStore(store *ChunkStore) {
+	chunkStoreOnce.Do(func() {})
+	chunkStore = store
+}
+
+// NewChunkStore return a ChunkStore of given directory.
+func NewChunkStore(dir string) *ChunkStore {
+	return &ChunkStore{
+		Dir:          dir,
+		MaxChunkSize: DefaultMaxChunkSize,
+		Expires:      DefaultChunkExpires,
+	}
+}
+
+// Init start a new upload session of the user for the field of the table of
+// the prefix. The checksum is the optional sha256 in hex of the whole file.
+func (c *ChunkStore) Init(prefix, field string, userId int64, filename string, size, chunkSize int64,
+	checksum string) (ChunkSession, error) {
+	if size < 0 || chunkSize <= 0 || chunkSize > c.MaxChunkSize {
+		return ChunkSession{}, ErrChunkSize
+	}
+
+	c.removeExpired()
+
+	sess := ChunkSession{
+		ID:        modules.Uuid(),
+		Prefix:    prefix,
+		Field:     field,
+		UserId:    userId,
+		Filename:  filepath.Base(filename),
+		Size:      size,
+		ChunkSize: chunkSize,
+		Checksum:  strings.ToLower(checksum),
+		CreatedAt: time.Now(),
+	}
+
+	if err := os.MkdirAll(c.sessionDir(sess.ID), os.ModePerm); err != nil {
+		return ChunkSession{}, err
+	}
+
+	meta, err := json.Marshal(sess)
+	if err != nil {
+		return ChunkSession{}, err
+	}
+
+	return sess, ioutil.WriteFile(filepath.Join(c.sessionDir(sess.ID), chunkMetaFile), meta, 0644)
+}
+
+// Get return the upload session of given id.
+func (c *ChunkStore) Get(id string) (ChunkSession, error) {
+	var sess ChunkSession
+
+	if !chunkSessionIDReg.MatchString(id) {
+		return sess, ErrChunkSessionNotFound
+	}
+
+	meta, err := ioutil.ReadFile(filepath.Join(c.sessionDir(id), chunkMetaFile))
+	if err != nil {
+		return sess, ErrChunkSessionNotFound
+	}
+
+	err = json.Unmarshal(meta, &sess)
+	return sess, err
+}
+
+// Received return the sorted indexes of the stored chunks, which lets the
+// client resume the upload after a disconnect.
+func (c *ChunkStore) Received(id string) ([]int, error) {
+	if _, err := c.Get(id); err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(c.sessionDir(id))
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make([]int, 0, len(files))
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), chunkSuffix) {
+			continue
+		}
+		if index, err := strconv.Atoi(strings.TrimSuffix(f.Name(), chunkSuffix)); err == nil {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Ints(indexes)
+
+	return indexes, nil
+}
+
+// Put store the chunk of given index. The checksum is the sha256 in hex of
+// the chunk, the chunk is dropped when it does not match. Putting a chunk
+// twice overwrites it.
+func (c *ChunkStore) Put(id string, index int, checksum string, r io.Reader) error {
+	sess, err := c.Get(id)
+	if err != nil {
+		return err
+	}
+
+	if index < 0 || index >= sess.ChunkCount() {
+		return ErrChunkIndex
+	}
+
+	tmp, err := ioutil.TempFile(c.sessionDir(id), "chunk")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(r, sess.chunkLength(index)+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if n != sess.chunkLength(index) {
+		return ErrChunkSize
+	}
+
+	if !strings.EqualFold(hex.EncodeToString(hash.Sum(nil)), checksum) {
+		return ErrChunkChecksum
+	}
+
+	return os.Rename(tmp.Name(), c.chunkPath(id, index))
+}
+
+// Assemble join the chunks of a complete upload into a multipart form which
+// can be passed to an Uploader. The caller should call RemoveAll of the form
+// and Remove of the session when done.
+func (c *ChunkStore) Assemble(id string) (*multipart.Form, error) {
+	sess, err := c.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	received, err := c.Received(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(received) != sess.ChunkCount() {
+		return nil, ErrChunkIncomplete
+	}
+
+	pr, pw := io.Pipe()
+	w := multipart.NewWriter(pw)
+	hash := sha256.New()
+
+	go func() {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(sess.Field), escapeQuotes(sess.Filename)))
+		h.Set("Content-Type", "application/octet-stream")
+
+		part, err := w.CreatePart(h)
+		if err != nil {
+			_ = pw.CloseWithError(err)
+			return
+		}
+
+		for index := 0; index < sess.ChunkCount(); index++ {
+			f, err := os.Open(c.chunkPath(id, index))
+			if err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+			_, err = copyZeroAlloc(io.MultiWriter(part, hash), f)
+			_ = f.Close()
+			if err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+		}
+
+		_ = pw.CloseWithError(w.Close())
+	}()
+
+	form, err := multipart.NewReader(pr, w.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		// stop the writer, which is blocked on the pipe
+		_ = pr.CloseWithError(err)
+		return nil, err
+	}
+
+	if sess.Checksum != "" && hex.EncodeToString(hash.Sum(nil)) != sess.Checksum {
+		_ = form.RemoveAll()
+		return nil, ErrFileChecksum
+	}
+
+	return form, nil
+}
+
+// Remove delete the upload session and its chunks.
+func (c *ChunkStore) Remove(id string) error {
+	if !chunkSessionIDReg.MatchString(id) {
+		return ErrChunkSessionNotFound
+	}
+	return os.RemoveAll(c.sessionDir(id))
+}
+
+// MarkUploaded remember the stored path of the finished upload of the
+// session, which can then be claimed once by the form post.
+func (c *ChunkStore) MarkUploaded(sess ChunkSession, path string) error {
+	meta, err := json.Marshal(uploadedChunkFile{
+		Path:   path,
+		Prefix: sess.Prefix,
+		Field:  sess.Field,
+		UserId: sess.UserId,
+		At:     time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Join(c.Dir, uploadedDir), os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Join(c.Dir, uploadedDir), "uploaded")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(meta)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.uploadedPath(path, uploadedSuffix))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// ClaimUploaded check if the path is a finished upload of the user for the
+// field of the table of the prefix and reserve it, so a form can not post
+// paths it has not uploaded, or which were checked with the upload rules of
+// another table. The claim is then consumed with ConsumeUploaded when the
+// form is stored, or given back with ReleaseUploaded.
+func (c *ChunkStore) ClaimUploaded(prefix, field string, userId int64, path string) bool {
+	meta, err := ioutil.ReadFile(c.uploadedPath(path, uploadedSuffix))
+	if err != nil {
+		return false
+	}
+
+	var up uploadedChunkFile
+	if err := json.Unmarshal(meta, &up); err != nil || up.Path != path || up.Prefix != prefix ||
+		up.Field != field || up.UserId != userId || time.Since(up.At) > c.Expires {
+		return false
+	}
+
+	// the rename fails when a concurrent post has claimed the path first
+	return os.Rename(c.uploadedPath(path, uploadedSuffix), c.uploadedPath(path, claimedSuffix)) == nil
+}
+
+// ConsumeUploaded forget the claimed path, which can not be claimed again.
+func (c *ChunkStore) ConsumeUploaded(path string) {
+	_ = os.Remove(c.uploadedPath(path, claimedSuffix))
+}
+
+// ReleaseUploaded give the claimed path back, so the form can be posted again.
+func (c *ChunkStore) ReleaseUploaded(path string) {
+	_ = os.Rename(c.uploadedPath(path, claimedSuffix), c.uploadedPath(path, uploadedSuffix))
+}
+
+func (c *ChunkStore) removeExpired() {
+	dirs, err := ioutil.ReadDir(c.Dir)
+	if err != nil {
+		return
+	}
+
+	for _, dir := range dirs {
+		if dir.IsDir() && dir.Name() != uploadedDir && time.Since(dir.ModTime()) > c.Expires {
+			_ = os.RemoveAll(filepath.Join(c.Dir, dir.Name()))
+		}
+	}
+
+	files, err := ioutil.ReadDir(filepath.Join(c.Dir, uploadedDir))
+	if err != nil {
+		return
+	}
+
+	for _, f := range files {
+		if time.Since(f.ModTime()) > c.Expires {
+			_ = os.Remove(filepath.Join(c.Dir, uploadedDir, f.Name()))
+		}
+	}
+}
+
+// uploadedPath return the file which records the finished upload of the path.
+func (c *ChunkStore) uploadedPath(path, suffix string) string {
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(c.Dir, uploadedDir, hex.EncodeToString(sum[:])+suffix)
+}
+
+func (c *ChunkStore) sessionDir(id string) string {
+	return filepath.Join(c.Dir, id)
+}
+
+func (c *ChunkStore) chunkPath(id string, index int) string {
+	return filepath.Join(c.sessionDir(id), strconv.Itoa(index)+chunkSuffix)
+}
@@ -0,0 +1,95 @@
+package file
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestChunkStore(t *testing.T) {
+	store := NewChunkStore(t.TempDir())
+
+	content := "0123456789abcdefghij!"
+
+	_, err := store.Init("posts", "attachment", 1, "a.txt", int64(len(content)), store.MaxChunkSize+1, "")
+	assert.Equal(t, ErrChunkSize, err)
+
+	sess, err := store.Init("posts", "attachment", 1, "../../a.txt", int64(len(content)), 10, sha256Hex(content))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, sess.ChunkCount())
+	assert.Equal(t, "a.txt", sess.Filename)
+
+	got, err := store.Get(sess.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "posts", got.Prefix)
+	assert.Equal(t, int64(1), got.UserId)
+
+	chunks := []string{content[:10], content[10:20], content[20:]}
+
+	assert.Equal(t, ErrChunkChecksum, store.Put(sess.ID, 0, sha256Hex("other"), strings.NewReader(chunks[0])))
+	assert.Equal(t, ErrChunkSize, store.Put(sess.ID, 0, sha256Hex(chunks[2]), strings.NewReader(chunks[2])))
+	assert.Equal(t, ErrChunkIndex, store.Put(sess.ID, 3, "", strings.NewReader("")))
+	assert.Equal(t, ErrChunkSessionNotFound, store.Put("../x", 0, "", strings.NewReader("")))
+
+	assert.NoError(t, store.Put(sess.ID, 2, sha256Hex(chunks[2]), strings.NewReader(chunks[2])))
+	assert.NoError(t, store.Put(sess.ID, 0, sha256Hex(chunks[0]), strings.NewReader(chunks[0])))
+
+	_, err = store.Assemble(sess.ID)
+	assert.Equal(t, ErrChunkIncomplete, err)
+
+	// resume with the missing chunk only
+	received, err := store.Received(sess.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, received)
+
+	assert.NoError(t, store.Put(sess.ID, 1, sha256Hex(chunks[1]), strings.NewReader(chunks[1])))
+
+	form, err := store.Assemble(sess.ID)
+	assert.NoError(t, err)
+	defer func() {
+		_ = form.RemoveAll()
+	}()
+
+	stored := ""
+	err = Upload(func(fh *multipart.FileHeader, filename string) (string, error) {
+		f, _ := fh.Open()
+		data, _ := ioutil.ReadAll(f)
+		stored = string(data)
+		return filename, nil
+	}, form)
+	assert.NoError(t, err)
+	assert.Equal(t, content, stored)
+
+	path := form.Value["attachment"][0]
+	assert.True(t, strings.HasSuffix(path, ".txt"))
+
+	assert.NoError(t, store.Remove(sess.ID))
+	_, err = store.Get(sess.ID)
+	assert.Equal(t, ErrChunkSessionNotFound, err)
+
+	assert.NoError(t, store.MarkUploaded(sess, path))
+	assert.False(t, store.ClaimUploaded("posts", "avatar", 1, path))
+	// the same field of another table or another user can not claim it
+	assert.False(t, store.ClaimUploaded("pages", "attachment", 1, path))
+	assert.False(t, store.ClaimUploaded("posts", "attachment", 2, path))
+	assert.True(t, store.ClaimUploaded("posts", "attachment", 1, path))
+	assert.False(t, store.ClaimUploaded("posts", "attachment", 1, path))
+
+	// a released claim can be claimed again, by another instance too
+	store.ReleaseUploaded(path)
+	other := NewChunkStore(store.Dir)
+	assert.True(t, other.ClaimUploaded("posts", "attachment", 1, path))
+	other.ConsumeUploaded(path)
+	store.ReleaseUploaded(path)
+	assert.False(t, store.ClaimUploaded("posts", "attachment", 1, path))
+}
@@ -5,12 +5,22 @@
 package file
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"mime/multipart"
+	"net/http"
+	"net/textproto"
 	"os"
 	"path"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
 
+	"github.com/wowucco/go-admin/modules/config"
 	"github.com/wowucco/go-admin/plugins/admin/modules"
 )
 
@@ -51,6 +61,116 @@ func GetFileEngine(name string) Uploader {
 	panic("wrong uploader name")
 }
 
+// URLGenerator is implemented by the Uploader which builds the links of its
+// files itself, such as object storages with presigned urls.
+type URLGenerator interface {
+	URL(path string) string
+}
+
+var (
+	urlUploader   Uploader
+	urlEngine     config.FileUploadEngine
+	urlUploaderMu sync.Mutex
+)
+
+// URL return the link of the stored file path with the configured file upload engine.
+func URL(path string) string {
+	if path == "" {
+		return ""
+	}
+	if gen, ok := engineUploader().(URLGenerator); ok {
+		return gen.URL(path)
+	}
+	return config.GetStore().URL(path)
+}
+
+// StoredURL return the link of the stored file path like URL, but empty when
+// the path is empty or the link is unknown, as the file upload engine does
+// not build the links and no store prefix is set.
+func StoredURL(path string) string {
+	if path == "" {
+		return ""
+	}
+	if gen, ok := engineUploader().(URLGenerator); ok {
+		return gen.URL(path)
+	}
+	if config.GetStore().Prefix == "" {
+		return ""
+	}
+	return config.GetStore().URL(path)
+}
+
+// engineUploader return the Uploader of the configured file upload engine,
+// which is built again only when the config is changed.
+func engineUploader() Uploader {
+	engine := config.GetFileUploadEngine()
+
+	urlUploaderMu.Lock()
+	defer urlUploaderMu.Unlock()
+
+	if urlUploader != nil && urlEngine.Name == engine.Name && reflect.DeepEqual(urlEngine.Config, engine.Config) {
+		return urlUploader
+	}
+
+	up, ok := uploaderList[engine.Name]
+	if !ok {
+		return nil
+	}
+	urlUploader, urlEngine = up(), engine
+	return urlUploader
+}
+
+// Deleter is implemented by the Uploader which can remove its stored files.
+type Deleter interface {
+	Delete(path string) error
+}
+
+// Exister is implemented by the Uploader which can check if a file is stored.
+type Exister interface {
+	Exists(path string) (bool, error)
+}
+
+// ErrNotDeletable is returned by Delete when the Uploader can not remove files.
+var ErrNotDeletable = errors.New("the file upload engine can not delete files")
+
+// Delete remove the stored file of given path and its image variants with the
+// Uploader of given name.
+func Delete(engine, filePath string) error {
+	up, ok := uploaderList[engine]
+	if !ok {
+		return ErrNotDeletable
+	}
+	deleter, ok := up().(Deleter)
+	if !ok {
+		return ErrNotDeletable
+	}
+	if pipeline := GetImagePipeline(); pipeline != nil {
+		for _, v := range pipeline.Variants {
+			if variant := variantPath(filePath, v.Name); variant != filePath {
+				if err := deleter.Delete(variant); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return deleter.Delete(filePath)
+}
+
+// DetectContentType sniff the mime type of the uploaded file. It should be
+// called before the file is uploaded, as the local Uploader moves the file.
+func DetectContentType(fh *multipart.FileHeader) string {
+	f, err := fh.Open()
+	if err != nil {
+		return fh.Header.Get("Content-Type")
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	return http.DetectContentType(head[:n])
+}
+
 // UploadFun is a function to process the uploading logic.
 type UploadFun func(*multipart.FileHeader, string) (string, error)
 
@@ -61,10 +181,41 @@ func Upload(c UploadFun, form *multipart.Form) error {
 		filename string
 	)
 
+	pipeline := GetImagePipeline()
+
 	for k := range form.File {
 		for _, fileObj := range form.File[k] {
 			suffix = path.Ext(fileObj.Filename)
-			filename = modules.Uuid() + suffix
+			name := modules.Uuid()
+			filename = name + suffix
+
+			var variants []string
+
+			if pipeline != nil {
+				processed, err := processImage(pipeline, fileObj)
+				if err != nil {
+					return err
+				}
+				if processed != nil {
+					filename = name + processed.Ext
+					for variant, data := range processed.Variants {
+						variantObj, err := memoryFileHeader(k, name+"_"+variant+processed.Ext,
+							processed.ContentType, data)
+						if err != nil {
+							return err
+						}
+						if _, err := c(variantObj, name+"_"+variant+processed.Ext); err != nil {
+							return err
+						}
+						variants = append(variants, variant)
+					}
+					sort.Strings(variants)
+					if fileObj, err = memoryFileHeader(k, fileObj.Filename, processed.ContentType,
+						processed.Original); err != nil {
+						return err
+					}
+				}
+			}
 
 			pathStr, err := c(fileObj, filename)
 
@@ -72,6 +223,8 @@ func Upload(c UploadFun, form *multipart.Form) error {
 				return err
 			}
 
+			rememberVariants(pathStr, variants)
+
 			form.Value[k] = append(form.Value[k], pathStr)
 		}
 	}
@@ -79,6 +232,67 @@ func Upload(c UploadFun, form *multipart.Form) error {
 	return nil
 }
 
+// processImage run the image pipeline on the file, it returns nil if the
+// file is not an image supported by the pipeline.
+func processImage(pipeline *ImagePipeline, fileObj *multipart.FileHeader) (*ProcessedImage, error) {
+	switch strings.ToLower(path.Ext(fileObj.Filename)) {
+	case ".jpg", ".jpeg", ".png":
+	default:
+		return nil, nil
+	}
+
+	f, err := fileObj.Open()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(f)
+	_ = f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	processed, err := pipeline.Process(data)
+	if err == errImageFormat {
+		return nil, nil
+	}
+	return processed, err
+}
+
+// memoryFileHeader build a multipart file header of the data, so the processed
+// files can be passed to an UploadFun like the uploaded ones.
+func memoryFileHeader(field, filename, contentType string, data []byte) (*multipart.FileHeader, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		escapeQuotes(field), escapeQuotes(filename)))
+	h.Set("Content-Type", contentType)
+
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	form, err := multipart.NewReader(&buf, w.Boundary()).ReadForm(int64(len(data)) + 1024)
+	if err != nil {
+		return nil, err
+	}
+	return form.File[field][0], nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
 // SaveMultipartFile used in a local Uploader which help to save file in the local path.
 func SaveMultipartFile(fh *multipart.FileHeader, path string) error {
 	f, err := fh.Open()
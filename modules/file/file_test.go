@@ -0,0 +1,41 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalFileUploader_Delete(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goadmin-upload")
+	assert.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	SetImagePipeline(DefaultImagePipeline())
+	defer SetImagePipeline(nil)
+
+	for _, name := range []string{"a.png", "a_thumb.png", "a_medium.png", "b.txt"} {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(name), 0644))
+	}
+
+	AddUploader("local_delete_test", func() Uploader {
+		return &LocalFileUploader{BasePath: dir}
+	})
+
+	assert.NoError(t, Delete("local_delete_test", "a.png"))
+	assert.NoError(t, Delete("local_delete_test", "missing.txt"))
+	// the path can not escape the base path
+	assert.NoError(t, Delete("local_delete_test", "../"+filepath.Base(dir)+"/b.txt"))
+
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	assert.Equal(t, "b.txt", files[0].Name())
+
+	assert.Equal(t, ErrNotDeletable, Delete("not_exist", "a.png"))
+}
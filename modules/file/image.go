@@ -0,0 +1,504 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package file
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/wowucco/go-admin/modules/config"
+)
+
+const (
+	// ThumbnailVariant is the name of the variant used by the image fields of list pages.
+	ThumbnailVariant = "thumb"
+	// MediumVariant is the name of the medium size variant.
+	MediumVariant = "medium"
+
+	defaultImageQuality = 85
+	// defaultImageMaxPixels is 40 megapixels, a decoded image of this size
+	// takes about 160MB of memory.
+	defaultImageMaxPixels = 40000000
+)
+
+// ImageVariant is a resized copy of an uploaded image. The image is scaled
+// down to fit in Width x Height, or to fill and center crop it when Crop is
+// true. A zero Width or Height means no limit on that side.
+type ImageVariant struct {
+	Name   string
+	Width  int
+	Height int
+	Crop   bool
+}
+
+// ImagePipeline processes the uploaded jpeg and png images before they are
+// handed to the Uploader: it fixes the exif orientation, strips the metadata
+// by re-encoding, converts the format and generates the variants, which are
+// stored next to the original as "<name>_<variant><ext>".
+type ImagePipeline struct {
+	Variants []ImageVariant
+	// Format is the format of the stored images, "jpeg" or "png". Empty
+	// keeps the uploaded format.
+	Format string
+	// Quality is the jpeg quality, default is 85.
+	Quality int
+	// MaxPixels is the limit of width x height of the uploaded images, the
+	// larger ones are rejected before they are decoded. Default is 40
+	// megapixels.
+	MaxPixels int
+}
+
+// ProcessedImage is the result of the ImagePipeline.
+type ProcessedImage struct {
+	Ext         string
+	ContentType string
+	Original    []byte
+	Variants    map[string][]byte
+}
+
+var (
+	imagePipeline   *ImagePipeline
+	imagePipelineMu sync.RWMutex
+
+	errImageFormat = errors.New("unsupported image format")
+
+	// ErrImageTooLarge is returned by Process when the image has more pixels
+	// than the limit of the pipeline.
+	ErrImageTooLarge = errors.New("the image is too large")
+
+	variantStore   VariantStore
+	variantCache   = make(map[string][]string)
+	variantCacheMu sync.RWMutex
+)
+
+// maxVariantCache is the limit of the cached variants of the stored images,
+// the cache is cleared when it is full.
+const maxVariantCache = 10000
+
+// DefaultImagePipeline return a pipeline with a 200x200 cropped thumbnail and
+// a 800x800 medium variant.
+func DefaultImagePipeline() *ImagePipeline {
+	return &ImagePipeline{
+		Variants: []ImageVariant{
+			{Name: ThumbnailVariant, Width: 200, Height: 200, Crop: true},
+			{Name: MediumVariant, Width: 800, Height: 800},
+		},
+	}
+}
+
+// NewImagePipeline return the pipeline of the config, nil if the config is nil.
+func NewImagePipeline(cfg *config.ImagePipeline) *ImagePipeline {
+	if cfg == nil {
+		return nil
+	}
+	p := DefaultImagePipeline()
+	if len(cfg.Variants) > 0 {
+		p.Variants = make([]ImageVariant, len(cfg.Variants))
+		for i, v := range cfg.Variants {
+			p.Variants[i] = ImageVariant{Name: v.Name, Width: v.Width, Height: v.Height, Crop: v.Crop}
+		}
+	}
+	p.Format = cfg.Format
+	p.Quality = cfg.Quality
+	p.MaxPixels = cfg.MaxPixels
+	return p
+}
+
+// SetImagePipeline set the pipeline of the uploaded images, nil turns it off.
+func SetImagePipeline(p *ImagePipeline) {
+	imagePipelineMu.Lock()
+	imagePipeline = p
+	imagePipelineMu.Unlock()
+}
+
+// GetImagePipeline return the pipeline of the uploaded images, which may be nil.
+func GetImagePipeline() *ImagePipeline {
+	imagePipelineMu.RLock()
+	defer imagePipelineMu.RUnlock()
+	return imagePipeline
+}
+
+// HasVariant check if the pipeline generates the variant of given name.
+func (p *ImagePipeline) HasVariant(name string) bool {
+	if p == nil {
+		return false
+	}
+	for _, v := range p.Variants {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// VariantStore keeps the names of the variants generated for the stored
+// images, such as the media library.
+type VariantStore interface {
+	FindVariants(path string) ([]string, error)
+}
+
+// SetVariantStore set the store of the variants recorded at upload.
+func SetVariantStore(store VariantStore) {
+	variantCacheMu.Lock()
+	variantStore = store
+	variantCache = make(map[string][]string)
+	variantCacheMu.Unlock()
+}
+
+// Variants return the names of the variants generated for the stored image.
+// The variants of the images uploaded by this process are remembered by
+// Upload, the others are read from the VariantStore once.
+func Variants(filePath string) []string {
+	variantCacheMu.RLock()
+	variants, ok := variantCache[filePath]
+	store := variantStore
+	variantCacheMu.RUnlock()
+	if ok || store == nil {
+		return variants
+	}
+
+	variants, err := store.FindVariants(filePath)
+	if err != nil {
+		// not cached, the store may be available later
+		return nil
+	}
+
+	rememberVariants(filePath, variants)
+	return variants
+}
+
+// rememberVariants cache the variants of the stored image, which never change
+// as every upload is stored with a new name.
+func rememberVariants(filePath string, variants []string) {
+	variantCacheMu.Lock()
+	if len(variantCache) >= maxVariantCache {
+		variantCache = make(map[string][]string)
+	}
+	variantCache[filePath] = variants
+	variantCacheMu.Unlock()
+}
+
+// VariantPath return the path of the variant of the stored image. The path
+// is returned unchanged if the variant was not generated when the image was
+// uploaded, as the images uploaded before the pipeline was enabled have no
+// variants.
+func VariantPath(filePath, variant string) string {
+	for _, v := range Variants(filePath) {
+		if v == variant {
+			return variantPath(filePath, variant)
+		}
+	}
+	return filePath
+}
+
+// variantPath return the path where the variant of the image is stored.
+func variantPath(filePath, variant string) string {
+	if filePath == "" {
+		return filePath
+	}
+	ext := path.Ext(filePath)
+	switch strings.ToLower(ext) {
+	case ".jpg", ".jpeg", ".png":
+		return strings.TrimSuffix(filePath, ext) + "_" + variant + ext
+	}
+	return filePath
+}
+
+// Process decode the image and return the processed original and variants.
+// It returns an error of unsupported format for anything but jpeg and png.
+func (p *ImagePipeline) Process(data []byte) (*ProcessedImage, error) {
+	var (
+		src    image.Image
+		format string
+		err    error
+	)
+
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		format = "jpeg"
+	case "image/png":
+		format = "png"
+	default:
+		return nil, errImageFormat
+	}
+
+	// the size is read from the header first, so a small file of a huge
+	// image can not exhaust the memory when it is decoded
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	maxPixels := p.MaxPixels
+	if maxPixels <= 0 {
+		maxPixels = defaultImageMaxPixels
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > maxPixels/cfg.Height {
+		return nil, ErrImageTooLarge
+	}
+
+	if format == "jpeg" {
+		src, err = jpeg.Decode(bytes.NewReader(data))
+	} else {
+		src, err = png.Decode(bytes.NewReader(data))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "jpeg" {
+		src = orient(src, jpegOrientation(data))
+	}
+
+	if p.Format != "" {
+		format = p.Format
+	}
+
+	res := &ProcessedImage{
+		Variants: make(map[string][]byte, len(p.Variants)),
+	}
+
+	if format == "png" {
+		res.Ext, res.ContentType = ".png", "image/png"
+	} else {
+		res.Ext, res.ContentType = ".jpg", "image/jpeg"
+	}
+
+	if res.Original, err = p.encode(src, format); err != nil {
+		return nil, err
+	}
+
+	for _, v := range p.Variants {
+		if res.Variants[v.Name], err = p.encode(resize(src, v), format); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+func (p *ImagePipeline) encode(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if format == "png" {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	// jpeg has no alpha channel, so flatten the image onto white.
+	b := img.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, b.Min, draw.Over)
+
+	quality := p.Quality
+	if quality <= 0 || quality > 100 {
+		quality = defaultImageQuality
+	}
+
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resize scale the image down with a box filter, it never scales up.
+func resize(src image.Image, v ImageVariant) image.Image {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+
+	// the crop window in the source image
+	cx, cy, cw, ch := 0, 0, sw, sh
+	dw, dh := sw, sh
+
+	switch {
+	case v.Crop && v.Width > 0 && v.Height > 0:
+		dw, dh = v.Width, v.Height
+		if sw*dh > sh*dw {
+			cw = sh * dw / dh
+			cx = (sw - cw) / 2
+		} else {
+			ch = sw * dh / dw
+			cy = (sh - ch) / 2
+		}
+		if cw < dw || ch < dh {
+			dw, dh = cw, ch
+		}
+	default:
+		if v.Width > 0 && dw > v.Width {
+			dh = dh * v.Width / dw
+			dw = v.Width
+		}
+		if v.Height > 0 && dh > v.Height {
+			dw = dw * v.Height / dh
+			dh = v.Height
+		}
+	}
+
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	rgba := toRGBA(src)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		y0 := cy + y*ch/dh
+		y1 := cy + (y+1)*ch/dh
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < dw; x++ {
+			x0 := cx + x*cw/dw
+			x1 := cx + (x+1)*cw/dw
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				i := rgba.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(rgba.Pix[i])
+					g += uint32(rgba.Pix[i+1])
+					bl += uint32(rgba.Pix[i+2])
+					a += uint32(rgba.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+
+			j := dst.PixOffset(x, y)
+			dst.Pix[j] = uint8(r / n)
+			dst.Pix[j+1] = uint8(g / n)
+			dst.Pix[j+2] = uint8(bl / n)
+			dst.Pix[j+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	if rgba, ok := src.(*image.RGBA); ok && b.Min == (image.Point{}) {
+		return rgba
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
+	return rgba
+}
+
+// orient rotate and flip the image according to the exif orientation.
+func orient(src image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	rgba := toRGBA(src)
+	w, h := rgba.Bounds().Dx(), rgba.Bounds().Dy()
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for sy := 0; sy < h; sy++ {
+		for sx := 0; sx < w; sx++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-sx, sy
+			case 3:
+				dx, dy = w-1-sx, h-1-sy
+			case 4:
+				dx, dy = sx, h-1-sy
+			case 5:
+				dx, dy = sy, sx
+			case 6:
+				dx, dy = h-1-sy, sx
+			case 7:
+				dx, dy = h-1-sy, w-1-sx
+			case 8:
+				dx, dy = sy, w-1-sx
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], rgba.Pix[rgba.PixOffset(sx, sy):rgba.PixOffset(sx, sy)+4])
+		}
+	}
+
+	return dst
+}
+
+// jpegOrientation return the exif orientation of the jpeg data, 1 if not found.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		size := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if marker == 0xDA || size < 2 || i+2+size > len(data) {
+			// start of scan, no exif before the image data
+			return 1
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && len(segment) > 14 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + size
+	}
+
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset+2 > len(tiff) {
+		return 1
+	}
+
+	count := int(order.Uint16(tiff[offset : offset+2]))
+	for k := 0; k < count; k++ {
+		entry := offset + 2 + k*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8 : entry+10]))
+		}
+	}
+
+	return 1
+}
@@ -0,0 +1,164 @@
+package file
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wowucco/go-admin/modules/config"
+)
+
+// jpegWithOrientation encode a jpeg with an exif segment holding the orientation.
+func jpegWithOrientation(t *testing.T, img image.Image, orientation uint16) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, img, nil))
+
+	tiff := make([]byte, 26)
+	copy(tiff, "MM")
+	binary.BigEndian.PutUint16(tiff[2:], 0x2A)
+	binary.BigEndian.PutUint32(tiff[4:], 8)
+	binary.BigEndian.PutUint16(tiff[8:], 1)
+	binary.BigEndian.PutUint16(tiff[10:], 0x0112)
+	binary.BigEndian.PutUint16(tiff[12:], 3)
+	binary.BigEndian.PutUint32(tiff[14:], 1)
+	binary.BigEndian.PutUint16(tiff[18:], orientation)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+
+	data := buf.Bytes()
+	res := append([]byte{}, data[:2]...)
+	res = append(res, app1...)
+	res = append(res, segment...)
+	return append(res, data[2:]...)
+}
+
+func TestJpegOrientation(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	data := jpegWithOrientation(t, img, 6)
+
+	assert.Equal(t, 6, jpegOrientation(data))
+
+	var plain bytes.Buffer
+	_ = jpeg.Encode(&plain, img, nil)
+	assert.Equal(t, 1, jpegOrientation(plain.Bytes()))
+}
+
+func TestImagePipeline_Process(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+
+	res, err := DefaultImagePipeline().Process(jpegWithOrientation(t, img, 6))
+	assert.NoError(t, err)
+	assert.Equal(t, ".jpg", res.Ext)
+
+	// rotated by the orientation and the exif segment is gone
+	original, err := jpeg.Decode(bytes.NewReader(res.Original))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 200, 400), original.Bounds())
+	assert.Equal(t, 1, jpegOrientation(res.Original))
+
+	thumb, _ := jpeg.Decode(bytes.NewReader(res.Variants[ThumbnailVariant]))
+	assert.Equal(t, image.Rect(0, 0, 200, 200), thumb.Bounds())
+
+	// the medium variant never scales up
+	medium, _ := jpeg.Decode(bytes.NewReader(res.Variants[MediumVariant]))
+	assert.Equal(t, image.Rect(0, 0, 200, 400), medium.Bounds())
+
+	var pngData bytes.Buffer
+	_ = png.Encode(&pngData, image.NewRGBA(image.Rect(0, 0, 1600, 800)))
+	res, err = (&ImagePipeline{Variants: []ImageVariant{{Name: MediumVariant, Width: 800, Height: 800}}, Format: "jpeg"}).
+		Process(pngData.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, ".jpg", res.Ext)
+	medium, _ = jpeg.Decode(bytes.NewReader(res.Variants[MediumVariant]))
+	assert.Equal(t, image.Rect(0, 0, 800, 400), medium.Bounds())
+
+	_, err = DefaultImagePipeline().Process([]byte("not an image"))
+	assert.Equal(t, errImageFormat, err)
+}
+
+func TestUploadWithImagePipeline(t *testing.T) {
+	SetImagePipeline(DefaultImagePipeline())
+	defer SetImagePipeline(nil)
+
+	var pngData bytes.Buffer
+	_ = png.Encode(&pngData, image.NewRGBA(image.Rect(0, 0, 300, 300)))
+
+	form := multipartForm(t, "avatar", "me.png", pngData.String())
+	stored := make(map[string][]byte)
+
+	err := Upload(func(fh *multipart.FileHeader, filename string) (string, error) {
+		f, _ := fh.Open()
+		stored[filename], _ = ioutil.ReadAll(f)
+		return filename, nil
+	}, form)
+
+	assert.NoError(t, err)
+	assert.Len(t, stored, 3)
+
+	value := form.Value["avatar"][0]
+	assert.True(t, strings.HasSuffix(value, ".png"))
+	assert.Contains(t, stored, variantPath(value, ThumbnailVariant))
+	assert.Contains(t, stored, variantPath(value, MediumVariant))
+	// the variants are known without asking the store
+	assert.Equal(t, []string{MediumVariant, ThumbnailVariant}, Variants(value))
+	assert.Equal(t, variantPath(value, ThumbnailVariant), VariantPath(value, ThumbnailVariant))
+	assert.Equal(t, "a.txt", variantPath("a.txt", ThumbnailVariant))
+}
+
+func TestImagePipeline_ProcessTooLarge(t *testing.T) {
+	var pngData bytes.Buffer
+	_ = png.Encode(&pngData, image.NewRGBA(image.Rect(0, 0, 300, 300)))
+
+	_, err := (&ImagePipeline{MaxPixels: 300 * 299}).Process(pngData.Bytes())
+	assert.Equal(t, ErrImageTooLarge, err)
+
+	_, err = (&ImagePipeline{MaxPixels: 300 * 300}).Process(pngData.Bytes())
+	assert.NoError(t, err)
+}
+
+type testVariantStore map[string][]string
+
+func (s testVariantStore) FindVariants(path string) ([]string, error) {
+	if path == "broken.png" {
+		return nil, errors.New("store is down")
+	}
+	return s[path], nil
+}
+
+func TestVariantPathFallback(t *testing.T) {
+	// b.png is uploaded before the pipeline was enabled
+	SetVariantStore(testVariantStore{"a.png": {ThumbnailVariant}})
+	defer SetVariantStore(nil)
+
+	assert.Equal(t, "a_thumb.png", VariantPath("a.png", ThumbnailVariant))
+	assert.Equal(t, "a.png", VariantPath("a.png", MediumVariant))
+	assert.Equal(t, "b.png", VariantPath("b.png", ThumbnailVariant))
+	assert.Equal(t, "broken.png", VariantPath("broken.png", ThumbnailVariant))
+	assert.Equal(t, "", VariantPath("", ThumbnailVariant))
+}
+
+func TestNewImagePipeline(t *testing.T) {
+	assert.Nil(t, NewImagePipeline(nil))
+	assert.Equal(t, DefaultImagePipeline().Variants, NewImagePipeline(&config.ImagePipeline{}).Variants)
+
+	p := NewImagePipeline(&config.ImagePipeline{
+		Variants: []config.ImageVariant{{Name: "small", Width: 50, Height: 50, Crop: true}},
+		Format:   "jpeg",
+		Quality:  70,
+	})
+	assert.Equal(t, []ImageVariant{{Name: "small", Width: 50, Height: 50, Crop: true}}, p.Variants)
+	assert.Equal(t, "jpeg", p.Format)
+	assert.Equal(t, 70, p.Quality)
+}
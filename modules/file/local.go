@@ -7,6 +7,8 @@ package file
 import (
 	"github.com/wowucco/go-admin/modules/config"
 	"mime/multipart"
+	"os"
+	"path/filepath"
 )
 
 // LocalFileUploader is an Uploader of local file engine.
@@ -30,3 +32,21 @@ func (local *LocalFileUploader) Upload(form *multipart.Form) error {
 		return filename, nil
 	}, form)
 }
+
+// Delete implements the Deleter.Delete, a missing file is not an error.
+func (local *LocalFileUploader) Delete(path string) error {
+	err := os.Remove(filepath.Join(local.BasePath, filepath.Clean("/"+path)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Exists implements the Exister.Exists.
+func (local *LocalFileUploader) Exists(path string) (bool, error) {
+	_, err := os.Stat(filepath.Join(local.BasePath, filepath.Clean("/"+path)))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
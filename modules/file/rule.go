@@ -0,0 +1,195 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package file
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"  // register gif decoder for the dimension check
+	_ "image/jpeg" // register jpeg decoder for the dimension check
+	_ "image/png"  // register png decoder for the dimension check
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/wowucco/go-admin/modules/language"
+)
+
+// Rule is the constraints of the files uploaded to one form field. The zero
+// value of every constraint means no limit.
+type Rule struct {
+	// MaxSize is the max size of each file in bytes.
+	MaxSize int64
+	// Extensions is the allowed file extensions, like ".png" or "png".
+	Extensions []string
+	// MimeTypes is the allowed mime types sniffed from the file content,
+	// like "image/png" or "image/*".
+	MimeTypes []string
+
+	MinWidth  int
+	MinHeight int
+	MaxWidth  int
+	MaxHeight int
+
+	// MaxCount is the max number of files of the field.
+	MaxCount int
+}
+
+// IsEmpty check if the rule has no constraint.
+func (r Rule) IsEmpty() bool {
+	return r.MaxSize == 0 && len(r.Extensions) == 0 && len(r.MimeTypes) == 0 &&
+		r.MinWidth == 0 && r.MinHeight == 0 && r.MaxWidth == 0 && r.MaxHeight == 0 && r.MaxCount == 0
+}
+
+func (r Rule) checkDimension() bool {
+	return r.MinWidth > 0 || r.MinHeight > 0 || r.MaxWidth > 0 || r.MaxHeight > 0
+}
+
+// Validate check the files against the rule and return the first violation.
+func (r Rule) Validate(files []*multipart.FileHeader) error {
+	if r.MaxCount > 0 && len(files) > r.MaxCount {
+		return ruleError("", "too many files, the max count is", strconv.Itoa(r.MaxCount))
+	}
+
+	for _, fh := range files {
+		if err := r.validateFile(fh); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateStored check a stored file, such as one picked from the media
+// library, against the size, extension and mime type constraints. The image
+// dimension is not checked as the file is not read.
+func (r Rule) ValidateStored(filename, mime string, size int64) error {
+	if r.MaxSize > 0 && size > r.MaxSize {
+		return ruleError(filename, "file is too large, the max size is", FormatSize(r.MaxSize))
+	}
+	if len(r.Extensions) > 0 && !r.allowExtension(path.Ext(filename)) {
+		return ruleError(filename, "file extension is not allowed, allowed:", strings.Join(r.Extensions, ", "))
+	}
+	if len(r.MimeTypes) > 0 && !r.allowMimeType(mime) {
+		return ruleError(filename, "file type is not allowed, allowed:", strings.Join(r.MimeTypes, ", "))
+	}
+	return nil
+}
+
+func (r Rule) validateFile(fh *multipart.FileHeader) error {
+	if r.MaxSize > 0 && fh.Size > r.MaxSize {
+		return ruleError(fh.Filename, "file is too large, the max size is", FormatSize(r.MaxSize))
+	}
+
+	if len(r.Extensions) > 0 && !r.allowExtension(path.Ext(fh.Filename)) {
+		return ruleError(fh.Filename, "file extension is not allowed, allowed:", strings.Join(r.Extensions, ", "))
+	}
+
+	if len(r.MimeTypes) == 0 && !r.checkDimension() {
+		return nil
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	if len(r.MimeTypes) > 0 {
+		head := make([]byte, 512)
+		n, err := io.ReadFull(f, head)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return err
+		}
+		if !r.allowMimeType(http.DetectContentType(head[:n])) {
+			return ruleError(fh.Filename, "file type is not allowed, allowed:", strings.Join(r.MimeTypes, ", "))
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+
+	if r.checkDimension() {
+		cfg, _, err := image.DecodeConfig(f)
+		if err != nil {
+			return ruleError(fh.Filename, "file is not a valid image", "")
+		}
+		if cfg.Width < r.MinWidth || cfg.Height < r.MinHeight {
+			return ruleError(fh.Filename, "image is too small, the min size is",
+				fmt.Sprintf("%dx%d", r.MinWidth, r.MinHeight))
+		}
+		if (r.MaxWidth > 0 && cfg.Width > r.MaxWidth) || (r.MaxHeight > 0 && cfg.Height > r.MaxHeight) {
+			return ruleError(fh.Filename, "image is too large, the max size is",
+				fmt.Sprintf("%dx%d", r.MaxWidth, r.MaxHeight))
+		}
+	}
+
+	return nil
+}
+
+func (r Rule) allowExtension(ext string) bool {
+	ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+	for _, allowed := range r.Extensions {
+		if strings.ToLower(strings.TrimPrefix(allowed, ".")) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) allowMimeType(mime string) bool {
+	if i := strings.Index(mime, ";"); i > -1 {
+		mime = mime[:i]
+	}
+	for _, allowed := range r.MimeTypes {
+		if allowed == mime {
+			return true
+		}
+		if strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mime, allowed[:len(allowed)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// RuleError is returned when an uploaded file breaks a Rule.
+type RuleError struct {
+	Filename string
+	Msg      string
+	Limit    string
+}
+
+func (e RuleError) Error() string {
+	msg := language.Get(e.Msg)
+	if e.Limit != "" {
+		msg += " " + e.Limit
+	}
+	if e.Filename != "" {
+		return e.Filename + ": " + msg
+	}
+	return msg
+}
+
+func ruleError(filename, msg, limit string) error {
+	return RuleError{Filename: filename, Msg: msg, Limit: limit}
+}
+
+// FormatSize return the human readable size of given bytes.
+func FormatSize(size int64) string {
+	switch {
+	case size >= 1<<30 && size%(1<<30) == 0:
+		return strconv.FormatInt(size>>30, 10) + " GB"
+	case size >= 1<<20 && size%(1<<20) == 0:
+		return strconv.FormatInt(size>>20, 10) + " MB"
+	case size >= 1<<10 && size%(1<<10) == 0:
+		return strconv.FormatInt(size>>10, 10) + " KB"
+	}
+	return strconv.FormatInt(size, 10) + " B"
+}
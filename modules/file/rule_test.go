@@ -0,0 +1,65 @@
+package file
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pngContent(width, height int) string {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)))
+	return buf.String()
+}
+
+func fileHeaders(t *testing.T, filename, content string) []*multipart.FileHeader {
+	return multipartForm(t, "file", filename, content).File["file"]
+}
+
+func TestRule_Validate(t *testing.T) {
+	img := pngContent(20, 10)
+
+	assert.NoError(t, Rule{}.Validate(fileHeaders(t, "a.exe", "anything")))
+
+	assert.Error(t, Rule{MaxSize: 4}.Validate(fileHeaders(t, "a.txt", "too long")))
+	assert.NoError(t, Rule{MaxSize: 8}.Validate(fileHeaders(t, "a.txt", "too long")))
+
+	assert.Error(t, Rule{Extensions: []string{"png", ".jpg"}}.Validate(fileHeaders(t, "a.mp4", img)))
+	assert.NoError(t, Rule{Extensions: []string{"png", ".jpg"}}.Validate(fileHeaders(t, "a.PNG", img)))
+
+	// the content decides the mime type, not the extension
+	assert.Error(t, Rule{MimeTypes: []string{"image/*"}}.Validate(fileHeaders(t, "a.png", "plain text")))
+	assert.NoError(t, Rule{MimeTypes: []string{"image/*"}}.Validate(fileHeaders(t, "a.png", img)))
+	assert.NoError(t, Rule{MimeTypes: []string{"image/png"}}.Validate(fileHeaders(t, "a.png", img)))
+
+	assert.Error(t, Rule{MinWidth: 30}.Validate(fileHeaders(t, "a.png", img)))
+	assert.Error(t, Rule{MaxHeight: 5}.Validate(fileHeaders(t, "a.png", img)))
+	assert.Error(t, Rule{MaxWidth: 100}.Validate(fileHeaders(t, "a.png", "not an image")))
+	assert.NoError(t, Rule{MinWidth: 20, MinHeight: 10, MaxWidth: 20, MaxHeight: 10}.
+		Validate(fileHeaders(t, "a.png", img)))
+
+	files := append(fileHeaders(t, "a.png", img), fileHeaders(t, "b.png", img)...)
+	assert.Error(t, Rule{MaxCount: 1}.Validate(files))
+	assert.NoError(t, Rule{MaxCount: 2}.Validate(files))
+}
+
+func TestRule_ValidateStored(t *testing.T) {
+	rule := Rule{MaxSize: 1 << 20, Extensions: []string{"png"}, MimeTypes: []string{"image/*"}}
+
+	assert.NoError(t, rule.ValidateStored("a.png", "image/png", 100))
+	assert.Error(t, rule.ValidateStored("a.png", "image/png", 2<<20))
+	assert.Error(t, rule.ValidateStored("a.pdf", "image/png", 100))
+	assert.Error(t, rule.ValidateStored("a.png", "text/plain; charset=utf-8", 100))
+	// the dimension is not checked without the file
+	assert.NoError(t, Rule{MinWidth: 100}.ValidateStored("a.png", "image/png", 100))
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "2 MB", FormatSize(2<<20))
+	assert.Equal(t, "1 GB", FormatSize(1<<30))
+	assert.Equal(t, "1536 B", FormatSize(1536))
+}
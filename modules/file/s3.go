@@ -0,0 +1,431 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package file
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wowucco/go-admin/modules/config"
+)
+
+func init() {
+	AddUploader("s3", GetS3FileUploader)
+}
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3EmptyPayload    = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	s3TimeFormat      = "20060102T150405Z"
+	s3DateFormat      = "20060102"
+
+	defaultS3Region         = "us-east-1"
+	defaultS3PresignExpires = 15 * time.Minute
+	// defaultS3Timeout is the limit of a whole request, including the upload
+	// of a large file.
+	defaultS3Timeout = 10 * time.Minute
+)
+
+// S3Config is the config of the S3 compatible object storage uploader. It is
+// read from the config of the file upload engine, for example:
+//
+//	{
+//	    "name": "s3",
+//	    "config": {
+//	        "endpoint": "https://s3.amazonaws.com",
+//	        "region": "us-east-1",
+//	        "bucket": "admin",
+//	        "access_key": "...",
+//	        "secret_key": "...",
+//	        "prefix": "uploads",
+//	        "presign": true,
+//	        "timeout": 600
+//	    }
+//	}
+type S3Config struct {
+	Endpoint     string
+	Region       string
+	Bucket       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Prefix       string
+	// PathStyle put the bucket in the path instead of the host name, which
+	// is what most self-hosted stores like MinIO expect.
+	PathStyle bool
+	// PublicURL is the base url of the bucket used for the file links, such
+	// as a cdn. It is ignored when Presign is on.
+	PublicURL string
+	// Presign makes the file links presigned urls of private objects.
+	Presign        bool
+	PresignExpires time.Duration
+	// Timeout is the limit of a request to the store, default is 10 minutes.
+	Timeout time.Duration
+}
+
+// S3FileUploader is an Uploader which streams files to a S3 compatible object storage.
+type S3FileUploader struct {
+	Config S3Config
+	Client *http.Client
+	now    func() time.Time
+}
+
+// GetS3FileUploader return the s3 Uploader with the global file upload engine config.
+func GetS3FileUploader() Uploader {
+	return NewS3FileUploader(S3ConfigFromMap(config.GetFileUploadEngine().Config))
+}
+
+// NewS3FileUploader return a s3 Uploader of given config.
+func NewS3FileUploader(cfg S3Config) *S3FileUploader {
+	if cfg.Region == "" {
+		cfg.Region = defaultS3Region
+	}
+	if cfg.PresignExpires <= 0 {
+		cfg.PresignExpires = defaultS3PresignExpires
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultS3Timeout
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	cfg.PublicURL = strings.TrimRight(cfg.PublicURL, "/")
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/")
+	return &S3FileUploader{
+		Config: cfg,
+		Client: &http.Client{Timeout: cfg.Timeout},
+		now:    time.Now,
+	}
+}
+
+// S3ConfigFromMap parse the config of the file upload engine.
+func S3ConfigFromMap(m map[string]interface{}) S3Config {
+	cfg := S3Config{
+		Endpoint:     mapString(m, "endpoint"),
+		Region:       mapString(m, "region"),
+		Bucket:       mapString(m, "bucket"),
+		AccessKey:    mapString(m, "access_key"),
+		SecretKey:    mapString(m, "secret_key"),
+		SessionToken: mapString(m, "session_token"),
+		Prefix:       mapString(m, "prefix"),
+		PathStyle:    mapString(m, "path_style") == "true",
+		PublicURL:    mapString(m, "public_url"),
+		Presign:      mapString(m, "presign") == "true",
+	}
+	if expires, err := strconv.Atoi(mapString(m, "presign_expires")); err == nil {
+		cfg.PresignExpires = time.Duration(expires) * time.Second
+	}
+	if timeout, err := strconv.Atoi(mapString(m, "timeout")); err == nil {
+		cfg.Timeout = time.Duration(timeout) * time.Second
+	}
+	return cfg
+}
+
+// Upload implements the Uploader.Upload.
+func (s *S3FileUploader) Upload(form *multipart.Form) error {
+	return Upload(func(fileObj *multipart.FileHeader, filename string) (string, error) {
+		key := s.key(filename)
+		if err := s.PutObject(key, fileObj); err != nil {
+			return "", err
+		}
+		return key, nil
+	}, form)
+}
+
+// PutObject streams the multipart file to the object of given key.
+func (s *S3FileUploader) PutObject(key string, fileObj *multipart.FileHeader) error {
+	f, err := fileObj.Open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	req, err := http.NewRequest(http.MethodPut, s.objectURL(key), f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = fileObj.Size
+
+	contentType := fileObj.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	s.sign(req, s3UnsignedPayload, s.now().UTC())
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("s3 upload %s: %s %s", key, res.Status, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+// Delete implements the Deleter.Delete.
+func (s *S3FileUploader) Delete(path string) error {
+	key := strings.TrimLeft(path, "/")
+
+	req, err := http.NewRequest(http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+
+	s.sign(req, s3EmptyPayload, s.now().UTC())
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	// deleting a missing object is a success in s3, some stores answer 404
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("s3 delete %s: %s %s", key, res.Status, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+// Exists implements the Exister.Exists.
+func (s *S3FileUploader) Exists(path string) (bool, error) {
+	key := strings.TrimLeft(path, "/")
+
+	req, err := http.NewRequest(http.MethodHead, s.objectURL(key), nil)
+	if err != nil {
+		return false, err
+	}
+
+	s.sign(req, s3EmptyPayload, s.now().UTC())
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	_ = res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return false, fmt.Errorf("s3 head %s: %s", key, res.Status)
+	}
+
+	return true, nil
+}
+
+// URL implements the URLGenerator.URL.
+func (s *S3FileUploader) URL(path string) string {
+	if path == "" || strings.HasPrefix(path, "http") {
+		return path
+	}
+	key := strings.TrimLeft(path, "/")
+	if s.Config.Presign {
+		return s.PresignURL(key, s.Config.PresignExpires)
+	}
+	if s.Config.PublicURL != "" {
+		return s.Config.PublicURL + "/" + s3EscapePath(key)
+	}
+	return s.objectURL(key)
+}
+
+// PresignURL return a presigned GET url of the object which is valid for the given duration.
+func (s *S3FileUploader) PresignURL(key string, expires time.Duration) string {
+	u, err := url.Parse(s.objectURL(key))
+	if err != nil {
+		return ""
+	}
+
+	t := s.now().UTC()
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.Config.AccessKey+"/"+s.scope(t))
+	query.Set("X-Amz-Date", t.Format(s3TimeFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if s.Config.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.Config.SessionToken)
+	}
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		s3CanonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+
+	u.RawQuery = s3CanonicalQuery(query) + "&X-Amz-Signature=" + s.signature(t, canonicalRequest)
+
+	return u.String()
+}
+
+func (s *S3FileUploader) key(filename string) string {
+	if s.Config.Prefix == "" {
+		return filename
+	}
+	return s.Config.Prefix + "/" + filename
+}
+
+func (s *S3FileUploader) objectURL(key string) string {
+	endpoint := s.Config.Endpoint
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	if s.Config.PathStyle {
+		return endpoint + "/" + s.Config.Bucket + "/" + s3EscapePath(key)
+	}
+	scheme := endpoint[:strings.Index(endpoint, "://")+3]
+	return scheme + s.Config.Bucket + "." + endpoint[len(scheme):] + "/" + s3EscapePath(key)
+}
+
+func (s *S3FileUploader) scope(t time.Time) string {
+	return t.Format(s3DateFormat) + "/" + s.Config.Region + "/s3/aws4_request"
+}
+
+// sign adds the signature version 4 authorization header to the request.
+func (s *S3FileUploader) sign(req *http.Request, payloadHash string, t time.Time) {
+	req.Header.Set("X-Amz-Date", t.Format(s3TimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.Config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.Config.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		lk := strings.ToLower(k)
+		if lk == "content-type" || lk == "range" || strings.HasPrefix(lk, "x-amz-") {
+			headers[lk] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := ""
+	for _, k := range names {
+		canonicalHeaders += k + ":" + headers[k] + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	req.Header.Set("Authorization", s3Algorithm+
+		" Credential="+s.Config.AccessKey+"/"+s.scope(t)+
+		",SignedHeaders="+signedHeaders+
+		",Signature="+s.signature(t, canonicalRequest))
+}
+
+func (s *S3FileUploader) signature(t time.Time, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := s3Algorithm + "\n" + t.Format(s3TimeFormat) + "\n" + s.scope(t) + "\n" + hex.EncodeToString(hash[:])
+
+	key := s3HMAC([]byte("AWS4"+s.Config.SecretKey), t.Format(s3DateFormat))
+	key = s3HMAC(key, s.Config.Region)
+	key = s3HMAC(key, "s3")
+	key = s3HMAC(key, "aws4_request")
+
+	return hex.EncodeToString(s3HMAC(key, stringToSign))
+}
+
+func s3HMAC(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, s3Escape(k)+"="+s3Escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func s3EscapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		segments[i] = s3Escape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+// s3Escape escapes the string as the rfc 3986 which is required by the signature.
+func s3Escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func mapString(m map[string]interface{}, key string) string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return ""
+	}
+	switch t := v.(type) {
+	case string:
+		return t
+	case bool:
+		return strconv.FormatBool(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -272,4 +272,181 @@ var cn = LangSet{
 	"system.go_admin_version": "应用版本",
 	"system.theme_name":       "主题",
 	"system.theme_version":    "主题版本",
+
+	"too many files, the max count is":        "文件数量过多，最多为",
+	"file is too large, the max size is":      "文件过大，最大为",
+	"file extension is not allowed, allowed:": "不允许的文件后缀，允许：",
+	"file type is not allowed, allowed:":      "不允许的文件类型，允许：",
+	"file is not a valid image":               "文件不是有效的图片",
+	"image is too small, the min size is":     "图片尺寸过小，最小为",
+	"image is too large, the max size is":     "图片尺寸过大，最大为",
+
+	"media library":             "媒体库",
+	"choose from media library": "从媒体库选择",
+	"preview":                   "预览",
+	"mime":                      "类型",
+	"size":                      "大小",
+	"uploader":                  "上传者",
+	"references":                "引用数",
+	"clean up":                  "清理",
+	"orphaned files deleted":    "已删除的孤立文件",
+	"file is referenced":        "文件正在被记录引用",
+
+	"two-factor authentication":                          "两步验证",
+	"two-factor code":                                    "验证码",
+	"two-factor code or recovery code":                   "验证码或恢复码",
+	"wrong two-factor code":                              "验证码错误",
+	"two-factor authentication is required by your role": "你的角色要求开启两步验证",
+	"scan the qr code with an authenticator app, or enter the secret manually":        "使用身份验证器应用扫描二维码，或手动输入密钥",
+	"save these recovery codes, each of them can be used once when the phone is lost": "请保存这些恢复码，手机丢失时每个恢复码可使用一次",
+	"recovery codes left":       "剩余恢复码",
+	"regenerate recovery codes": "重新生成恢复码",
+	"enable":                    "启用",
+	"disable":                   "停用",
+	"enabled":                   "已启用",
+	"continue":                  "继续",
+	"reset two-factor":          "重置两步验证",
+	"two-factor authentication is not enabled": "未开启两步验证",
+	"two-factor authentication is reset":       "两步验证已重置",
+	"required":                                 "必须",
+	"optional":                                 "可选",
+	"the users of the role and its child roles must enroll the two-factor authentication": "该角色及其子角色的用户必须开启两步验证",
+
+	"sign in with":        "登录方式：",
+	"single sign-on fail": "单点登录失败，请重试或联系管理员",
+
+	"too many failed logins, please try again later": "登录失败次数过多，请稍后再试",
+	"login locks":    "登录锁定",
+	"kind":           "类型",
+	"value":          "值",
+	"failures":       "失败次数",
+	"locked":         "已锁定",
+	"locked until":   "锁定至",
+	"last failed at": "最近失败时间",
+	"unlock":         "解锁",
+	"unlocked":       "已解锁",
+
+	"email":                     "邮箱",
+	"use to reset the password": "用于重置密码",
+	"the password must have at least %d characters":                          "密码至少需要 %d 个字符",
+	"the password must have an uppercase letter":                             "密码必须包含大写字母",
+	"the password must have a lowercase letter":                              "密码必须包含小写字母",
+	"the password must have a digit":                                         "密码必须包含数字",
+	"the password must have a symbol":                                        "密码必须包含符号",
+	"the password can not be the username":                                   "密码不能与用户名相同",
+	"the password can not be one of the last %d passwords":                   "密码不能与最近 %d 次使用的密码相同",
+	"the password reset link is invalid or expired":                          "密码重置链接无效或已过期",
+	"password reset is not enabled":                                          "未开启密码重置",
+	"username or email can not be empty":                                     "用户名或邮箱不能为空",
+	"password can not be empty":                                              "密码不能为空",
+	"reset password":                                                         "重置密码",
+	"open the link to reset the password":                                    "打开以下链接重置密码",
+	"a password reset link is sent to the email of the account if it exists": "如果账号存在，密码重置链接已发送到其邮箱",
+	"the password is reset, please login":                                    "密码已重置，请登录",
+	"forgot password?":                                                       "忘记密码？",
+	"username or email":                                                      "用户名或邮箱",
+	"send reset link":                                                        "发送重置链接",
+	"back to login":                                                          "返回登录",
+	"your password has expired, please set a new one":                        "密码已过期，请设置新密码",
+	"request fail":                                                           "请求失败",
+
+	"api tokens":       "API 令牌",
+	"new api token":    "新建 API 令牌",
+	"no api token":     "没有 API 令牌",
+	"api token scopes": "权限范围",
+	"a table prefix and its methods per line, * for all the tables": "每行一个表前缀及其请求方法，* 表示所有表",
+	"expire days": "有效天数",
+	"0 is never":  "0 表示永不过期",
+	"copy the token now, it will not be shown again": "请立即复制令牌，它不会再次显示",
+	"expires at":                  "过期时间",
+	"last used":                   "最后使用",
+	"status":                      "状态",
+	"active":                      "有效",
+	"expired":                     "已过期",
+	"revoked":                     "已撤销",
+	"revoke":                      "撤销",
+	"never":                       "永不",
+	"create":                      "创建",
+	"wrong api token":             "错误的 API 令牌",
+	"wrong api token name":        "错误的 API 令牌名称",
+	"wrong api token expire days": "错误的 API 令牌有效天数",
+	"wrong api token scope: %s":   "错误的 API 令牌权限范围：%s",
+	"the api token needs a scope": "API 令牌至少需要一个权限范围",
+	"invalid api token":           "无效的 API 令牌",
+	"the api token scopes do not allow the request": "API 令牌的权限范围不允许该请求",
+
+	"my sessions":               "我的会话",
+	"no session":                "没有会话",
+	"current session":           "当前会话",
+	"ip":                        "IP",
+	"user agent":                "浏览器",
+	"login at":                  "登录时间",
+	"last seen":                 "最后活动",
+	"revoke all other sessions": "撤销其他所有会话",
+	"wrong session":             "错误的会话",
+	"wrong id":                  "错误的 id",
+	"force logout everywhere":   "强制退出所有登录",
+	"logged out everywhere":     "已退出所有登录",
+
+	"impersonate":                     "模拟登录",
+	"impersonator":                    "模拟者",
+	"impersonating":                   "正在模拟登录",
+	"stop impersonating":              "停止模拟",
+	"the user cannot be impersonated": "无法模拟该用户",
+	"%s is impersonating %s":          "%s 正在模拟 %s",
+
+	"denied permission":             "拒绝权限",
+	"denied permissions":            "被拒绝的权限",
+	"inherited roles":               "继承的角色",
+	"effective permissions":         "有效权限",
+	"parent role":                   "父角色",
+	"the parent role makes a cycle": "父角色形成了循环",
+	"override the permissions of the user and the roles":            "覆盖用户和角色的权限",
+	"override the permissions of the role and the parent roles":     "覆盖该角色和父角色的权限",
+	"the role has the permissions and the menus of the parent role": "该角色拥有父角色的权限和菜单",
+
+	"change requested":                       "变更已提交",
+	"the change is waiting for approval":     "变更正在等待审批",
+	"change request":                         "变更申请",
+	"change requests":                        "变更申请",
+	"a change is waiting for approval":       "有一个变更等待审批",
+	"the change request is approved":         "变更申请已通过",
+	"the change request is rejected":         "变更申请已驳回",
+	"the change request has been decided":    "变更申请已被处理",
+	"choose to approve or reject the change": "请选择通过或驳回该变更",
+	"insert":                                 "新增",
+	"update":                                 "修改",
+	"pending":                                "待审批",
+	"approved":                               "已通过",
+	"rejected":                               "已驳回",
+	"requester":                              "申请人",
+	"approver":                               "审批人",
+	"comment":                                "备注",
+	"changes":                                "变更内容",
+	"record":                                 "记录",
+	"decision":                               "审批结果",
+	"field":                                  "字段",
+	"old value":                              "原值",
+	"new value":                              "新值",
+	"table":                                  "表",
+
+	"approve": "通过",
+	"reject":  "驳回",
+
+	"query":           "查询",
+	"show_edit":       "打开编辑表单",
+	"show_create":     "打开新建表单",
+	"logout":          "登出",
+	"other":           "其他",
+	"response status": "响应状态",
+
+	"the table needs approval, the order can not be changed": "该表需要审批，无法修改排序",
+	"the table is not stored in the database":                "该表不在数据库中",
+
+	"not allowed while impersonating": "模拟登录时不允许此操作",
+
+	"the record has been changed since the change was requested": "该记录在申请变更后已被修改",
+
+	"upload in chunks": "分片上传",
+	"retry":            "重试",
 }
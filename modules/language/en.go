@@ -238,4 +238,183 @@ var en = LangSet{
 	"system.go_admin_version": "App Version",
 	"system.theme_name":       "Theme",
 	"system.theme_version":    "Theme Version",
+
+	"too many files, the max count is":        "Too many files, the max count is",
+	"file is too large, the max size is":      "File is too large, the max size is",
+	"file extension is not allowed, allowed:": "File extension is not allowed, allowed:",
+	"file type is not allowed, allowed:":      "File type is not allowed, allowed:",
+	"file is not a valid image":               "File is not a valid image",
+	"image is too small, the min size is":     "Image is too small, the min size is",
+	"image is too large, the max size is":     "Image is too large, the max size is",
+
+	"media library":             "Media library",
+	"choose from media library": "Choose from media library",
+	"preview":                   "Preview",
+	"mime":                      "MIME",
+	"size":                      "Size",
+	"uploader":                  "Uploader",
+	"references":                "References",
+	"clean up":                  "Clean up",
+	"orphaned files deleted":    "Orphaned files deleted",
+	"file is referenced":        "The file is referenced by records",
+
+	"two-factor authentication":                          "Two-factor authentication",
+	"two-factor code":                                    "Two-factor code",
+	"two-factor code or recovery code":                   "Two-factor code or recovery code",
+	"wrong two-factor code":                              "Wrong two-factor code",
+	"two-factor authentication is required by your role": "Two-factor authentication is required by your role",
+	"scan the qr code with an authenticator app, or enter the secret manually":        "Scan the QR code with an authenticator app, or enter the secret manually",
+	"save these recovery codes, each of them can be used once when the phone is lost": "Save these recovery codes, each of them can be used once when the phone is lost",
+	"recovery codes left":       "Recovery codes left",
+	"regenerate recovery codes": "Regenerate recovery codes",
+	"enable":                    "Enable",
+	"disable":                   "Disable",
+	"enabled":                   "Enabled",
+	"continue":                  "Continue",
+	"reset two-factor":          "Reset two-factor",
+	"two-factor authentication is not enabled": "Two-factor authentication is not enabled",
+	"two-factor authentication is reset":       "Two-factor authentication is reset",
+	"required":                                 "Required",
+	"optional":                                 "Optional",
+	"the users of the role and its child roles must enroll the two-factor authentication": "The users of the role and its child roles must enroll the two-factor authentication",
+
+	"sign in with":        "Sign in with",
+	"single sign-on fail": "Single sign-on failed, please try again or contact the administrator",
+
+	"too many failed logins, please try again later": "Too many failed logins, please try again later",
+	"login locks":    "Login locks",
+	"kind":           "Kind",
+	"value":          "Value",
+	"failures":       "Failures",
+	"locked":         "Locked",
+	"locked until":   "Locked until",
+	"last failed at": "Last failed at",
+	"unlock":         "Unlock",
+	"unlocked":       "Unlocked",
+
+	"email":                     "Email",
+	"use to reset the password": "Use to reset the password",
+	"the password must have at least %d characters":                          "The password must have at least %d characters",
+	"the password must have an uppercase letter":                             "The password must have an uppercase letter",
+	"the password must have a lowercase letter":                              "The password must have a lowercase letter",
+	"the password must have a digit":                                         "The password must have a digit",
+	"the password must have a symbol":                                        "The password must have a symbol",
+	"the password can not be the username":                                   "The password can not be the username",
+	"the password can not be one of the last %d passwords":                   "The password can not be one of the last %d passwords",
+	"the password reset link is invalid or expired":                          "The password reset link is invalid or expired",
+	"password reset is not enabled":                                          "Password reset is not enabled",
+	"username or email can not be empty":                                     "Username or email can not be empty",
+	"password can not be empty":                                              "Password can not be empty",
+	"reset password":                                                         "Reset password",
+	"open the link to reset the password":                                    "Open the link to reset the password",
+	"a password reset link is sent to the email of the account if it exists": "A password reset link is sent to the email of the account if it exists",
+	"the password is reset, please login":                                    "The password is reset, please login",
+	"forgot password?":                                                       "Forgot password?",
+	"username or email":                                                      "Username or email",
+	"send reset link":                                                        "Send reset link",
+	"back to login":                                                          "Back to login",
+	"your password has expired, please set a new one":                        "Your password has expired, please set a new one",
+	"request fail":                                                           "Request failed",
+
+	"api tokens":       "API tokens",
+	"new api token":    "New API token",
+	"no api token":     "No API token",
+	"api token scopes": "Scopes",
+	"a table prefix and its methods per line, * for all the tables": "A table prefix and its methods per line, * for all the tables",
+	"expire days": "Expire days",
+	"0 is never":  "0 is never",
+	"copy the token now, it will not be shown again": "Copy the token now, it will not be shown again",
+	"expires at":                  "Expires at",
+	"last used":                   "Last used",
+	"status":                      "Status",
+	"active":                      "Active",
+	"expired":                     "Expired",
+	"revoked":                     "Revoked",
+	"revoke":                      "Revoke",
+	"never":                       "Never",
+	"create":                      "Create",
+	"wrong api token":             "Wrong API token",
+	"wrong api token name":        "Wrong API token name",
+	"wrong api token expire days": "Wrong API token expire days",
+	"wrong api token scope: %s":   "Wrong API token scope: %s",
+	"the api token needs a scope": "The API token needs a scope",
+	"invalid api token":           "Invalid API token",
+	"the api token scopes do not allow the request": "The API token scopes do not allow the request",
+
+	"my sessions":               "My sessions",
+	"no session":                "No session",
+	"current session":           "Current",
+	"ip":                        "IP",
+	"user agent":                "User agent",
+	"login at":                  "Login at",
+	"last seen":                 "Last seen",
+	"revoke all other sessions": "Revoke all other sessions",
+	"wrong session":             "Wrong session",
+	"wrong id":                  "Wrong id",
+	"force logout everywhere":   "Force logout everywhere",
+	"logged out everywhere":     "Logged out everywhere",
+
+	"impersonate":                     "Impersonate",
+	"impersonator":                    "Impersonator",
+	"impersonating":                   "Impersonating",
+	"stop impersonating":              "Stop impersonating",
+	"the user cannot be impersonated": "The user cannot be impersonated",
+	"%s is impersonating %s":          "%s is impersonating %s",
+
+	"denied permission":             "Denied permission",
+	"denied permissions":            "Denied permissions",
+	"inherited roles":               "Inherited roles",
+	"effective permissions":         "Effective permissions",
+	"parent role":                   "Parent role",
+	"the parent role makes a cycle": "The parent role makes a cycle",
+	"override the permissions of the user and the roles":            "Override the permissions of the user and the roles",
+	"override the permissions of the role and the parent roles":     "Override the permissions of the role and the parent roles",
+	"the role has the permissions and the menus of the parent role": "The role has the permissions and the menus of the parent role",
+
+	"change requested":                       "change requested",
+	"the change is waiting for approval":     "the change is waiting for approval",
+	"change request":                         "change request",
+	"change requests":                        "change requests",
+	"a change is waiting for approval":       "a change is waiting for approval",
+	"the change request is approved":         "the change request is approved",
+	"the change request is rejected":         "the change request is rejected",
+	"the change request has been decided":    "the change request has been decided",
+	"choose to approve or reject the change": "choose to approve or reject the change",
+	"insert":                                 "insert",
+	"update":                                 "update",
+	"pending":                                "pending",
+	"approved":                               "approved",
+	"rejected":                               "rejected",
+	"requester":                              "requester",
+	"approver":                               "approver",
+	"comment":                                "comment",
+	"changes":                                "changes",
+	"record":                                 "record",
+	"decision":                               "decision",
+	"field":                                  "field",
+	"old value":                              "old value",
+	"new value":                              "new value",
+	"table":                                  "table",
+
+	"approve": "approve",
+	"reject":  "reject",
+
+	"query":           "query",
+	"show_edit":       "open edit form",
+	"show_create":     "open create form",
+	"export":          "export",
+	"login":           "login",
+	"logout":          "logout",
+	"other":           "other",
+	"response status": "response status",
+
+	"the table needs approval, the order can not be changed": "the table needs approval, the order can not be changed",
+	"the table is not stored in the database":                "the table is not stored in the database",
+
+	"not allowed while impersonating": "Not allowed while impersonating",
+
+	"the record has been changed since the change was requested": "The record has been changed since the change was requested",
+
+	"upload in chunks": "Upload in chunks",
+	"retry":            "Retry",
 }
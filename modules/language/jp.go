@@ -249,4 +249,181 @@ var jp = LangSet{
 	"system.go_admin_version": "App Version",
 	"system.theme_name":       "Theme",
 	"system.theme_version":    "Theme Version",
+
+	"too many files, the max count is":        "ファイルが多すぎます。最大数は",
+	"file is too large, the max size is":      "ファイルが大きすぎます。最大サイズは",
+	"file extension is not allowed, allowed:": "許可されていない拡張子です。許可：",
+	"file type is not allowed, allowed:":      "許可されていないファイル形式です。許可：",
+	"file is not a valid image":               "有効な画像ではありません",
+	"image is too small, the min size is":     "画像が小さすぎます。最小サイズは",
+	"image is too large, the max size is":     "画像が大きすぎます。最大サイズは",
+
+	"media library":             "メディアライブラリ",
+	"choose from media library": "メディアライブラリから選択",
+	"preview":                   "プレビュー",
+	"mime":                      "MIME",
+	"size":                      "サイズ",
+	"uploader":                  "アップロード者",
+	"references":                "参照数",
+	"clean up":                  "クリーンアップ",
+	"orphaned files deleted":    "削除された孤立ファイル",
+	"file is referenced":        "ファイルはレコードから参照されています",
+
+	"two-factor authentication":                          "二要素認証",
+	"two-factor code":                                    "認証コード",
+	"two-factor code or recovery code":                   "認証コードまたはリカバリーコード",
+	"wrong two-factor code":                              "認証コードが間違っています",
+	"two-factor authentication is required by your role": "あなたのロールでは二要素認証が必須です",
+	"scan the qr code with an authenticator app, or enter the secret manually":        "認証アプリでQRコードをスキャンするか、シークレットを手動で入力してください",
+	"save these recovery codes, each of them can be used once when the phone is lost": "これらのリカバリーコードを保存してください。端末を紛失した時に各コードを一度だけ使用できます",
+	"recovery codes left":       "残りのリカバリーコード",
+	"regenerate recovery codes": "リカバリーコードを再生成",
+	"enable":                    "有効にする",
+	"disable":                   "無効にする",
+	"enabled":                   "有効",
+	"continue":                  "続ける",
+	"reset two-factor":          "二要素認証をリセット",
+	"two-factor authentication is not enabled": "二要素認証は有効になっていません",
+	"two-factor authentication is reset":       "二要素認証がリセットされました",
+	"required":                                 "必須",
+	"optional":                                 "任意",
+	"the users of the role and its child roles must enroll the two-factor authentication": "このロールと子ロールのユーザーは二要素認証を登録する必要があります",
+
+	"sign in with":        "ログイン：",
+	"single sign-on fail": "シングルサインオンに失敗しました。再試行するか管理者に連絡してください",
+
+	"too many failed logins, please try again later": "ログインの失敗が多すぎます。しばらくしてから再試行してください",
+	"login locks":    "ログインロック",
+	"kind":           "種類",
+	"value":          "値",
+	"failures":       "失敗回数",
+	"locked":         "ロック中",
+	"locked until":   "ロック期限",
+	"last failed at": "最終失敗日時",
+	"unlock":         "ロック解除",
+	"unlocked":       "ロックを解除しました",
+
+	"email":                     "メールアドレス",
+	"use to reset the password": "パスワードのリセットに使用します",
+	"the password must have at least %d characters":                          "パスワードは %d 文字以上必要です",
+	"the password must have an uppercase letter":                             "パスワードには大文字が必要です",
+	"the password must have a lowercase letter":                              "パスワードには小文字が必要です",
+	"the password must have a digit":                                         "パスワードには数字が必要です",
+	"the password must have a symbol":                                        "パスワードには記号が必要です",
+	"the password can not be the username":                                   "パスワードをユーザー名と同じにすることはできません",
+	"the password can not be one of the last %d passwords":                   "直近 %d 回のパスワードは使用できません",
+	"the password reset link is invalid or expired":                          "パスワードリセットのリンクが無効か期限切れです",
+	"password reset is not enabled":                                          "パスワードリセットは有効になっていません",
+	"username or email can not be empty":                                     "ユーザー名またはメールアドレスを入力してください",
+	"password can not be empty":                                              "パスワードを入力してください",
+	"reset password":                                                         "パスワードリセット",
+	"open the link to reset the password":                                    "次のリンクを開いてパスワードをリセットしてください",
+	"a password reset link is sent to the email of the account if it exists": "アカウントが存在する場合、パスワードリセットのリンクをメールで送信しました",
+	"the password is reset, please login":                                    "パスワードをリセットしました。ログインしてください",
+	"forgot password?":                                                       "パスワードをお忘れですか？",
+	"username or email":                                                      "ユーザー名またはメールアドレス",
+	"send reset link":                                                        "リセットリンクを送信",
+	"back to login":                                                          "ログインに戻る",
+	"your password has expired, please set a new one":                        "パスワードの有効期限が切れました。新しいパスワードを設定してください",
+	"request fail":                                                           "リクエストに失敗しました",
+
+	"api tokens":       "API トークン",
+	"new api token":    "新しい API トークン",
+	"no api token":     "API トークンはありません",
+	"api token scopes": "スコープ",
+	"a table prefix and its methods per line, * for all the tables": "1 行に 1 つのテーブルプレフィックスとメソッド、* はすべてのテーブル",
+	"expire days": "有効日数",
+	"0 is never":  "0 は無期限",
+	"copy the token now, it will not be shown again": "今すぐトークンをコピーしてください。再表示されません",
+	"expires at":                  "有効期限",
+	"last used":                   "最終使用",
+	"status":                      "状態",
+	"active":                      "有効",
+	"expired":                     "期限切れ",
+	"revoked":                     "取り消し済み",
+	"revoke":                      "取り消す",
+	"never":                       "なし",
+	"create":                      "作成",
+	"wrong api token":             "API トークンが正しくありません",
+	"wrong api token name":        "API トークン名が正しくありません",
+	"wrong api token expire days": "API トークンの有効日数が正しくありません",
+	"wrong api token scope: %s":   "API トークンのスコープが正しくありません：%s",
+	"the api token needs a scope": "API トークンにはスコープが必要です",
+	"invalid api token":           "無効な API トークン",
+	"the api token scopes do not allow the request": "API トークンのスコープではこのリクエストは許可されません",
+
+	"my sessions":               "マイセッション",
+	"no session":                "セッションはありません",
+	"current session":           "現在のセッション",
+	"ip":                        "IP",
+	"user agent":                "ユーザーエージェント",
+	"login at":                  "ログイン日時",
+	"last seen":                 "最終アクセス",
+	"revoke all other sessions": "他のすべてのセッションを取り消す",
+	"wrong session":             "セッションが正しくありません",
+	"wrong id":                  "ID が正しくありません",
+	"force logout everywhere":   "すべての場所から強制ログアウト",
+	"logged out everywhere":     "すべての場所からログアウトしました",
+
+	"impersonate":                     "なりすまし",
+	"impersonator":                    "なりすまし元",
+	"impersonating":                   "なりすまし中",
+	"stop impersonating":              "なりすましを終了",
+	"the user cannot be impersonated": "このユーザーにはなりすませません",
+	"%s is impersonating %s":          "%s が %s になりすましています",
+
+	"denied permission":             "拒否する権限",
+	"denied permissions":            "拒否された権限",
+	"inherited roles":               "継承したロール",
+	"effective permissions":         "有効な権限",
+	"parent role":                   "親ロール",
+	"the parent role makes a cycle": "親ロールが循環しています",
+	"override the permissions of the user and the roles":            "ユーザーとロールの権限より優先されます",
+	"override the permissions of the role and the parent roles":     "このロールと親ロールの権限より優先されます",
+	"the role has the permissions and the menus of the parent role": "このロールは親ロールの権限とメニューを持ちます",
+
+	"change requested":                       "変更を申請しました",
+	"the change is waiting for approval":     "変更は承認待ちです",
+	"change request":                         "変更申請",
+	"change requests":                        "変更申請",
+	"a change is waiting for approval":       "承認待ちの変更があります",
+	"the change request is approved":         "変更申請が承認されました",
+	"the change request is rejected":         "変更申請が却下されました",
+	"the change request has been decided":    "変更申請は処理済みです",
+	"choose to approve or reject the change": "変更を承認または却下してください",
+	"insert":                                 "追加",
+	"update":                                 "更新",
+	"pending":                                "承認待ち",
+	"approved":                               "承認済み",
+	"rejected":                               "却下",
+	"requester":                              "申請者",
+	"approver":                               "承認者",
+	"comment":                                "コメント",
+	"changes":                                "変更内容",
+	"record":                                 "レコード",
+	"decision":                               "判断",
+	"field":                                  "フィールド",
+	"old value":                              "変更前",
+	"new value":                              "変更後",
+	"table":                                  "テーブル",
+
+	"approve": "承認",
+	"reject":  "却下",
+
+	"query":           "照会",
+	"show_edit":       "編集フォームを開く",
+	"show_create":     "作成フォームを開く",
+	"logout":          "ログアウト",
+	"other":           "その他",
+	"response status": "レスポンスステータス",
+
+	"the table needs approval, the order can not be changed": "このテーブルは承認が必要なため、順序を変更できません",
+	"the table is not stored in the database":                "このテーブルはデータベースに保存されていません",
+
+	"not allowed while impersonating": "なりすまし中は許可されていません",
+
+	"the record has been changed since the change was requested": "変更の申請後にレコードが変更されました",
+
+	"upload in chunks": "分割してアップロード",
+	"retry":            "再試行",
 }
@@ -261,4 +261,181 @@ var tc = LangSet{
 	"system.go_admin_version": "應用版本",
 	"system.theme_name":       "主題",
 	"system.theme_version":    "主題版本",
+
+	"too many files, the max count is":        "文件數量過多，最多為",
+	"file is too large, the max size is":      "文件過大，最大為",
+	"file extension is not allowed, allowed:": "不允許的文件後綴，允許：",
+	"file type is not allowed, allowed:":      "不允許的文件類型，允許：",
+	"file is not a valid image":               "文件不是有效的圖片",
+	"image is too small, the min size is":     "圖片尺寸過小，最小為",
+	"image is too large, the max size is":     "圖片尺寸過大，最大為",
+
+	"media library":             "媒體庫",
+	"choose from media library": "從媒體庫選擇",
+	"preview":                   "預覽",
+	"mime":                      "類型",
+	"size":                      "大小",
+	"uploader":                  "上傳者",
+	"references":                "引用數",
+	"clean up":                  "清理",
+	"orphaned files deleted":    "已刪除的孤立文件",
+	"file is referenced":        "文件正在被記錄引用",
+
+	"two-factor authentication":                          "兩步驗證",
+	"two-factor code":                                    "驗證碼",
+	"two-factor code or recovery code":                   "驗證碼或恢復碼",
+	"wrong two-factor code":                              "驗證碼錯誤",
+	"two-factor authentication is required by your role": "你的角色要求開啟兩步驗證",
+	"scan the qr code with an authenticator app, or enter the secret manually":        "使用身份驗證器應用掃描二維碼，或手動輸入密鑰",
+	"save these recovery codes, each of them can be used once when the phone is lost": "請保存這些恢復碼，手機遺失時每個恢復碼可使用一次",
+	"recovery codes left":       "剩餘恢復碼",
+	"regenerate recovery codes": "重新生成恢復碼",
+	"enable":                    "啟用",
+	"disable":                   "停用",
+	"enabled":                   "已啟用",
+	"continue":                  "繼續",
+	"reset two-factor":          "重置兩步驗證",
+	"two-factor authentication is not enabled": "未開啟兩步驗證",
+	"two-factor authentication is reset":       "兩步驗證已重置",
+	"required":                                 "必須",
+	"optional":                                 "可選",
+	"the users of the role and its child roles must enroll the two-factor authentication": "該角色及其子角色的用戶必須開啟兩步驗證",
+
+	"sign in with":        "登入方式：",
+	"single sign-on fail": "單點登入失敗，請重試或聯繫管理員",
+
+	"too many failed logins, please try again later": "登入失敗次數過多，請稍後再試",
+	"login locks":    "登入鎖定",
+	"kind":           "類型",
+	"value":          "值",
+	"failures":       "失敗次數",
+	"locked":         "已鎖定",
+	"locked until":   "鎖定至",
+	"last failed at": "最近失敗時間",
+	"unlock":         "解鎖",
+	"unlocked":       "已解鎖",
+
+	"email":                     "郵箱",
+	"use to reset the password": "用於重置密碼",
+	"the password must have at least %d characters":                          "密碼至少需要 %d 個字符",
+	"the password must have an uppercase letter":                             "密碼必須包含大寫字母",
+	"the password must have a lowercase letter":                              "密碼必須包含小寫字母",
+	"the password must have a digit":                                         "密碼必須包含數字",
+	"the password must have a symbol":                                        "密碼必須包含符號",
+	"the password can not be the username":                                   "密碼不能與用戶名相同",
+	"the password can not be one of the last %d passwords":                   "密碼不能與最近 %d 次使用的密碼相同",
+	"the password reset link is invalid or expired":                          "密碼重置鏈接無效或已過期",
+	"password reset is not enabled":                                          "未開啟密碼重置",
+	"username or email can not be empty":                                     "用戶名或郵箱不能為空",
+	"password can not be empty":                                              "密碼不能為空",
+	"reset password":                                                         "重置密碼",
+	"open the link to reset the password":                                    "打開以下鏈接重置密碼",
+	"a password reset link is sent to the email of the account if it exists": "如果賬號存在，密碼重置鏈接已發送到其郵箱",
+	"the password is reset, please login":                                    "密碼已重置，請登入",
+	"forgot password?":                                                       "忘記密碼？",
+	"username or email":                                                      "用戶名或郵箱",
+	"send reset link":                                                        "發送重置鏈接",
+	"back to login":                                                          "返回登入",
+	"your password has expired, please set a new one":                        "密碼已過期，請設置新密碼",
+	"request fail":                                                           "請求失敗",
+
+	"api tokens":       "API 令牌",
+	"new api token":    "新建 API 令牌",
+	"no api token":     "沒有 API 令牌",
+	"api token scopes": "權限範圍",
+	"a table prefix and its methods per line, * for all the tables": "每行一個表前綴及其請求方法，* 表示所有表",
+	"expire days": "有效天數",
+	"0 is never":  "0 表示永不過期",
+	"copy the token now, it will not be shown again": "請立即複製令牌，它不會再次顯示",
+	"expires at":                  "過期時間",
+	"last used":                   "最後使用",
+	"status":                      "狀態",
+	"active":                      "有效",
+	"expired":                     "已過期",
+	"revoked":                     "已撤銷",
+	"revoke":                      "撤銷",
+	"never":                       "永不",
+	"create":                      "創建",
+	"wrong api token":             "錯誤的 API 令牌",
+	"wrong api token name":        "錯誤的 API 令牌名稱",
+	"wrong api token expire days": "錯誤的 API 令牌有效天數",
+	"wrong api token scope: %s":   "錯誤的 API 令牌權限範圍：%s",
+	"the api token needs a scope": "API 令牌至少需要一個權限範圍",
+	"invalid api token":           "無效的 API 令牌",
+	"the api token scopes do not allow the request": "API 令牌的權限範圍不允許該請求",
+
+	"my sessions":               "我的會話",
+	"no session":                "沒有會話",
+	"current session":           "當前會話",
+	"ip":                        "IP",
+	"user agent":                "瀏覽器",
+	"login at":                  "登錄時間",
+	"last seen":                 "最後活動",
+	"revoke all other sessions": "撤銷其他所有會話",
+	"wrong session":             "錯誤的會話",
+	"wrong id":                  "錯誤的 id",
+	"force logout everywhere":   "強制退出所有登錄",
+	"logged out everywhere":     "已退出所有登錄",
+
+	"impersonate":                     "模擬登錄",
+	"impersonator":                    "模擬者",
+	"impersonating":                   "正在模擬登錄",
+	"stop impersonating":              "停止模擬",
+	"the user cannot be impersonated": "無法模擬該用戶",
+	"%s is impersonating %s":          "%s 正在模擬 %s",
+
+	"denied permission":             "拒絕權限",
+	"denied permissions":            "被拒絕的權限",
+	"inherited roles":               "繼承的角色",
+	"effective permissions":         "有效權限",
+	"parent role":                   "父角色",
+	"the parent role makes a cycle": "父角色形成了循環",
+	"override the permissions of the user and the roles":            "覆蓋用戶和角色的權限",
+	"override the permissions of the role and the parent roles":     "覆蓋該角色和父角色的權限",
+	"the role has the permissions and the menus of the parent role": "該角色擁有父角色的權限和菜單",
+
+	"change requested":                       "變更已提交",
+	"the change is waiting for approval":     "變更正在等待審批",
+	"change request":                         "變更申請",
+	"change requests":                        "變更申請",
+	"a change is waiting for approval":       "有一個變更等待審批",
+	"the change request is approved":         "變更申請已通過",
+	"the change request is rejected":         "變更申請已駁回",
+	"the change request has been decided":    "變更申請已被處理",
+	"choose to approve or reject the change": "請選擇通過或駁回該變更",
+	"insert":                                 "新增",
+	"update":                                 "修改",
+	"pending":                                "待審批",
+	"approved":                               "已通過",
+	"rejected":                               "已駁回",
+	"requester":                              "申請人",
+	"approver":                               "審批人",
+	"comment":                                "備註",
+	"changes":                                "變更內容",
+	"record":                                 "記錄",
+	"decision":                               "審批結果",
+	"field":                                  "欄位",
+	"old value":                              "原值",
+	"new value":                              "新值",
+	"table":                                  "表",
+
+	"approve": "通過",
+	"reject":  "駁回",
+
+	"query":           "查詢",
+	"show_edit":       "打開編輯表單",
+	"show_create":     "打開新建表單",
+	"logout":          "登出",
+	"other":           "其他",
+	"response status": "響應狀態",
+
+	"the table needs approval, the order can not be changed": "該表需要審批，無法修改排序",
+	"the table is not stored in the database":                "該表不在數據庫中",
+
+	"not allowed while impersonating": "模擬登錄時不允許此操作",
+
+	"the record has been changed since the change was requested": "該記錄在申請變更後已被修改",
+
+	"upload in chunks": "分片上傳",
+	"retry":            "重試",
 }
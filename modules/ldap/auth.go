@@ -0,0 +1,69 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrUserNotFound = errors.New("ldap: user not found")
+
+// Config is the directory to authenticate the users against.
+type Config struct {
+	URL                string
+	StartTLS           bool
+	InsecureSkipVerify bool
+	// BindDN and BindPassword is the account to search the users, the search
+	// is anonymous when BindDN is empty.
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	// UserFilter finds the entry of the user, %s is the escaped username.
+	UserFilter string
+	Attributes []string
+	Timeout    time.Duration
+}
+
+// Authenticate search the entry of the username and bind with its password,
+// the entry is returned when the password is right.
+func Authenticate(cfg Config, username, password string) (*Entry, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+
+	conn, err := Dial(cfg.URL, tlsConfig, cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if cfg.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.BindDN != "" {
+		if err := conn.Bind(cfg.BindDN, cfg.BindPassword); err != nil {
+			return nil, err
+		}
+	}
+
+	filter := strings.Replace(cfg.UserFilter, "%s", EscapeFilter(username), -1)
+	entries, err := conn.Search(cfg.BaseDN, filter, cfg.Attributes, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	// an ambiguous filter must not log in one of the users
+	if len(entries) != 1 {
+		return nil, ErrUserNotFound
+	}
+
+	if err := conn.Bind(entries[0].DN, password); err != nil {
+		return nil, err
+	}
+
+	return entries[0], nil
+}
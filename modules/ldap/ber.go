@@ -0,0 +1,198 @@
+package ldap
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// The BER tags of the LDAP messages, see RFC 4511.
+const (
+	tagBoolean     = 0x01
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagEnumerated  = 0x0a
+	tagSequence    = 0x30
+	tagSet         = 0x31
+
+	tagBindRequest        = 0x60
+	tagBindResponse       = 0x61
+	tagUnbindRequest      = 0x42
+	tagSearchRequest      = 0x63
+	tagSearchEntry        = 0x64
+	tagSearchDone         = 0x65
+	tagSearchReference    = 0x73
+	tagExtendedRequest    = 0x77
+	tagExtendedResponse   = 0x78
+	tagSimpleAuth         = 0x80
+	tagExtendedRequestOID = 0x80
+
+	constructed = 0x20
+)
+
+// maxPacketLength limits the size of a message read from the server.
+const maxPacketLength = 16 << 20
+
+var errMalformedPacket = errors.New("ldap: malformed packet")
+
+// packet is a BER element, the constructed ones have children and the
+// primitive ones have a value.
+type packet struct {
+	tag      byte
+	value    []byte
+	children []*packet
+}
+
+func newPacket(tag byte, children ...*packet) *packet {
+	return &packet{tag: tag, children: children}
+}
+
+func newString(tag byte, s string) *packet {
+	return &packet{tag: tag, value: []byte(s)}
+}
+
+func newInteger(tag byte, n int64) *packet {
+	var b []byte
+	for {
+		b = append([]byte{byte(n)}, b...)
+		n >>= 8
+		if (n == 0 && b[0]&0x80 == 0) || (n == -1 && b[0]&0x80 != 0) {
+			break
+		}
+	}
+	return &packet{tag: tag, value: b}
+}
+
+func newBoolean(b bool) *packet {
+	if b {
+		return &packet{tag: tagBoolean, value: []byte{0xff}}
+	}
+	return &packet{tag: tagBoolean, value: []byte{0}}
+}
+
+func (p *packet) isConstructed() bool {
+	return p.tag&constructed != 0
+}
+
+func (p *packet) bytes() []byte {
+	content := p.value
+	if p.isConstructed() {
+		content = nil
+		for _, child := range p.children {
+			content = append(content, child.bytes()...)
+		}
+	}
+	return append(append([]byte{p.tag}, encodeLength(len(content))...), content...)
+}
+
+func (p *packet) str() string {
+	return string(p.value)
+}
+
+func (p *packet) integer() int64 {
+	var n int64
+	for i, b := range p.value {
+		if i == 0 && b&0x80 != 0 {
+			n = -1
+		}
+		n = n<<8 | int64(b)
+	}
+	return n
+}
+
+func (p *packet) child(i int) *packet {
+	if i < len(p.children) {
+		return p.children[i]
+	}
+	return &packet{}
+}
+
+func encodeLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var b []byte
+	for n > 0 {
+		b = append([]byte{byte(n)}, b...)
+		n >>= 8
+	}
+	return append([]byte{0x80 | byte(len(b))}, b...)
+}
+
+// readPacket read a BER element, the tags of LDAP fit in one byte.
+func readPacket(r *bufio.Reader) (*packet, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	length := int(first)
+	if first&0x80 != 0 {
+		n := int(first & 0x7f)
+		if n == 0 || n > 4 {
+			return nil, errMalformedPacket
+		}
+		length = 0
+		for i := 0; i < n; i++ {
+			b, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			length = length<<8 | int(b)
+		}
+	}
+
+	if length > maxPacketLength {
+		return nil, errMalformedPacket
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+
+	return parsePacket(tag, content)
+}
+
+func parsePacket(tag byte, content []byte) (*packet, error) {
+	p := &packet{tag: tag}
+	if tag&constructed == 0 {
+		p.value = content
+		return p, nil
+	}
+
+	for len(content) > 0 {
+		if len(content) < 2 {
+			return nil, errMalformedPacket
+		}
+		childTag := content[0]
+		length := int(content[1])
+		offset := 2
+		if content[1]&0x80 != 0 {
+			n := int(content[1] & 0x7f)
+			if n == 0 || n > 4 || len(content) < 2+n {
+				return nil, errMalformedPacket
+			}
+			length = 0
+			for _, b := range content[2 : 2+n] {
+				length = length<<8 | int(b)
+			}
+			offset += n
+		}
+		if length < 0 || len(content) < offset+length {
+			return nil, errMalformedPacket
+		}
+		child, err := parsePacket(childTag, content[offset:offset+length])
+		if err != nil {
+			return nil, err
+		}
+		p.children = append(p.children, child)
+		content = content[offset+length:]
+	}
+	return p, nil
+}
@@ -0,0 +1,206 @@
+package ldap
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// The tags of the filter choices.
+const (
+	filterAnd            = 0xa0
+	filterOr             = 0xa1
+	filterNot            = 0xa2
+	filterEquality       = 0xa3
+	filterSubstrings     = 0xa4
+	filterGreaterOrEqual = 0xa5
+	filterLessOrEqual    = 0xa6
+	filterPresent        = 0x87
+	filterApprox         = 0xa8
+
+	substringInitial = 0x80
+	substringAny     = 0x81
+	substringFinal   = 0x82
+)
+
+// EscapeFilter escape the special characters of a value put in a filter.
+func EscapeFilter(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '\\', '*', '(', ')', 0:
+			b.WriteString(fmt.Sprintf("\\%02x", c))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// compileFilter compile the string representation of a filter of RFC 4515.
+func compileFilter(s string) (*packet, error) {
+	p := &filterParser{s: s}
+	f, err := p.filter()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(s) {
+		return nil, p.errorf("unexpected trailing characters")
+	}
+	return f, nil
+}
+
+type filterParser struct {
+	s   string
+	pos int
+}
+
+func (p *filterParser) errorf(msg string) error {
+	return fmt.Errorf("ldap: invalid filter %q at %d: %s", p.s, p.pos, msg)
+}
+
+func (p *filterParser) filter() (*packet, error) {
+	if p.pos >= len(p.s) || p.s[p.pos] != '(' {
+		return nil, p.errorf("expected (")
+	}
+	p.pos++
+
+	if p.pos >= len(p.s) {
+		return nil, p.errorf("unexpected end")
+	}
+
+	var f *packet
+	var err error
+
+	switch p.s[p.pos] {
+	case '&':
+		p.pos++
+		f, err = p.set(filterAnd)
+	case '|':
+		p.pos++
+		f, err = p.set(filterOr)
+	case '!':
+		p.pos++
+		var child *packet
+		child, err = p.filter()
+		f = newPacket(filterNot, child)
+	default:
+		f, err = p.item()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos >= len(p.s) || p.s[p.pos] != ')' {
+		return nil, p.errorf("expected )")
+	}
+	p.pos++
+	return f, nil
+}
+
+func (p *filterParser) set(tag byte) (*packet, error) {
+	f := newPacket(tag)
+	for p.pos < len(p.s) && p.s[p.pos] == '(' {
+		child, err := p.filter()
+		if err != nil {
+			return nil, err
+		}
+		f.children = append(f.children, child)
+	}
+	if len(f.children) == 0 {
+		return nil, p.errorf("empty set")
+	}
+	return f, nil
+}
+
+func (p *filterParser) item() (*packet, error) {
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune("=~<>()", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	attr := p.s[start:p.pos]
+	if attr == "" || p.pos >= len(p.s) {
+		return nil, p.errorf("expected an attribute")
+	}
+
+	var tag byte
+	switch {
+	case strings.HasPrefix(p.s[p.pos:], ">="):
+		tag = filterGreaterOrEqual
+		p.pos += 2
+	case strings.HasPrefix(p.s[p.pos:], "<="):
+		tag = filterLessOrEqual
+		p.pos += 2
+	case strings.HasPrefix(p.s[p.pos:], "~="):
+		tag = filterApprox
+		p.pos += 2
+	case p.s[p.pos] == '=':
+		tag = filterEquality
+		p.pos++
+	default:
+		return nil, p.errorf("expected an operator")
+	}
+
+	start = p.pos
+	for p.pos < len(p.s) && p.s[p.pos] != ')' && p.s[p.pos] != '(' {
+		p.pos++
+	}
+	raw := p.s[start:p.pos]
+
+	if tag != filterEquality || !strings.Contains(raw, "*") {
+		value, err := unescapeFilter(raw)
+		if err != nil {
+			return nil, p.errorf(err.Error())
+		}
+		return newPacket(tag, newString(tagOctetString, attr), newString(tagOctetString, value)), nil
+	}
+
+	if raw == "*" {
+		return newString(filterPresent, attr), nil
+	}
+
+	parts := strings.Split(raw, "*")
+	subs := newPacket(tagSequence)
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		value, err := unescapeFilter(part)
+		if err != nil {
+			return nil, p.errorf(err.Error())
+		}
+		subTag := byte(substringAny)
+		if i == 0 {
+			subTag = substringInitial
+		} else if i == len(parts)-1 {
+			subTag = substringFinal
+		}
+		subs.children = append(subs.children, newString(subTag, value))
+	}
+	return newPacket(filterSubstrings, newString(tagOctetString, attr), subs), nil
+}
+
+func unescapeFilter(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+3 > len(s) {
+			return "", fmt.Errorf("bad escape")
+		}
+		c, err := hex.DecodeString(s[i+1 : i+3])
+		if err != nil {
+			return "", fmt.Errorf("bad escape")
+		}
+		b.WriteByte(c[0])
+		i += 2
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,285 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+// Package ldap is a small LDAP v3 client for the authentication of the admin,
+// which supports the simple bind, the search and StartTLS.
+package ldap
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// The result codes used by the client, see RFC 4511.
+const (
+	ResultSuccess            = 0
+	ResultInvalidCredentials = 49
+)
+
+// startTLSOID is the name of the StartTLS extended operation.
+const startTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// DefaultTimeout is the timeout of an operation when the Conn has none.
+const DefaultTimeout = 10 * time.Second
+
+var ErrUnexpectedResponse = errors.New("ldap: unexpected response")
+
+// Error is a result of the server other than success.
+type Error struct {
+	ResultCode int64
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("ldap: result code %d: %s", e.ResultCode, e.Message)
+}
+
+// IsInvalidCredentials report if the error is a rejected bind.
+func IsInvalidCredentials(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.ResultCode == ResultInvalidCredentials
+}
+
+// Entry is a search result.
+type Entry struct {
+	DN string
+	// Attributes are keyed by the lowercase attribute names.
+	Attributes map[string][]string
+}
+
+// Values return the values of the attribute.
+func (e *Entry) Values(name string) []string {
+	return e.Attributes[strings.ToLower(name)]
+}
+
+// Value return the first value of the attribute.
+func (e *Entry) Value(name string) string {
+	if v := e.Values(name); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// Conn is a connection to a LDAP server, it is not safe for the concurrent
+// use.
+type Conn struct {
+	conn    net.Conn
+	r       *bufio.Reader
+	host    string
+	msgId   int64
+	Timeout time.Duration
+}
+
+// Dial connect to the server of the url, which is ldap://host:port or
+// ldaps://host:port.
+func Dial(rawurl string, tlsConfig *tls.Config, timeout time.Duration) (*Conn, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	host := u.Host
+	dialer := &net.Dialer{Timeout: timeout}
+
+	var conn net.Conn
+	switch u.Scheme {
+	case "ldap":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "389")
+		}
+		conn, err = dialer.Dial("tcp", host)
+	case "ldaps":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "636")
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", host, serverTLSConfig(tlsConfig, u.Hostname()))
+	default:
+		return nil, fmt.Errorf("ldap: unsupported scheme %q", u.Scheme)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewConn(conn, timeout)
+	c.host = u.Hostname()
+	return c, nil
+}
+
+// NewConn return a Conn of the connection.
+func NewConn(conn net.Conn, timeout time.Duration) *Conn {
+	return &Conn{conn: conn, r: bufio.NewReader(conn), Timeout: timeout}
+}
+
+func serverTLSConfig(cfg *tls.Config, host string) *tls.Config {
+	if cfg == nil {
+		cfg = &tls.Config{}
+	} else {
+		cfg = cfg.Clone()
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = host
+	}
+	return cfg
+}
+
+// StartTLS upgrade the connection to TLS.
+func (c *Conn) StartTLS(tlsConfig *tls.Config) error {
+	res, err := c.request(newPacket(tagExtendedRequest, newString(tagExtendedRequestOID, startTLSOID)), tagExtendedResponse)
+	if err != nil {
+		return err
+	}
+	if err := resultError(res); err != nil {
+		return err
+	}
+
+	host := c.host
+	if host == "" {
+		host, _, _ = net.SplitHostPort(c.conn.RemoteAddr().String())
+	}
+	tlsConn := tls.Client(c.conn, serverTLSConfig(tlsConfig, host))
+	_ = tlsConn.SetDeadline(time.Now().Add(c.timeout()))
+	if err := tlsConn.Handshake(); err != nil {
+		return err
+	}
+
+	c.conn = tlsConn
+	c.r = bufio.NewReader(tlsConn)
+	return nil
+}
+
+// Bind authenticate the connection with the password of the dn. An empty
+// password is refused since the servers take it as an anonymous bind.
+func (c *Conn) Bind(dn, password string) error {
+	if password == "" {
+		return &Error{ResultCode: ResultInvalidCredentials, Message: "empty password"}
+	}
+
+	res, err := c.request(newPacket(tagBindRequest,
+		newInteger(tagInteger, 3),
+		newString(tagOctetString, dn),
+		newString(tagSimpleAuth, password)), tagBindResponse)
+	if err != nil {
+		return err
+	}
+	return resultError(res)
+}
+
+// Search search the subtree of the base dn and return at most sizeLimit
+// entries, zero means no limit.
+func (c *Conn) Search(baseDN, filter string, attributes []string, sizeLimit int) ([]*Entry, error) {
+	f, err := compileFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := newPacket(tagSequence)
+	for _, attr := range attributes {
+		attrs.children = append(attrs.children, newString(tagOctetString, attr))
+	}
+
+	id, err := c.send(newPacket(tagSearchRequest,
+		newString(tagOctetString, baseDN),
+		newInteger(tagEnumerated, 2), // whole subtree
+		newInteger(tagEnumerated, 0), // never deref aliases
+		newInteger(tagInteger, int64(sizeLimit)),
+		newInteger(tagInteger, int64(c.timeout()/time.Second)),
+		newBoolean(false),
+		f,
+		attrs))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*Entry, 0)
+	for {
+		op, err := c.receive(id)
+		if err != nil {
+			return nil, err
+		}
+
+		switch op.tag {
+		case tagSearchEntry:
+			entry := &Entry{DN: op.child(0).str(), Attributes: make(map[string][]string)}
+			for _, attr := range op.child(1).children {
+				name := strings.ToLower(attr.child(0).str())
+				for _, v := range attr.child(1).children {
+					entry.Attributes[name] = append(entry.Attributes[name], v.str())
+				}
+			}
+			entries = append(entries, entry)
+		case tagSearchReference:
+			// referrals are not followed
+		case tagSearchDone:
+			return entries, resultError(op)
+		default:
+			return nil, ErrUnexpectedResponse
+		}
+	}
+}
+
+// Close unbind and close the connection.
+func (c *Conn) Close() error {
+	_, _ = c.send(&packet{tag: tagUnbindRequest})
+	return c.conn.Close()
+}
+
+func (c *Conn) timeout() time.Duration {
+	if c.Timeout == 0 {
+		return DefaultTimeout
+	}
+	return c.Timeout
+}
+
+func (c *Conn) request(op *packet, responseTag byte) (*packet, error) {
+	id, err := c.send(op)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.receive(id)
+	if err != nil {
+		return nil, err
+	}
+	if res.tag != responseTag {
+		return nil, ErrUnexpectedResponse
+	}
+	return res, nil
+}
+
+func (c *Conn) send(op *packet) (int64, error) {
+	c.msgId++
+	msg := newPacket(tagSequence, newInteger(tagInteger, c.msgId), op)
+	_ = c.conn.SetDeadline(time.Now().Add(c.timeout()))
+	_, err := c.conn.Write(msg.bytes())
+	return c.msgId, err
+}
+
+func (c *Conn) receive(id int64) (*packet, error) {
+	_ = c.conn.SetDeadline(time.Now().Add(c.timeout()))
+	msg, err := readPacket(c.r)
+	if err != nil {
+		return nil, err
+	}
+	if msg.tag != tagSequence || len(msg.children) < 2 || msg.child(0).integer() != id {
+		return nil, ErrUnexpectedResponse
+	}
+	return msg.child(1), nil
+}
+
+func resultError(res *packet) error {
+	if code := res.child(0).integer(); code != ResultSuccess {
+		return &Error{ResultCode: code, Message: res.child(2).str()}
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package ldap
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testServer is a stand-in directory with the service account and a user.
+type testServer struct {
+	ln        net.Listener
+	tlsConfig *tls.Config
+	passwords map[string]string
+	entries   map[string]map[string][]string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Equal(t, err, nil)
+
+	s := &testServer{
+		ln:        ln,
+		tlsConfig: testTLSConfig(t),
+		passwords: map[string]string{
+			"cn=svc,dc=example,dc=com":              "svc-pass",
+			"uid=alice,ou=people,dc=example,dc=com": "alice-pass",
+		},
+		entries: map[string]map[string][]string{
+			"uid=alice,ou=people,dc=example,dc=com": {
+				"objectClass": {"person"},
+				"uid":         {"alice"},
+				"cn":          {"Alice Liddell"},
+				"memberOf":    {"cn=ops,ou=groups,dc=example,dc=com", "cn=staff,ou=groups,dc=example,dc=com"},
+			},
+			"uid=bob,ou=people,dc=example,dc=com": {
+				"objectClass": {"person"},
+				"uid":         {"bob"},
+				"cn":          {"Bob"},
+			},
+		},
+	}
+	go s.serve()
+	return s
+}
+
+func (s *testServer) url() string {
+	return "ldap://" + s.ln.Addr().String()
+}
+
+func (s *testServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *testServer) handle(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	bound := ""
+
+	for {
+		msg, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		id := msg.child(0).integer()
+		op := msg.child(1)
+
+		reply := func(res *packet) {
+			_, _ = conn.Write(newPacket(tagSequence, newInteger(tagInteger, id), res).bytes())
+		}
+		result := func(tag byte, code int64, children ...*packet) *packet {
+			return newPacket(tag, append([]*packet{
+				newInteger(tagEnumerated, code),
+				newString(tagOctetString, ""),
+				newString(tagOctetString, ""),
+			}, children...)...)
+		}
+
+		switch op.tag {
+		case tagBindRequest:
+			dn, password := op.child(1).str(), op.child(2).str()
+			if pw, ok := s.passwords[dn]; ok && pw == password {
+				bound = dn
+				reply(result(tagBindResponse, ResultSuccess))
+			} else {
+				bound = ""
+				reply(result(tagBindResponse, ResultInvalidCredentials))
+			}
+		case tagExtendedRequest:
+			reply(result(tagExtendedResponse, ResultSuccess))
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if tlsConn.Handshake() != nil {
+				return
+			}
+			conn = tlsConn
+			r = bufio.NewReader(conn)
+		case tagSearchRequest:
+			if bound == "" {
+				reply(result(tagSearchDone, 50)) // insufficient access rights
+				continue
+			}
+			for dn, attrs := range s.entries {
+				if !strings.HasSuffix(dn, op.child(0).str()) || !matchFilter(op.child(6), attrs) {
+					continue
+				}
+				list := newPacket(tagSequence)
+				for _, name := range op.child(7).children {
+					vals := newPacket(tagSet)
+					for _, v := range attrs[name.str()] {
+						vals.children = append(vals.children, newString(tagOctetString, v))
+					}
+					list.children = append(list.children, newPacket(tagSequence, newString(tagOctetString, name.str()), vals))
+				}
+				reply(newPacket(tagSearchEntry, newString(tagOctetString, dn), list))
+			}
+			reply(result(tagSearchDone, ResultSuccess))
+		case tagUnbindRequest:
+			return
+		}
+	}
+}
+
+// matchFilter support the filters used by the tests.
+func matchFilter(f *packet, attrs map[string][]string) bool {
+	switch f.tag {
+	case filterAnd:
+		for _, child := range f.children {
+			if !matchFilter(child, attrs) {
+				return false
+			}
+		}
+		return true
+	case filterPresent:
+		return len(attrs[f.str()]) > 0
+	case filterEquality:
+		for _, v := range attrs[f.child(0).str()] {
+			if v == f.child(1).str() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func testTLSConfig(t *testing.T) *tls.Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Equal(t, err, nil)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.Equal(t, err, nil)
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+}
+
+func testConfig(s *testServer) Config {
+	return Config{
+		URL:          s.url(),
+		BindDN:       "cn=svc,dc=example,dc=com",
+		BindPassword: "svc-pass",
+		BaseDN:       "dc=example,dc=com",
+		UserFilter:   "(&(objectClass=person)(uid=%s))",
+		Attributes:   []string{"cn", "memberOf"},
+		Timeout:      time.Second,
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	s := newTestServer(t)
+	defer func() {
+		_ = s.ln.Close()
+	}()
+
+	cfg := testConfig(s)
+
+	entry, err := Authenticate(cfg, "alice", "alice-pass")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, entry.DN, "uid=alice,ou=people,dc=example,dc=com")
+	assert.Equal(t, entry.Value("cn"), "Alice Liddell")
+	assert.Equal(t, len(entry.Values("memberof")), 2)
+
+	_, err = Authenticate(cfg, "alice", "wrong")
+	assert.Equal(t, IsInvalidCredentials(err), true)
+
+	_, err = Authenticate(cfg, "alice", "")
+	assert.Equal(t, IsInvalidCredentials(err), true)
+
+	_, err = Authenticate(cfg, "nobody", "alice-pass")
+	assert.Equal(t, err, ErrUserNotFound)
+
+	// the username can not change the filter
+	_, err = Authenticate(cfg, "*", "alice-pass")
+	assert.Equal(t, err, ErrUserNotFound)
+
+	cfg.BindPassword = "wrong"
+	_, err = Authenticate(cfg, "alice", "alice-pass")
+	assert.Equal(t, IsInvalidCredentials(err), true)
+}
+
+func TestStartTLS(t *testing.T) {
+	s := newTestServer(t)
+	defer func() {
+		_ = s.ln.Close()
+	}()
+
+	cfg := testConfig(s)
+	cfg.StartTLS = true
+
+	// the certificate is self-signed
+	_, err := Authenticate(cfg, "alice", "alice-pass")
+	assert.NotEqual(t, err, nil)
+
+	cfg.InsecureSkipVerify = true
+	entry, err := Authenticate(cfg, "alice", "alice-pass")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, entry.Value("cn"), "Alice Liddell")
+}
+
+func TestCompileFilter(t *testing.T) {
+	f, err := compileFilter("(&(objectClass=*)(|(uid=a\\2ab)(!(cn=x*y*z)))(age>=3))")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, f.tag, byte(filterAnd))
+	assert.Equal(t, f.child(0).tag, byte(filterPresent))
+	assert.Equal(t, f.child(1).child(0).child(1).str(), "a*b")
+	sub := f.child(1).child(1).child(0)
+	assert.Equal(t, sub.tag, byte(filterSubstrings))
+	assert.Equal(t, len(sub.child(1).children), 3)
+	assert.Equal(t, sub.child(1).child(2).tag, byte(substringFinal))
+	assert.Equal(t, f.child(2).tag, byte(filterGreaterOrEqual))
+
+	for _, bad := range []string{"", "uid=a", "(uid=a", "(uid=a))", "(&)", "(uid=\\zz)", "(=a)"} {
+		_, err := compileFilter(bad)
+		assert.NotEqual(t, err, nil, bad)
+	}
+
+	assert.Equal(t, EscapeFilter("a*(b)\\"), "a\\2a\\28b\\29\\5c")
+}
+
+func TestBER(t *testing.T) {
+	for _, n := range []int64{0, 1, 127, 128, 255, 256, -1, -129, 1 << 40} {
+		p := newInteger(tagInteger, n)
+		parsed, err := readPacket(bufio.NewReader(strings.NewReader(string(p.bytes()))))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, parsed.integer(), n)
+	}
+
+	long := newPacket(tagSequence, newString(tagOctetString, strings.Repeat("x", 300)))
+	parsed, err := readPacket(bufio.NewReader(strings.NewReader(string(long.bytes()))))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(parsed.child(0).str()), 300)
+
+	_, err = readPacket(bufio.NewReader(strings.NewReader("\x30\x85\x01\x02\x03\x04\x05")))
+	assert.Equal(t, err, errMalformedPacket)
+}
@@ -7,8 +7,11 @@ import (
 )
 
 type NestedSetTable struct {
-	Name string
+	Name  string
 	Title string
+	// Connection is the name of the connection of the table, the default
+	// connection is used if empty.
+	Connection string
 }
 
 type NestedSetItem struct {
@@ -33,7 +36,7 @@ func GetNestedSetTree(user models.UserModel, conn db.Connection, tbl NestedSetTa
 	user.WithRoles().WithMenus()
 
 	if user.IsSuperAdmin() {
-		items, _ = db.WithDriver(conn).Table(tbl.Name).
+		items, _ = tbl.sql(conn).Table(tbl.Name).
 			Where("depth", ">", 0).
 			OrderBy("lft", "asc").
 			All()
@@ -44,7 +47,7 @@ func GetNestedSetTree(user models.UserModel, conn db.Connection, tbl NestedSetTa
 			ids = append(ids, val)
 		}
 
-		items, _ = db.WithDriver(conn).Table(tbl.Name).
+		items, _ = tbl.sql(conn).Table(tbl.Name).
 			WhereIn("id", ids).
 			Where("depth", ">", 0).
 			OrderBy("lft", "asc").
@@ -96,4 +99,10 @@ func constructNestedSetTree(items []map[string]interface{}, depth int64, tbl Nes
 	}
 
 	return branch
-}
\ No newline at end of file
+}
+func (tbl NestedSetTable) sql(conn db.Connection) *db.SQL {
+	if tbl.Connection == "" {
+		return db.WithDriver(conn)
+	}
+	return db.WithDriverAndConnection(tbl.Connection, conn)
+}
@@ -0,0 +1,681 @@
+package menu
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/db/dialect"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+)
+
+// NestedSet maintains the lft, rgt and depth columns of a nested set table.
+// Every write operation runs in its own transaction.
+type NestedSet struct {
+	conn db.Connection
+	tbl  NestedSetTable
+}
+
+// NestedSetNode is the position of a node in the nested set.
+type NestedSetNode struct {
+	ID    string
+	Lft   int64
+	Rgt   int64
+	Depth int64
+}
+
+// Width return the number of lft/rgt values taken by the node and its descendants.
+func (n NestedSetNode) Width() int64 {
+	return n.Rgt - n.Lft + 1
+}
+
+// Contains check if the given node is the node itself or one of its descendants.
+func (n NestedSetNode) Contains(node NestedSetNode) bool {
+	return node.Lft >= n.Lft && node.Rgt <= n.Rgt
+}
+
+var (
+	errNestedSetMoveIntoItself = errors.New("nested set: can not move a node into its own subtree")
+	errNestedSetRootSibling    = errors.New("nested set: the root node can not have siblings")
+	errNestedSetRootMove       = errors.New("nested set: the root node can not be moved or deleted")
+	errNestedSetPartialOrder   = errors.New("nested set: the order contains unknown or duplicated nodes")
+	errNestedSetReparent       = errors.New("nested set: a partial order can only move the nodes among their siblings")
+)
+
+// NewNestedSet return a NestedSet of given table.
+func NewNestedSet(conn db.Connection, tbl NestedSetTable) *NestedSet {
+	return &NestedSet{conn: conn, tbl: tbl}
+}
+
+// Node return the position of the node with given id.
+func (n *NestedSet) Node(id string) (NestedSetNode, error) {
+	return n.node(nil, id)
+}
+
+// InsertAsChild insert a new node as the last child of the parent node.
+func (n *NestedSet) InsertAsChild(parentID string, values dialect.H) (int64, error) {
+	return n.insert(parentID, values, func(parent NestedSetNode) (int64, int64, error) {
+		return parent.Rgt, parent.Depth + 1, nil
+	})
+}
+
+// InsertBefore insert a new node as the previous sibling of the given node.
+func (n *NestedSet) InsertBefore(siblingID string, values dialect.H) (int64, error) {
+	return n.insert(siblingID, values, func(sibling NestedSetNode) (int64, int64, error) {
+		if sibling.Depth == 0 {
+			return 0, 0, errNestedSetRootSibling
+		}
+		return sibling.Lft, sibling.Depth, nil
+	})
+}
+
+// InsertAfter insert a new node as the next sibling of the given node.
+func (n *NestedSet) InsertAfter(siblingID string, values dialect.H) (int64, error) {
+	return n.insert(siblingID, values, func(sibling NestedSetNode) (int64, int64, error) {
+		if sibling.Depth == 0 {
+			return 0, 0, errNestedSetRootSibling
+		}
+		return sibling.Rgt + 1, sibling.Depth, nil
+	})
+}
+
+// MoveToChild move the node and its descendants to be the last child of the parent node.
+func (n *NestedSet) MoveToChild(id, parentID string) error {
+	return n.move(id, parentID, func(parent NestedSetNode) (int64, int64, error) {
+		return parent.Rgt, parent.Depth + 1, nil
+	})
+}
+
+// MoveBefore move the node and its descendants in front of the given sibling.
+func (n *NestedSet) MoveBefore(id, siblingID string) error {
+	return n.move(id, siblingID, func(sibling NestedSetNode) (int64, int64, error) {
+		if sibling.Depth == 0 {
+			return 0, 0, errNestedSetRootSibling
+		}
+		return sibling.Lft, sibling.Depth, nil
+	})
+}
+
+// MoveAfter move the node and its descendants behind the given sibling.
+func (n *NestedSet) MoveAfter(id, siblingID string) error {
+	return n.move(id, siblingID, func(sibling NestedSetNode) (int64, int64, error) {
+		if sibling.Depth == 0 {
+			return 0, 0, errNestedSetRootSibling
+		}
+		return sibling.Rgt + 1, sibling.Depth, nil
+	})
+}
+
+// Delete delete the node and all of its descendants and close the gap.
+func (n *NestedSet) Delete(id string) error {
+	_, txErr := n.tbl.sql(n.conn).WithTransaction(func(tx *sql.Tx) (e error, i map[string]interface{}) {
+
+		node, err := n.node(tx, id)
+		if err != nil {
+			return err, nil
+		}
+
+		if node.Depth == 0 {
+			return errNestedSetRootMove, nil
+		}
+
+		err = n.sql(tx).
+			WhereRaw("lft >= ? and rgt <= ?", node.Lft, node.Rgt).
+			Delete()
+
+		if db.CheckError(err, db.DELETE) {
+			return err, nil
+		}
+
+		return n.shift(tx, node.Rgt+1, -node.Width()), nil
+	})
+
+	return txErr
+}
+
+// ResetOrder rewrite the tree from the given order, which is the payload of
+// the drag and drop tree component. An order which contains every node under
+// the root rewrites the whole tree, otherwise the given nodes are only
+// reordered among their siblings and the other nodes keep their places.
+func (n *NestedSet) ResetOrder(items models.OrderItems) error {
+	_, txErr := n.tbl.sql(n.conn).WithTransaction(func(tx *sql.Tx) (e error, i map[string]interface{}) {
+
+		nodes, err := n.nodes(tx)
+		if err != nil {
+			return err, nil
+		}
+
+		var root *NestedSetNode
+		for k := range nodes {
+			if nodes[k].Depth == 0 {
+				root = &nodes[k]
+				break
+			}
+		}
+
+		var (
+			positions []NestedSetNode
+			next      int64
+		)
+
+		if root != nil {
+			positions, next = nestedSetPositions(items, 2, 1)
+			positions = append(positions, NestedSetNode{ID: root.ID, Lft: 1, Rgt: next, Depth: 0})
+		} else {
+			positions, _ = nestedSetPositions(items, 1, 1)
+		}
+
+		if !sameNestedSetNodes(nodes, positions) {
+			positions, err = mergeNestedSetOrder(nodes, items)
+			if err != nil {
+				return err, nil
+			}
+		}
+
+		return n.save(tx, positions), nil
+	})
+
+	return txErr
+}
+
+// Rebuild renumber lft and rgt following the current lft order and depth,
+// which repairs gaps and overlaps left by manual edits.
+func (n *NestedSet) Rebuild() error {
+	_, txErr := n.tbl.sql(n.conn).WithTransaction(func(tx *sql.Tx) (e error, i map[string]interface{}) {
+
+		nodes, err := n.nodes(tx)
+		if err != nil {
+			return err, nil
+		}
+
+		return n.save(tx, rebuildNestedSet(nodes)), nil
+	})
+
+	return txErr
+}
+
+// Valid check if the table has the lft, rgt and depth columns of a nested set.
+func (n *NestedSet) Valid() bool {
+	columns, err := n.tbl.sql(n.conn).Table(n.tbl.Name).ShowColumns()
+	if err != nil {
+		return false
+	}
+
+	key := "name"
+	switch n.conn.Name() {
+	case db.DriverMysql:
+		key = "Field"
+	case db.DriverPostgresql, db.DriverMssql:
+		key = "column_name"
+	}
+
+	found := 0
+	for _, column := range columns {
+		switch fmt.Sprintf("%s", column[key]) {
+		case "lft", "rgt", "depth":
+			found++
+		}
+	}
+
+	return found == 3
+}
+
+// Verify check the integrity of the tree and return the first problem found.
+func (n *NestedSet) Verify() error {
+	nodes, err := n.nodes(nil)
+	if err != nil {
+		return err
+	}
+	return verifyNestedSet(nodes)
+}
+
+func (n *NestedSet) insert(targetID string, values dialect.H,
+	position func(target NestedSetNode) (int64, int64, error)) (int64, error) {
+
+	res, txErr := n.tbl.sql(n.conn).WithTransaction(func(tx *sql.Tx) (e error, i map[string]interface{}) {
+
+		target, err := n.node(tx, targetID)
+		if err != nil {
+			return err, nil
+		}
+
+		lft, depth, err := position(target)
+		if err != nil {
+			return err, nil
+		}
+
+		if err := n.shift(tx, lft, 2); err != nil {
+			return err, nil
+		}
+
+		row := make(dialect.H, len(values)+3)
+		for k, v := range values {
+			row[k] = v
+		}
+		row["lft"] = lft
+		row["rgt"] = lft + 1
+		row["depth"] = depth
+
+		id, err := n.sql(tx).Insert(row)
+
+		if db.CheckError(err, db.INSERT) {
+			return err, nil
+		}
+
+		return nil, map[string]interface{}{"id": id}
+	})
+
+	if txErr != nil {
+		return 0, txErr
+	}
+
+	return res["id"].(int64), nil
+}
+
+func (n *NestedSet) move(id, targetID string,
+	position func(target NestedSetNode) (int64, int64, error)) error {
+
+	_, txErr := n.tbl.sql(n.conn).WithTransaction(func(tx *sql.Tx) (e error, i map[string]interface{}) {
+
+		node, err := n.node(tx, id)
+		if err != nil {
+			return err, nil
+		}
+
+		if node.Depth == 0 {
+			return errNestedSetRootMove, nil
+		}
+
+		target, err := n.node(tx, targetID)
+		if err != nil {
+			return err, nil
+		}
+
+		if node.Contains(target) {
+			return errNestedSetMoveIntoItself, nil
+		}
+
+		lft, depth, err := position(target)
+		if err != nil {
+			return err, nil
+		}
+
+		width := node.Width()
+
+		// Take the subtree out of the way by negating its values, so the
+		// shifts below do not touch it.
+		_, err = n.sql(tx).
+			WhereRaw("lft >= ? and rgt <= ?", node.Lft, node.Rgt).
+			UpdateRaw("lft = 0 - lft").
+			UpdateRaw("rgt = 0 - rgt").
+			Exec()
+
+		if db.CheckError(err, db.UPDATE) {
+			return err, nil
+		}
+
+		if err := n.shift(tx, node.Rgt+1, -width); err != nil {
+			return err, nil
+		}
+
+		if lft > node.Rgt {
+			lft -= width
+		}
+
+		if err := n.shift(tx, lft, width); err != nil {
+			return err, nil
+		}
+
+		offset := lft - node.Lft
+
+		_, err = n.sql(tx).
+			WhereRaw("lft < 0").
+			UpdateRaw("lft = ? - lft", offset).
+			UpdateRaw("rgt = ? - rgt", offset).
+			UpdateRaw("depth = depth + ?", depth-node.Depth).
+			Exec()
+
+		if db.CheckError(err, db.UPDATE) {
+			return err, nil
+		}
+
+		return nil, nil
+	})
+
+	return txErr
+}
+
+// shift add delta to every lft and rgt which is greater than or equal to from.
+func (n *NestedSet) shift(tx *sql.Tx, from, delta int64) error {
+	_, err := n.sql(tx).
+		WhereRaw("lft >= ?", from).
+		UpdateRaw("lft = lft + ?", delta).
+		Exec()
+
+	if db.CheckError(err, db.UPDATE) {
+		return err
+	}
+
+	_, err = n.sql(tx).
+		WhereRaw("rgt >= ?", from).
+		UpdateRaw("rgt = rgt + ?", delta).
+		Exec()
+
+	if db.CheckError(err, db.UPDATE) {
+		return err
+	}
+
+	return nil
+}
+
+func (n *NestedSet) save(tx *sql.Tx, nodes []NestedSetNode) error {
+	for _, node := range nodes {
+		_, err := n.sql(tx).
+			Where("id", "=", node.ID).
+			Update(dialect.H{
+				"lft":   node.Lft,
+				"rgt":   node.Rgt,
+				"depth": node.Depth,
+			})
+
+		if db.CheckError(err, db.UPDATE) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *NestedSet) node(tx *sql.Tx, id string) (NestedSetNode, error) {
+	item, err := n.sql(tx).
+		Select("id", "lft", "rgt", "depth").
+		Where("id", "=", id).
+		First()
+
+	if err != nil {
+		return NestedSetNode{}, fmt.Errorf("nested set: node %s of %s not found", id, n.tbl.Name)
+	}
+
+	return toNestedSetNode(item), nil
+}
+
+func (n *NestedSet) nodes(tx *sql.Tx) ([]NestedSetNode, error) {
+	items, err := n.sql(tx).
+		Select("id", "lft", "rgt", "depth").
+		OrderBy("lft", "asc").
+		All()
+
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]NestedSetNode, len(items))
+	for k, item := range items {
+		nodes[k] = toNestedSetNode(item)
+	}
+
+	return nodes, nil
+}
+
+func (n *NestedSet) sql(tx *sql.Tx) *db.SQL {
+	if tx != nil {
+		return n.tbl.sql(n.conn).WithTx(tx).Table(n.tbl.Name)
+	}
+	return n.tbl.sql(n.conn).Table(n.tbl.Name)
+}
+
+func toNestedSetNode(item map[string]interface{}) NestedSetNode {
+	return NestedSetNode{
+		ID:    fmt.Sprintf("%v", item["id"]),
+		Lft:   toInt64(item["lft"]),
+		Rgt:   toInt64(item["rgt"]),
+		Depth: toInt64(item["depth"]),
+	}
+}
+
+func toInt64(v interface{}) int64 {
+	switch t := v.(type) {
+	case int64:
+		return t
+	case int32:
+		return int64(t)
+	case int:
+		return int64(t)
+	case uint64:
+		return int64(t)
+	case float64:
+		return int64(t)
+	case []byte:
+		i, _ := strconv.ParseInt(string(t), 10, 64)
+		return i
+	case string:
+		i, _ := strconv.ParseInt(t, 10, 64)
+		return i
+	}
+	return 0
+}
+
+// nestedSetPositions number the items in pre-order starting from the given
+// lft and depth, and return the positions with the next free lft value.
+func nestedSetPositions(items models.OrderItems, lft, depth int64) ([]NestedSetNode, int64) {
+	positions := make([]NestedSetNode, 0, len(items))
+
+	for _, item := range items {
+		children, next := nestedSetPositions(item.Children, lft+1, depth+1)
+		positions = append(positions, NestedSetNode{
+			ID:    strconv.FormatUint(uint64(item.ID), 10),
+			Lft:   lft,
+			Rgt:   next,
+			Depth: depth,
+		})
+		positions = append(positions, children...)
+		lft = next + 1
+	}
+
+	return positions, lft
+}
+
+// sameNestedSetNodes check if the positions hold every node exactly once.
+func sameNestedSetNodes(nodes, positions []NestedSetNode) bool {
+	if len(positions) != len(nodes) {
+		return false
+	}
+
+	known := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		known[node.ID] = true
+	}
+
+	for _, pos := range positions {
+		if !known[pos.ID] {
+			return false
+		}
+		delete(known, pos.ID)
+	}
+
+	return true
+}
+
+// mergeNestedSetOrder apply an order which holds part of the nodes sorted by
+// lft, e.g. the nodes a user can see. Every given node must keep its nearest
+// given ancestor, so the nodes only change places among their siblings.
+func mergeNestedSetOrder(nodes []NestedSetNode, items models.OrderItems) ([]NestedSetNode, error) {
+
+	var (
+		index   = make(map[string]int)
+		parents = make(map[string]string)
+		flatten func(items models.OrderItems, parent string) bool
+	)
+
+	flatten = func(items models.OrderItems, parent string) bool {
+		for _, item := range items {
+			id := strconv.FormatUint(uint64(item.ID), 10)
+			if _, ok := index[id]; ok {
+				return false
+			}
+			index[id] = len(index)
+			parents[id] = parent
+			if !flatten(item.Children, id) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if !flatten(items, "") {
+		return nil, errNestedSetPartialOrder
+	}
+
+	var (
+		depths     = make(map[string]int64, len(nodes))
+		children   = make(map[string][]string, len(nodes))
+		realParent = make(map[string]string, len(nodes))
+		stack      = make([]NestedSetNode, 0)
+	)
+
+	for _, node := range nodes {
+		for len(stack) > 0 && stack[len(stack)-1].Rgt < node.Lft {
+			stack = stack[:len(stack)-1]
+		}
+		parent := ""
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1].ID
+		}
+		depths[node.ID] = node.Depth
+		realParent[node.ID] = parent
+		children[parent] = append(children[parent], node.ID)
+		stack = append(stack, node)
+	}
+
+	for id, parent := range parents {
+		if _, ok := depths[id]; !ok {
+			return nil, errNestedSetPartialOrder
+		}
+		ancestor := realParent[id]
+		for ancestor != "" {
+			if _, ok := index[ancestor]; ok {
+				break
+			}
+			ancestor = realParent[ancestor]
+		}
+		if ancestor != parent {
+			return nil, errNestedSetReparent
+		}
+	}
+
+	// sort the given nodes of every sibling group within their own slots
+	for _, siblings := range children {
+		slots := make([]int, 0, len(siblings))
+		given := make([]string, 0, len(siblings))
+		for k, id := range siblings {
+			if _, ok := index[id]; ok {
+				slots = append(slots, k)
+				given = append(given, id)
+			}
+		}
+		sort.SliceStable(given, func(i, j int) bool {
+			return index[given[i]] < index[given[j]]
+		})
+		for k, slot := range slots {
+			siblings[slot] = given[k]
+		}
+	}
+
+	var (
+		res     = make([]NestedSetNode, 0, len(nodes))
+		counter = int64(1)
+		number  func(id string, depth int64)
+	)
+
+	number = func(id string, depth int64) {
+		k := len(res)
+		res = append(res, NestedSetNode{ID: id, Lft: counter, Depth: depth})
+		counter++
+		for _, child := range children[id] {
+			number(child, depth+1)
+		}
+		res[k].Rgt = counter
+		counter++
+	}
+
+	for _, id := range children[""] {
+		number(id, depths[id])
+	}
+
+	return res, nil
+}
+
+// rebuildNestedSet renumber nodes sorted by lft using their depth only.
+func rebuildNestedSet(nodes []NestedSetNode) []NestedSetNode {
+	res := make([]NestedSetNode, len(nodes))
+	stack := make([]int, 0)
+	counter := int64(1)
+
+	for k, node := range nodes {
+		for len(stack) > 0 && res[stack[len(stack)-1]].Depth >= node.Depth {
+			res[stack[len(stack)-1]].Rgt = counter
+			counter++
+			stack = stack[:len(stack)-1]
+		}
+		res[k] = NestedSetNode{ID: node.ID, Lft: counter, Depth: node.Depth}
+		counter++
+		stack = append(stack, k)
+	}
+
+	for len(stack) > 0 {
+		res[stack[len(stack)-1]].Rgt = counter
+		counter++
+		stack = stack[:len(stack)-1]
+	}
+
+	return res
+}
+
+// verifyNestedSet check nodes sorted by lft.
+func verifyNestedSet(nodes []NestedSetNode) error {
+	seen := make(map[int64]string, len(nodes)*2)
+	stack := make([]NestedSetNode, 0)
+
+	for _, node := range nodes {
+		if node.Lft >= node.Rgt {
+			return fmt.Errorf("nested set: node %s has lft %d not less than rgt %d", node.ID, node.Lft, node.Rgt)
+		}
+		if node.Width()%2 != 0 {
+			return fmt.Errorf("nested set: node %s has an odd width", node.ID)
+		}
+		for _, v := range []int64{node.Lft, node.Rgt} {
+			if other, ok := seen[v]; ok {
+				return fmt.Errorf("nested set: value %d is used by node %s and node %s", v, other, node.ID)
+			}
+			seen[v] = node.ID
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].Rgt < node.Lft {
+			stack = stack[:len(stack)-1]
+		}
+
+		if len(stack) > 0 {
+			parent := stack[len(stack)-1]
+			if node.Rgt > parent.Rgt {
+				return fmt.Errorf("nested set: node %s overlaps node %s", node.ID, parent.ID)
+			}
+			if node.Depth != parent.Depth+1 {
+				return fmt.Errorf("nested set: node %s has depth %d, expect %d", node.ID, node.Depth, parent.Depth+1)
+			}
+		} else if len(nodes) > 0 && node.Depth != nodes[0].Depth {
+			return fmt.Errorf("nested set: node %s has depth %d, expect %d", node.ID, node.Depth, nodes[0].Depth)
+		}
+
+		stack = append(stack, node)
+	}
+
+	for v := int64(1); v <= int64(len(nodes)*2); v++ {
+		if _, ok := seen[v]; !ok {
+			return fmt.Errorf("nested set: value %d is missing", v)
+		}
+	}
+
+	return nil
+}
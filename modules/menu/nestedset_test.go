@@ -0,0 +1,113 @@
+package menu
+
+import (
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+)
+
+func TestNestedSetPositions(t *testing.T) {
+	items := models.OrderItems{
+		{ID: 2, Children: models.OrderItems{
+			{ID: 3},
+			{ID: 4, Children: models.OrderItems{{ID: 5}}},
+		}},
+		{ID: 6},
+	}
+
+	positions, next := nestedSetPositions(items, 2, 1)
+
+	assert.Equal(t, next, int64(12))
+	assert.Equal(t, positions, []NestedSetNode{
+		{ID: "2", Lft: 2, Rgt: 9, Depth: 1},
+		{ID: "3", Lft: 3, Rgt: 4, Depth: 2},
+		{ID: "4", Lft: 5, Rgt: 8, Depth: 2},
+		{ID: "5", Lft: 6, Rgt: 7, Depth: 3},
+		{ID: "6", Lft: 10, Rgt: 11, Depth: 1},
+	})
+
+	root := NestedSetNode{ID: "1", Lft: 1, Rgt: next, Depth: 0}
+	assert.Equal(t, verifyNestedSet(append([]NestedSetNode{root}, positions...)), nil)
+}
+
+func TestRebuildNestedSet(t *testing.T) {
+	broken := []NestedSetNode{
+		{ID: "1", Lft: 1, Rgt: 30, Depth: 0},
+		{ID: "2", Lft: 3, Rgt: 4, Depth: 1},
+		{ID: "3", Lft: 7, Rgt: 7, Depth: 2},
+		{ID: "4", Lft: 12, Rgt: 20, Depth: 1},
+	}
+
+	assert.Equal(t, verifyNestedSet(broken) != nil, true)
+
+	rebuilt := rebuildNestedSet(broken)
+
+	assert.Equal(t, rebuilt, []NestedSetNode{
+		{ID: "1", Lft: 1, Rgt: 8, Depth: 0},
+		{ID: "2", Lft: 2, Rgt: 5, Depth: 1},
+		{ID: "3", Lft: 3, Rgt: 4, Depth: 2},
+		{ID: "4", Lft: 6, Rgt: 7, Depth: 1},
+	})
+	assert.Equal(t, verifyNestedSet(rebuilt), nil)
+}
+
+func TestVerifyNestedSet(t *testing.T) {
+	overlap := []NestedSetNode{
+		{ID: "1", Lft: 1, Rgt: 4, Depth: 0},
+		{ID: "2", Lft: 2, Rgt: 5, Depth: 1},
+		{ID: "3", Lft: 3, Rgt: 6, Depth: 1},
+	}
+	assert.Equal(t, verifyNestedSet(overlap) != nil, true)
+
+	wrongDepth := []NestedSetNode{
+		{ID: "1", Lft: 1, Rgt: 4, Depth: 0},
+		{ID: "2", Lft: 2, Rgt: 3, Depth: 2},
+	}
+	assert.Equal(t, verifyNestedSet(wrongDepth) != nil, true)
+}
+
+func TestMergeNestedSetOrder(t *testing.T) {
+	// 1 ─┬─ 2 ─┬─ 3
+	//    │     ├─ 4 (hidden)
+	//    │     └─ 5
+	//    ├─ 6 (hidden)
+	//    └─ 7
+	nodes := []NestedSetNode{
+		{ID: "1", Lft: 1, Rgt: 14, Depth: 0},
+		{ID: "2", Lft: 2, Rgt: 9, Depth: 1},
+		{ID: "3", Lft: 3, Rgt: 4, Depth: 2},
+		{ID: "4", Lft: 5, Rgt: 6, Depth: 2},
+		{ID: "5", Lft: 7, Rgt: 8, Depth: 2},
+		{ID: "6", Lft: 10, Rgt: 11, Depth: 1},
+		{ID: "7", Lft: 12, Rgt: 13, Depth: 1},
+	}
+
+	merged, err := mergeNestedSetOrder(nodes, models.OrderItems{
+		{ID: 7},
+		{ID: 2, Children: models.OrderItems{{ID: 5}, {ID: 3}}},
+	})
+
+	assert.Equal(t, err, nil)
+	assert.Equal(t, merged, []NestedSetNode{
+		{ID: "1", Lft: 1, Rgt: 14, Depth: 0},
+		{ID: "7", Lft: 2, Rgt: 3, Depth: 1},
+		{ID: "6", Lft: 4, Rgt: 5, Depth: 1},
+		{ID: "2", Lft: 6, Rgt: 13, Depth: 1},
+		{ID: "5", Lft: 7, Rgt: 8, Depth: 2},
+		{ID: "4", Lft: 9, Rgt: 10, Depth: 2},
+		{ID: "3", Lft: 11, Rgt: 12, Depth: 2},
+	})
+	assert.Equal(t, verifyNestedSet(merged), nil)
+
+	_, err = mergeNestedSetOrder(nodes, models.OrderItems{
+		{ID: 2, Children: models.OrderItems{{ID: 7}}},
+	})
+	assert.Equal(t, err, errNestedSetReparent)
+
+	_, err = mergeNestedSetOrder(nodes, models.OrderItems{{ID: 2}, {ID: 2}})
+	assert.Equal(t, err, errNestedSetPartialOrder)
+
+	_, err = mergeNestedSetOrder(nodes, models.OrderItems{{ID: 9}})
+	assert.Equal(t, err, errNestedSetPartialOrder)
+}
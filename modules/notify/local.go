@@ -0,0 +1,61 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package notify
+
+import (
+	"io/ioutil"
+	"mime"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/plugins/admin/modules"
+)
+
+// LocalNotifier is a stand-in of the mail server for the development. The
+// messages are written to the files of Dir, or to the info log when Dir is
+// empty.
+type LocalNotifier struct {
+	Dir string
+}
+
+// GetLocalNotifier return the local Notifier with the global notifier config.
+func GetLocalNotifier() Notifier {
+	return &LocalNotifier{Dir: mapString(config.GetNotifier().Config, "dir")}
+}
+
+// Notify implements the Notifier.Notify.
+func (l *LocalNotifier) Notify(msg Message) error {
+	content := formatMessage("", msg, time.Now())
+
+	if l.Dir == "" {
+		logger.Info("notify message:\n", content)
+		return nil
+	}
+
+	name := time.Now().Format("20060102150405") + "_" + modules.Uuid() + ".eml"
+	return ioutil.WriteFile(filepath.Join(l.Dir, name), []byte(content), 0600)
+}
+
+var headerEscaper = strings.NewReplacer("\r", "", "\n", "")
+
+// formatMessage return the message in the internet message format.
+func formatMessage(from string, msg Message, date time.Time) string {
+	var b strings.Builder
+	if from != "" {
+		b.WriteString("From: " + headerEscaper.Replace(from) + "\r\n")
+	}
+	b.WriteString("To: " + headerEscaper.Replace(msg.To) + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("UTF-8", headerEscaper.Replace(msg.Subject)) + "\r\n")
+	b.WriteString("Date: " + date.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.Replace(strings.Replace(msg.Body, "\r\n", "\n", -1), "\n", "\r\n", -1))
+	return b.String()
+}
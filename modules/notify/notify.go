@@ -0,0 +1,106 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+// Package notify sends the messages to the users, such as the password reset
+// links. The notifier is chosen by the notifier config.
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"sync"
+
+	"github.com/wowucco/go-admin/modules/config"
+)
+
+// Message is a plain text message to an email address.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier sends the messages.
+type Notifier interface {
+	Notify(msg Message) error
+}
+
+// NotifierGenerator is a function return a Notifier.
+type NotifierGenerator func() Notifier
+
+var notifierList = map[string]NotifierGenerator{
+	"local": GetLocalNotifier,
+	"smtp":  GetSMTPNotifier,
+}
+
+var mu sync.Mutex
+
+// ErrNoRecipient is returned when the message has no address.
+var ErrNoRecipient = errors.New("the message has no recipient")
+
+// AddNotifier makes a notifier generator available by the provided name.
+// If Add is called twice with the same name or if the generator is nil,
+// it panics.
+func AddNotifier(name string, gen NotifierGenerator) {
+	mu.Lock()
+	defer mu.Unlock()
+	if gen == nil {
+		panic("notifier generator is nil")
+	}
+	if _, dup := notifierList[name]; dup {
+		panic("add notifier generator twice " + name)
+	}
+	notifierList[name] = gen
+}
+
+// GetNotifier return the Notifier of given name.
+func GetNotifier(name string) (Notifier, bool) {
+	mu.Lock()
+	gen, ok := notifierList[name]
+	mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return gen(), true
+}
+
+// Send sends the message with the notifier of the config.
+func Send(msg Message) error {
+	if msg.To == "" {
+		return ErrNoRecipient
+	}
+	name := config.GetNotifier().Name
+	n, ok := GetNotifier(name)
+	if !ok {
+		return errors.New("wrong notifier name: " + name)
+	}
+	return n.Notify(msg)
+}
+
+// parseAddress return the address of "Name <address>" or of an address.
+func parseAddress(s string) (string, error) {
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return "", err
+	}
+	return addr.Address, nil
+}
+
+func mapString(m map[string]interface{}, key string) string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return ""
+	}
+	switch t := v.(type) {
+	case string:
+		return t
+	case bool:
+		return strconv.FormatBool(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalNotifier(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notify")
+	assert.Equal(t, err, nil)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	n := &LocalNotifier{Dir: dir}
+	assert.Equal(t, n.Notify(Message{To: "a@example.com", Subject: "Reset\r\nBcc: x@example.com", Body: "line1\nline2"}), nil)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	assert.Equal(t, len(files), 1)
+
+	content, _ := ioutil.ReadFile(files[0])
+	assert.Equal(t, strings.Contains(string(content), "To: a@example.com\r\n"), true)
+	assert.Equal(t, strings.Contains(string(content), "\r\nBcc:"), false)
+	assert.Equal(t, strings.HasSuffix(string(content), "\r\n\r\nline1\r\nline2"), true)
+}
+
+// fakeSMTP serves one plain smtp conversation and return the received data.
+func fakeSMTP(t *testing.T) (int, chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan string, 1)
+
+	go func() {
+		defer func() {
+			_ = ln.Close()
+		}()
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		r := bufio.NewReader(conn)
+		write := func(s string) {
+			_, _ = conn.Write([]byte(s + "\r\n"))
+		}
+
+		var log strings.Builder
+		write("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"):
+				write("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"):
+				log.WriteString(strings.TrimSpace(line) + "\n")
+				write("250 ok")
+			case cmd == "DATA":
+				write("354 go ahead")
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					log.WriteString(l)
+				}
+				write("250 queued")
+			case cmd == "QUIT":
+				write("221 bye")
+				received <- log.String()
+				return
+			default:
+				write("502 not implemented")
+			}
+		}
+	}()
+
+	return ln.Addr().(*net.TCPAddr).Port, received
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	port, received := fakeSMTP(t)
+
+	n := NewSMTPNotifier(SMTPConfigFromMap(map[string]interface{}{
+		"host": "127.0.0.1",
+		"port": float64(port),
+		"from": "GoAdmin <admin@example.com>",
+		"tls":  "none",
+	}))
+	assert.Equal(t, n.Config.Port, port)
+
+	err := n.Notify(Message{To: "user@example.com", Subject: "Hello", Body: "hi"})
+	assert.Equal(t, err, nil)
+
+	data := <-received
+	assert.Equal(t, strings.Contains(data, "MAIL FROM:<admin@example.com>"), true)
+	assert.Equal(t, strings.Contains(data, "RCPT TO:<user@example.com>"), true)
+	assert.Equal(t, strings.Contains(data, "Subject: Hello\r\n"), true)
+	assert.Equal(t, strings.Contains(data, "From: GoAdmin <admin@example.com>\r\n"), true)
+}
+
+func TestSMTPNotifierRequireStartTLS(t *testing.T) {
+	port, _ := fakeSMTP(t)
+
+	n := NewSMTPNotifier(SMTPConfig{Host: "127.0.0.1", Port: port, From: "admin@example.com"})
+	err := n.Notify(Message{To: "user@example.com", Subject: "Hello", Body: "hi"})
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, strings.Contains(err.Error(), "STARTTLS"), true)
+}
@@ -0,0 +1,180 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+package notify
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/wowucco/go-admin/modules/config"
+)
+
+const defaultSMTPTimeout = 10 * time.Second
+
+// SMTPConfig is the config of the smtp notifier. It is read from the notifier
+// config, for example:
+//
+//	{
+//	    "name": "smtp",
+//	    "config": {
+//	        "host": "smtp.example.com",
+//	        "port": 587,
+//	        "username": "admin@example.com",
+//	        "password": "...",
+//	        "from": "GoAdmin <admin@example.com>",
+//	        "tls": "starttls"
+//	    }
+//	}
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	// TLS is "starttls", which is the default, "tls" for the implicit tls of
+	// the port 465, or "none".
+	TLS                string
+	InsecureSkipVerify bool
+	Timeout            time.Duration
+}
+
+// SMTPNotifier is a Notifier which sends emails through a smtp server.
+type SMTPNotifier struct {
+	Config SMTPConfig
+}
+
+// GetSMTPNotifier return the smtp Notifier with the global notifier config.
+func GetSMTPNotifier() Notifier {
+	return NewSMTPNotifier(SMTPConfigFromMap(config.GetNotifier().Config))
+}
+
+// NewSMTPNotifier return a smtp Notifier of given config.
+func NewSMTPNotifier(cfg SMTPConfig) *SMTPNotifier {
+	if cfg.Port == 0 {
+		if cfg.TLS == "tls" {
+			cfg.Port = 465
+		} else {
+			cfg.Port = 587
+		}
+	}
+	if cfg.TLS == "" {
+		cfg.TLS = "starttls"
+	}
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultSMTPTimeout
+	}
+	return &SMTPNotifier{Config: cfg}
+}
+
+// SMTPConfigFromMap parse the notifier config.
+func SMTPConfigFromMap(m map[string]interface{}) SMTPConfig {
+	cfg := SMTPConfig{
+		Host:               mapString(m, "host"),
+		Username:           mapString(m, "username"),
+		Password:           mapString(m, "password"),
+		From:               mapString(m, "from"),
+		TLS:                mapString(m, "tls"),
+		InsecureSkipVerify: mapString(m, "insecure_skip_verify") == "true",
+	}
+	cfg.Port, _ = strconv.Atoi(mapString(m, "port"))
+	if timeout, err := strconv.Atoi(mapString(m, "timeout")); err == nil {
+		cfg.Timeout = time.Duration(timeout) * time.Second
+	}
+	return cfg
+}
+
+// Notify implements the Notifier.Notify.
+func (s *SMTPNotifier) Notify(msg Message) error {
+	if s.Config.Host == "" {
+		return errors.New("the smtp host is not set")
+	}
+
+	from, err := parseAddress(s.Config.From)
+	if err != nil {
+		return err
+	}
+	to, err := parseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+
+	c, err := s.dial()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+
+	tlsConfig := &tls.Config{ServerName: s.Config.Host, InsecureSkipVerify: s.Config.InsecureSkipVerify}
+
+	if s.Config.TLS == "starttls" {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("the smtp server does not support STARTTLS")
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if s.Config.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.Config.Username, s.Config.Password, s.Config.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(formatMessage(s.Config.From, msg, time.Now()))); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func (s *SMTPNotifier) dial() (*smtp.Client, error) {
+	addr := net.JoinHostPort(s.Config.Host, strconv.Itoa(s.Config.Port))
+	dialer := &net.Dialer{Timeout: s.Config.Timeout}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if s.Config.TLS == "tls" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr,
+			&tls.Config{ServerName: s.Config.Host, InsecureSkipVerify: s.Config.InsecureSkipVerify})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the deadline covers the whole conversation
+	_ = conn.SetDeadline(time.Now().Add(s.Config.Timeout))
+
+	c, err := smtp.NewClient(conn, s.Config.Host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,328 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// ClockSkew is the tolerance of the time claims of the ID tokens.
+const ClockSkew = time.Minute
+
+// keysRefetchInterval limits the refetch of the keys when a token is signed
+// by an unknown key.
+const keysRefetchInterval = time.Minute
+
+var (
+	ErrMalformedToken   = errors.New("oidc: malformed id token")
+	ErrUnsupportedAlg   = errors.New("oidc: unsupported signing algorithm")
+	ErrUnknownKey       = errors.New("oidc: unknown signing key")
+	ErrInvalidSignature = errors.New("oidc: invalid id token signature")
+	ErrInvalidClaims    = errors.New("oidc: invalid id token claims")
+	ErrTokenExpired     = errors.New("oidc: id token is expired")
+	ErrNonceMismatch    = errors.New("oidc: nonce does not match")
+)
+
+// Claims are the claims of an ID token.
+type Claims map[string]interface{}
+
+// Subject return the sub claim.
+func (c Claims) Subject() string {
+	return c.String("sub")
+}
+
+// String return the string claim of given name.
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Bool return the boolean claim of given name, some providers send it as
+// a string.
+func (c Claims) Bool(name string) bool {
+	switch v := c[name].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// Strings return the claim of given name as a list, a single string claim is
+// a list of one item.
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// Username return the claim of given name, or the email, or the subject.
+func (c Claims) Username(claim string) string {
+	for _, name := range []string{claim, "email", "sub"} {
+		if s := c.String(name); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func (c Claims) time(name string) (time.Time, bool) {
+	n, ok := c[name].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(f), 0), true
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// VerifyIDToken check the signature and the claims of the ID token and return
+// its claims.
+func (p *Provider) VerifyIDToken(raw, nonce string) (Claims, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	key, err := p.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	dec := json.NewDecoder(strings.NewReader(string(payload)))
+	dec.UseNumber()
+	var claims Claims
+	if err := dec.Decode(&claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if err := p.checkClaims(claims, nonce, time.Now()); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (p *Provider) checkClaims(claims Claims, nonce string, now time.Time) error {
+	if strings.TrimRight(claims.String("iss"), "/") != p.Issuer {
+		return ErrIssuerMismatch
+	}
+
+	aud := claims.Strings("aud")
+	if !contains(aud, p.ClientID) {
+		return ErrInvalidClaims
+	}
+	if len(aud) > 1 && claims.String("azp") != p.ClientID {
+		return ErrInvalidClaims
+	}
+
+	if claims.Subject() == "" {
+		return ErrInvalidClaims
+	}
+
+	exp, ok := claims.time("exp")
+	if !ok {
+		return ErrInvalidClaims
+	}
+	if now.After(exp.Add(ClockSkew)) {
+		return ErrTokenExpired
+	}
+	if iat, ok := claims.time("iat"); ok && iat.After(now.Add(ClockSkew)) {
+		return ErrInvalidClaims
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.String("nonce")), []byte(nonce)) != 1 {
+		return ErrNonceMismatch
+	}
+	return nil
+}
+
+func verifySignature(alg string, key interface{}, signed string, sig []byte) error {
+	sum := sha256.Sum256([]byte(signed))
+
+	switch alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrUnsupportedAlg
+		}
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], sig) != nil {
+			return ErrInvalidSignature
+		}
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(pub, sum[:], r, s) {
+			return ErrInvalidSignature
+		}
+	default:
+		return ErrUnsupportedAlg
+	}
+	return nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// key return the signing key of given id, the keys are fetched again when the
+// id is unknown since the provider may have rotated them.
+func (p *Provider) key(kid string) (interface{}, error) {
+	d, err := p.Discover()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	if !p.keysFetched.IsZero() && time.Since(p.keysFetched) < keysRefetchInterval {
+		return nil, ErrUnknownKey
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(d.JwksURI, &set); err != nil {
+		return nil, err
+	}
+
+	p.keys = make(map[string]interface{})
+	p.keysFetched = time.Now()
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if key, err := k.publicKey(); err == nil {
+			p.keys[k.Kid] = key
+		}
+	}
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// lookupKey find the key of given id, a token without an id is accepted when
+// the provider has only one key.
+func (p *Provider) lookupKey(kid string) (interface{}, bool) {
+	if key, ok := p.keys[kid]; ok {
+		return key, true
+	}
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, ErrUnsupportedAlg
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, ErrInvalidClaims
+		}
+		return pub, nil
+	}
+	return nil, ErrUnsupportedAlg
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
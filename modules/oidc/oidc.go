@@ -0,0 +1,315 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+// Package oidc is an OpenID Connect relying party for the single sign-on of
+// the admin. It supports the discovery, the authorization code flow with PKCE
+// and the validation of the RS256 and ES256 signed ID tokens.
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrIssuerMismatch = errors.New("oidc: issuer does not match")
+	ErrNoIDToken      = errors.New("oidc: no id token in the token response")
+)
+
+// Config is the config of an OpenID Connect provider.
+type Config struct {
+	// Name is the key of the provider in the urls, such as "google".
+	Name string
+	// Title is shown on the sign in button of the login page.
+	Title string
+
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback url registered at the provider. It is
+	// built from the request when empty, set it when the admin is behind a
+	// proxy which changes the host.
+	RedirectURL string
+	// Scopes default to "openid profile email".
+	Scopes []string
+
+	// UsernameClaim is the claim used as the username, "preferred_username"
+	// by default, the email and the subject are used when it is missing.
+	UsernameClaim string
+	// GroupsClaim is the claim of the groups of the user, "groups" by default.
+	GroupsClaim string
+	// RoleMapping maps the groups to the role slugs. When it is set the roles
+	// of the user are synced with the groups on every login.
+	RoleMapping map[string]string
+	// DefaultRoles are the role slugs given when no group is mapped.
+	DefaultRoles []string
+
+	// AutoCreate creates a user at the first login.
+	AutoCreate bool
+	// LinkExisting links the first login to the user of the same email,
+	// only when the provider sends email_verified as true. The super
+	// administrators are never linked.
+	LinkExisting bool
+
+	HTTPClient *http.Client
+}
+
+// Discovery is the provider metadata of the discovery document.
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// Token is the response of the token endpoint.
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Provider is an OpenID Connect provider. The discovery document and the keys
+// are fetched at the first use and cached.
+type Provider struct {
+	Config
+
+	mu          sync.Mutex
+	discovery   *Discovery
+	keys        map[string]interface{}
+	keysFetched time.Time
+}
+
+// New return a Provider of given config.
+func New(cfg Config) *Provider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "profile", "email"}
+	}
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = "preferred_username"
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	if cfg.Title == "" {
+		cfg.Title = cfg.Name
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	cfg.Issuer = strings.TrimRight(cfg.Issuer, "/")
+	return &Provider{Config: cfg}
+}
+
+// Discover return the discovery document of the provider.
+func (p *Provider) Discover() (*Discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var d Discovery
+	if err := p.getJSON(p.Issuer+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimRight(d.Issuer, "/") != p.Issuer {
+		return nil, ErrIssuerMismatch
+	}
+
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JwksURI == "" {
+		return nil, errors.New("oidc: incomplete discovery document")
+	}
+
+	p.discovery = &d
+	return p.discovery, nil
+}
+
+// AuthCodeURL return the url of the provider to start the login.
+func (p *Provider) AuthCodeURL(redirectURL, state, nonce, codeChallenge string) (string, error) {
+	d, err := p.Discover()
+	if err != nil {
+		return "", err
+	}
+
+	v := url.Values{}
+	v.Set("response_type", "code")
+	v.Set("client_id", p.ClientID)
+	v.Set("redirect_uri", redirectURL)
+	v.Set("scope", strings.Join(p.Scopes, " "))
+	v.Set("state", state)
+	v.Set("nonce", nonce)
+	v.Set("code_challenge", codeChallenge)
+	v.Set("code_challenge_method", "S256")
+
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		return d.AuthorizationEndpoint + "&" + v.Encode(), nil
+	}
+	return d.AuthorizationEndpoint + "?" + v.Encode(), nil
+}
+
+// Exchange exchange the authorization code for the tokens.
+func (p *Provider) Exchange(code, codeVerifier, redirectURL string) (*Token, error) {
+	d, err := p.Discover()
+	if err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+	v.Set("grant_type", "authorization_code")
+	v.Set("code", code)
+	v.Set("redirect_uri", redirectURL)
+	v.Set("code_verifier", codeVerifier)
+	v.Set("client_id", p.ClientID)
+
+	req, err := http.NewRequest(http.MethodPost, d.TokenEndpoint, strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+
+	res, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		var e struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		_ = json.Unmarshal(body, &e)
+		return nil, fmt.Errorf("oidc: token endpoint returned %d: %s %s", res.StatusCode, e.Error, e.Description)
+	}
+
+	var token Token
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, err
+	}
+	if token.IDToken == "" {
+		return nil, ErrNoIDToken
+	}
+	return &token, nil
+}
+
+// Login exchange the code and return the claims of the validated ID token.
+func (p *Provider) Login(code, codeVerifier, redirectURL, nonce string) (Claims, error) {
+	token, err := p.Exchange(code, codeVerifier, redirectURL)
+	if err != nil {
+		return nil, err
+	}
+	return p.VerifyIDToken(token.IDToken, nonce)
+}
+
+// MapRoles return the role slugs of the groups of the claims, or the default
+// roles when no group is mapped.
+func (p *Provider) MapRoles(claims Claims) []string {
+	roles := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, group := range claims.Strings(p.GroupsClaim) {
+		if slug, ok := p.RoleMapping[group]; ok && !seen[slug] {
+			seen[slug] = true
+			roles = append(roles, slug)
+		}
+	}
+	if len(roles) == 0 {
+		return p.DefaultRoles
+	}
+	return roles
+}
+
+func (p *Provider) getJSON(u string, v interface{}) error {
+	res, err := p.HTTPClient.Get(u)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: get %s returned %d", u, res.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(v)
+}
+
+// NewCodeVerifier return a random PKCE code verifier.
+func NewCodeVerifier() string {
+	return randomString(32)
+}
+
+// CodeChallenge return the S256 PKCE code challenge of the verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+var (
+	providers     = make(map[string]*Provider)
+	providerNames = make([]string, 0)
+	providersLock sync.RWMutex
+)
+
+// Add register the provider, a provider of the same name is replaced.
+func Add(p *Provider) {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+	if _, ok := providers[p.Name]; !ok {
+		providerNames = append(providerNames, p.Name)
+	}
+	providers[p.Name] = p
+}
+
+// Get return the provider of given name.
+func Get(name string) (*Provider, bool) {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+	p, ok := providers[name]
+	return p, ok
+}
+
+// List return the providers in the order they were added.
+func List() []*Provider {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+	list := make([]*Provider, len(providerNames))
+	for i, name := range providerNames {
+		list[i] = providers[name]
+	}
+	return list
+}
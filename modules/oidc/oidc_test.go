@@ -0,0 +1,281 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testIdP is a stand-in provider which issues the id tokens of a fixed user.
+type testIdP struct {
+	server *httptest.Server
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+	kid    string
+
+	codes  map[string]testGrant
+	claims map[string]interface{}
+	alg    string
+}
+
+type testGrant struct {
+	challenge string
+	nonce     string
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Equal(t, err, nil)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Equal(t, err, nil)
+
+	idp := &testIdP{rsaKey: rsaKey, ecKey: ecKey, kid: "rsa-1", codes: make(map[string]testGrant), alg: "RS256"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": idp.kid,
+			"use": "sig",
+			"n":   b64(idp.rsaKey.N.Bytes()),
+			"e":   b64(big.NewInt(int64(idp.rsaKey.E)).Bytes()),
+		}, {
+			"kty": "EC",
+			"kid": "ec-1",
+			"crv": "P-256",
+			"x":   b64(pad32(idp.ecKey.X.Bytes())),
+			"y":   b64(pad32(idp.ecKey.Y.Bytes())),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		grant, ok := idp.codes[r.FormValue("code")]
+		if id != "admin" || secret != "s3cret" || !ok ||
+			CodeChallenge(r.FormValue("code_verifier")) != grant.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		delete(idp.codes, r.FormValue("code"))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "at",
+			"token_type":   "Bearer",
+			"id_token":     idp.sign(t, idp.tokenClaims(grant.nonce)),
+		})
+	})
+	idp.server = httptest.NewServer(mux)
+	return idp
+}
+
+// authorize is what the provider does when the user signs in, it returns the
+// code of the redirect.
+func (idp *testIdP) authorize(authURL string) string {
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	idp.codes["code-1"] = testGrant{challenge: q.Get("code_challenge"), nonce: q.Get("nonce")}
+	return "code-1"
+}
+
+func (idp *testIdP) tokenClaims(nonce string) map[string]interface{} {
+	claims := map[string]interface{}{
+		"iss":                idp.server.URL,
+		"sub":                "user-1",
+		"aud":                "admin",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"iat":                time.Now().Unix(),
+		"nonce":              nonce,
+		"preferred_username": "alice",
+		"groups":             []string{"staff", "ops"},
+	}
+	for k, v := range idp.claims {
+		claims[k] = v
+	}
+	return claims
+}
+
+func (idp *testIdP) sign(t *testing.T, claims map[string]interface{}) string {
+	kid := idp.kid
+	if idp.alg == "ES256" {
+		kid = "ec-1"
+	}
+	header, _ := json.Marshal(map[string]string{"alg": idp.alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+	sum := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	var err error
+	if idp.alg == "ES256" {
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, idp.ecKey, sum[:])
+		sig = append(pad32(r.Bytes()), pad32(s.Bytes())...)
+	} else {
+		sig, err = rsa.SignPKCS1v15(rand.Reader, idp.rsaKey, crypto.SHA256, sum[:])
+	}
+	assert.Equal(t, err, nil)
+	return signed + "." + b64(sig)
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func pad32(b []byte) []byte {
+	return append(make([]byte, 32-len(b)), b...)
+}
+
+func newTestProvider(idp *testIdP) *Provider {
+	return New(Config{
+		Name:         "test",
+		Issuer:       idp.server.URL,
+		ClientID:     "admin",
+		ClientSecret: "s3cret",
+		RoleMapping:  map[string]string{"ops": "operator", "dev": "developer"},
+		DefaultRoles: []string{"viewer"},
+	})
+}
+
+func TestLogin(t *testing.T) {
+	idp := newTestIdP(t)
+	defer idp.server.Close()
+
+	p := newTestProvider(idp)
+	store := NewStateStore()
+	st := store.Begin("test", "http://admin.test/oidc/test/callback", "/admin/info/manager")
+
+	authURL, err := p.AuthCodeURL(st.RedirectURL, st.State, st.Nonce, CodeChallenge(st.CodeVerifier))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, strings.HasPrefix(authURL, idp.server.URL+"/authorize?"), true)
+	assert.Equal(t, strings.Contains(authURL, "code_challenge_method=S256"), true)
+	assert.Equal(t, strings.Contains(authURL, "scope=openid+profile+email"), true)
+
+	code := idp.authorize(authURL)
+
+	got, ok := store.Finish(st.State)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, got.Ref, "/admin/info/manager")
+	_, ok = store.Finish(st.State)
+	assert.Equal(t, ok, false)
+
+	// the code is bound to the verifier
+	_, err = p.Login(code, NewCodeVerifier(), got.RedirectURL, got.Nonce)
+	assert.NotEqual(t, err, nil)
+
+	code = idp.authorize(authURL)
+	claims, err := p.Login(code, got.CodeVerifier, got.RedirectURL, got.Nonce)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, claims.Subject(), "user-1")
+	assert.Equal(t, claims.Username(p.UsernameClaim), "alice")
+	assert.Equal(t, p.MapRoles(claims), []string{"operator"})
+	assert.Equal(t, p.MapRoles(Claims{}), []string{"viewer"})
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	idp := newTestIdP(t)
+	defer idp.server.Close()
+
+	p := newTestProvider(idp)
+
+	_, err := p.VerifyIDToken(idp.sign(t, idp.tokenClaims("n")), "n")
+	assert.Equal(t, err, nil)
+
+	idp.alg = "ES256"
+	_, err = p.VerifyIDToken(idp.sign(t, idp.tokenClaims("n")), "n")
+	assert.Equal(t, err, nil)
+	idp.alg = "RS256"
+
+	_, err = p.VerifyIDToken(idp.sign(t, idp.tokenClaims("n")), "other")
+	assert.Equal(t, err, ErrNonceMismatch)
+
+	for name, c := range map[string]struct {
+		claims map[string]interface{}
+		err    error
+	}{
+		"expired":      {map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()}, ErrTokenExpired},
+		"issuer":       {map[string]interface{}{"iss": "https://evil.test"}, ErrIssuerMismatch},
+		"audience":     {map[string]interface{}{"aud": "other"}, ErrInvalidClaims},
+		"azp":          {map[string]interface{}{"aud": []string{"admin", "other"}}, ErrInvalidClaims},
+		"future":       {map[string]interface{}{"iat": time.Now().Add(time.Hour).Unix()}, ErrInvalidClaims},
+		"no subject":   {map[string]interface{}{"sub": ""}, ErrInvalidClaims},
+		"no exp claim": {map[string]interface{}{"exp": nil}, ErrInvalidClaims},
+	} {
+		idp.claims = c.claims
+		_, err = p.VerifyIDToken(idp.sign(t, idp.tokenClaims("n")), "n")
+		assert.Equal(t, err, c.err, name)
+	}
+	idp.claims = nil
+
+	token := idp.sign(t, idp.tokenClaims("n"))
+	parts := strings.Split(token, ".")
+	forged, _ := json.Marshal(idp.tokenClaims("n"))
+	_, err = p.VerifyIDToken(parts[0]+"."+b64(append(forged[:len(forged)-1], []byte(`,"x":1}`)...))+"."+parts[2], "n")
+	assert.Equal(t, err, ErrInvalidSignature)
+
+	header, _ := json.Marshal(map[string]string{"alg": "none", "kid": idp.kid})
+	_, err = p.VerifyIDToken(b64(header)+"."+parts[1]+".", "n")
+	assert.Equal(t, err, ErrUnsupportedAlg)
+
+	_, err = p.VerifyIDToken("abc", "n")
+	assert.Equal(t, err, ErrMalformedToken)
+
+	// a rotated key is fetched again
+	idp.rsaKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+	idp.kid = "rsa-2"
+	p.keysFetched = time.Now().Add(-2 * keysRefetchInterval)
+	_, err = p.VerifyIDToken(idp.sign(t, idp.tokenClaims("n")), "n")
+	assert.Equal(t, err, nil)
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	idp := newTestIdP(t)
+	defer idp.server.Close()
+
+	p := New(Config{Name: "test", Issuer: idp.server.URL + "/other", ClientID: "admin"})
+	_, err := p.Discover()
+	assert.NotEqual(t, err, nil)
+}
+
+func TestRegistry(t *testing.T) {
+	Add(New(Config{Name: "a", Title: "A"}))
+	Add(New(Config{Name: "b"}))
+	Add(New(Config{Name: "a", Title: "A2"}))
+
+	list := List()
+	assert.Equal(t, len(list), 2)
+	assert.Equal(t, list[0].Title, "A2")
+	assert.Equal(t, list[1].Title, "b")
+
+	_, ok := Get("c")
+	assert.Equal(t, ok, false)
+}
+
+func TestClaimsBool(t *testing.T) {
+	claims := Claims{"a": true, "b": "true", "c": false, "d": "yes"}
+	assert.Equal(t, claims.Bool("a"), true)
+	assert.Equal(t, claims.Bool("b"), true)
+	assert.Equal(t, claims.Bool("c"), false)
+	assert.Equal(t, claims.Bool("d"), false)
+	assert.Equal(t, claims.Bool("missing"), false)
+}
@@ -0,0 +1,81 @@
+package oidc
+
+import (
+	"sync"
+	"time"
+)
+
+// StateExpires is how long the provider has to send the user back.
+const StateExpires = 10 * time.Minute
+
+// LoginState is what a login keeps between the redirect to the provider and
+// the callback.
+type LoginState struct {
+	State        string
+	Provider     string
+	CodeVerifier string
+	Nonce        string
+	RedirectURL  string
+	Ref          string
+
+	expires time.Time
+}
+
+// StateStore keeps the pending logins by their state.
+type StateStore struct {
+	mu     sync.Mutex
+	states map[string]LoginState
+}
+
+// NewStateStore return an empty StateStore.
+func NewStateStore() *StateStore {
+	return &StateStore{states: make(map[string]LoginState)}
+}
+
+var defaultStateStore = NewStateStore()
+
+// GetStateStore return the global StateStore.
+func GetStateStore() *StateStore {
+	return defaultStateStore
+}
+
+// Begin start a login of the provider and return its state.
+func (s *StateStore) Begin(provider, redirectURL, ref string) LoginState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, st := range s.states {
+		if now.After(st.expires) {
+			delete(s.states, key)
+		}
+	}
+
+	st := LoginState{
+		State:        randomString(24),
+		Provider:     provider,
+		CodeVerifier: NewCodeVerifier(),
+		Nonce:        randomString(24),
+		RedirectURL:  redirectURL,
+		Ref:          ref,
+		expires:      now.Add(StateExpires),
+	}
+	s.states[st.State] = st
+	return st
+}
+
+// Finish remove the login of the state and return it, a state is used once.
+func (s *StateStore) Finish(state string) (LoginState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.states[state]
+	if !ok {
+		return LoginState{}, false
+	}
+	delete(s.states, state)
+	if time.Now().After(st.expires) {
+		return LoginState{}, false
+	}
+	return st, true
+}
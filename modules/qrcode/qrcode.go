@@ -0,0 +1,573 @@
+// Copyright 2019 GoAdmin Core Team. All rights reserved.
+// Use of this source code is governed by a Apache-2.0 style
+// license that can be found in the LICENSE file.
+
+// Package qrcode is a small QR code encoder which is enough for the short
+// texts of the admin, such as the otpauth urls of the two-factor enrollment.
+// It encodes in byte mode with the error correction level M, up to version 15.
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// ErrTooLong is returned when the content does not fit in the largest version.
+var ErrTooLong = errors.New("qrcode: content is too long")
+
+// Code is an encoded QR code.
+type Code struct {
+	Version int
+	Size    int
+
+	modules  [][]bool
+	reserved [][]bool
+}
+
+// ecBlocks is the error correction blocks of a version of level M.
+type ecBlocks struct {
+	ecPerBlock int
+	group1     int
+	data1      int
+	group2     int
+	data2      int
+}
+
+func (b ecBlocks) dataCodewords() int {
+	return b.group1*b.data1 + b.group2*b.data2
+}
+
+var levelMBlocks = [...]ecBlocks{
+	1:  {10, 1, 16, 0, 0},
+	2:  {16, 1, 28, 0, 0},
+	3:  {26, 1, 44, 0, 0},
+	4:  {18, 2, 32, 0, 0},
+	5:  {24, 2, 43, 0, 0},
+	6:  {16, 4, 27, 0, 0},
+	7:  {18, 4, 31, 0, 0},
+	8:  {22, 2, 38, 2, 39},
+	9:  {22, 3, 36, 2, 37},
+	10: {26, 4, 43, 1, 44},
+	11: {30, 1, 50, 4, 51},
+	12: {22, 6, 36, 2, 37},
+	13: {22, 8, 37, 1, 38},
+	14: {24, 4, 40, 5, 41},
+	15: {24, 5, 41, 5, 42},
+}
+
+var alignmentPositions = [...][]int{
+	2:  {6, 18},
+	3:  {6, 22},
+	4:  {6, 26},
+	5:  {6, 30},
+	6:  {6, 34},
+	7:  {6, 22, 38},
+	8:  {6, 24, 42},
+	9:  {6, 26, 46},
+	10: {6, 28, 50},
+	11: {6, 30, 54},
+	12: {6, 32, 58},
+	13: {6, 34, 62},
+	14: {6, 26, 46, 66},
+	15: {6, 26, 48, 70},
+}
+
+var remainderBits = [...]int{0, 0, 7, 7, 7, 7, 7, 0, 0, 0, 0, 0, 0, 0, 3, 3}
+
+const maxVersion = 15
+
+// Encode encode the content into the smallest QR code it fits in.
+func Encode(content string) (*Code, error) {
+	data := []byte(content)
+
+	version := 0
+	for v := 1; v <= maxVersion; v++ {
+		if len(data)+byteModeOverhead(v) <= levelMBlocks[v].dataCodewords() {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	c := &Code{Version: version, Size: version*4 + 17}
+	c.modules = newGrid(c.Size)
+	c.reserved = newGrid(c.Size)
+
+	c.drawFunctionPatterns()
+	c.drawCodewords(interleave(version, encodeData(version, data)))
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormat(mask)
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		// masking twice restores the data
+		c.applyMask(mask)
+	}
+	c.applyMask(best)
+	c.drawFormat(best)
+
+	return c, nil
+}
+
+// Black report if the module at given position is dark.
+func (c *Code) Black(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y][x]
+}
+
+// Image return the code as an image, each module is scale pixels and the
+// quiet zone is four modules wide.
+func (c *Code) Image(scale int) image.Image {
+	if scale < 1 {
+		scale = 1
+	}
+	const quiet = 4
+	size := (c.Size + quiet*2) * scale
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if c.Black(x/scale-quiet, y/scale-quiet) {
+				img.SetGray(x, y, color.Gray{Y: 0})
+			} else {
+				img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	return img
+}
+
+// PNG return the png of the code.
+func (c *Code) PNG(scale int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.Image(scale)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DataURI return the png of the content as a data uri, which can be used as
+// the src of an img tag.
+func DataURI(content string, scale int) (string, error) {
+	c, err := Encode(content)
+	if err != nil {
+		return "", err
+	}
+	b, err := c.PNG(scale)
+	if err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(b), nil
+}
+
+func newGrid(size int) [][]bool {
+	grid := make([][]bool, size)
+	for i := range grid {
+		grid[i] = make([]bool, size)
+	}
+	return grid
+}
+
+func byteModeOverhead(version int) int {
+	// 4 bits of mode and 8 or 16 bits of length, rounded up to bytes
+	if version < 10 {
+		return 2
+	}
+	return 3
+}
+
+type bitBuffer struct {
+	bytes []byte
+	n     int
+}
+
+func (b *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		if b.n%8 == 0 {
+			b.bytes = append(b.bytes, 0)
+		}
+		if value>>uint(i)&1 == 1 {
+			b.bytes[b.n/8] |= 0x80 >> uint(b.n%8)
+		}
+		b.n++
+	}
+}
+
+// encodeData return the data codewords of the content in byte mode.
+func encodeData(version int, data []byte) []byte {
+	capacity := levelMBlocks[version].dataCodewords()
+
+	var b bitBuffer
+	b.append(0x4, 4)
+	if version < 10 {
+		b.append(len(data), 8)
+	} else {
+		b.append(len(data), 16)
+	}
+	for _, d := range data {
+		b.append(int(d), 8)
+	}
+
+	// terminator
+	if rest := capacity*8 - b.n; rest > 4 {
+		b.append(0, 4)
+	} else {
+		b.append(0, rest)
+	}
+	if b.n%8 != 0 {
+		b.append(0, 8-b.n%8)
+	}
+
+	for pad := 0; len(b.bytes) < capacity; pad++ {
+		if pad%2 == 0 {
+			b.bytes = append(b.bytes, 0xEC)
+		} else {
+			b.bytes = append(b.bytes, 0x11)
+		}
+	}
+
+	return b.bytes
+}
+
+// interleave split the data into the blocks, add the error correction
+// codewords and interleave them.
+func interleave(version int, data []byte) []byte {
+	blocks := levelMBlocks[version]
+
+	var dataBlocks, ecBlocks [][]byte
+	offset := 0
+	for i := 0; i < blocks.group1+blocks.group2; i++ {
+		n := blocks.data1
+		if i >= blocks.group1 {
+			n = blocks.data2
+		}
+		block := data[offset : offset+n]
+		offset += n
+		dataBlocks = append(dataBlocks, block)
+		ecBlocks = append(ecBlocks, reedSolomon(block, blocks.ecPerBlock))
+	}
+
+	res := make([]byte, 0, len(data)+len(dataBlocks)*blocks.ecPerBlock)
+	for i := 0; i < blocks.data2 || i < blocks.data1; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				res = append(res, block[i])
+			}
+		}
+	}
+	for i := 0; i < blocks.ecPerBlock; i++ {
+		for _, block := range ecBlocks {
+			res = append(res, block[i])
+		}
+	}
+	return res
+}
+
+var gfExp, gfLog [256]byte
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfLog[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11D
+		}
+	}
+	gfExp[255] = gfExp[0]
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[(int(gfLog[a])+int(gfLog[b]))%255]
+}
+
+// reedSolomon return the error correction codewords of the data.
+func reedSolomon(data []byte, n int) []byte {
+	// generator polynomial (x - a^0)(x - a^1)...(x - a^(n-1)), highest term first
+	gen := []byte{1}
+	for i := 0; i < n; i++ {
+		next := make([]byte, len(gen)+1)
+		for j, g := range gen {
+			next[j] ^= g
+			next[j+1] ^= gfMul(g, gfExp[i])
+		}
+		gen = next
+	}
+
+	rem := make([]byte, n)
+	for _, d := range data {
+		factor := d ^ rem[0]
+		copy(rem, rem[1:])
+		rem[n-1] = 0
+		for j := 0; j < n; j++ {
+			rem[j] ^= gfMul(gen[j+1], factor)
+		}
+	}
+	return rem
+}
+
+func (c *Code) set(x, y int, black bool) {
+	c.modules[y][x] = black
+	c.reserved[y][x] = true
+}
+
+func (c *Code) drawFunctionPatterns() {
+	// timing patterns
+	for i := 0; i < c.Size; i++ {
+		c.set(6, i, i%2 == 0)
+		c.set(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	if c.Version >= 2 {
+		pos := alignmentPositions[c.Version]
+		last := len(pos) - 1
+		for i, y := range pos {
+			for j, x := range pos {
+				// skip the corners of the finder patterns
+				if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+					continue
+				}
+				c.drawAlignment(x, y)
+			}
+		}
+	}
+
+	// reserve the format areas, they are drawn after masking
+	c.drawFormat(0)
+
+	if c.Version >= 7 {
+		bits := versionBits(c.Version)
+		for i := 0; i < 18; i++ {
+			black := bits>>uint(i)&1 == 1
+			a, b := c.Size-11+i%3, i/3
+			c.set(a, b, black)
+			c.set(b, a, black)
+		}
+	}
+}
+
+func (c *Code) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+				continue
+			}
+			d := abs(dx)
+			if abs(dy) > d {
+				d = abs(dy)
+			}
+			c.set(x, y, d != 2 && d != 4)
+		}
+	}
+}
+
+func (c *Code) drawAlignment(cx, cy int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			d := abs(dx)
+			if abs(dy) > d {
+				d = abs(dy)
+			}
+			c.set(cx+dx, cy+dy, d != 1)
+		}
+	}
+}
+
+// formatBits return the 15 bits format information of level M and the mask.
+func formatBits(mask int) int {
+	data := 0<<3 | mask // level M is 00
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func versionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	return version<<12 | rem
+}
+
+func (c *Code) drawFormat(mask int) {
+	bits := formatBits(mask)
+	bit := func(i int) bool {
+		return bits>>uint(i)&1 == 1
+	}
+
+	// around the top left finder
+	for i := 0; i <= 5; i++ {
+		c.set(8, i, bit(i))
+	}
+	c.set(8, 7, bit(6))
+	c.set(8, 8, bit(7))
+	c.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.set(14-i, 8, bit(i))
+	}
+
+	// split between the other two finders
+	for i := 0; i < 8; i++ {
+		c.set(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.set(8, c.Size-15+i, bit(i))
+	}
+	// the dark module
+	c.set(8, c.Size-8, true)
+}
+
+// drawCodewords place the codewords in the zigzag order.
+func (c *Code) drawCodewords(codewords []byte) {
+	total := len(codewords)*8 + remainderBits[c.Version]
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				upward := (right+1)&2 == 0
+				y := vert
+				if upward {
+					y = c.Size - 1 - vert
+				}
+				if c.reserved[y][x] || i >= total {
+					continue
+				}
+				if i < len(codewords)*8 {
+					c.modules[y][x] = codewords[i>>3]>>uint(7-i&7)&1 == 1
+				}
+				i++
+			}
+		}
+	}
+}
+
+func maskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.reserved[y][x] && maskBit(mask, x, y) {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty score the code with the rules of the standard, the mask of the
+// lowest score is used.
+func (c *Code) penalty() int {
+	score := 0
+	size := c.Size
+	at := func(x, y int, horizontal bool) bool {
+		if horizontal {
+			return c.modules[y][x]
+		}
+		return c.modules[x][y]
+	}
+
+	for _, horizontal := range []bool{true, false} {
+		for a := 0; a < size; a++ {
+			// runs of five or more modules of the same color
+			run := 1
+			for b := 1; b < size; b++ {
+				if at(b, a, horizontal) == at(b-1, a, horizontal) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += run - 2
+				}
+				run = 1
+			}
+			if run >= 5 {
+				score += run - 2
+			}
+
+			// finder like patterns
+			for b := 0; b+10 < size; b++ {
+				pattern := [11]bool{}
+				for k := 0; k < 11; k++ {
+					pattern[k] = at(b+k, a, horizontal)
+				}
+				if pattern == [11]bool{true, false, true, true, true, false, true, false, false, false, false} ||
+					pattern == [11]bool{false, false, false, false, true, false, true, true, true, false, true} {
+					score += 40
+				}
+			}
+		}
+	}
+
+	// 2x2 blocks of the same color
+	dark := 0
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x+1 < size && y+1 < size {
+				v := c.modules[y][x]
+				if c.modules[y][x+1] == v && c.modules[y+1][x] == v && c.modules[y+1][x+1] == v {
+					score += 3
+				}
+			}
+		}
+	}
+
+	// balance of dark and light modules
+	percent := dark * 100 / (size * size)
+	deviation := abs(percent-50) / 5
+	score += deviation * 10
+
+	return score
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
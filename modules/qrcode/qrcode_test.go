@@ -0,0 +1,104 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// "HELLO WORLD" in alphanumeric mode, version 1-M
+	data := []byte{0x20, 0x5B, 0x0B, 0x78, 0xD1, 0x72, 0xDC, 0x4D, 0x43, 0x40, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11}
+	ec := []byte{0xC4, 0x23, 0x27, 0x77, 0xEB, 0xD7, 0xE7, 0xE2, 0x5D, 0x17}
+	assert.Equal(t, reedSolomon(data, 10), ec)
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	assert.Equal(t, formatBits(0), 0x5412)
+	assert.Equal(t, formatBits(1), 0x5125)
+	assert.Equal(t, versionBits(7), 0x07C94)
+}
+
+func TestEncode(t *testing.T) {
+	content := "otpauth://totp/GoAdmin:admin?secret=JBSWY3DPEHPK3PXP&issuer=GoAdmin"
+
+	c, err := Encode(content)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, c.Version, 5)
+	assert.Equal(t, c.Size, 37)
+
+	// finder patterns
+	for _, corner := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+		assert.Equal(t, c.Black(corner[0], corner[1]), true)
+		assert.Equal(t, c.Black(corner[0]+1, corner[1]+1), false)
+		assert.Equal(t, c.Black(corner[0]+3, corner[1]+3), true)
+	}
+
+	// read the codewords back from the grid
+	mask := -1
+	for m := 0; m < 8; m++ {
+		bits := formatBits(m)
+		ok := true
+		for i := 0; i <= 5; i++ {
+			ok = ok && c.Black(8, i) == (bits>>uint(i)&1 == 1)
+		}
+		if ok {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		t.Fatal("format information not found")
+	}
+	c.applyMask(mask)
+	defer c.applyMask(mask)
+
+	want := interleave(c.Version, encodeData(c.Version, []byte(content)))
+	got := make([]byte, len(want))
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if c.reserved[y][x] || i >= len(want)*8 {
+					continue
+				}
+				if c.modules[y][x] {
+					got[i>>3] |= 0x80 >> uint(i&7)
+				}
+				i++
+			}
+		}
+	}
+	assert.Equal(t, got, want)
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	_, err := Encode(strings.Repeat("a", 500))
+	assert.Equal(t, err, ErrTooLong)
+
+	c, err := Encode(strings.Repeat("a", 200))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, c.Version >= 7, true)
+}
+
+func TestDataURI(t *testing.T) {
+	uri, err := DataURI("hello", 4)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, strings.HasPrefix(uri, "data:image/png;base64,"), true)
+
+	c, _ := Encode("hello")
+	b, err := c.PNG(4)
+	assert.Equal(t, err, nil)
+	img, err := png.Decode(bytes.NewReader(b))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, img.Bounds().Dx(), (c.Size+8)*4)
+}
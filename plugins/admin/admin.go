@@ -1,12 +1,21 @@
 package admin
 
 import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/auth"
 	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/file"
+	"github.com/wowucco/go-admin/modules/logger"
 	"github.com/wowucco/go-admin/modules/service"
 	"github.com/wowucco/go-admin/plugins"
 	"github.com/wowucco/go-admin/plugins/admin/controller"
 	"github.com/wowucco/go-admin/plugins/admin/models"
 	"github.com/wowucco/go-admin/plugins/admin/modules/guard"
+	"github.com/wowucco/go-admin/plugins/admin/modules/oplog"
 	"github.com/wowucco/go-admin/plugins/admin/modules/table"
 	"github.com/wowucco/go-admin/template/types"
 	_ "github.com/wowucco/go-admin/template/types/display"
@@ -18,6 +27,9 @@ type Admin struct {
 	tableList table.GeneratorList
 	guardian  *guard.Guard
 	handler   *controller.Handler
+
+	syncCfg    *table.SyncConfig
+	syncReport table.SyncReport
 }
 
 // InitPlugin implements Plugin.InitPlugin.
@@ -28,15 +40,38 @@ func (admin *Admin) InitPlugin(services service.List) {
 	admin.InitBase(services)
 
 	c := config.GetService(services.Get("config"))
-	st := table.NewSystemTable(admin.Conn, c)
+
+	if err := auth.InitSessionStore(admin.Conn); err != nil {
+		logger.Error("init session store error: ", err)
+		panic(err)
+	}
+
+	if err := auth.GetTokenService(services.Get(auth.TokenServiceKey)).InitStore(); err != nil {
+		panic(err)
+	}
+
+	oplog.StartCleaner(admin.Conn)
+
+	// the image variants are linked as recorded by the media library
+	file.SetVariantStore(models.Media().SetConn(admin.Conn))
+
+	// only the generators of the users are synced, not the system tables
+	if admin.syncCfg != nil {
+		admin.syncGenerators(*admin.syncCfg)
+	}
+
+	st := table.NewSystemTable(admin.Conn, c).SetGenerators(admin.tableList)
 	admin.tableList.Combine(table.GeneratorList{
-		"manager":        st.GetManagerTable,
-		"permission":     st.GetPermissionTable,
-		"roles":          st.GetRolesTable,
-		"op":             st.GetOpTable,
-		"menu":           st.GetMenuTable,
-		"normal_manager": st.GetNormalManagerTable,
-		"site":           st.GetSiteTable,
+		"manager":         st.GetManagerTable,
+		"permission":      st.GetPermissionTable,
+		"roles":           st.GetRolesTable,
+		"op":              st.GetOpTable,
+		"menu":            st.GetMenuTable,
+		"normal_manager":  st.GetNormalManagerTable,
+		"site":            st.GetSiteTable,
+		"media":           st.GetMediaTable,
+		"login_throttle":  st.GetLoginThrottleTable,
+		"change_requests": st.GetChangeRequestTable,
 	})
 	admin.guardian = guard.New(admin.Services, admin.Conn, admin.tableList)
 	handlerCfg := controller.Config{
@@ -85,6 +120,49 @@ func (admin *Admin) AddGenerators(gen ...table.GeneratorList) *Admin {
 	return admin
 }
 
+// CleanMedia delete the uploaded files which no record references and are
+// older than the grace period, it can be run by a cron job for example. The
+// generators are called with an empty request, they should not depend on it.
+func (admin *Admin) CleanMedia(grace time.Duration) ([]string, error) {
+	ctx := context.NewContext(&http.Request{URL: &url.URL{}, Header: make(http.Header)})
+	return table.CleanMedia(ctx, admin.Conn, admin.tableList, grace)
+}
+
+// SyncGenerators enable the sync of the generators on startup, which
+// creates the standard permissions and a menu of every generator.
+func (admin *Admin) SyncGenerators(cfg ...table.SyncConfig) *Admin {
+	syncCfg := table.SyncConfig{}
+	if len(cfg) > 0 {
+		syncCfg = cfg[0]
+	}
+	admin.syncCfg = &syncCfg
+	return admin
+}
+
+// GeneratorSyncReport return the report of the sync of the generators on
+// startup.
+func (admin *Admin) GeneratorSyncReport() table.SyncReport {
+	return admin.syncReport
+}
+
+func (admin *Admin) syncGenerators(cfg table.SyncConfig) {
+	ctx := context.NewContext(&http.Request{URL: &url.URL{}, Header: make(http.Header)})
+	report := table.SyncGenerators(ctx, admin.Conn, admin.tableList, cfg)
+	admin.syncReport = report
+	for _, err := range report.Failed {
+		logger.Error("sync generators error: ", err)
+	}
+	for _, item := range report.Created {
+		logger.Info("sync generators: created ", item)
+	}
+	for _, item := range report.Updated {
+		logger.Info("sync generators: updated ", item)
+	}
+	for _, item := range report.Drifted {
+		logger.Warn("sync generators: kept the edited ", item)
+	}
+}
+
 // AddGlobalDisplayProcessFn call types.AddGlobalDisplayProcessFn
 func (admin *Admin) AddGlobalDisplayProcessFn(f types.DisplayProcessFn) *Admin {
 	types.AddGlobalDisplayProcessFn(f)
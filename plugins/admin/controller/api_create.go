@@ -2,7 +2,6 @@ package controller
 
 import (
 	"github.com/wowucco/go-admin/context"
-	"github.com/wowucco/go-admin/modules/file"
 	"github.com/wowucco/go-admin/plugins/admin/modules/constant"
 	"github.com/wowucco/go-admin/plugins/admin/modules/guard"
 	"github.com/wowucco/go-admin/plugins/admin/modules/response"
@@ -11,8 +10,16 @@ import (
 func (h *Handler) ApiCreate(ctx *context.Context) {
 	param := guard.GetNewFormParam(ctx)
 
+	chunked := h.applyChunkUploads(ctx, param.Prefix, param.Panel, param.MultiForm)
+	defer chunked.close()
+
+	if _, err := h.applyMediaPicks(ctx, param.Panel, param.MultiForm); err != nil {
+		response.Error(ctx, err.Error())
+		return
+	}
+
 	if len(param.MultiForm.File) > 0 {
-		err := file.GetFileEngine(h.config.FileUploadEngine.Name).Upload(param.MultiForm)
+		err := h.upload(ctx, param.Panel, param.MultiForm)
 		if err != nil {
 			response.Error(ctx, err.Error())
 			return
@@ -20,6 +27,7 @@ func (h *Handler) ApiCreate(ctx *context.Context) {
 	}
 
 	err := param.Panel.InsertData(param.Value())
+	chunked.done(err)
 	if err != nil {
 		response.Error(ctx, err.Error())
 		return
@@ -42,7 +50,7 @@ func (h *Handler) ApiCreateForm(ctx *context.Context) {
 
 	referer := ctx.Headers("Referer")
 
-	if referer != "" && !isInfoUrl(referer) && !isNewUrl(referer, ctx.Query(constant.PrefixKey)) {
+	if referer != "" && !isInfoUrl(referer) && !isNewUrl(referer, ctx.Param(constant.PrefixKey)) {
 		infoUrl = referer
 	}
 
@@ -58,7 +66,7 @@ func (h *Handler) ApiCreateForm(ctx *context.Context) {
 		"header": f.HeaderHtml,
 		"footer": f.FooterHtml,
 		"prefix": h.config.PrefixFixSlash(),
-		"token":  h.authSrv().AddToken(),
+		"token":  h.authSrv().AddSessionToken(ctx),
 		"operation_footer": formFooter("new", f.IsHideContinueEditCheckBox, f.IsHideContinueNewCheckBox,
 			f.IsHideResetButton),
 	})
@@ -8,7 +8,7 @@ import (
 )
 
 func (h *Handler) ApiList(ctx *context.Context) {
-	prefix := ctx.Query(constant.PrefixKey)
+	prefix := ctx.Param(constant.PrefixKey)
 
 	panel := h.table(prefix, ctx)
 
@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/auth"
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/language"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+	"github.com/wowucco/go-admin/plugins/admin/modules/response"
+	"github.com/wowucco/go-admin/template/types"
+)
+
+const apiTokenNameMaxLength = 100
+
+// ShowAPITokens show the personal api tokens of the login user and the form
+// to create one.
+func (h *Handler) ShowAPITokens(ctx *context.Context) {
+
+	user := auth.Auth(ctx)
+	tokens := models.APIToken().SetConn(h.conn).ListByUserId(user.Id)
+
+	listBox := aBox().
+		WithHeadBorder().
+		SetHeader(template.HTML("<b>" + language.Get("api tokens") + "</b>")).
+		SetBody(apiTokenList(tokens, time.Now())).
+		GetContent()
+
+	newBox := aBox().
+		WithHeadBorder().
+		SetHeader(template.HTML("<b>" + language.Get("new api token") + "</b>")).
+		SetBody(apiTokenNewContent() + apiTokenScript(h.config.Url("/tokens"))).
+		GetContent()
+
+	h.HTML(ctx, user, types.Panel{
+		Content: aRow().SetContent(
+			aCol().SetSize(types.SizeMD(8)).SetContent(listBox).GetContent() +
+				aCol().SetSize(types.SizeMD(4)).SetContent(newBox).GetContent()).GetContent(),
+		Title:       template.HTML(language.Get("api tokens")),
+		Description: template.HTML(language.Get("api tokens")),
+	})
+}
+
+// NewAPIToken create a token of the login user and return it, the token is
+// shown only once.
+func (h *Handler) NewAPIToken(ctx *context.Context) {
+
+	if denyImpersonated(ctx) {
+		return
+	}
+
+	user := auth.Auth(ctx)
+
+	name := strings.TrimSpace(ctx.FormValue("name"))
+	if name == "" || len([]rune(name)) > apiTokenNameMaxLength {
+		response.BadRequest(ctx, "wrong api token name")
+		return
+	}
+
+	scopes, err := auth.ParseAPIScopes(ctx.FormValue("scopes"))
+	if err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	expiresAt := int64(0)
+	if days := strings.TrimSpace(ctx.FormValue("expire_days")); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			response.BadRequest(ctx, "wrong api token expire days")
+			return
+		}
+		if n > 0 {
+			expiresAt = time.Now().Add(time.Duration(n) * 24 * time.Hour).Unix()
+		}
+	}
+
+	token, _, err := auth.NewAPIToken(user.Id, name, scopes, expiresAt, h.conn)
+	if err != nil {
+		response.Error(ctx, err.Error())
+		return
+	}
+
+	response.OkWithData(ctx, map[string]interface{}{
+		"token": token,
+	})
+}
+
+// RevokeAPIToken revoke a token of the login user.
+func (h *Handler) RevokeAPIToken(ctx *context.Context) {
+
+	if denyImpersonated(ctx) {
+		return
+	}
+
+	user := auth.Auth(ctx)
+
+	t := models.APIToken().SetConn(h.conn).Find(ctx.FormValue("id"))
+	if t.IsEmpty() || t.UserId != user.Id {
+		response.BadRequest(ctx, "wrong api token")
+		return
+	}
+
+	if !t.IsRevoked() {
+		if _, err := t.Revoke(time.Now().Unix()); db.CheckError(err, db.UPDATE) {
+			response.Error(ctx, err.Error())
+			return
+		}
+	}
+
+	response.Ok(ctx)
+}
+
+func apiTokenList(tokens []models.APITokenModel, now time.Time) template.HTML {
+	if len(tokens) == 0 {
+		return template.HTML("<p>" + language.Get("no api token") + "</p>")
+	}
+
+	var rows strings.Builder
+	for _, t := range tokens {
+		status := `<span class="label label-success">` + language.Get("active") + `</span>`
+		action := fmt.Sprintf(`<button class="btn btn-xs btn-danger api-token-revoke" data-id="%d">%s</button>`,
+			t.Id, language.Get("revoke"))
+		switch {
+		case t.IsRevoked():
+			status = `<span class="label label-default">` + language.Get("revoked") + `</span>`
+			action = ""
+		case t.IsExpired(now):
+			status = `<span class="label label-warning">` + language.Get("expired") + `</span>`
+			action = ""
+		}
+
+		lastUsed := formatUnix(t.LastUsedAt, "-")
+		if t.LastUsedIp != "" {
+			lastUsed += " (" + template.HTMLEscapeString(t.LastUsedIp) + ")"
+		}
+
+		rows.WriteString(fmt.Sprintf(`<tr><td>%s</td><td><code>%s</code></td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>`,
+			template.HTMLEscapeString(t.Name),
+			strings.Replace(template.HTMLEscapeString(t.Scopes), "\n", "<br>", -1),
+			template.HTMLEscapeString(t.CreatedAt),
+			formatUnix(t.ExpiresAt, language.Get("never")),
+			lastUsed,
+			status,
+			action))
+	}
+
+	return template.HTML(fmt.Sprintf(`<table class="table table-striped">
+	<thead><tr><th>%s</th><th>%s</th><th>%s</th><th>%s</th><th>%s</th><th>%s</th><th></th></tr></thead>
+	<tbody>%s</tbody>
+</table>`,
+		language.Get("name"),
+		language.Get("api token scopes"),
+		language.Get("createdat"),
+		language.Get("expires at"),
+		language.Get("last used"),
+		language.Get("status"),
+		rows.String()))
+}
+
+func apiTokenNewContent() template.HTML {
+	return template.HTML(fmt.Sprintf(`<form class="api-token-form">
+	<div class="form-group">
+		<label>%s</label>
+		<input type="text" name="name" class="form-control" maxlength="%d">
+	</div>
+	<div class="form-group">
+		<label>%s</label>
+		<textarea name="scopes" class="form-control" rows="4" placeholder="posts:GET,POST&#10;users:GET"></textarea>
+		<span class="help-block">%s</span>
+	</div>
+	<div class="form-group">
+		<label>%s</label>
+		<input type="number" name="expire_days" class="form-control" min="0" value="90">
+		<span class="help-block">%s</span>
+	</div>
+	<button type="submit" class="btn btn-primary">%s</button>
+</form>
+<div class="api-token-created" style="display:none;">
+	<p>%s</p>
+	<pre></pre>
+	<button type="button" class="btn btn-default api-token-done">%s</button>
+</div>`,
+		language.Get("name"),
+		apiTokenNameMaxLength,
+		language.Get("api token scopes"),
+		language.Get("a table prefix and its methods per line, * for all the tables"),
+		language.Get("expire days"),
+		language.Get("0 is never"),
+		language.Get("create"),
+		language.Get("copy the token now, it will not be shown again"),
+		language.Get("continue")))
+}
+
+func apiTokenScript(prefix string) template.HTML {
+	return template.HTML(`<script>
+function apiTokenError(data) {
+	let msg = data.responseJSON ? data.responseJSON.msg : data.responseText;
+	if (typeof(swal) === "function") {
+		swal(msg, "", "error");
+	} else {
+		alert(msg);
+	}
+}
+$(".api-token-form").on("submit", function (e) {
+	e.preventDefault();
+	let form = $(this);
+	$.ajax({
+		method: "post",
+		url: "` + prefix + `/new",
+		data: form.serialize(),
+		success: function (data) {
+			form.hide();
+			let box = form.siblings(".api-token-created");
+			box.find("pre").text(data.data.token);
+			box.show();
+		},
+		error: apiTokenError
+	});
+});
+$(".api-token-done").on("click", function () {
+	$.pjax.reload("#pjax-container");
+});
+$(".api-token-revoke").on("click", function () {
+	$.ajax({
+		method: "post",
+		url: "` + prefix + `/revoke",
+		data: {id: $(this).data("id")},
+		success: function () {
+			$.pjax.reload("#pjax-container");
+		},
+		error: apiTokenError
+	});
+});
+</script>`)
+}
+
+func formatUnix(sec int64, zero string) string {
+	if sec <= 0 {
+		return zero
+	}
+	return time.Unix(sec, 0).Format("2006-01-02 15:04:05")
+}
@@ -3,7 +3,6 @@ package controller
 import (
 	"github.com/wowucco/go-admin/context"
 	"github.com/wowucco/go-admin/modules/auth"
-	"github.com/wowucco/go-admin/modules/file"
 	"github.com/wowucco/go-admin/plugins/admin/modules"
 	"github.com/wowucco/go-admin/plugins/admin/modules/constant"
 	"github.com/wowucco/go-admin/plugins/admin/modules/guard"
@@ -15,8 +14,17 @@ import (
 func (h *Handler) ApiUpdate(ctx *context.Context) {
 	param := guard.GetEditFormParam(ctx)
 
+	chunked := h.applyChunkUploads(ctx, param.Prefix, param.Panel, param.MultiForm)
+	defer chunked.close()
+
+	picked, err := h.applyMediaPicks(ctx, param.Panel, param.MultiForm)
+	if err != nil {
+		response.Error(ctx, err.Error())
+		return
+	}
+
 	if len(param.MultiForm.File) > 0 {
-		err := file.GetFileEngine(h.config.FileUploadEngine.Name).Upload(param.MultiForm)
+		err := h.upload(ctx, param.Panel, param.MultiForm)
 		if err != nil {
 			response.Error(ctx, err.Error())
 			return
@@ -25,13 +33,14 @@ func (h *Handler) ApiUpdate(ctx *context.Context) {
 
 	for _, field := range param.Panel.GetForm().FieldList {
 		if field.FormType == form.File &&
-			len(param.MultiForm.File[field.Field]) == 0 &&
+			len(param.MultiForm.File[field.Field]) == 0 && !chunked.fields[field.Field] && !picked[field.Field] &&
 			param.MultiForm.Value[field.Field+"__delete_flag"][0] != "1" {
 			delete(param.MultiForm.Value, field.Field)
 		}
 	}
 
-	err := param.Panel.UpdateData(param.Value())
+	err = param.Panel.UpdateData(param.Value())
+	chunked.done(err)
 	if err != nil {
 		response.Error(ctx, err.Error())
 		return
@@ -65,7 +74,7 @@ func (h *Handler) ApiUpdateForm(ctx *context.Context) {
 	}
 
 	infoUrl := h.routePathWithPrefix("api_info", prefix) + param.DeleteField(constant.EditPKKey).GetRouteParamStr()
-	editUrl := h.routePathWithPrefix("api_edit", prefix)
+	editUrl := h.routePathWithPrefix("api_update", prefix)
 
 	f := panel.GetForm()
 
@@ -79,7 +88,7 @@ func (h *Handler) ApiUpdateForm(ctx *context.Context) {
 		"header": f.HeaderHtml,
 		"footer": f.FooterHtml,
 		"prefix": h.config.PrefixFixSlash(),
-		"token":  h.authSrv().AddToken(),
+		"token":  h.authSrv().AddSessionToken(ctx),
 		"operation_footer": formFooter(footerKind, f.IsHideContinueEditCheckBox, f.IsHideContinueNewCheckBox,
 			f.IsHideResetButton),
 	})
@@ -7,6 +7,7 @@ import (
 	"github.com/wowucco/go-admin/modules/config"
 	"github.com/wowucco/go-admin/modules/db"
 	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/modules/oidc"
 	"github.com/wowucco/go-admin/modules/system"
 	"github.com/wowucco/go-admin/plugins/admin/models"
 	"github.com/wowucco/go-admin/plugins/admin/modules/captcha"
@@ -26,8 +27,17 @@ func (h *Handler) Auth(ctx *context.Context) {
 		ok       bool
 		errMsg   = "fail"
 		s, exist = h.services.GetOrNot(auth.ServiceKey)
+		username = ctx.FormValue("username")
+		ip       = auth.LoginIP(ctx)
 	)
 
+	// the locked attempts are rejected before the password is checked, and
+	// are not counted
+	if auth.LoginLockout(username, ip, h.conn) > 0 {
+		response.TooManyRequests(ctx, "too many failed logins, please try again later")
+		return
+	}
+
 	if capDriver, ok := h.captchaConfig["driver"]; ok {
 		capt, ok := captcha.Get(capDriver)
 
@@ -41,7 +51,6 @@ func (h *Handler) Auth(ctx *context.Context) {
 
 	if !exist {
 		password := ctx.FormValue("password")
-		username := ctx.FormValue("username")
 
 		if password == "" || username == "" {
 			response.BadRequest(ctx, "wrong password or username")
@@ -53,10 +62,51 @@ func (h *Handler) Auth(ctx *context.Context) {
 	}
 
 	if !ok {
+		auth.LoginFailed(username, ip, h.conn)
 		response.BadRequest(ctx, errMsg)
 		return
 	}
 
+	// the expired password is changed with a reset token before the login,
+	// the passwords of the custom processors and the directory are not kept
+	// here
+	expired := !exist && !config.GetLdap().Enable && auth.PasswordExpired(user, h.conn)
+
+	tf := models.TwoFactor().SetConn(h.conn).FindByUserId(user.Id)
+
+	// the cookie, or the reset token of the expired password, is given by
+	// AuthTwoFactor after the code is checked, the failures are reset by it
+	// too
+	if tf.Enabled {
+		token := auth.GetTwoFactorChallenges().Add(user.Id)
+		if expired {
+			token = auth.GetTwoFactorChallenges().AddPasswordExpired(user.Id)
+		}
+		response.OkWithData(ctx, map[string]interface{}{
+			"two_factor": true,
+			"token":      token,
+		})
+		return
+	}
+
+	auth.LoginSucceeded(username, h.conn)
+
+	if expired {
+		h.passwordExpired(ctx, user)
+		return
+	}
+
+	if user.SetConn(h.conn).IsTwoFactorRequired() {
+		if err := auth.SetTwoFactorSetupCookie(ctx, user, h.conn); err != nil {
+			response.Error(ctx, err.Error())
+			return
+		}
+		response.OkWithData(ctx, map[string]interface{}{
+			"url": h.config.Url("/2fa"),
+		})
+		return
+	}
+
 	err := auth.SetCookie(ctx, user, h.conn)
 
 	if err != nil {
@@ -64,6 +114,26 @@ func (h *Handler) Auth(ctx *context.Context) {
 		return
 	}
 
+	h.loginRedirect(ctx)
+}
+
+// passwordExpired respond a reset token to change the expired password of
+// the user, who has passed all the factors of the login.
+func (h *Handler) passwordExpired(ctx *context.Context, user models.UserModel) {
+	token, err := auth.NewPasswordReset(user.Id, h.conn)
+	if err != nil {
+		response.Error(ctx, err.Error())
+		return
+	}
+	response.OkWithData(ctx, map[string]interface{}{
+		"password_expired": true,
+		"token":            token,
+	})
+}
+
+// loginRedirect respond the url to go after the login, which is the "ref"
+// query of the login page when it has one.
+func (h *Handler) loginRedirect(ctx *context.Context) {
 	if ref := ctx.Headers("Referer"); ref != "" {
 		if u, err := url.Parse(ref); err == nil {
 			v := u.Query()
@@ -80,8 +150,6 @@ func (h *Handler) Auth(ctx *context.Context) {
 	response.OkWithData(ctx, map[string]interface{}{
 		"url": h.config.GetIndexURL(),
 	})
-	return
-
 }
 
 // Logout delete the cookie.
@@ -94,21 +162,46 @@ func (h *Handler) Logout(ctx *context.Context) {
 	ctx.SetStatusCode(302)
 }
 
+type loginProvider struct {
+	Title string
+	URL   string
+}
+
 // ShowLogin show the login page.
 func (h *Handler) ShowLogin(ctx *context.Context) {
 
+	query := ""
+	if ref := ctx.Query("ref"); isLocalURL(ref) {
+		query = "?ref=" + url.QueryEscape(ref)
+	}
+
+	providers := make([]loginProvider, 0)
+	for _, p := range oidc.List() {
+		providers = append(providers, loginProvider{
+			Title: p.Title,
+			URL:   h.config.Url("/oidc/"+url.PathEscape(p.Name)+"/login") + query,
+		})
+	}
+
 	tmpl, name := template.GetComp("login").GetTemplate()
 	buf := new(bytes.Buffer)
 	if err := tmpl.ExecuteTemplate(buf, name, struct {
-		UrlPrefix string
-		Title     string
-		Logo      template2.HTML
-		CdnUrl    string
-		System    types.SystemInfo
+		UrlPrefix     string
+		Title         string
+		Logo          template2.HTML
+		CdnUrl        string
+		System        types.SystemInfo
+		Providers     []loginProvider
+		SSOError      bool
+		PasswordReset bool
 	}{
 		UrlPrefix: h.config.AssertPrefix(),
 		Title:     h.config.LoginTitle,
 		Logo:      h.config.LoginLogo,
+		Providers: providers,
+		SSOError:  ctx.Query("sso_error") != "",
+		// the reset form is always in the page for the expired passwords
+		PasswordReset: passwordResetEnabled(),
 		System: types.SystemInfo{
 			Version: system.Version(),
 		},
@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"mime/multipart"
+	"strconv"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/auth"
+	"github.com/wowucco/go-admin/modules/file"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/plugins/admin/modules/constant"
+	"github.com/wowucco/go-admin/plugins/admin/modules/response"
+	"github.com/wowucco/go-admin/plugins/admin/modules/table"
+	"github.com/wowucco/go-admin/template/types"
+)
+
+// ChunkUploadInit start a resumable upload of a file field of the table.
+func (h *Handler) ChunkUploadInit(ctx *context.Context) {
+
+	field, ok := h.chunkUploadField(ctx, ctx.FormValue("field"))
+	if !ok {
+		response.BadRequest(ctx, "wrong field")
+		return
+	}
+
+	size, err := strconv.ParseInt(ctx.FormValue("size"), 10, 64)
+	if err != nil {
+		response.BadRequest(ctx, "wrong size")
+		return
+	}
+
+	chunkSize, err := strconv.ParseInt(ctx.FormValue("chunk_size"), 10, 64)
+	if err != nil {
+		response.BadRequest(ctx, "wrong size")
+		return
+	}
+
+	// check the size early instead of after the whole file is sent
+	if field.UploadRule.MaxSize > 0 && size > field.UploadRule.MaxSize {
+		response.BadRequest(ctx, file.RuleError{
+			Filename: ctx.FormValue("filename"),
+			Msg:      "file is too large, the max size is",
+			Limit:    file.FormatSize(field.UploadRule.MaxSize),
+		}.Error())
+		return
+	}
+
+	sess, err := file.GetChunkStore().Init(ctx.Param(constant.PrefixKey), field.Field, auth.Auth(ctx).Id,
+		ctx.FormValue("filename"), size, chunkSize, ctx.FormValue("checksum"))
+	if err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	response.OkWithData(ctx, map[string]interface{}{
+		"upload_id":   sess.ID,
+		"chunk_count": sess.ChunkCount(),
+	})
+}
+
+// ChunkUploadStatus return the received chunks of an upload, so the client
+// can resume the upload and send the missing chunks only.
+func (h *Handler) ChunkUploadStatus(ctx *context.Context) {
+
+	sess, err := h.chunkSession(ctx, ctx.Query("upload_id"))
+	if err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	received, err := file.GetChunkStore().Received(sess.ID)
+	if err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	response.OkWithData(ctx, map[string]interface{}{
+		"received": received,
+	})
+}
+
+// ChunkUpload receive one chunk of an upload in the request body, the chunk
+// index and its sha256 checksum are given in the query.
+func (h *Handler) ChunkUpload(ctx *context.Context) {
+
+	index, err := strconv.Atoi(ctx.Query("index"))
+	if err != nil {
+		response.BadRequest(ctx, file.ErrChunkIndex.Error())
+		return
+	}
+
+	sess, err := h.chunkSession(ctx, ctx.Query("upload_id"))
+	if err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	err = file.GetChunkStore().Put(sess.ID, index, ctx.Query("checksum"), ctx.Request.Body)
+	if err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	response.Ok(ctx)
+}
+
+// ChunkUploadComplete assemble the chunks, check the upload rules of the field
+// and upload the file with the configured file upload engine. It returns the
+// stored path, which the form posts as "<field>__chunk_path".
+func (h *Handler) ChunkUploadComplete(ctx *context.Context) {
+
+	var (
+		store = file.GetChunkStore()
+		id    = ctx.FormValue("upload_id")
+	)
+
+	sess, err := h.chunkSession(ctx, id)
+	if err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	if _, ok := h.chunkUploadField(ctx, sess.Field); !ok {
+		response.BadRequest(ctx, "wrong field")
+		return
+	}
+
+	multiForm, err := store.Assemble(id)
+	if err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	defer func() {
+		_ = multiForm.RemoveAll()
+	}()
+
+	panel := h.table(sess.Prefix, ctx)
+
+	if err := panel.GetForm().ValidateUpload(multiForm); err != nil {
+		_ = store.Remove(id)
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	// keep the chunks when the uploader fails, so the client can retry
+	if err := h.storeUpload(ctx, multiForm); err != nil {
+		logger.Error("chunk upload error: ", err)
+		response.Error(ctx, err.Error())
+		return
+	}
+
+	_ = store.Remove(id)
+
+	path := multiForm.Value[sess.Field][0]
+	if err := store.MarkUploaded(sess, path); err != nil {
+		logger.Error("chunk upload error: ", err)
+		response.Error(ctx, err.Error())
+		return
+	}
+
+	response.OkWithData(ctx, map[string]interface{}{
+		"path": path,
+		"url":  file.URL(path),
+	})
+}
+
+// chunkSession return the upload session of given id, which must be started
+// by the user of the request for the table of the request.
+func (h *Handler) chunkSession(ctx *context.Context, id string) (file.ChunkSession, error) {
+	sess, err := file.GetChunkStore().Get(id)
+	if err != nil {
+		return sess, err
+	}
+	if sess.Prefix != ctx.Param(constant.PrefixKey) || sess.UserId != auth.Auth(ctx).Id {
+		return file.ChunkSession{}, file.ErrChunkSessionNotFound
+	}
+	return sess, nil
+}
+
+func (h *Handler) chunkUploadField(ctx *context.Context, name string) (types.FormField, bool) {
+	panel := h.table(ctx.Param(constant.PrefixKey), ctx)
+	for _, field := range panel.GetForm().FieldList {
+		if field.Field == name && field.FormType.IsFile() {
+			return field, true
+		}
+	}
+	return types.FormField{}, false
+}
+
+// chunkClaims are the finished chunked uploads claimed by a form post. They
+// are consumed when the form is stored, and released otherwise so the form
+// can be posted again.
+type chunkClaims struct {
+	fields map[string]bool
+	paths  []string
+	stored bool
+}
+
+// done record the error of storing the form, a change which waits for
+// approval keeps the paths.
+func (c *chunkClaims) done(err error) {
+	c.stored = err == nil || err == table.ErrChangeRequested
+}
+
+func (c *chunkClaims) close() {
+	store := file.GetChunkStore()
+	for _, path := range c.paths {
+		if c.stored {
+			store.ConsumeUploaded(path)
+		} else {
+			store.ReleaseUploaded(path)
+		}
+	}
+}
+
+// applyChunkUploads put the paths of the finished chunked uploads of the user
+// for the table of the prefix posted with the form into their file fields.
+// The caller should call close of the claims when done.
+func (h *Handler) applyChunkUploads(ctx *context.Context, prefix string, panel table.Table,
+	multiForm *multipart.Form) *chunkClaims {
+	var (
+		claims = &chunkClaims{fields: make(map[string]bool)}
+		store  = file.GetChunkStore()
+		userId = auth.Auth(ctx).Id
+	)
+
+	for _, field := range panel.GetForm().FieldList {
+		key := field.Field + file.ChunkPathSuffix
+		paths := multiForm.Value[key]
+		delete(multiForm.Value, key)
+
+		for _, path := range paths {
+			if path == "" || !store.ClaimUploaded(prefix, field.Field, userId, path) {
+				continue
+			}
+			claims.paths = append(claims.paths, path)
+			if !claims.fields[field.Field] {
+				multiForm.Value[field.Field] = nil
+				claims.fields[field.Field] = true
+			}
+			multiForm.Value[field.Field] = append(multiForm.Value[field.Field], path)
+		}
+	}
+
+	return claims
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/wowucco/go-admin/modules/auth"
 	c "github.com/wowucco/go-admin/modules/config"
 	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/file"
 	"github.com/wowucco/go-admin/modules/language"
 	"github.com/wowucco/go-admin/modules/menu"
 	"github.com/wowucco/go-admin/modules/service"
@@ -17,6 +18,7 @@ import (
 	"github.com/wowucco/go-admin/template/icon"
 	"github.com/wowucco/go-admin/template/types"
 	template2 "html/template"
+	"mime/multipart"
 	"net/http"
 	"regexp"
 	"strings"
@@ -76,6 +78,9 @@ func (h *Handler) SetRoutes(r context.RouterMap) {
 
 func (h *Handler) table(prefix string, ctx *context.Context) table.Table {
 	t := h.generators[prefix](ctx)
+	if user, ok := ctx.User().(models.UserModel); ok {
+		t = table.ForRequest(t, prefix, user)
+	}
 	authHandler := auth.Middleware(db.GetConnection(h.services))
 	for _, cb := range t.GetInfo().Callbacks {
 		if cb.Value[constant.ContextNodeNeedAuth] == 1 {
@@ -201,10 +206,42 @@ func (h *Handler) authSrv() *auth.TokenService {
 	return auth.GetTokenService(h.services.Get(auth.TokenServiceKey))
 }
 
+// upload validates the files of the form against the upload rules of the
+// panel, saves them with the configured file upload engine and records them
+// in the media library.
+func (h *Handler) upload(ctx *context.Context, panel table.Table, multiForm *multipart.Form) error {
+	if err := panel.GetForm().ValidateUpload(multiForm); err != nil {
+		return err
+	}
+	return h.storeUpload(ctx, multiForm)
+}
+
+// storeUpload upload the validated files with the file upload engine.
+func (h *Handler) storeUpload(ctx *context.Context, multiForm *multipart.Form) error {
+	uploads := collectMediaUploads(multiForm)
+	if err := file.GetFileEngine(h.config.FileUploadEngine.Name).Upload(multiForm); err != nil {
+		return err
+	}
+	h.recordMedia(ctx, uploads, multiForm)
+	return nil
+}
+
 func aAlert() types.AlertAttribute {
 	return aTemplate().Alert()
 }
 
+// changeAlert return the alert of the error of a change, the change which
+// waits for approval is shown as a notice.
+func changeAlert(err error) template2.HTML {
+	if err == table.ErrChangeRequested {
+		return aAlert().SetTitle(icon.Icon(icon.InfoCircle, 2) + language.GetFromHtml("change requested")).
+			SetTheme("info").
+			SetContent(language.GetFromHtml(template2.HTML(err.Error()))).
+			GetContent()
+	}
+	return aAlert().Warning(err.Error())
+}
+
 func aForm() types.FormAttribute {
 	return aTemplate().Form()
 }
@@ -2,9 +2,11 @@ package controller
 
 import (
 	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/language"
 	"github.com/wowucco/go-admin/modules/logger"
 	"github.com/wowucco/go-admin/plugins/admin/modules/guard"
 	"github.com/wowucco/go-admin/plugins/admin/modules/response"
+	"github.com/wowucco/go-admin/plugins/admin/modules/table"
 )
 
 // Delete delete the row from database.
@@ -20,13 +22,19 @@ func (h *Handler) Delete(ctx *context.Context) {
 	//	return
 	//}
 
-	if err := h.table(param.Prefix, ctx).DeleteData(param.Id); err != nil {
+	if err := h.table(param.Prefix, ctx).DeleteData(param.Id); err == table.ErrChangeRequested {
+		// the delete waits for approval, which is not a failure
+		response.OkWithMsgAndData(ctx, language.Get(err.Error()), map[string]interface{}{
+			"token": h.authSrv().AddSessionToken(ctx),
+		})
+		return
+	} else if err != nil {
 		logger.Error(err)
 		response.Error(ctx, "delete fail")
 		return
 	}
 
 	response.OkWithData(ctx, map[string]interface{}{
-		"token": h.authSrv().AddToken(),
+		"token": h.authSrv().AddSessionToken(ctx),
 	})
 }
@@ -15,7 +15,7 @@ import (
 )
 
 func (h *Handler) ShowDetail(ctx *context.Context) {
-	prefix := ctx.Query(constant.PrefixKey)
+	prefix := ctx.Param(constant.PrefixKey)
 	id := ctx.Query(constant.DetailPKKey)
 	panel := h.table(prefix, ctx)
 	user := auth.Auth(ctx)
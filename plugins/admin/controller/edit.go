@@ -8,7 +8,6 @@ import (
 
 	"github.com/wowucco/go-admin/context"
 	"github.com/wowucco/go-admin/modules/auth"
-	"github.com/wowucco/go-admin/modules/file"
 	"github.com/wowucco/go-admin/modules/language"
 	"github.com/wowucco/go-admin/plugins/admin/modules"
 	"github.com/wowucco/go-admin/plugins/admin/modules/constant"
@@ -61,7 +60,7 @@ func (h *Handler) showForm(ctx *context.Context, alert template2.HTML, prefix st
 
 	referer := ctx.Headers("Referer")
 
-	if referer != "" && !isInfoUrl(referer) && !isEditUrl(referer, ctx.Query(constant.PrefixKey)) {
+	if referer != "" && !isInfoUrl(referer) && !isEditUrl(referer, ctx.Param(constant.PrefixKey)) {
 		infoUrl = referer
 	}
 
@@ -76,7 +75,7 @@ func (h *Handler) showForm(ctx *context.Context, alert template2.HTML, prefix st
 			SetPrimaryKey(panel.GetPrimaryKey().Name).
 			SetUrl(editUrl).
 			SetHiddenFields(map[string]string{
-				form2.TokenKey:    h.authSrv().AddToken(),
+				form2.TokenKey:    h.authSrv().AddSessionToken(ctx),
 				form2.PreviousKey: infoUrl,
 			}).
 			SetOperationFooter(formFooter(footerKind, f.IsHideContinueEditCheckBox, f.IsHideContinueNewCheckBox,
@@ -96,8 +95,17 @@ func (h *Handler) EditForm(ctx *context.Context) {
 
 	param := guard.GetEditFormParam(ctx)
 
+	chunked := h.applyChunkUploads(ctx, param.Prefix, param.Panel, param.MultiForm)
+	defer chunked.close()
+
+	picked, err := h.applyMediaPicks(ctx, param.Panel, param.MultiForm)
+	if err != nil {
+		h.showForm(ctx, aAlert().Warning(err.Error()), param.Prefix, param.Param, true)
+		return
+	}
+
 	if len(param.MultiForm.File) > 0 {
-		err := file.GetFileEngine(h.config.FileUploadEngine.Name).Upload(param.MultiForm)
+		err := h.upload(ctx, param.Panel, param.MultiForm)
 		if err != nil {
 			alert := aAlert().Warning(err.Error())
 			h.showForm(ctx, alert, param.Prefix, param.Param, true)
@@ -107,16 +115,16 @@ func (h *Handler) EditForm(ctx *context.Context) {
 
 	for _, field := range param.Panel.GetForm().FieldList {
 		if field.FormType == form.File &&
-			len(param.MultiForm.File[field.Field]) == 0 &&
+			len(param.MultiForm.File[field.Field]) == 0 && !chunked.fields[field.Field] && !picked[field.Field] &&
 			param.MultiForm.Value[field.Field+"__delete_flag"][0] != "1" {
 			delete(param.MultiForm.Value, field.Field)
 		}
 	}
 
-	err := param.Panel.UpdateData(param.Value())
+	err = param.Panel.UpdateData(param.Value())
+	chunked.done(err)
 	if err != nil {
-		alert := aAlert().Warning(err.Error())
-		h.showForm(ctx, alert, param.Prefix, param.Param, true)
+		h.showForm(ctx, changeAlert(err), param.Prefix, param.Param, true)
 		return
 	}
 
@@ -22,7 +22,8 @@ func (h *Handler) GlobalDeferHandler(ctx *context.Context) {
 
 	logger.Access(ctx)
 
-	h.RecordOperationLog(ctx)
+	// recorded at last to get the status of the error response
+	defer h.RecordOperationLog(ctx)
 
 	if err := recover(); err != nil {
 		logger.Error(err)
@@ -70,7 +71,7 @@ func (h *Handler) setFormWithReturnErrMessage(ctx *context.Context, errMsg strin
 
 	var (
 		formInfo table.FormInfo
-		prefix   = ctx.Query(constant.PrefixKey)
+		prefix   = ctx.Param(constant.PrefixKey)
 		panel    = h.table(prefix, ctx)
 		f        = panel.GetForm()
 	)
@@ -102,7 +103,7 @@ func (h *Handler) setFormWithReturnErrMessage(ctx *context.Context, errMsg strin
 			SetPrimaryKey(panel.GetPrimaryKey().Name).
 			SetPrefix(h.config.PrefixFixSlash()).
 			SetHiddenFields(map[string]string{
-				form.TokenKey:    h.authSrv().AddToken(),
+				form.TokenKey:    h.authSrv().AddSessionToken(ctx),
 				form.PreviousKey: h.config.Url("/info/" + prefix + queryParam),
 			}).
 			SetUrl(h.config.Url("/"+kind+"/"+prefix)).
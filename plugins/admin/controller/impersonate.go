@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/auth"
+	"github.com/wowucco/go-admin/plugins/admin/modules/response"
+)
+
+// StopImpersonate switch the session of an impersonated user back to the
+// real user.
+func (h *Handler) StopImpersonate(ctx *context.Context) {
+	if err := auth.StopImpersonate(ctx, h.conn); err != nil {
+		response.Error(ctx, err.Error())
+		return
+	}
+	response.Ok(ctx)
+}
+
+// denyImpersonated respond the request of an impersonated user with an
+// error and return true, the lasting changes of the account are made only
+// by the real user.
+func denyImpersonated(ctx *context.Context) bool {
+	if auth.Auth(ctx).IsImpersonated() {
+		response.BadRequest(ctx, auth.ErrImpersonated.Error())
+		return true
+	}
+	return false
+}
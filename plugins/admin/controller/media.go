@@ -0,0 +1,217 @@
+package controller
+
+import (
+	"errors"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/auth"
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/file"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+	"github.com/wowucco/go-admin/plugins/admin/modules"
+	"github.com/wowucco/go-admin/plugins/admin/modules/constant"
+	"github.com/wowucco/go-admin/plugins/admin/modules/response"
+	"github.com/wowucco/go-admin/plugins/admin/modules/table"
+)
+
+const mediaLibraryPageSize = 24
+
+// MediaLibrary return a page of the recorded uploads as json for the media
+// picker of the file fields. The query "search" filters by the file name and
+// "type=image" lists the images only. The uploads of the user and the ones of
+// the tables the user can query or edit are listed.
+func (h *Handler) MediaLibrary(ctx *context.Context) {
+
+	page, _ := strconv.Atoi(ctx.Query("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	user := auth.Auth(ctx)
+	prefixes, all := h.mediaPrefixes(user)
+
+	query := func() *db.SQL {
+		sql := db.WithDriver(h.conn).Table(models.Media().TableName)
+		if search := strings.TrimSpace(ctx.Query("search")); search != "" {
+			sql = sql.Where("name", "like", "%"+search+"%")
+		}
+		if ctx.Query("type") == "image" {
+			sql = sql.Where("mime", "like", "image/%")
+		}
+		if all {
+			return sql
+		}
+		if len(prefixes) == 0 {
+			return sql.Where("user_id", "=", user.Id)
+		}
+		args := []interface{}{user.Id}
+		for _, prefix := range prefixes {
+			args = append(args, prefix)
+		}
+		return sql.WhereRaw("(user_id = ? or prefix in (?"+strings.Repeat(",?", len(prefixes)-1)+"))", args...)
+	}
+
+	total, err := query().Count()
+	if err != nil {
+		response.Error(ctx, err.Error())
+		return
+	}
+
+	items, err := query().OrderBy("id", "desc").
+		Skip((page - 1) * mediaLibraryPageSize).
+		Take(mediaLibraryPageSize).
+		All()
+	if db.CheckError(err, db.QUERY) {
+		response.Error(ctx, err.Error())
+		return
+	}
+
+	list := make([]map[string]interface{}, len(items))
+	for k, item := range items {
+		m := models.Media().MapToModel(item)
+		list[k] = map[string]interface{}{
+			"id":    m.Id,
+			"name":  m.Name,
+			"path":  m.Path,
+			"mime":  m.Mime,
+			"size":  file.FormatSize(m.Size),
+			"url":   file.URL(m.Path),
+			"thumb": file.URL(file.VariantPath(m.Path, file.ThumbnailVariant)),
+		}
+	}
+
+	response.OkWithData(ctx, map[string]interface{}{
+		"list":      list,
+		"total":     total,
+		"page":      page,
+		"page_size": mediaLibraryPageSize,
+	})
+}
+
+// mediaUpload is an uploaded file of a form which is recorded in the media
+// library once the Uploader stored it.
+type mediaUpload struct {
+	field string
+	index int
+	name  string
+	size  int64
+	mime  string
+}
+
+// collectMediaUploads remember the uploaded files of the form before the
+// Uploader moves them. The stored path of a file is found afterwards at its
+// index in the values of the field.
+func collectMediaUploads(multiForm *multipart.Form) []mediaUpload {
+	uploads := make([]mediaUpload, 0)
+	for field, files := range multiForm.File {
+		offset := len(multiForm.Value[field])
+		for i, fh := range files {
+			uploads = append(uploads, mediaUpload{
+				field: field,
+				index: offset + i,
+				name:  fh.Filename,
+				size:  fh.Size,
+				mime:  file.DetectContentType(fh),
+			})
+		}
+	}
+	return uploads
+}
+
+// recordMedia insert the stored uploads into the media library. A failure is
+// logged only, the files are uploaded already.
+func (h *Handler) recordMedia(ctx *context.Context, uploads []mediaUpload, multiForm *multipart.Form) {
+	var (
+		media  = models.Media().SetConn(h.conn)
+		user   = auth.Auth(ctx)
+		prefix = ctx.Param(constant.PrefixKey)
+	)
+
+	for _, up := range uploads {
+		values := multiForm.Value[up.field]
+		if up.index >= len(values) {
+			continue
+		}
+		stored := values[up.index]
+		contentType := up.mime
+		// the image pipeline may have converted the format
+		if ext := path.Ext(stored); !strings.EqualFold(ext, path.Ext(up.name)) {
+			if t := mime.TypeByExtension(ext); t != "" {
+				contentType = t
+			}
+		}
+		_, err := media.New(user.Id, h.config.FileUploadEngine.Name, prefix, up.field, stored, up.name, up.size,
+			contentType, file.Variants(stored))
+		if db.CheckError(err, db.INSERT) {
+			logger.Error("record media error: ", err)
+		}
+	}
+}
+
+// mediaPrefixes return the prefixes of the tables which the user can query or
+// edit, and true when the user can use all the media.
+func (h *Handler) mediaPrefixes(user models.UserModel) ([]string, bool) {
+	if user.IsSuperAdmin() {
+		return nil, true
+	}
+	prefixes := make([]string, 0)
+	for prefix := range h.generators {
+		if user.CheckPermissionByUrlMethod(h.routePathWithPrefix("info", prefix), "GET", url.Values{}) ||
+			user.CheckPermissionByUrlMethod(h.routePathWithPrefix("edit", prefix), "POST", url.Values{}) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	sort.Strings(prefixes)
+	return prefixes, false
+}
+
+// applyMediaPicks put the paths picked from the media library and posted with
+// the form as "<field>__media_path" into their file fields, and return the
+// names of these fields. Paths which are not in the library or which the user
+// can not list are dropped, the picked files are checked against the upload
+// rules of the field.
+func (h *Handler) applyMediaPicks(ctx *context.Context, panel table.Table,
+	multiForm *multipart.Form) (map[string]bool, error) {
+	applied := make(map[string]bool)
+	media := models.Media().SetConn(h.conn)
+	user := auth.Auth(ctx)
+	prefixes, all := h.mediaPrefixes(user)
+
+	for _, field := range panel.GetForm().FieldList {
+		key := field.Field + file.MediaPathSuffix
+		paths := multiForm.Value[key]
+		delete(multiForm.Value, key)
+
+		if !field.FormType.IsFile() {
+			continue
+		}
+
+		for _, p := range paths {
+			if p == "" {
+				continue
+			}
+			m := media.FindByPath(p)
+			if m.IsEmpty() || !all && m.UserId != user.Id && !modules.InArray(prefixes, m.Prefix) {
+				continue
+			}
+			if err := field.UploadRule.ValidateStored(m.Name, m.Mime, m.Size); err != nil {
+				return nil, errors.New(field.Head + ": " + err.Error())
+			}
+			if !applied[field.Field] {
+				multiForm.Value[field.Field] = nil
+				applied[field.Field] = true
+			}
+			multiForm.Value[field.Field] = append(multiForm.Value[field.Field], p)
+		}
+	}
+
+	return applied, nil
+}
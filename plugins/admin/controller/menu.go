@@ -52,7 +52,7 @@ func (h *Handler) showNewMenu(ctx *context.Context, err error) {
 			SetPrimaryKey(panel.GetPrimaryKey().Name).
 			SetUrl(h.routePath("menu_edit")).
 			SetHiddenFields(map[string]string{
-				form2.TokenKey:    h.authSrv().AddToken(),
+				form2.TokenKey:    h.authSrv().AddSessionToken(ctx),
 				form2.PreviousKey: h.routePath("menu"),
 			}).
 			SetOperationFooter(formFooter("new", false, false, false)), false),
@@ -107,7 +107,7 @@ func (h *Handler) showEditMenu(ctx *context.Context, formInfo table.FormInfo, er
 			SetUrl(h.routePath("menu_edit")).
 			SetOperationFooter(formFooter("edit", false, false, false)).
 			SetHiddenFields(map[string]string{
-				form2.TokenKey:    h.authSrv().AddToken(),
+				form2.TokenKey:    h.authSrv().AddSessionToken(ctx),
 				form2.PreviousKey: h.routePath("menu"),
 			}), false),
 		Description: template2.HTML(formInfo.Description),
@@ -119,6 +119,7 @@ func (h *Handler) showEditMenu(ctx *context.Context, formInfo table.FormInfo, er
 // DeleteMenu delete the menu of given id.
 func (h *Handler) DeleteMenu(ctx *context.Context) {
 	models.MenuWithId(guard.GetMenuDeleteParam(ctx).Id).SetConn(h.conn).Delete()
+	auth.InvalidateUserCache()
 	response.OkWithMsg(ctx, language.Get("delete succeed"))
 }
 
@@ -136,6 +137,9 @@ func (h *Handler) EditMenu(ctx *context.Context) {
 
 	menuModel := models.MenuWithId(param.Id).SetConn(h.conn)
 
+	// the menus of the cached users change with the roles of the menu
+	defer auth.InvalidateUserCache()
+
 	// TODO: use transaction
 	deleteRolesErr := menuModel.DeleteRoles()
 	if db.CheckError(deleteRolesErr, db.DELETE) {
@@ -191,6 +195,8 @@ func (h *Handler) NewMenu(ctx *context.Context) {
 		return
 	}
 
+	defer auth.InvalidateUserCache()
+
 	for _, roleId := range param.Roles {
 		_, addRoleErr := menuModel.AddRole(roleId)
 		if db.CheckError(addRoleErr, db.INSERT) {
@@ -239,7 +245,7 @@ func (h *Handler) getMenuInfoPanel(ctx *context.Context, alert template2.HTML) {
 		SetUrl(h.routePath("menu_new")).
 		SetPrimaryKey(h.table("menu", ctx).GetPrimaryKey().Name).
 		SetHiddenFields(map[string]string{
-			form2.TokenKey:    h.authSrv().AddToken(),
+			form2.TokenKey:    h.authSrv().AddSessionToken(ctx),
 			form2.PreviousKey: h.routePath("menu"),
 		}).
 		SetOperationFooter(formFooter("menu", false, false, false)).
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"encoding/json"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/modules/menu"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+	"github.com/wowucco/go-admin/plugins/admin/modules/constant"
+	"github.com/wowucco/go-admin/plugins/admin/modules/response"
+	"github.com/wowucco/go-admin/plugins/admin/modules/table"
+)
+
+// NestedSetOrder rewrite the lft, rgt and depth of a nested set table with
+// the order posted by the drag and drop tree.
+func (h *Handler) NestedSetOrder(ctx *context.Context) {
+
+	var items models.OrderItems
+
+	if err := json.Unmarshal([]byte(ctx.FormValue("_order")), &items); err != nil {
+		response.BadRequest(ctx, "wrong order")
+		return
+	}
+
+	panel := h.table(ctx.Param(constant.PrefixKey), ctx)
+
+	// the order is written directly, it can not wait for an approval
+	if table.NeedsApproval(panel) {
+		response.BadRequest(ctx, "the table needs approval, the order can not be changed")
+		return
+	}
+
+	conn, connName := table.Connection(panel)
+	if conn == nil {
+		response.BadRequest(ctx, "the table is not stored in the database")
+		return
+	}
+
+	set := menu.NewNestedSet(conn, menu.NestedSetTable{Name: panel.GetInfo().Table, Connection: connName})
+
+	if !set.Valid() {
+		response.BadRequest(ctx, "the table is not a nested set")
+		return
+	}
+
+	if err := set.ResetOrder(items); err != nil {
+		logger.Error("nested set order error: ", err)
+		response.Error(ctx, err.Error())
+		return
+	}
+
+	response.Ok(ctx)
+}
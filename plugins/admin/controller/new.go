@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"github.com/wowucco/go-admin/context"
 	"github.com/wowucco/go-admin/modules/auth"
-	"github.com/wowucco/go-admin/modules/file"
 	"github.com/wowucco/go-admin/plugins/admin/modules/constant"
 	form2 "github.com/wowucco/go-admin/plugins/admin/modules/form"
 	"github.com/wowucco/go-admin/plugins/admin/modules/guard"
@@ -33,7 +32,7 @@ func (h *Handler) showNewForm(ctx *context.Context, alert template2.HTML, prefix
 
 	referer := ctx.Headers("Referer")
 
-	if referer != "" && !isInfoUrl(referer) && !isNewUrl(referer, ctx.Query(constant.PrefixKey)) {
+	if referer != "" && !isInfoUrl(referer) && !isNewUrl(referer, ctx.Param(constant.PrefixKey)) {
 		infoUrl = referer
 	}
 
@@ -48,7 +47,7 @@ func (h *Handler) showNewForm(ctx *context.Context, alert template2.HTML, prefix
 			SetUrl(newUrl).
 			SetPrimaryKey(panel.GetPrimaryKey().Name).
 			SetHiddenFields(map[string]string{
-				form2.TokenKey:    h.authSrv().AddToken(),
+				form2.TokenKey:    h.authSrv().AddSessionToken(ctx),
 				form2.PreviousKey: infoUrl,
 			}).
 			SetTitle("New").
@@ -70,9 +69,17 @@ func (h *Handler) NewForm(ctx *context.Context) {
 
 	param := guard.GetNewFormParam(ctx)
 
-	// process uploading files, only support local storage
+	chunked := h.applyChunkUploads(ctx, param.Prefix, param.Panel, param.MultiForm)
+	defer chunked.close()
+
+	if _, err := h.applyMediaPicks(ctx, param.Panel, param.MultiForm); err != nil {
+		h.showNewForm(ctx, aAlert().Warning(err.Error()), param.Prefix, param.Param.GetRouteParamStr(), true)
+		return
+	}
+
+	// validate and process uploading files
 	if len(param.MultiForm.File) > 0 {
-		err := file.GetFileEngine(h.config.FileUploadEngine.Name).Upload(param.MultiForm)
+		err := h.upload(ctx, param.Panel, param.MultiForm)
 		if err != nil {
 			h.showNewForm(ctx, aAlert().Warning(err.Error()), param.Prefix, param.Param.GetRouteParamStr(), true)
 			return
@@ -80,8 +87,9 @@ func (h *Handler) NewForm(ctx *context.Context) {
 	}
 
 	err := param.Panel.InsertData(param.Value())
+	chunked.done(err)
 	if err != nil {
-		h.showNewForm(ctx, aAlert().Warning(err.Error()), param.Prefix, param.Param.GetRouteParamStr(), true)
+		h.showNewForm(ctx, changeAlert(err), param.Prefix, param.Param.GetRouteParamStr(), true)
 		return
 	}
 
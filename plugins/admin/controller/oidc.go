@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/auth"
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/modules/oidc"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+)
+
+// oidcStateCookie binds the login to the browser which started it, so that a
+// callback url can not be used to log in someone else.
+const oidcStateCookie = "go_admin_oidc_state"
+
+// OIDCLogin redirect to the provider to start the single sign-on.
+func (h *Handler) OIDCLogin(ctx *context.Context) {
+
+	p, ok := oidc.Get(ctx.Query("__provider"))
+	if !ok {
+		h.oidcFail(ctx, "", "provider not found")
+		return
+	}
+
+	redirectURL := p.RedirectURL
+	if redirectURL == "" {
+		redirectURL = requestOrigin(ctx) + h.config.Url("/oidc/"+p.Name+"/callback")
+	}
+
+	st := oidc.GetStateStore().Begin(p.Name, redirectURL, ctx.Query("ref"))
+
+	authURL, err := p.AuthCodeURL(redirectURL, st.State, st.Nonce, oidc.CodeChallenge(st.CodeVerifier))
+	if err != nil {
+		h.oidcFail(ctx, st.Ref, err.Error())
+		return
+	}
+
+	ctx.SetCookie(&http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    st.State,
+		MaxAge:   int(oidc.StateExpires.Seconds()),
+		HttpOnly: true,
+		Path:     "/",
+		SameSite: http.SameSiteLaxMode,
+	})
+	ctx.Redirect(authURL)
+}
+
+// OIDCCallback check the response of the provider and log in the user of the
+// account.
+func (h *Handler) OIDCCallback(ctx *context.Context) {
+
+	state := ctx.Query("state")
+	cookie, err := ctx.Request.Cookie(oidcStateCookie)
+	if err != nil || cookie.Value == "" || cookie.Value != state {
+		h.oidcFail(ctx, "", "state does not match")
+		return
+	}
+
+	ctx.SetCookie(&http.Cookie{Name: oidcStateCookie, Path: "/", MaxAge: -1})
+
+	st, ok := oidc.GetStateStore().Finish(state)
+	if !ok || st.Provider != ctx.Query("__provider") {
+		h.oidcFail(ctx, "", "state is expired")
+		return
+	}
+
+	if e := ctx.Query("error"); e != "" {
+		h.oidcFail(ctx, st.Ref, e+" "+ctx.Query("error_description"))
+		return
+	}
+
+	p, ok := oidc.Get(st.Provider)
+	if !ok {
+		h.oidcFail(ctx, st.Ref, "provider not found")
+		return
+	}
+
+	claims, err := p.Login(ctx.Query("code"), st.CodeVerifier, st.RedirectURL, st.Nonce)
+	if err != nil {
+		h.oidcFail(ctx, st.Ref, err.Error())
+		return
+	}
+
+	user, err := auth.CheckOIDC(p, claims, h.conn)
+	if err != nil {
+		h.oidcFail(ctx, st.Ref, err.Error())
+		return
+	}
+
+	// a user with the two-factor authentication still sends a code on the
+	// login page, the token is put in the fragment to keep it out of the logs
+	if models.TwoFactor().SetConn(h.conn).FindByUserId(user.Id).Enabled {
+		ctx.Redirect(h.loginURL(st.Ref) + "#two_factor=" + auth.GetTwoFactorChallenges().Add(user.Id))
+		return
+	}
+
+	if user.SetConn(h.conn).IsTwoFactorRequired() {
+		if err := auth.SetTwoFactorSetupCookie(ctx, user, h.conn); err != nil {
+			h.oidcFail(ctx, st.Ref, err.Error())
+			return
+		}
+		ctx.Redirect(h.config.Url("/2fa"))
+		return
+	}
+
+	if err := auth.SetCookie(ctx, user, h.conn); err != nil {
+		h.oidcFail(ctx, st.Ref, err.Error())
+		return
+	}
+
+	if isLocalURL(st.Ref) {
+		ctx.Redirect(st.Ref)
+		return
+	}
+	ctx.Redirect(h.config.GetIndexURL())
+}
+
+// oidcFail log the reason and go back to the login page, which shows a
+// general message only.
+func (h *Handler) oidcFail(ctx *context.Context, ref, reason string) {
+	logger.Warn("single sign-on fail: ", reason)
+	u := h.loginURL(ref)
+	if strings.Contains(u, "?") {
+		u += "&sso_error=1"
+	} else {
+		u += "?sso_error=1"
+	}
+	ctx.Redirect(u)
+}
+
+func (h *Handler) loginURL(ref string) string {
+	u := h.config.Url(config.GetLoginUrl())
+	if isLocalURL(ref) {
+		u += "?ref=" + url.QueryEscape(ref)
+	}
+	return u
+}
+
+// isLocalURL report if the url is a path of this site, other urls are not
+// followed after the login.
+func isLocalURL(u string) bool {
+	return strings.HasPrefix(u, "/") && !strings.HasPrefix(u, "//") && !strings.HasPrefix(u, "/\\")
+}
+
+func requestOrigin(ctx *context.Context) string {
+	scheme := "http"
+	if ctx.Request.TLS != nil || ctx.Headers("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + ctx.Request.Host
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/auth"
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/language"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/modules/notify"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+	"github.com/wowucco/go-admin/plugins/admin/modules/response"
+)
+
+// passwordResetEnabled report if the users can reset the forgotten
+// passwords. The passwords of the directory users are not kept here, and the
+// links are not sent without the configured site url, as the host of the
+// request is chosen by the client.
+func passwordResetEnabled() bool {
+	cfg := config.GetPasswordReset()
+	return cfg.Enable && cfg.Url != "" && !config.GetLdap().Enable
+}
+
+// ForgotPassword send the password reset link to the email of the user of
+// the username or the email. The response does not tell whether the user
+// exists.
+func (h *Handler) ForgotPassword(ctx *context.Context) {
+	if !passwordResetEnabled() {
+		if config.GetPasswordReset().Enable && config.GetPasswordReset().Url == "" {
+			logger.Error("password reset is disabled: the url of the reset links is not set")
+		}
+		response.BadRequest(ctx, "password reset is not enabled")
+		return
+	}
+
+	account := strings.TrimSpace(ctx.FormValue("account"))
+	if account == "" {
+		response.BadRequest(ctx, "username or email can not be empty")
+		return
+	}
+
+	// the ip which is locked for the failed logins can not send the links
+	if auth.LoginLockout("", auth.LoginIP(ctx), h.conn) > 0 {
+		response.TooManyRequests(ctx, "too many failed logins, please try again later")
+		return
+	}
+
+	user := models.User().SetConn(h.conn).FindByUserName(account)
+	if user.IsEmpty() && strings.Contains(account, "@") {
+		user = models.User().SetConn(h.conn).FindByEmail(account)
+	}
+
+	if !user.IsEmpty() && user.Email != "" {
+		token, err := auth.NewPasswordReset(user.Id, h.conn)
+		if err != nil {
+			logger.Error("create password reset error: ", err)
+		} else {
+			link := h.passwordResetURL(token)
+			// sent in the background, so that the response time does not
+			// tell whether the user exists
+			go func() {
+				if err := notify.Send(notify.Message{
+					To:      user.Email,
+					Subject: h.config.Title + " - " + language.Get("reset password"),
+					Body:    language.Get("open the link to reset the password") + ":\n\n" + link,
+				}); err != nil {
+					logger.Error("send password reset error: ", err)
+				}
+			}()
+		}
+	}
+
+	response.OkWithMsg(ctx, language.Get("a password reset link is sent to the email of the account if it exists"))
+}
+
+// ResetPassword set the password of the user of the reset token.
+func (h *Handler) ResetPassword(ctx *context.Context) {
+	password := ctx.FormValue("password")
+
+	if password == "" {
+		response.BadRequest(ctx, "password can not be empty")
+		return
+	}
+
+	if password != ctx.FormValue("password_again") {
+		response.BadRequest(ctx, "password does not match")
+		return
+	}
+
+	user, err := auth.ResetPassword(ctx.FormValue("token"), password, h.conn)
+	if err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	// the user who proved the email is not kept out by the failed logins
+	auth.LoginSucceeded(user.UserName, h.conn)
+
+	response.OkWithMsg(ctx, language.Get("the password is reset, please login"))
+}
+
+// passwordResetURL return the link of the login page which shows the reset
+// form. The token is in the fragment, so that it is not sent to the server
+// or to other sites in the referer.
+func (h *Handler) passwordResetURL(token string) string {
+	base := strings.TrimRight(config.GetPasswordReset().Url, "/")
+	return base + h.config.Url(config.GetLoginUrl()) + "#reset=" + token
+}
@@ -36,7 +36,7 @@ import (
 // ShowInfo show info page.
 func (h *Handler) ShowInfo(ctx *context.Context) {
 
-	prefix := ctx.Query(constant.PrefixKey)
+	prefix := ctx.Param(constant.PrefixKey)
 
 	if prefix == "site" {
 		ctx.Redirect(h.config.Url("/info/site/edit"))
@@ -299,7 +299,7 @@ func (h *Handler) Export(ctx *context.Context) {
 	param := guard.GetExportParam(ctx)
 
 	tableName := "Sheet1"
-	prefix := ctx.Query(constant.PrefixKey)
+	prefix := ctx.Param(constant.PrefixKey)
 	panel := h.table(prefix, ctx)
 
 	f := excelize.NewFile()
@@ -0,0 +1,337 @@
+package controller
+
+import (
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/auth"
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/language"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/modules/qrcode"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+	"github.com/wowucco/go-admin/plugins/admin/modules/response"
+	"github.com/wowucco/go-admin/template/types"
+)
+
+const recoveryCodeCount = 10
+
+// AuthTwoFactor is the second login step, it checks the code of the challenge
+// returned by Auth and sets the cookie. The wrong codes are throttled like
+// the wrong passwords.
+func (h *Handler) AuthTwoFactor(ctx *context.Context) {
+
+	var (
+		code       = ctx.FormValue("code")
+		token      = ctx.FormValue("token")
+		ip         = auth.LoginIP(ctx)
+		challenges = auth.GetTwoFactorChallenges()
+	)
+
+	userId, ok := challenges.User(token)
+	if !ok {
+		response.BadRequest(ctx, "wrong two-factor code")
+		return
+	}
+
+	user := models.User().SetConn(h.conn).Find(userId)
+
+	if user.IsEmpty() {
+		response.BadRequest(ctx, "wrong two-factor code")
+		return
+	}
+
+	if auth.LoginLockout(user.UserName, ip, h.conn) > 0 {
+		response.TooManyRequests(ctx, "too many failed logins, please try again later")
+		return
+	}
+
+	expired := challenges.PasswordExpired(token)
+
+	if _, ok := challenges.Verify(token, func(userId int64) bool {
+		return auth.CheckTwoFactorCode(userId, code, h.conn)
+	}); !ok {
+		auth.LoginFailed(user.UserName, ip, h.conn)
+		response.BadRequest(ctx, "wrong two-factor code")
+		return
+	}
+
+	auth.LoginSucceeded(user.UserName, h.conn)
+
+	if expired {
+		h.passwordExpired(ctx, user)
+		return
+	}
+
+	if err := auth.SetCookie(ctx, user, h.conn); err != nil {
+		response.Error(ctx, err.Error())
+		return
+	}
+
+	h.loginRedirect(ctx)
+}
+
+// ShowTwoFactor show the two-factor settings of the login user. A user who
+// has not enrolled gets a new secret and its qr code, which is not shown to
+// an impersonated user.
+func (h *Handler) ShowTwoFactor(ctx *context.Context) {
+
+	user := auth.Auth(ctx)
+
+	if user.IsImpersonated() {
+		h.HTML(ctx, user, types.Panel{
+			Content:     aAlert().Warning(language.Get(auth.ErrImpersonated.Error())),
+			Title:       template.HTML(language.Get("two-factor authentication")),
+			Description: template.HTML(language.Get("two-factor authentication")),
+		})
+		return
+	}
+	tf := models.TwoFactor().SetConn(h.conn).FindByUserId(user.Id)
+
+	var body template.HTML
+
+	if tf.Enabled {
+		body = h.twoFactorEnabledContent(tf)
+	} else {
+		if tf.IsEmpty() || tf.Secret == "" {
+			secret, err := auth.GenerateTOTPSecret()
+			if err == nil {
+				tf, err = tf.Save(user.Id, secret)
+			}
+			if db.CheckError(err, db.INSERT) {
+				logger.Error("save two-factor secret error: ", err)
+				h.HTML(ctx, user, types.Panel{
+					Content:     aAlert().Warning(err.Error()),
+					Title:       template.HTML(language.Get("two-factor authentication")),
+					Description: template.HTML(language.Get("two-factor authentication")),
+				})
+				return
+			}
+		}
+		body = h.twoFactorEnrollContent(user, tf)
+	}
+
+	box := aBox().
+		WithHeadBorder().
+		SetHeader(template.HTML("<b>" + language.Get("two-factor authentication") + "</b>")).
+		SetBody(body + twoFactorScript(h.config.Url("/2fa"))).
+		GetContent()
+
+	h.HTML(ctx, user, types.Panel{
+		Content:     aRow().SetContent(aCol().SetSize(types.SizeMD(8)).SetContent(box).GetContent()).GetContent(),
+		Title:       template.HTML(language.Get("two-factor authentication")),
+		Description: template.HTML(language.Get("two-factor authentication")),
+	})
+}
+
+// EnableTwoFactor confirm the pending secret with a code and return the
+// recovery codes, which are shown only once.
+func (h *Handler) EnableTwoFactor(ctx *context.Context) {
+
+	if denyImpersonated(ctx) {
+		return
+	}
+
+	user := auth.Auth(ctx)
+	tf := models.TwoFactor().SetConn(h.conn).FindByUserId(user.Id)
+
+	if tf.IsEmpty() || tf.Enabled {
+		response.BadRequest(ctx, "wrong two-factor code")
+		return
+	}
+
+	step, ok := auth.ValidateTOTP(tf.Secret, ctx.FormValue("code"), time.Now(), 0)
+	if !ok {
+		response.BadRequest(ctx, "wrong two-factor code")
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		response.Error(ctx, err.Error())
+		return
+	}
+
+	if _, err := tf.Enable(step, hashes); err != nil {
+		response.Error(ctx, err.Error())
+		return
+	}
+
+	if err := auth.ClearTwoFactorSetup(ctx, h.conn); err != nil {
+		logger.Error("clear two-factor setup error: ", err)
+	}
+
+	response.OkWithData(ctx, map[string]interface{}{
+		"codes": codes,
+		"url":   h.config.GetIndexURL(),
+	})
+}
+
+// RegenerateRecoveryCodes replace the recovery codes after a code is checked.
+func (h *Handler) RegenerateRecoveryCodes(ctx *context.Context) {
+
+	if denyImpersonated(ctx) {
+		return
+	}
+
+	user := auth.Auth(ctx)
+
+	if !auth.CheckTwoFactorCode(user.Id, ctx.FormValue("code"), h.conn) {
+		response.BadRequest(ctx, "wrong two-factor code")
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		response.Error(ctx, err.Error())
+		return
+	}
+
+	tf := models.TwoFactor().SetConn(h.conn).FindByUserId(user.Id)
+	if _, err := tf.SetRecoveryCodes(hashes); err != nil {
+		response.Error(ctx, err.Error())
+		return
+	}
+
+	response.OkWithData(ctx, map[string]interface{}{
+		"codes": codes,
+	})
+}
+
+// DisableTwoFactor turn off the two-factor authentication after a code is
+// checked, unless a role of the user requires it.
+func (h *Handler) DisableTwoFactor(ctx *context.Context) {
+
+	if denyImpersonated(ctx) {
+		return
+	}
+
+	user := auth.Auth(ctx)
+
+	if user.SetConn(h.conn).IsTwoFactorRequired() {
+		response.BadRequest(ctx, "two-factor authentication is required by your role")
+		return
+	}
+
+	if !auth.CheckTwoFactorCode(user.Id, ctx.FormValue("code"), h.conn) {
+		response.BadRequest(ctx, "wrong two-factor code")
+		return
+	}
+
+	err := models.TwoFactor().SetConn(h.conn).FindByUserId(user.Id).Delete()
+	if db.CheckError(err, db.DELETE) {
+		response.Error(ctx, err.Error())
+		return
+	}
+
+	response.Ok(ctx)
+}
+
+func newRecoveryCodes() (codes []string, hashes []string, err error) {
+	codes, err = auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes = make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashRecoveryCode(code)
+	}
+	return codes, hashes, nil
+}
+
+func (h *Handler) twoFactorEnrollContent(user models.UserModel, tf models.TwoFactorModel) template.HTML {
+
+	uri := auth.TOTPURI(h.config.Title, user.UserName, tf.Secret)
+
+	img := ""
+	if src, err := qrcode.DataURI(uri, 5); err == nil {
+		img = `<img src="` + src + `" alt="qrcode" style="max-width:100%;">`
+	} else {
+		logger.Error("two-factor qrcode error: ", err)
+	}
+
+	return template.HTML(fmt.Sprintf(`<p>%s</p>
+<div class="text-center">%s</div>
+<p class="text-center"><code>%s</code></p>
+<form class="two-factor-form" data-action="enable">
+	<div class="form-group">
+		<label>%s</label>
+		<input type="text" name="code" class="form-control" autocomplete="one-time-code" inputmode="numeric" maxlength="6">
+	</div>
+	<button type="submit" class="btn btn-primary">%s</button>
+</form>
+<div class="two-factor-codes" style="display:none;">
+	<p>%s</p>
+	<pre></pre>
+	<a class="btn btn-default" href="%s">%s</a>
+</div>`,
+		language.Get("scan the qr code with an authenticator app, or enter the secret manually"),
+		img,
+		template.HTMLEscapeString(tf.Secret),
+		language.Get("two-factor code"),
+		language.Get("enable"),
+		language.Get("save these recovery codes, each of them can be used once when the phone is lost"),
+		h.config.GetIndexURL(),
+		language.Get("continue")))
+}
+
+func (h *Handler) twoFactorEnabledContent(tf models.TwoFactorModel) template.HTML {
+	return template.HTML(fmt.Sprintf(`<p><span class="label label-success">%s</span></p>
+<p>%s: %d</p>
+<form class="two-factor-form">
+	<div class="form-group">
+		<label>%s</label>
+		<input type="text" name="code" class="form-control" autocomplete="one-time-code">
+	</div>
+	<button type="submit" class="btn btn-default" data-action="recovery_codes">%s</button>
+	<button type="submit" class="btn btn-danger" data-action="disable">%s</button>
+</form>
+<div class="two-factor-codes" style="display:none;">
+	<p>%s</p>
+	<pre></pre>
+</div>`,
+		language.Get("enabled"),
+		language.Get("recovery codes left"), len(tf.RecoveryCodes),
+		language.Get("two-factor code or recovery code"),
+		language.Get("regenerate recovery codes"),
+		language.Get("disable"),
+		language.Get("save these recovery codes, each of them can be used once when the phone is lost")))
+}
+
+func twoFactorScript(prefix string) template.HTML {
+	return template.HTML(`<script>
+$(".two-factor-form button[type=submit]").on("click", function () {
+	$(this).closest("form").data("clicked", $(this).data("action"));
+});
+$(".two-factor-form").on("submit", function (e) {
+	e.preventDefault();
+	let form = $(this);
+	let action = form.data("clicked") || form.data("action");
+	$.ajax({
+		method: "post",
+		url: "` + prefix + `/" + action,
+		data: {code: form.find("input[name=code]").val()},
+		success: function (data) {
+			if (data.data && data.data.codes) {
+				form.hide();
+				let box = form.siblings(".two-factor-codes");
+				box.find("pre").text(data.data.codes.join("\n"));
+				box.show();
+			} else {
+				$.pjax.reload("#pjax-container");
+			}
+		},
+		error: function (data) {
+			let msg = data.responseJSON ? data.responseJSON.msg : data.responseText;
+			if (typeof(swal) === "function") {
+				swal(msg, "", "error");
+			} else {
+				alert(msg);
+			}
+		}
+	});
+});
+</script>`)
+}
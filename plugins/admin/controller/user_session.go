@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/auth"
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/language"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+	"github.com/wowucco/go-admin/plugins/admin/modules/response"
+	"github.com/wowucco/go-admin/template/types"
+)
+
+// ShowUserSessions show the active sessions of the login user.
+func (h *Handler) ShowUserSessions(ctx *context.Context) {
+
+	user := auth.Auth(ctx)
+	sessions := models.UserSession().SetConn(h.conn).ListByUserId(user.Id, time.Now())
+
+	box := aBox().
+		WithHeadBorder().
+		SetHeader(template.HTML("<b>" + language.Get("my sessions") + "</b>")).
+		SetBody(userSessionList(sessions, auth.CurrentSessionId(ctx)) + userSessionScript(h.config.Url("/sessions"))).
+		GetContent()
+
+	h.HTML(ctx, user, types.Panel{
+		Content:     aRow().SetContent(aCol().SetSize(types.SizeMD(12)).SetContent(box).GetContent()).GetContent(),
+		Title:       template.HTML(language.Get("my sessions")),
+		Description: template.HTML(language.Get("my sessions")),
+	})
+}
+
+// RevokeUserSession end a session of the login user, which may be the
+// current one.
+func (h *Handler) RevokeUserSession(ctx *context.Context) {
+
+	if denyImpersonated(ctx) {
+		return
+	}
+
+	user := auth.Auth(ctx)
+
+	ses := models.UserSession().SetConn(h.conn).Find(ctx.FormValue("id"))
+	if ses.IsEmpty() || ses.UserId != user.Id {
+		response.BadRequest(ctx, "wrong session")
+		return
+	}
+
+	if err := ses.Delete(); db.CheckError(err, db.DELETE) {
+		response.Error(ctx, err.Error())
+		return
+	}
+
+	response.Ok(ctx)
+}
+
+// RevokeOtherUserSessions end the sessions of the login user except the
+// current one.
+func (h *Handler) RevokeOtherUserSessions(ctx *context.Context) {
+
+	if denyImpersonated(ctx) {
+		return
+	}
+
+	user := auth.Auth(ctx)
+
+	if err := auth.RevokeUserSessions(user.Id, auth.CurrentSessionId(ctx), h.conn); err != nil {
+		response.Error(ctx, err.Error())
+		return
+	}
+
+	response.Ok(ctx)
+}
+
+func userSessionList(sessions []models.UserSessionModel, current string) template.HTML {
+	if len(sessions) == 0 {
+		return template.HTML("<p>" + language.Get("no session") + "</p>")
+	}
+
+	var rows strings.Builder
+	for _, ses := range sessions {
+		name := ""
+		if ses.SessionId == current {
+			name = ` <span class="label label-success">` + language.Get("current session") + `</span>`
+		}
+		rows.WriteString(fmt.Sprintf(`<tr><td>%s%s</td><td>%s</td><td>%s</td><td>%s</td><td><button class="btn btn-xs btn-danger user-session-revoke" data-id="%d">%s</button></td></tr>`,
+			template.HTMLEscapeString(ses.Ip),
+			name,
+			template.HTMLEscapeString(ses.UserAgent),
+			template.HTMLEscapeString(ses.CreatedAt),
+			formatUnix(ses.LastSeenAt, "-"),
+			ses.Id,
+			language.Get("revoke")))
+	}
+
+	return template.HTML(fmt.Sprintf(`<table class="table table-striped">
+	<thead><tr><th>%s</th><th>%s</th><th>%s</th><th>%s</th><th></th></tr></thead>
+	<tbody>%s</tbody>
+</table>
+<button class="btn btn-default user-session-revoke-others">%s</button>`,
+		language.Get("ip"),
+		language.Get("user agent"),
+		language.Get("login at"),
+		language.Get("last seen"),
+		rows.String(),
+		language.Get("revoke all other sessions")))
+}
+
+func userSessionScript(prefix string) template.HTML {
+	return template.HTML(`<script>
+function userSessionRevoke(url, data) {
+	$.ajax({
+		method: "post",
+		url: url,
+		data: data,
+		success: function () {
+			$.pjax.reload("#pjax-container");
+		},
+		error: function (data) {
+			let msg = data.responseJSON ? data.responseJSON.msg : data.responseText;
+			if (typeof(swal) === "function") {
+				swal(msg, "", "error");
+			} else {
+				alert(msg);
+			}
+		}
+	});
+}
+$(".user-session-revoke").on("click", function () {
+	userSessionRevoke("` + prefix + `/revoke", {id: $(this).data("id")});
+});
+$(".user-session-revoke-others").on("click", function () {
+	userSessionRevoke("` + prefix + `/revoke_others", {});
+});
+</script>`)
+}
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/db/dialect"
+)
+
+// APITokenModel is api token model structure, a row of it is a personal
+// access token of the user for the admin api. Only the hash of the token
+// is stored.
+type APITokenModel struct {
+	Base
+
+	Id     int64
+	UserId int64
+	Name   string
+	Token  string
+	// Scopes is a scope per line, see auth.ParseAPIScopes.
+	Scopes string
+	// ExpiresAt, LastUsedAt and RevokedAt are unix seconds, zero is never.
+	ExpiresAt  int64
+	LastUsedAt int64
+	LastUsedIp string
+	RevokedAt  int64
+
+	CreatedAt string
+	UpdatedAt string
+}
+
+// APIToken return a default api token model.
+func APIToken() APITokenModel {
+	return APITokenModel{Base: Base{TableName: "goadmin_api_tokens"}}
+}
+
+func (t APITokenModel) SetConn(con db.Connection) APITokenModel {
+	t.Conn = con
+	return t
+}
+
+func (t APITokenModel) WithTx(tx *sql.Tx) APITokenModel {
+	t.Tx = tx
+	return t
+}
+
+// Find return a default api token model of given id.
+func (t APITokenModel) Find(id interface{}) APITokenModel {
+	item, _ := t.Table(t.TableName).Find(id)
+	if item == nil {
+		return t
+	}
+	return t.MapToModel(item)
+}
+
+// FindByToken return a default api token model of given token hash.
+func (t APITokenModel) FindByToken(token string) APITokenModel {
+	item, _ := t.Table(t.TableName).Where("token", "=", token).First()
+	if item == nil {
+		return t
+	}
+	return t.MapToModel(item)
+}
+
+// ListByUserId return the tokens of the user, the newest first.
+func (t APITokenModel) ListByUserId(userId int64) []APITokenModel {
+	items, _ := t.Table(t.TableName).
+		Where("user_id", "=", userId).
+		OrderBy("id", "desc").
+		All()
+
+	tokens := make([]APITokenModel, len(items))
+	for i, item := range items {
+		tokens[i] = APIToken().MapToModel(item)
+	}
+	return tokens
+}
+
+// IsEmpty check the api token model is empty or not.
+func (t APITokenModel) IsEmpty() bool {
+	return t.Id == int64(0)
+}
+
+// IsRevoked check the api token is revoked.
+func (t APITokenModel) IsRevoked() bool {
+	return t.RevokedAt > 0
+}
+
+// IsExpired check the api token is expired at given time.
+func (t APITokenModel) IsExpired(now time.Time) bool {
+	return t.ExpiresAt > 0 && t.ExpiresAt <= now.Unix()
+}
+
+// IsActive check the api token can be used at given time.
+func (t APITokenModel) IsActive(now time.Time) bool {
+	return !t.IsEmpty() && !t.IsRevoked() && !t.IsExpired(now)
+}
+
+// New add a token of the user.
+func (t APITokenModel) New(userId int64, name, token, scopes string, expiresAt int64) (APITokenModel, error) {
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+
+	id, err := t.WithTx(t.Tx).Table(t.TableName).Insert(dialect.H{
+		"user_id":    userId,
+		"name":       name,
+		"token":      token,
+		"scopes":     scopes,
+		"expires_at": expiresAt,
+		"created_at": now,
+		"updated_at": now,
+	})
+
+	t.Id = id
+	t.UserId = userId
+	t.Name = name
+	t.Token = token
+	t.Scopes = scopes
+	t.ExpiresAt = expiresAt
+	t.CreatedAt = now
+	t.UpdatedAt = now
+
+	return t, err
+}
+
+// UpdateLastUsed record the use of the token.
+func (t APITokenModel) UpdateLastUsed(at int64, ip string) (int64, error) {
+	t.LastUsedAt = at
+	t.LastUsedIp = ip
+	return t.WithTx(t.Tx).Table(t.TableName).
+		Where("id", "=", t.Id).
+		Update(dialect.H{
+			"last_used_at": at,
+			"last_used_ip": ip,
+		})
+}
+
+// Revoke revoke the token, the row is kept for the audit.
+func (t APITokenModel) Revoke(at int64) (int64, error) {
+	t.RevokedAt = at
+	return t.WithTx(t.Tx).Table(t.TableName).
+		Where("id", "=", t.Id).
+		Update(dialect.H{
+			"revoked_at": at,
+			"updated_at": time.Now().Format("2006-01-02 15:04:05"),
+		})
+}
+
+// DeleteByUserId delete the tokens of the user.
+func (t APITokenModel) DeleteByUserId(userId int64) error {
+	return t.WithTx(t.Tx).Table(t.TableName).
+		Where("user_id", "=", userId).
+		Delete()
+}
+
+// MapToModel get the api token model from given map.
+func (t APITokenModel) MapToModel(m map[string]interface{}) APITokenModel {
+	t.Id = m["id"].(int64)
+	t.UserId, _ = m["user_id"].(int64)
+	t.Name, _ = m["name"].(string)
+	t.Token, _ = m["token"].(string)
+	t.Scopes, _ = m["scopes"].(string)
+	t.ExpiresAt, _ = m["expires_at"].(int64)
+	t.LastUsedAt, _ = m["last_used_at"].(int64)
+	t.LastUsedIp, _ = m["last_used_ip"].(string)
+	t.RevokedAt, _ = m["revoked_at"].(int64)
+	t.CreatedAt, _ = m["created_at"].(string)
+	t.UpdatedAt, _ = m["updated_at"].(string)
+	return t
+}
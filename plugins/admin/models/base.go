@@ -19,5 +19,8 @@ func (b Base) SetConn(con db.Connection) Base {
 }
 
 func (b Base) Table(table string) *db.SQL {
+	if b.Tx != nil {
+		return db.Table(table).WithDriver(b.Conn).WithTx(b.Tx)
+	}
 	return db.Table(table).WithDriver(b.Conn)
 }
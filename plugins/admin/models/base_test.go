@@ -0,0 +1,38 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wowucco/go-admin/modules/db"
+)
+
+func TestBaseTableWithTx(t *testing.T) {
+	conn, clean := testSqliteConn(t)
+	defer clean()
+
+	user := User().SetConn(conn)
+	user.Id = 1
+
+	roles := func() int {
+		items, _ := db.WithDriver(conn).Table("goadmin_role_users").Where("user_id", "=", 1).All()
+		return len(items)
+	}
+
+	assert.Equal(t, roles(), 1)
+
+	_, err := db.WithDriver(conn).WithTransaction(func(tx *sql.Tx) (error, map[string]interface{}) {
+		assert.NoError(t, user.WithTx(tx).DeleteRoles())
+		for _, id := range []string{"2", "3"} {
+			_, err := user.WithTx(tx).AddRole(id)
+			assert.NoError(t, err)
+		}
+		return errors.New("rollback"), nil
+	})
+
+	// the writes of the models went through the transaction and are undone
+	assert.Error(t, err)
+	assert.Equal(t, roles(), 1)
+}
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/db/dialect"
+)
+
+// The kinds of the change requests.
+const (
+	ChangeRequestInsert = "insert"
+	ChangeRequestUpdate = "update"
+	ChangeRequestDelete = "delete"
+)
+
+// The statuses of the change requests.
+const (
+	ChangeRequestPending  = "pending"
+	ChangeRequestApproved = "approved"
+	ChangeRequestRejected = "rejected"
+)
+
+// ErrChangeRequestDecided is returned when the change request has been
+// approved or rejected already.
+var ErrChangeRequestDecided = errors.New("the change request has been decided")
+
+// ChangeRequestModel is change request model structure. It is a change of a
+// table which waits for the approval of a second user, the data are the form
+// values of the change and the diff is shown to the approvers.
+type ChangeRequestModel struct {
+	Base
+
+	Id          int64
+	Prefix      string
+	Kind        string
+	RecordId    string
+	Permission  string
+	Data        string
+	Diff        string
+	Status      string
+	RequesterId int64
+	ApproverId  int64
+	Comment     string
+
+	CreatedAt string
+	UpdatedAt string
+}
+
+// ChangeRequest return a default change request model.
+func ChangeRequest() ChangeRequestModel {
+	return ChangeRequestModel{Base: Base{TableName: "goadmin_change_requests"}}
+}
+
+func (t ChangeRequestModel) SetConn(con db.Connection) ChangeRequestModel {
+	t.Conn = con
+	return t
+}
+
+// WithTx set the transaction of the model.
+func (t ChangeRequestModel) WithTx(tx *sql.Tx) ChangeRequestModel {
+	t.Tx = tx
+	return t
+}
+
+// Find return a default change request model of given id.
+func (t ChangeRequestModel) Find(id interface{}) ChangeRequestModel {
+	item, _ := t.Table(t.TableName).Where("id", "=", id).First()
+	if item == nil {
+		return t
+	}
+	return t.MapToModel(item)
+}
+
+// IsEmpty check the change request model is empty or not.
+func (t ChangeRequestModel) IsEmpty() bool {
+	return t.Id == int64(0)
+}
+
+// IsPending check the change request waits for the approval.
+func (t ChangeRequestModel) IsPending() bool {
+	return t.Status == ChangeRequestPending
+}
+
+// New create a pending change request model.
+func (t ChangeRequestModel) New(prefix, kind, recordId, permission, data, diff string, requesterId int64) (ChangeRequestModel, error) {
+
+	id, err := t.Table(t.TableName).Insert(dialect.H{
+		"prefix":       prefix,
+		"kind":         kind,
+		"record_id":    recordId,
+		"permission":   permission,
+		"data":         data,
+		"diff":         diff,
+		"status":       ChangeRequestPending,
+		"requester_id": requesterId,
+	})
+
+	if db.CheckError(err, db.INSERT) {
+		return t, err
+	}
+
+	t.Id = id
+	t.Prefix = prefix
+	t.Kind = kind
+	t.RecordId = recordId
+	t.Permission = permission
+	t.Data = data
+	t.Diff = diff
+	t.Status = ChangeRequestPending
+	t.RequesterId = requesterId
+
+	return t, nil
+}
+
+// Decide set the status of the pending change request, only one decision of
+// it succeeds, the others get ErrChangeRequestDecided.
+func (t ChangeRequestModel) Decide(status string, approverId int64, comment string) (ChangeRequestModel, error) {
+	_, err := t.Table(t.TableName).WithTx(t.Tx).
+		Where("id", "=", t.Id).
+		Where("status", "=", ChangeRequestPending).
+		Update(dialect.H{
+			"status":      status,
+			"approver_id": approverId,
+			"comment":     comment,
+			"updated_at":  time.Now().Format("2006-01-02 15:04:05"),
+		})
+	if err != nil && strings.Contains(err.Error(), "no affect") {
+		return t, ErrChangeRequestDecided
+	}
+	if db.CheckError(err, db.UPDATE) {
+		return t, err
+	}
+	t.Status = status
+	t.ApproverId = approverId
+	t.Comment = comment
+	return t, nil
+}
+
+// Reopen set the approved change request pending again, when the change
+// fails to be applied.
+func (t ChangeRequestModel) Reopen(comment string) error {
+	_, err := t.Table(t.TableName).
+		Where("id", "=", t.Id).
+		Update(dialect.H{
+			"status":      ChangeRequestPending,
+			"approver_id": 0,
+			"comment":     comment,
+			"updated_at":  time.Now().Format("2006-01-02 15:04:05"),
+		})
+	if db.CheckError(err, db.UPDATE) {
+		return err
+	}
+	return nil
+}
+
+// MapToModel get the change request model from given map.
+func (t ChangeRequestModel) MapToModel(m map[string]interface{}) ChangeRequestModel {
+	t.Id, _ = m["id"].(int64)
+	t.Prefix, _ = m["prefix"].(string)
+	t.Kind, _ = m["kind"].(string)
+	t.RecordId, _ = m["record_id"].(string)
+	t.Permission, _ = m["permission"].(string)
+	t.Data, _ = m["data"].(string)
+	t.Diff, _ = m["diff"].(string)
+	t.Status, _ = m["status"].(string)
+	t.RequesterId, _ = m["requester_id"].(int64)
+	t.ApproverId, _ = m["approver_id"].(int64)
+	t.Comment, _ = m["comment"].(string)
+	t.CreatedAt, _ = m["created_at"].(string)
+	t.UpdatedAt, _ = m["updated_at"].(string)
+	return t
+}
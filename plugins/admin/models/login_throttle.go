@@ -0,0 +1,181 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/db/dialect"
+)
+
+// The kinds of the login throttles.
+const (
+	LoginThrottleUsername = "username"
+	LoginThrottleIP       = "ip"
+)
+
+// LoginThrottleModel is login throttle model structure, a row of it counts
+// the failed logins of a username or an ip.
+type LoginThrottleModel struct {
+	Base
+
+	Id       int64
+	Kind     string
+	Value    string
+	Failures int64
+	// LockedUntil and LastFailedAt are unix seconds.
+	LockedUntil  int64
+	LastFailedAt int64
+
+	CreatedAt string
+	UpdatedAt string
+}
+
+// LoginThrottle return a default login throttle model.
+func LoginThrottle() LoginThrottleModel {
+	return LoginThrottleModel{Base: Base{TableName: "goadmin_login_throttles"}}
+}
+
+func (t LoginThrottleModel) SetConn(con db.Connection) LoginThrottleModel {
+	t.Conn = con
+	return t
+}
+
+func (t LoginThrottleModel) WithTx(tx *sql.Tx) LoginThrottleModel {
+	t.Tx = tx
+	return t
+}
+
+// Find return a default login throttle model of given kind and value.
+func (t LoginThrottleModel) Find(kind, value string) LoginThrottleModel {
+	t.Kind = kind
+	t.Value = value
+	item, _ := t.Table(t.TableName).
+		Where("kind", "=", kind).
+		Where("value", "=", value).
+		First()
+	if item == nil {
+		return t
+	}
+	return t.MapToModel(item)
+}
+
+// FindById return a default login throttle model of given id.
+func (t LoginThrottleModel) FindById(id interface{}) LoginThrottleModel {
+	item, _ := t.Table(t.TableName).Where("id", "=", id).First()
+	if item == nil {
+		return t
+	}
+	return t.MapToModel(item)
+}
+
+// IsEmpty check the login throttle model is empty or not.
+func (t LoginThrottleModel) IsEmpty() bool {
+	return t.Id == int64(0)
+}
+
+// IsLocked check the login throttle is locked at given time.
+func (t LoginThrottleModel) IsLocked(now time.Time) bool {
+	return t.LockedUntil > now.Unix()
+}
+
+// Save store the failures and the lock.
+func (t LoginThrottleModel) Save() (LoginThrottleModel, error) {
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+
+	values := dialect.H{
+		"failures":       t.Failures,
+		"locked_until":   t.LockedUntil,
+		"last_failed_at": t.LastFailedAt,
+		"updated_at":     now,
+	}
+
+	if t.IsEmpty() {
+		values["kind"] = t.Kind
+		values["value"] = t.Value
+		values["created_at"] = now
+		id, err := t.WithTx(t.Tx).Table(t.TableName).Insert(values)
+		t.Id = id
+		return t, err
+	}
+
+	_, err := t.WithTx(t.Tx).Table(t.TableName).Where("id", "=", t.Id).Update(values)
+	if db.CheckError(err, db.UPDATE) {
+		return t, err
+	}
+	return t, nil
+}
+
+// Fail count a failed login at now with one update, so that the concurrent
+// failures are all counted. The failures restart from one when there was no
+// failure nor lockout since resetBefore. It returns the stored throttle.
+func (t LoginThrottleModel) Fail(now, resetBefore int64) (LoginThrottleModel, error) {
+	if t.IsEmpty() {
+		t = t.Find(t.Kind, t.Value)
+	}
+
+	if t.IsEmpty() {
+		created := time.Now().Format("2006-01-02 15:04:05")
+		_, err := t.WithTx(t.Tx).Table(t.TableName).Insert(dialect.H{
+			"kind":       t.Kind,
+			"value":      t.Value,
+			"created_at": created,
+			"updated_at": created,
+		})
+		// a concurrent failure may have inserted the row first
+		if t = t.Find(t.Kind, t.Value); t.IsEmpty() {
+			return t, err
+		}
+	}
+
+	// the failures are assigned first, the databases which apply the
+	// assignments in order must compare with the old times
+	_, err := t.WithTx(t.Tx).Table(t.TableName).
+		Where("id", "=", t.Id).
+		UpdateRaw("failures = CASE WHEN last_failed_at < ? AND locked_until < ? THEN 1 ELSE failures + 1 END",
+			resetBefore, resetBefore).
+		UpdateRaw("last_failed_at = ?", now).
+		UpdateRaw("updated_at = ?", time.Now().Format("2006-01-02 15:04:05")).
+		Update(dialect.H{})
+	if db.CheckError(err, db.UPDATE) {
+		return t, err
+	}
+
+	return t.FindById(t.Id), nil
+}
+
+// Lock lock the login throttle until given time, a longer lock is kept.
+func (t LoginThrottleModel) Lock(until int64) (LoginThrottleModel, error) {
+	_, err := t.WithTx(t.Tx).Table(t.TableName).
+		Where("id", "=", t.Id).
+		Where("locked_until", "<", until).
+		Update(dialect.H{
+			"locked_until": until,
+			"updated_at":   time.Now().Format("2006-01-02 15:04:05"),
+		})
+	if db.CheckError(err, db.UPDATE) {
+		return t, err
+	}
+	return t.FindById(t.Id), nil
+}
+
+// Delete delete the login throttle, which unlocks it.
+func (t LoginThrottleModel) Delete() error {
+	return t.WithTx(t.Tx).Table(t.TableName).
+		Where("id", "=", t.Id).
+		Delete()
+}
+
+// MapToModel get the login throttle model from given map.
+func (t LoginThrottleModel) MapToModel(m map[string]interface{}) LoginThrottleModel {
+	t.Id = m["id"].(int64)
+	t.Kind, _ = m["kind"].(string)
+	t.Value, _ = m["value"].(string)
+	t.Failures, _ = m["failures"].(int64)
+	t.LockedUntil, _ = m["locked_until"].(int64)
+	t.LastFailedAt, _ = m["last_failed_at"].(int64)
+	t.CreatedAt, _ = m["created_at"].(string)
+	t.UpdatedAt, _ = m["updated_at"].(string)
+	return t
+}
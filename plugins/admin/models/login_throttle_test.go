@@ -0,0 +1,72 @@
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/db"
+	_ "github.com/wowucco/go-admin/modules/db/drivers/sqlite"
+)
+
+// testSqliteConn return a connection to a copy of the sqlite fixture and a
+// function removing the copy.
+func testSqliteConn(t *testing.T) (db.Connection, func()) {
+	data, err := ioutil.ReadFile("../../../data/admin.db")
+	assert.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "goadmin")
+	assert.NoError(t, err)
+
+	file := filepath.Join(dir, "admin.db")
+	assert.NoError(t, ioutil.WriteFile(file, data, 0644))
+
+	conn := db.GetConnectionByDriver(db.DriverSqlite).InitDB(config.DatabaseList{
+		"default": {Driver: db.DriverSqlite, File: file},
+	})
+	return conn, func() { _ = os.RemoveAll(dir) }
+}
+
+func TestLoginThrottleFail(t *testing.T) {
+	conn, clean := testSqliteConn(t)
+	defer clean()
+
+	throttle := func() LoginThrottleModel {
+		th := LoginThrottle().SetConn(conn)
+		th.Kind, th.Value = LoginThrottleUsername, "admin"
+		return th
+	}
+
+	now := int64(100000)
+
+	th, err := throttle().Fail(now, now-900)
+	assert.NoError(t, err)
+	assert.Equal(t, th.Failures, int64(1))
+	assert.Equal(t, th.LastFailedAt, now)
+
+	// the failures of the throttles which were not loaded are counted
+	for i := 0; i < 3; i++ {
+		th, err = throttle().Fail(now+60, now+60-900)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, th.Failures, int64(4))
+
+	// the quiet period starts when the lockout ends
+	th, err = th.Lock(now + 600)
+	assert.NoError(t, err)
+	th, err = th.Fail(now+1200, now+1200-900)
+	assert.NoError(t, err)
+	assert.Equal(t, th.Failures, int64(5))
+
+	// a shorter lock does not shorten the lockout
+	th, err = th.Lock(now + 300)
+	assert.NoError(t, err)
+	assert.Equal(t, th.LockedUntil, now+600)
+
+	th, err = th.Fail(now+3000, now+3000-900)
+	assert.NoError(t, err)
+	assert.Equal(t, th.Failures, int64(1))
+}
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/db/dialect"
+)
+
+// MediaModel is media model structure, a row of it records one uploaded file.
+type MediaModel struct {
+	Base
+
+	Id     int64
+	UserId int64
+	Engine string
+	// Prefix and Field are the table and the file field the file was
+	// uploaded for, empty for the files which are not uploaded by a form.
+	Prefix string
+	Field  string
+	Path   string
+	Name   string
+	Size   int64
+	Mime   string
+	// Variants is the names of the image variants generated at upload.
+	Variants []string
+	// Refs is the number of references found by the last scan of the tables.
+	Refs int64
+
+	CreatedAt string
+	UpdatedAt string
+}
+
+// Media return a default media model.
+func Media() MediaModel {
+	return MediaModel{Base: Base{TableName: "goadmin_media"}}
+}
+
+func (t MediaModel) SetConn(con db.Connection) MediaModel {
+	t.Conn = con
+	return t
+}
+
+func (t MediaModel) WithTx(tx *sql.Tx) MediaModel {
+	t.Tx = tx
+	return t
+}
+
+// Find return a default media model of given id.
+func (t MediaModel) Find(id interface{}) MediaModel {
+	item, _ := t.Table(t.TableName).Find(id)
+	if item == nil {
+		return t
+	}
+	return t.MapToModel(item)
+}
+
+// FindByPath return a default media model of given stored path.
+func (t MediaModel) FindByPath(path string) MediaModel {
+	item, _ := t.Table(t.TableName).Where("path", "=", path).First()
+	if item == nil {
+		return t
+	}
+	return t.MapToModel(item)
+}
+
+// FindVariants return the names of the image variants generated for the
+// media of given stored path, it implements the file.VariantStore.
+func (t MediaModel) FindVariants(path string) ([]string, error) {
+	items, err := t.Table(t.TableName).Select("variants").Where("path", "=", path).All()
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	variants, _ := items[0]["variants"].(string)
+	return splitVariants(variants), nil
+}
+
+// IsEmpty check the media model is empty or not.
+func (t MediaModel) IsEmpty() bool {
+	return t.Id == int64(0)
+}
+
+// New create a media model of the file uploaded for the field of the table
+// of the prefix.
+func (t MediaModel) New(userId int64, engine, prefix, field, path, name string, size int64,
+	mime string, variants []string) (MediaModel, error) {
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+
+	id, err := t.WithTx(t.Tx).Table(t.TableName).Insert(dialect.H{
+		"user_id":    userId,
+		"engine":     engine,
+		"prefix":     prefix,
+		"field":      field,
+		"path":       path,
+		"name":       name,
+		"size":       size,
+		"mime":       mime,
+		"variants":   strings.Join(variants, ","),
+		"refs":       0,
+		"created_at": now,
+		"updated_at": now,
+	})
+
+	t.Id = id
+	t.UserId = userId
+	t.Engine = engine
+	t.Prefix = prefix
+	t.Field = field
+	t.Path = path
+	t.Name = name
+	t.Size = size
+	t.Mime = mime
+	t.Variants = variants
+	t.CreatedAt = now
+	t.UpdatedAt = now
+
+	return t, err
+}
+
+// UpdateRefs update the number of references of the media.
+func (t MediaModel) UpdateRefs(refs int64) error {
+	if t.Refs == refs {
+		return nil
+	}
+	_, err := t.WithTx(t.Tx).Table(t.TableName).
+		Where("id", "=", t.Id).
+		Update(dialect.H{
+			"refs":       refs,
+			"updated_at": time.Now().Format("2006-01-02 15:04:05"),
+		})
+	if db.CheckError(err, db.UPDATE) {
+		return err
+	}
+	return nil
+}
+
+// Delete delete the media record, the stored file is not touched.
+func (t MediaModel) Delete() error {
+	return t.WithTx(t.Tx).Table(t.TableName).
+		Where("id", "=", t.Id).
+		Delete()
+}
+
+// MapToModel get the media model from given map.
+func (t MediaModel) MapToModel(m map[string]interface{}) MediaModel {
+	t.Id = m["id"].(int64)
+	t.UserId, _ = m["user_id"].(int64)
+	t.Engine, _ = m["engine"].(string)
+	t.Prefix, _ = m["prefix"].(string)
+	t.Field, _ = m["field"].(string)
+	t.Path, _ = m["path"].(string)
+	t.Name, _ = m["name"].(string)
+	t.Size, _ = m["size"].(int64)
+	t.Mime, _ = m["mime"].(string)
+	variants, _ := m["variants"].(string)
+	t.Variants = splitVariants(variants)
+	t.Refs, _ = m["refs"].(int64)
+	t.CreatedAt, _ = m["created_at"].(string)
+	t.UpdatedAt, _ = m["updated_at"].(string)
+	return t
+}
+
+func splitVariants(variants string) []string {
+	if variants == "" {
+		return nil
+	}
+	return strings.Split(variants, ",")
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMediaFindVariants(t *testing.T) {
+	conn, clean := testSqliteConn(t)
+	defer clean()
+
+	media := Media().SetConn(conn)
+
+	_, err := media.New(1, "local", "posts", "cover", "a.png", "a.png", 10, "image/png", []string{"medium", "thumb"})
+	assert.NoError(t, err)
+	_, err = media.New(1, "local", "posts", "attachment", "b.txt", "b.txt", 10, "text/plain", nil)
+	assert.NoError(t, err)
+
+	variants, err := media.FindVariants("a.png")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"medium", "thumb"}, variants)
+
+	variants, err = media.FindVariants("b.txt")
+	assert.NoError(t, err)
+	assert.Nil(t, variants)
+
+	// the files uploaded before the media library have no record
+	variants, err = media.FindVariants("c.png")
+	assert.NoError(t, err)
+	assert.Nil(t, variants)
+
+	assert.Equal(t, []string{"medium", "thumb"}, media.FindByPath("a.png").Variants)
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/wowucco/go-admin/modules/db"
 	"github.com/wowucco/go-admin/modules/db/dialect"
 )
@@ -17,6 +19,46 @@ type OperationLogModel struct {
 	Input     string
 	CreatedAt string
 	UpdatedAt string
+
+	// ImpersonatorId is the real user of the operation when the user is
+	// impersonated, otherwise it is zero.
+	ImpersonatorId int64
+
+	// Prefix is the prefix of the table, the RecordId is the primary key of
+	// the record and the Status is the http status of the response.
+	Prefix   string
+	RecordId string
+	Action   string
+	Status   int
+}
+
+// The actions of the operation logs.
+const (
+	OperationLogQuery      = "query"
+	OperationLogDetail     = "detail"
+	OperationLogShowEdit   = "show_edit"
+	OperationLogShowCreate = "show_create"
+	OperationLogEdit       = "edit"
+	OperationLogCreate     = "create"
+	OperationLogDelete     = "delete"
+	OperationLogExport     = "export"
+	OperationLogLogin      = "login"
+	OperationLogLogout     = "logout"
+	OperationLogOther      = "other"
+)
+
+// OperationLogEntry is the values of a new operation log.
+type OperationLogEntry struct {
+	UserId         int64
+	ImpersonatorId int64
+	Path           string
+	Method         string
+	Ip             string
+	Input          string
+	Prefix         string
+	RecordId       string
+	Action         string
+	Status         int
 }
 
 // OperationLog return a default operation log model.
@@ -37,25 +79,78 @@ func (t OperationLogModel) SetConn(con db.Connection) OperationLogModel {
 
 // New create a new operation log model.
 func (t OperationLogModel) New(userId int64, path, method, ip, input string) OperationLogModel {
+	return t.NewEntry(OperationLogEntry{UserId: userId, Path: path, Method: method, Ip: ip, Input: input})
+}
 
-	id, _ := t.Table(t.TableName).Insert(dialect.H{
-		"user_id": userId,
-		"path":    path,
-		"method":  method,
-		"ip":      ip,
-		"input":   input,
-	})
+// NewByUser create a new operation log model of the user, which records the
+// real user as well when the user is impersonated.
+func (t OperationLogModel) NewByUser(user UserModel, path, method, ip, input string) OperationLogModel {
+	return t.NewEntry(OperationLogEntry{UserId: user.Id, ImpersonatorId: user.ImpersonatorId,
+		Path: path, Method: method, Ip: ip, Input: input})
+}
+
+// NewEntry create a new operation log model of the entry.
+func (t OperationLogModel) NewEntry(entry OperationLogEntry) OperationLogModel {
+
+	values := dialect.H{
+		"user_id": entry.UserId,
+		"path":    entry.Path,
+		"method":  entry.Method,
+		"ip":      entry.Ip,
+		"input":   entry.Input,
+	}
+	if entry.ImpersonatorId != 0 {
+		values["impersonator_id"] = entry.ImpersonatorId
+	}
+	if entry.Prefix != "" {
+		values["prefix"] = entry.Prefix
+	}
+	if entry.RecordId != "" {
+		values["record_id"] = entry.RecordId
+	}
+	if entry.Action != "" {
+		values["action"] = entry.Action
+	}
+	if entry.Status != 0 {
+		values["status"] = entry.Status
+	}
+
+	id, _ := t.Table(t.TableName).Insert(values)
 
 	t.Id = id
-	t.UserId = userId
-	t.Path = path
-	t.Method = method
-	t.Ip = ip
-	t.Input = input
+	t.UserId = entry.UserId
+	t.ImpersonatorId = entry.ImpersonatorId
+	t.Path = entry.Path
+	t.Method = entry.Method
+	t.Ip = entry.Ip
+	t.Input = entry.Input
+	t.Prefix = entry.Prefix
+	t.RecordId = entry.RecordId
+	t.Action = entry.Action
+	t.Status = entry.Status
 
 	return t
 }
 
+// Expired return at most limit logs created before the time, the oldest
+// first.
+func (t OperationLogModel) Expired(before time.Time, limit int) ([]map[string]interface{}, error) {
+	return t.Table(t.TableName).
+		Where("created_at", "<", before.Format("2006-01-02 15:04:05")).
+		OrderBy("id", "asc").
+		Take(limit).
+		All()
+}
+
+// DeleteExpired delete the logs created before the time whose ids are not
+// greater than the last id.
+func (t OperationLogModel) DeleteExpired(before time.Time, lastId int64) error {
+	return t.Table(t.TableName).
+		Where("created_at", "<", before.Format("2006-01-02 15:04:05")).
+		Where("id", "<=", lastId).
+		Delete()
+}
+
 // MapToModel get the operation log model from given map.
 func (t OperationLogModel) MapToModel(m map[string]interface{}) OperationLogModel {
 	t.Id = m["id"].(int64)
@@ -64,6 +159,12 @@ func (t OperationLogModel) MapToModel(m map[string]interface{}) OperationLogMode
 	t.Method, _ = m["method"].(string)
 	t.Ip, _ = m["ip"].(string)
 	t.Input, _ = m["input"].(string)
+	t.ImpersonatorId, _ = m["impersonator_id"].(int64)
+	t.Prefix, _ = m["prefix"].(string)
+	t.RecordId, _ = m["record_id"].(string)
+	t.Action, _ = m["action"].(string)
+	status, _ := m["status"].(int64)
+	t.Status = int(status)
 	t.CreatedAt, _ = m["created_at"].(string)
 	t.UpdatedAt, _ = m["updated_at"].(string)
 	return t
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/db/dialect"
+)
+
+// PasswordHistoryModel is password history model structure, a row of it is
+// a password hash which the user had.
+type PasswordHistoryModel struct {
+	Base
+
+	Id       int64
+	UserId   int64
+	Password string
+
+	CreatedAt string
+	UpdatedAt string
+}
+
+// PasswordHistory return a default password history model.
+func PasswordHistory() PasswordHistoryModel {
+	return PasswordHistoryModel{Base: Base{TableName: "goadmin_password_histories"}}
+}
+
+func (t PasswordHistoryModel) SetConn(con db.Connection) PasswordHistoryModel {
+	t.Conn = con
+	return t
+}
+
+func (t PasswordHistoryModel) WithTx(tx *sql.Tx) PasswordHistoryModel {
+	t.Tx = tx
+	return t
+}
+
+// Latest return the last n passwords of the user, the newest first.
+func (t PasswordHistoryModel) Latest(userId interface{}, n int) []PasswordHistoryModel {
+	items, _ := t.Table(t.TableName).
+		Where("user_id", "=", userId).
+		OrderBy("id", "desc").
+		Take(n).
+		All()
+	list := make([]PasswordHistoryModel, len(items))
+	for k, item := range items {
+		list[k] = t.MapToModel(item)
+	}
+	return list
+}
+
+// New add the password hash of the user.
+func (t PasswordHistoryModel) New(userId int64, password string) (PasswordHistoryModel, error) {
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+
+	id, err := t.WithTx(t.Tx).Table(t.TableName).Insert(dialect.H{
+		"user_id":    userId,
+		"password":   password,
+		"created_at": now,
+		"updated_at": now,
+	})
+
+	t.Id = id
+	t.UserId = userId
+	t.Password = password
+	t.CreatedAt = now
+	t.UpdatedAt = now
+
+	return t, err
+}
+
+// Prune delete the passwords of the user except the last keep ones.
+func (t PasswordHistoryModel) Prune(userId int64, keep int) error {
+	items, err := t.Table(t.TableName).
+		Select("id").
+		Where("user_id", "=", userId).
+		OrderBy("id", "desc").
+		All()
+	if err != nil || len(items) <= keep {
+		return err
+	}
+	ids := make([]interface{}, 0, len(items)-keep)
+	for _, item := range items[keep:] {
+		ids = append(ids, item["id"])
+	}
+	return t.WithTx(t.Tx).Table(t.TableName).WhereIn("id", ids).Delete()
+}
+
+// MapToModel get the password history model from given map.
+func (t PasswordHistoryModel) MapToModel(m map[string]interface{}) PasswordHistoryModel {
+	t.Id = m["id"].(int64)
+	t.UserId, _ = m["user_id"].(int64)
+	t.Password, _ = m["password"].(string)
+	t.CreatedAt, _ = m["created_at"].(string)
+	t.UpdatedAt, _ = m["updated_at"].(string)
+	return t
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/db/dialect"
+)
+
+// PasswordResetModel is password reset model structure, a row of it is a
+// single-use token to set the password of the user. Only the hash of the
+// token is stored.
+type PasswordResetModel struct {
+	Base
+
+	Id     int64
+	UserId int64
+	Token  string
+	// ExpiresAt is unix seconds.
+	ExpiresAt int64
+
+	CreatedAt string
+	UpdatedAt string
+}
+
+// PasswordReset return a default password reset model.
+func PasswordReset() PasswordResetModel {
+	return PasswordResetModel{Base: Base{TableName: "goadmin_password_resets"}}
+}
+
+func (t PasswordResetModel) SetConn(con db.Connection) PasswordResetModel {
+	t.Conn = con
+	return t
+}
+
+func (t PasswordResetModel) WithTx(tx *sql.Tx) PasswordResetModel {
+	t.Tx = tx
+	return t
+}
+
+// FindByToken return a default password reset model of given token hash.
+func (t PasswordResetModel) FindByToken(token string) PasswordResetModel {
+	item, _ := t.Table(t.TableName).Where("token", "=", token).First()
+	if item == nil {
+		return t
+	}
+	return t.MapToModel(item)
+}
+
+// IsEmpty check the password reset model is empty or not.
+func (t PasswordResetModel) IsEmpty() bool {
+	return t.Id == int64(0)
+}
+
+// IsExpired check the password reset is expired at given time.
+func (t PasswordResetModel) IsExpired(now time.Time) bool {
+	return t.ExpiresAt <= now.Unix()
+}
+
+// New add a token of the user.
+func (t PasswordResetModel) New(userId int64, token string, expiresAt int64) (PasswordResetModel, error) {
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+
+	id, err := t.WithTx(t.Tx).Table(t.TableName).Insert(dialect.H{
+		"user_id":    userId,
+		"token":      token,
+		"expires_at": expiresAt,
+		"created_at": now,
+		"updated_at": now,
+	})
+
+	t.Id = id
+	t.UserId = userId
+	t.Token = token
+	t.ExpiresAt = expiresAt
+	t.CreatedAt = now
+	t.UpdatedAt = now
+
+	return t, err
+}
+
+// DeleteByUserId delete the tokens of the user.
+func (t PasswordResetModel) DeleteByUserId(userId int64) error {
+	return t.WithTx(t.Tx).Table(t.TableName).
+		Where("user_id", "=", userId).
+		Delete()
+}
+
+// Consume delete the token if it is not expired at given time, false if the
+// token is used or expired. A token is consumed by one request only.
+func (t PasswordResetModel) Consume(token string, now time.Time) (bool, error) {
+	err := t.WithTx(t.Tx).Table(t.TableName).
+		Where("token", "=", token).
+		Where("expires_at", ">", now.Unix()).
+		Delete()
+	if err != nil && strings.Contains(err.Error(), "no affect") {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteExpired delete the tokens expired at given time.
+func (t PasswordResetModel) DeleteExpired(now time.Time) error {
+	return t.WithTx(t.Tx).Table(t.TableName).
+		Where("expires_at", "<=", now.Unix()).
+		Delete()
+}
+
+// MapToModel get the password reset model from given map.
+func (t PasswordResetModel) MapToModel(m map[string]interface{}) PasswordResetModel {
+	t.Id = m["id"].(int64)
+	t.UserId, _ = m["user_id"].(int64)
+	t.Token, _ = m["token"].(string)
+	t.ExpiresAt, _ = m["expires_at"].(int64)
+	t.CreatedAt, _ = m["created_at"].(string)
+	t.UpdatedAt, _ = m["updated_at"].(string)
+	return t
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/logger"
+)
+
+// PermissionMatcher matches the requests with the paths of the permissions,
+// which are compiled once. The literal paths are found by a map and the
+// patterns by a trie of their literal prefixes, so a request only runs the
+// patterns which can match it.
+type PermissionMatcher struct {
+	all   []permissionRule
+	exact map[string][]permissionRule
+	root  *prefixNode
+}
+
+type permissionRule struct {
+	methods []string
+	params  url.Values
+	reg     *regexp.Regexp
+}
+
+type prefixNode struct {
+	children map[byte]*prefixNode
+	rules    []permissionRule
+}
+
+// NewPermissionMatcher compile the paths of the permissions.
+func NewPermissionMatcher(permissions []PermissionModel) *PermissionMatcher {
+	m := &PermissionMatcher{
+		exact: make(map[string][]permissionRule),
+		root:  new(prefixNode),
+	}
+
+	for _, v := range permissions {
+		if len(v.HttpPath) == 0 {
+			continue
+		}
+
+		methods := v.HttpMethod
+		if len(methods) == 0 || methods[0] == "" {
+			methods = nil
+		}
+
+		if v.HttpPath[0] == "*" {
+			m.all = append(m.all, permissionRule{methods: methods})
+			continue
+		}
+
+		for i := 0; i < len(v.HttpPath); i++ {
+			matchPath := config.Url(strings.TrimSpace(v.HttpPath[i]))
+			matchPath, matchParam := getParam(matchPath)
+
+			rule := permissionRule{methods: methods, params: matchParam}
+			m.exact[matchPath] = append(m.exact[matchPath], rule)
+
+			if regexp.QuoteMeta(matchPath) == matchPath {
+				continue
+			}
+
+			reg, err := compilePermissionPath(matchPath)
+			if err != nil {
+				logger.Error("CheckPermissions error: ", err)
+				continue
+			}
+
+			rule.reg = reg
+			prefix, _ := reg.LiteralPrefix()
+			m.root.insert(prefix, rule)
+		}
+	}
+
+	return m
+}
+
+// Match check if any of the permissions matches the path, the method and the
+// params of the request.
+func (m *PermissionMatcher) Match(path, method string, params url.Values) bool {
+	for _, rule := range m.all {
+		if rule.allowMethod(method) {
+			return true
+		}
+	}
+
+	for _, rule := range m.exact[path] {
+		if rule.allowMethod(method) && checkParam(params, rule.params) {
+			return true
+		}
+	}
+
+	node := m.root
+	for i := 0; node != nil; i++ {
+		for _, rule := range node.rules {
+			if rule.allowMethod(method) && rule.reg.FindString(path) == path && checkParam(params, rule.params) {
+				return true
+			}
+		}
+		if i == len(path) {
+			break
+		}
+		node = node.children[path[i]]
+	}
+
+	return false
+}
+
+func (rule permissionRule) allowMethod(method string) bool {
+	return rule.methods == nil || inMethodArr(rule.methods, method)
+}
+
+func (node *prefixNode) insert(prefix string, rule permissionRule) {
+	for i := 0; i < len(prefix); i++ {
+		if node.children == nil {
+			node.children = make(map[byte]*prefixNode)
+		}
+		child, ok := node.children[prefix[i]]
+		if !ok {
+			child = new(prefixNode)
+			node.children[prefix[i]] = child
+		}
+		node = child
+	}
+	node.rules = append(node.rules, rule)
+}
+
+// the patterns are shared by the users, so they are compiled once
+var permissionRegexps = struct {
+	sync.RWMutex
+	m map[string]*regexp.Regexp
+}{m: make(map[string]*regexp.Regexp)}
+
+func compilePermissionPath(pattern string) (*regexp.Regexp, error) {
+	permissionRegexps.RLock()
+	reg, ok := permissionRegexps.m[pattern]
+	permissionRegexps.RUnlock()
+	if ok {
+		return reg, nil
+	}
+
+	reg, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	permissionRegexps.Lock()
+	permissionRegexps.m[pattern] = reg
+	permissionRegexps.Unlock()
+	return reg, nil
+}
@@ -15,6 +15,8 @@ type RoleModel struct {
 	Id        int64
 	Name      string
 	Slug      string
+	ParentId  int64
+	TwoFactor bool
 	CreatedAt string
 	UpdatedAt string
 }
@@ -46,6 +48,20 @@ func (t RoleModel) Find(id interface{}) RoleModel {
 	return t.MapToModel(item)
 }
 
+// FindBySlug return a default role model of given slug.
+func (t RoleModel) FindBySlug(slug string) RoleModel {
+	item, _ := t.Table(t.TableName).Where("slug", "=", slug).First()
+	if item == nil {
+		return t
+	}
+	return t.MapToModel(item)
+}
+
+// IsEmpty check the role model is empty or not.
+func (t RoleModel) IsEmpty() bool {
+	return t.Id == int64(0)
+}
+
 // IsSlugExist check the row exist with given slug and id.
 func (t RoleModel) IsSlugExist(slug string, id string) bool {
 	if id == "" {
@@ -86,6 +102,158 @@ func (t RoleModel) Update(name, slug string) (int64, error) {
 		})
 }
 
+// IsTwoFactorRequired check if the role requires the two-factor
+// authentication.
+func (t RoleModel) IsTwoFactorRequired() bool {
+	item, _ := t.Table(t.TableName).
+		Select("two_factor").
+		Where("id", "=", t.Id).
+		First()
+	if item == nil {
+		return false
+	}
+	required, _ := item["two_factor"].(int64)
+	return required == 1
+}
+
+// UpdateTwoFactor set if the role requires the two-factor authentication.
+func (t RoleModel) UpdateTwoFactor(required bool) error {
+	value := 0
+	if required {
+		value = 1
+	}
+	_, err := t.WithTx(t.Tx).Table(t.TableName).
+		Where("id", "=", t.Id).
+		Update(dialect.H{
+			"two_factor": value,
+		})
+	if db.CheckError(err, db.UPDATE) {
+		return err
+	}
+	return nil
+}
+
+// UpdateParent set the parent role, whose permissions and menus are
+// inherited by the role.
+func (t RoleModel) UpdateParent(parentId int64) error {
+	_, err := t.WithTx(t.Tx).Table(t.TableName).
+		Where("id", "=", t.Id).
+		Update(dialect.H{
+			"parent_id": parentId,
+		})
+	if db.CheckError(err, db.UPDATE) {
+		return err
+	}
+	return nil
+}
+
+// parents return the parent ids of all the roles. It is empty when the
+// roles have no parent column yet.
+func (t RoleModel) parents() map[int64]int64 {
+	items, err := t.Table(t.TableName).Select("id", "parent_id").All()
+	parents := make(map[int64]int64, len(items))
+	if err != nil {
+		return parents
+	}
+	for _, item := range items {
+		id, _ := item["id"].(int64)
+		parentId, _ := item["parent_id"].(int64)
+		parents[id] = parentId
+	}
+	return parents
+}
+
+// CanSetParent check the parent role does not make a cycle, which is a role
+// being an ancestor of itself.
+func (t RoleModel) CanSetParent(parentId int64) bool {
+	if parentId == 0 {
+		return true
+	}
+	return canSetParent(t.parents(), t.Id, parentId)
+}
+
+func canSetParent(parents map[int64]int64, id, parentId int64) bool {
+	if parentId == 0 {
+		return true
+	}
+	if parentId == id {
+		return false
+	}
+	for _, ancestorId := range ancestorIds(parents, []int64{parentId}) {
+		if ancestorId == id {
+			return false
+		}
+	}
+	return true
+}
+
+// Ancestors return the ancestor roles of the given roles, which are not in
+// the given roles, the nearest first.
+func (t RoleModel) Ancestors(roleIds []int64) []RoleModel {
+	ids := ancestorIds(t.parents(), roleIds)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	items, _ := t.Table(t.TableName).WhereIn("id", args).All()
+
+	byId := make(map[int64]RoleModel, len(items))
+	for _, item := range items {
+		role := Role().MapToModel(item)
+		byId[role.Id] = role
+	}
+
+	roles := make([]RoleModel, 0, len(ids))
+	for _, id := range ids {
+		if role, ok := byId[id]; ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// ancestorIds walk up the parents of the roles, a role is visited once so
+// that a cycle of the stored parents does not loop.
+func ancestorIds(parents map[int64]int64, roleIds []int64) []int64 {
+	visited := make(map[int64]bool, len(roleIds))
+	for _, id := range roleIds {
+		visited[id] = true
+	}
+
+	var ids []int64
+	for _, id := range roleIds {
+		for parentId := parents[id]; parentId != 0 && !visited[parentId]; parentId = parents[parentId] {
+			visited[parentId] = true
+			ids = append(ids, parentId)
+		}
+	}
+	return ids
+}
+
+// DeleteDeniedPermissions delete all the denied permissions of the role.
+func (t RoleModel) DeleteDeniedPermissions() error {
+	return t.WithTx(t.Tx).Table("goadmin_permission_denies").
+		Where("role_id", "=", t.Id).
+		Delete()
+}
+
+// AddDeniedPermission deny the permission to the role, which overrides the
+// permission allowed by the role itself or its ancestors.
+func (t RoleModel) AddDeniedPermission(permissionId string) (int64, error) {
+	if permissionId == "" {
+		return 0, nil
+	}
+	return t.WithTx(t.Tx).Table("goadmin_permission_denies").
+		Insert(dialect.H{
+			"permission_id": permissionId,
+			"role_id":       t.Id,
+		})
+}
+
 // CheckPermission check the permission of role.
 func (t RoleModel) CheckPermission(permissionId string) bool {
 	checkPermission, _ := t.Table("goadmin_role_permissions").
@@ -121,6 +289,9 @@ func (t RoleModel) MapToModel(m map[string]interface{}) RoleModel {
 	t.Id = m["id"].(int64)
 	t.Name, _ = m["name"].(string)
 	t.Slug, _ = m["slug"].(string)
+	t.ParentId, _ = m["parent_id"].(int64)
+	twoFactor, _ := m["two_factor"].(int64)
+	t.TwoFactor = twoFactor == 1
 	t.CreatedAt, _ = m["created_at"].(string)
 	t.UpdatedAt, _ = m["updated_at"].(string)
 	return t
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAncestorIds(t *testing.T) {
+	// 4 -> 3 -> 2 -> 1, 5 -> 2, and 6 <-> 7 is a stored cycle
+	parents := map[int64]int64{1: 0, 2: 1, 3: 2, 4: 3, 5: 2, 6: 7, 7: 6}
+
+	cases := []struct {
+		name    string
+		roleIds []int64
+		want    []int64
+	}{
+		{"no parent", []int64{1}, nil},
+		{"nearest first", []int64{4}, []int64{3, 2, 1}},
+		{"shared ancestors once", []int64{4, 5}, []int64{3, 2, 1}},
+		{"assigned ancestors skipped", []int64{4, 2}, []int64{3, 1}},
+		{"cycle stops", []int64{6}, []int64{7}},
+		{"unknown role", []int64{9}, nil},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.want, ancestorIds(parents, c.roleIds), c.name)
+	}
+}
+
+func TestCanSetParent(t *testing.T) {
+	parents := map[int64]int64{1: 0, 2: 1, 3: 2, 4: 0, 6: 7, 7: 6}
+
+	cases := []struct {
+		name     string
+		id       int64
+		parentId int64
+		want     bool
+	}{
+		{"no parent", 3, 0, true},
+		{"itself", 3, 3, false},
+		{"ancestor", 3, 1, true},
+		{"another tree", 3, 4, true},
+		{"child", 1, 2, false},
+		{"descendant", 1, 3, false},
+		{"new role", 0, 3, true},
+		{"stored cycle", 5, 6, true},
+		{"into stored cycle", 6, 7, false},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.want, canSetParent(parents, c.id, c.parentId), c.name)
+	}
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/db/dialect"
+)
+
+// The kinds of the synced records.
+const (
+	SyncRecordPermission = "permission"
+	SyncRecordMenu       = "menu"
+)
+
+// SyncRecordModel is sync record model structure. It keeps the checksum of a
+// permission or a menu written by the sync of the generators, a record which
+// no longer matches it has been edited by hand.
+type SyncRecordModel struct {
+	Base
+
+	Id       int64
+	Kind     string
+	RecordId int64
+	Checksum string
+
+	CreatedAt string
+	UpdatedAt string
+}
+
+// SyncRecord return a default sync record model.
+func SyncRecord() SyncRecordModel {
+	return SyncRecordModel{Base: Base{TableName: "goadmin_sync_records"}}
+}
+
+func (t SyncRecordModel) SetConn(con db.Connection) SyncRecordModel {
+	t.Conn = con
+	return t
+}
+
+// Find return a default sync record model of given kind and record id.
+func (t SyncRecordModel) Find(kind string, recordId int64) SyncRecordModel {
+	t.Kind = kind
+	t.RecordId = recordId
+	item, _ := t.Table(t.TableName).
+		Where("kind", "=", kind).
+		Where("record_id", "=", recordId).
+		First()
+	if item == nil {
+		return t
+	}
+	return t.MapToModel(item)
+}
+
+// IsEmpty check the sync record model is empty or not.
+func (t SyncRecordModel) IsEmpty() bool {
+	return t.Id == int64(0)
+}
+
+// Save store the checksum of the record, the sync record is created if it
+// does not exist.
+func (t SyncRecordModel) Save(checksum string) (SyncRecordModel, error) {
+	if t.IsEmpty() {
+		id, err := t.Table(t.TableName).Insert(dialect.H{
+			"kind":      t.Kind,
+			"record_id": t.RecordId,
+			"checksum":  checksum,
+		})
+		if db.CheckError(err, db.INSERT) {
+			return t, err
+		}
+		t.Id = id
+		t.Checksum = checksum
+		return t, nil
+	}
+
+	_, err := t.Table(t.TableName).
+		Where("id", "=", t.Id).
+		Update(dialect.H{
+			"checksum":   checksum,
+			"updated_at": time.Now().Format("2006-01-02 15:04:05"),
+		})
+	if db.CheckError(err, db.UPDATE) {
+		return t, err
+	}
+	t.Checksum = checksum
+	return t, nil
+}
+
+// MapToModel get the sync record model from given map.
+func (t SyncRecordModel) MapToModel(m map[string]interface{}) SyncRecordModel {
+	t.Id, _ = m["id"].(int64)
+	t.Kind, _ = m["kind"].(string)
+	t.RecordId, _ = m["record_id"].(int64)
+	t.Checksum, _ = m["checksum"].(string)
+	t.CreatedAt, _ = m["created_at"].(string)
+	t.UpdatedAt, _ = m["updated_at"].(string)
+	return t
+}
@@ -0,0 +1,193 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/db/dialect"
+)
+
+// TwoFactorModel is two-factor model structure, a row of it keeps the totp
+// secret and the recovery codes of a user.
+type TwoFactorModel struct {
+	Base
+
+	Id      int64
+	UserId  int64
+	Secret  string
+	Enabled bool
+	// RecoveryCodes are the hashes of the unused recovery codes.
+	RecoveryCodes []string
+	// LastStep is the time step of the last accepted code.
+	LastStep int64
+
+	CreatedAt string
+	UpdatedAt string
+}
+
+// TwoFactor return a default two-factor model.
+func TwoFactor() TwoFactorModel {
+	return TwoFactorModel{Base: Base{TableName: "goadmin_user_two_factor"}}
+}
+
+func (t TwoFactorModel) SetConn(con db.Connection) TwoFactorModel {
+	t.Conn = con
+	return t
+}
+
+func (t TwoFactorModel) WithTx(tx *sql.Tx) TwoFactorModel {
+	t.Tx = tx
+	return t
+}
+
+// FindByUserId return a default two-factor model of given user id.
+func (t TwoFactorModel) FindByUserId(userId interface{}) TwoFactorModel {
+	item, _ := t.Table(t.TableName).Where("user_id", "=", userId).First()
+	if item == nil {
+		return t
+	}
+	return t.MapToModel(item)
+}
+
+// IsEmpty check the two-factor model is empty or not.
+func (t TwoFactorModel) IsEmpty() bool {
+	return t.Id == int64(0)
+}
+
+// Save store a new secret of the user which is not enabled until a code of
+// it is confirmed.
+func (t TwoFactorModel) Save(userId int64, secret string) (TwoFactorModel, error) {
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+
+	t.UserId = userId
+	t.Secret = secret
+	t.Enabled = false
+	t.RecoveryCodes = nil
+	t.LastStep = 0
+	t.UpdatedAt = now
+
+	values := dialect.H{
+		"secret":         secret,
+		"enabled":        0,
+		"recovery_codes": "[]",
+		"last_step":      0,
+		"updated_at":     now,
+	}
+
+	if t.IsEmpty() {
+		values["user_id"] = userId
+		values["created_at"] = now
+		id, err := t.WithTx(t.Tx).Table(t.TableName).Insert(values)
+		t.Id = id
+		t.CreatedAt = now
+		return t, err
+	}
+
+	_, err := t.WithTx(t.Tx).Table(t.TableName).Where("id", "=", t.Id).Update(values)
+	if db.CheckError(err, db.UPDATE) {
+		return t, err
+	}
+	return t, nil
+}
+
+// Enable enable the two-factor authentication with the confirmed step and the
+// hashes of the recovery codes.
+func (t TwoFactorModel) Enable(step int64, recoveryCodes []string) (TwoFactorModel, error) {
+	t.Enabled = true
+	t.LastStep = step
+	t.RecoveryCodes = recoveryCodes
+	return t, t.update(dialect.H{
+		"enabled":        1,
+		"last_step":      step,
+		"recovery_codes": encodeRecoveryCodes(recoveryCodes),
+	})
+}
+
+// SetRecoveryCodes replace the hashes of the recovery codes.
+func (t TwoFactorModel) SetRecoveryCodes(recoveryCodes []string) (TwoFactorModel, error) {
+	t.RecoveryCodes = recoveryCodes
+	return t, t.update(dialect.H{
+		"recovery_codes": encodeRecoveryCodes(recoveryCodes),
+	})
+}
+
+// UpdateLastStep remember the step of an accepted code and report if the
+// step is newer than the last one, so a code is accepted only once even by
+// concurrent requests.
+func (t TwoFactorModel) UpdateLastStep(step int64) bool {
+	_, err := t.WithTx(t.Tx).Table(t.TableName).
+		Where("id", "=", t.Id).
+		Where("last_step", "<", step).
+		Update(dialect.H{
+			"last_step":  step,
+			"updated_at": time.Now().Format("2006-01-02 15:04:05"),
+		})
+	return err == nil
+}
+
+// UseRecoveryCode remove the hash of a recovery code and report if it was
+// unused. The codes are only replaced if nobody changed them in between.
+func (t TwoFactorModel) UseRecoveryCode(hash string) bool {
+	for i, code := range t.RecoveryCodes {
+		if code != hash {
+			continue
+		}
+		rest := make([]string, 0, len(t.RecoveryCodes)-1)
+		rest = append(rest, t.RecoveryCodes[:i]...)
+		rest = append(rest, t.RecoveryCodes[i+1:]...)
+		_, err := t.WithTx(t.Tx).Table(t.TableName).
+			Where("id", "=", t.Id).
+			Where("recovery_codes", "=", encodeRecoveryCodes(t.RecoveryCodes)).
+			Update(dialect.H{
+				"recovery_codes": encodeRecoveryCodes(rest),
+				"updated_at":     time.Now().Format("2006-01-02 15:04:05"),
+			})
+		return err == nil
+	}
+	return false
+}
+
+// Delete delete the two-factor authentication of the user.
+func (t TwoFactorModel) Delete() error {
+	return t.WithTx(t.Tx).Table(t.TableName).
+		Where("user_id", "=", t.UserId).
+		Delete()
+}
+
+func (t TwoFactorModel) update(values dialect.H) error {
+	values["updated_at"] = time.Now().Format("2006-01-02 15:04:05")
+	_, err := t.WithTx(t.Tx).Table(t.TableName).
+		Where("id", "=", t.Id).
+		Update(values)
+	if db.CheckError(err, db.UPDATE) {
+		return err
+	}
+	return nil
+}
+
+func encodeRecoveryCodes(codes []string) string {
+	if codes == nil {
+		codes = []string{}
+	}
+	b, _ := json.Marshal(codes)
+	return string(b)
+}
+
+// MapToModel get the two-factor model from given map.
+func (t TwoFactorModel) MapToModel(m map[string]interface{}) TwoFactorModel {
+	t.Id = m["id"].(int64)
+	t.UserId, _ = m["user_id"].(int64)
+	t.Secret, _ = m["secret"].(string)
+	enabled, _ := m["enabled"].(int64)
+	t.Enabled = enabled == 1
+	t.LastStep, _ = m["last_step"].(int64)
+	codes, _ := m["recovery_codes"].(string)
+	t.RecoveryCodes = nil
+	_ = json.Unmarshal([]byte(codes), &t.RecoveryCodes)
+	t.CreatedAt, _ = m["created_at"].(string)
+	t.UpdatedAt, _ = m["updated_at"].(string)
+	return t
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoFactorConditionalUpdates(t *testing.T) {
+	conn, clean := testSqliteConn(t)
+	defer clean()
+
+	tf, err := TwoFactor().SetConn(conn).Save(1, "secret")
+	assert.NoError(t, err)
+	tf, err = tf.Enable(10, []string{"a", "b"})
+	assert.NoError(t, err)
+
+	// a step is accepted once, older steps are refused
+	assert.True(t, tf.UpdateLastStep(11))
+	assert.False(t, tf.UpdateLastStep(11))
+	assert.False(t, tf.UpdateLastStep(9))
+	assert.Equal(t, TwoFactor().SetConn(conn).FindByUserId(1).LastStep, int64(11))
+
+	// a model loaded before the code was used can not use it again
+	stale := TwoFactor().SetConn(conn).FindByUserId(1)
+	assert.True(t, tf.UseRecoveryCode("a"))
+	assert.False(t, stale.UseRecoveryCode("a"))
+	assert.False(t, tf.UseRecoveryCode("c"))
+	assert.Equal(t, TwoFactor().SetConn(conn).FindByUserId(1).RecoveryCodes, []string{"b"})
+}
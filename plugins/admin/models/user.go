@@ -5,10 +5,8 @@ import (
 	"github.com/wowucco/go-admin/modules/config"
 	"github.com/wowucco/go-admin/modules/db"
 	"github.com/wowucco/go-admin/modules/db/dialect"
-	"github.com/wowucco/go-admin/modules/logger"
 	"github.com/wowucco/go-admin/plugins/admin/modules/constant"
 	"net/url"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -23,6 +21,7 @@ type UserModel struct {
 	UserName      string            `json:"user_name"`
 	Password      string            `json:"password"`
 	Avatar        string            `json:"avatar"`
+	Email         string            `json:"email"`
 	RememberToken string            `json:"remember_token"`
 	Permissions   []PermissionModel `json:"permissions"`
 	MenuIds       []int64           `json:"menu_ids"`
@@ -30,8 +29,22 @@ type UserModel struct {
 	Level         string            `json:"level"`
 	LevelName     string            `json:"level_name"`
 
+	// InheritedRoles are the ancestors of the roles of the user, whose
+	// permissions and menus the user has as well.
+	InheritedRoles []RoleModel `json:"inherited_roles"`
+	// DeniedPermissions override the permissions of the user.
+	DeniedPermissions []PermissionModel `json:"denied_permissions"`
+
+	// ImpersonatorId and ImpersonatorName are of the real user when the
+	// user is impersonated by another one.
+	ImpersonatorId   int64  `json:"impersonator_id"`
+	ImpersonatorName string `json:"impersonator_name"`
+
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
+
+	matcher     *PermissionMatcher
+	denyMatcher *PermissionMatcher
 }
 
 // User return a default user model.
@@ -67,11 +80,22 @@ func (t UserModel) FindByUserName(username interface{}) UserModel {
 	return t.MapToModel(item)
 }
 
+// FindByEmail return a default user model of given email.
+func (t UserModel) FindByEmail(email interface{}) UserModel {
+	item, _ := t.Table(t.TableName).Where("email", "=", email).First()
+	return t.MapToModel(item)
+}
+
 // IsEmpty check the user model is empty or not.
 func (t UserModel) IsEmpty() bool {
 	return t.Id == int64(0)
 }
 
+// IsImpersonated check the user model is impersonated by another user or not.
+func (t UserModel) IsImpersonated() bool {
+	return t.ImpersonatorId != 0
+}
+
 // HasMenu check the user has visitable menu or not.
 func (t UserModel) HasMenu() bool {
 	return len(t.MenuIds) != 0 || t.IsSuperAdmin()
@@ -104,18 +128,26 @@ func (t UserModel) HideUserCenterEntrance() bool {
 
 func (t UserModel) CheckPermissionByUrlMethod(path, method string, formParams url.Values) bool {
 
-	if t.IsSuperAdmin() {
+	if t.IsSuperAdmin() && len(t.DeniedPermissions) == 0 {
+		return true
+	}
+
+	if strings.Contains(path, config.Url("/logout")) {
 		return true
 	}
 
-	logoutCheck, _ := regexp.Compile(config.Url("/logout") + "(.*?)")
+	// the impersonated user can always go back to the real one
+	if p, _ := getParam(path); p == config.Url("/impersonate/stop") {
+		return true
+	}
 
-	if logoutCheck.MatchString(path) {
+	// every user manages their own two-factor authentication
+	if p, _ := getParam(path); p == config.Url("/2fa") || strings.HasPrefix(p, config.Url("/2fa/")) {
 		return true
 	}
 
 	if path == "" {
-		return false
+		return t.IsSuperAdmin()
 	}
 
 	if path != "/" && path[len(path)-1] == '/' {
@@ -132,42 +164,34 @@ func (t UserModel) CheckPermissionByUrlMethod(path, method string, formParams ur
 		}
 	}
 
-	for _, v := range t.Permissions {
-
-		if v.HttpMethod[0] == "" || inMethodArr(v.HttpMethod, method) {
-
-			if v.HttpPath[0] == "*" {
-				return true
-			}
-
-			for i := 0; i < len(v.HttpPath); i++ {
-
-				matchPath := config.Url(strings.TrimSpace(v.HttpPath[i]))
-				matchPath, matchParam := getParam(matchPath)
-
-				if matchPath == path {
-					if checkParam(params, matchParam) {
-						return true
-					}
-				}
+	// a denied permission overrides the allowed ones, the super
+	// administrator's too
+	if len(t.DeniedPermissions) > 0 && t.deniedMatcher().Match(path, method, params) {
+		return false
+	}
 
-				reg, err := regexp.Compile(matchPath)
+	if t.IsSuperAdmin() {
+		return true
+	}
 
-				if err != nil {
-					logger.Error("CheckPermissions error: ", err)
-					continue
-				}
+	return t.permissionMatcher().Match(path, method, params)
+}
 
-				if reg.FindString(path) == path {
-					if checkParam(params, matchParam) {
-						return true
-					}
-				}
-			}
-		}
+// permissionMatcher return the compiled matcher of the permissions, which
+// is built by WithPermissions or otherwise here.
+func (t UserModel) permissionMatcher() *PermissionMatcher {
+	if t.matcher != nil {
+		return t.matcher
 	}
+	return NewPermissionMatcher(t.Permissions)
+}
 
-	return false
+// deniedMatcher return the compiled matcher of the denied permissions.
+func (t UserModel) deniedMatcher() *PermissionMatcher {
+	if t.denyMatcher != nil {
+		return t.denyMatcher
+	}
+	return NewPermissionMatcher(t.DeniedPermissions)
 }
 
 func getParam(u string) (string, url.Values) {
@@ -237,8 +261,14 @@ func (t UserModel) WithRoles() UserModel {
 			"goadmin_roles.created_at", "goadmin_roles.updated_at").
 		All()
 
+	roleIds := make([]int64, 0, len(roleModel))
 	for _, role := range roleModel {
 		t.Roles = append(t.Roles, Role().MapToModel(role))
+		roleIds = append(roleIds, t.Roles[len(t.Roles)-1].Id)
+	}
+
+	if len(roleIds) > 0 {
+		t.InheritedRoles = Role().SetConn(t.Conn).Ancestors(roleIds)
 	}
 
 	if len(t.Roles) > 0 {
@@ -249,12 +279,52 @@ func (t UserModel) WithRoles() UserModel {
 	return t
 }
 
+// IsTwoFactorRequired check if any role of the user, including the inherited
+// ones, requires the two-factor authentication.
+func (t UserModel) IsTwoFactorRequired() bool {
+	items, err := t.Table("goadmin_role_users").
+		Where("user_id", "=", t.Id).
+		Select("role_id").
+		All()
+	if err != nil || len(items) == 0 {
+		return false
+	}
+
+	roleIds := make([]int64, 0, len(items))
+	for _, item := range items {
+		if id, ok := item["role_id"].(int64); ok {
+			roleIds = append(roleIds, id)
+		}
+	}
+	if len(roleIds) == 0 {
+		return false
+	}
+	roleIds = append(roleIds, ancestorIds(Role().SetConn(t.Conn).parents(), roleIds)...)
+
+	args := make([]interface{}, len(roleIds))
+	for i, id := range roleIds {
+		args[i] = id
+	}
+	item, err := t.Table("goadmin_roles").
+		WhereIn("id", args).
+		Where("two_factor", "=", 1).
+		Select("id").
+		First()
+	return err == nil && item != nil
+}
+
+// GetAllRoleId return the ids of the roles of the user, including the
+// inherited ones.
 func (t UserModel) GetAllRoleId() []interface{} {
 
-	var ids = make([]interface{}, len(t.Roles))
+	var ids = make([]interface{}, 0, len(t.Roles)+len(t.InheritedRoles))
+
+	for _, role := range t.Roles {
+		ids = append(ids, role.Id)
+	}
 
-	for key, role := range t.Roles {
-		ids[key] = role.Id
+	for _, role := range t.InheritedRoles {
+		ids = append(ids, role.Id)
 	}
 
 	return ids
@@ -287,6 +357,8 @@ func (t UserModel) WithPermissions() UserModel {
 
 	permissions = append(permissions, userPermissions...)
 
+	t.DeniedPermissions = t.deniedPermissions(roleIds)
+
 	for i := 0; i < len(permissions); i++ {
 		exist := false
 		for j := 0; j < len(t.Permissions); j++ {
@@ -295,15 +367,58 @@ func (t UserModel) WithPermissions() UserModel {
 				break
 			}
 		}
+		for j := 0; j < len(t.DeniedPermissions); j++ {
+			if t.DeniedPermissions[j].Id == permissions[i]["id"] {
+				exist = true
+				break
+			}
+		}
 		if exist {
 			continue
 		}
 		t.Permissions = append(t.Permissions, Permission().MapToModel(permissions[i]))
 	}
 
+	t.matcher = NewPermissionMatcher(t.Permissions)
+	t.denyMatcher = NewPermissionMatcher(t.DeniedPermissions)
+
 	return t
 }
 
+// deniedPermissions query the permissions denied to the user or the roles.
+func (t UserModel) deniedPermissions(roleIds []interface{}) []PermissionModel {
+	fields := []string{"goadmin_permissions.http_method", "goadmin_permissions.http_path",
+		"goadmin_permissions.id", "goadmin_permissions.name", "goadmin_permissions.slug",
+		"goadmin_permissions.created_at", "goadmin_permissions.updated_at"}
+
+	denies, _ := t.Table("goadmin_permission_denies").
+		LeftJoin("goadmin_permissions", "goadmin_permissions.id", "=", "goadmin_permission_denies.permission_id").
+		Where("user_id", "=", t.Id).
+		Select(fields...).
+		All()
+
+	if len(roleIds) > 0 {
+		roleDenies, _ := t.Table("goadmin_permission_denies").
+			LeftJoin("goadmin_permissions", "goadmin_permissions.id", "=", "goadmin_permission_denies.permission_id").
+			WhereIn("role_id", roleIds).
+			Select(fields...).
+			All()
+		denies = append(denies, roleDenies...)
+	}
+
+	var permissions []PermissionModel
+	seen := make(map[int64]bool, len(denies))
+	for _, deny := range denies {
+		id, ok := deny["id"].(int64)
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		permissions = append(permissions, Permission().MapToModel(deny))
+	}
+	return permissions
+}
+
 // WithMenus query the menu info of the user.
 func (t UserModel) WithMenus() UserModel {
 
@@ -395,6 +510,15 @@ func (t UserModel) UpdatePwd(password string) UserModel {
 	return t
 }
 
+// UpdateEmail update the email of the user model.
+func (t UserModel) UpdateEmail(email string) (int64, error) {
+	return t.WithTx(t.Tx).Table(t.TableName).
+		Where("id", "=", t.Id).
+		Update(dialect.H{
+			"email": email,
+		})
+}
+
 // CheckRole check the role of the user model.
 func (t UserModel) CheckRoleId(roleId string) bool {
 	checkRole, _ := t.Table("goadmin_role_users").
@@ -447,6 +571,12 @@ func (t UserModel) CheckPermissionById(permissionId string) bool {
 
 // CheckPermission check the permission of the user.
 func (t UserModel) CheckPermission(permission string) bool {
+	for _, per := range t.DeniedPermissions {
+		if per.Slug == permission {
+			return false
+		}
+	}
+
 	for _, per := range t.Permissions {
 		if per.Slug == permission {
 			return true
@@ -477,6 +607,26 @@ func (t UserModel) AddPermission(permissionId string) (int64, error) {
 	return 0, nil
 }
 
+// DeleteDeniedPermissions delete all the denied permissions of the user.
+func (t UserModel) DeleteDeniedPermissions() error {
+	return t.WithTx(t.Tx).Table("goadmin_permission_denies").
+		Where("user_id", "=", t.Id).
+		Delete()
+}
+
+// AddDeniedPermission deny the permission to the user, which overrides the
+// permission allowed by the user or the roles.
+func (t UserModel) AddDeniedPermission(permissionId string) (int64, error) {
+	if permissionId == "" {
+		return 0, nil
+	}
+	return t.WithTx(t.Tx).Table("goadmin_permission_denies").
+		Insert(dialect.H{
+			"permission_id": permissionId,
+			"user_id":       t.Id,
+		})
+}
+
 // MapToModel get the user model from given map.
 func (t UserModel) MapToModel(m map[string]interface{}) UserModel {
 	t.Id, _ = m["id"].(int64)
@@ -484,6 +634,7 @@ func (t UserModel) MapToModel(m map[string]interface{}) UserModel {
 	t.UserName, _ = m["username"].(string)
 	t.Password, _ = m["password"].(string)
 	t.Avatar, _ = m["avatar"].(string)
+	t.Email, _ = m["email"].(string)
 	t.RememberToken, _ = m["remember_token"].(string)
 	t.CreatedAt, _ = m["created_at"].(string)
 	t.UpdatedAt, _ = m["updated_at"].(string)
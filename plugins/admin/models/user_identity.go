@@ -0,0 +1,96 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/db/dialect"
+)
+
+// UserIdentityModel is user identity model structure, a row of it links a
+// user to the subject of a single sign-on provider.
+type UserIdentityModel struct {
+	Base
+
+	Id       int64
+	UserId   int64
+	Provider string
+	Subject  string
+
+	CreatedAt string
+	UpdatedAt string
+}
+
+// UserIdentity return a default user identity model.
+func UserIdentity() UserIdentityModel {
+	return UserIdentityModel{Base: Base{TableName: "goadmin_user_identities"}}
+}
+
+func (t UserIdentityModel) SetConn(con db.Connection) UserIdentityModel {
+	t.Conn = con
+	return t
+}
+
+func (t UserIdentityModel) WithTx(tx *sql.Tx) UserIdentityModel {
+	t.Tx = tx
+	return t
+}
+
+// Find return a default user identity model of given provider and subject.
+func (t UserIdentityModel) Find(provider, subject string) UserIdentityModel {
+	item, _ := t.Table(t.TableName).
+		Where("provider", "=", provider).
+		Where("subject", "=", subject).
+		First()
+	if item == nil {
+		return t
+	}
+	return t.MapToModel(item)
+}
+
+// IsEmpty check the user identity model is empty or not.
+func (t UserIdentityModel) IsEmpty() bool {
+	return t.Id == int64(0)
+}
+
+// New link the subject of the provider to the user.
+func (t UserIdentityModel) New(userId int64, provider, subject string) (UserIdentityModel, error) {
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+
+	id, err := t.WithTx(t.Tx).Table(t.TableName).Insert(dialect.H{
+		"user_id":    userId,
+		"provider":   provider,
+		"subject":    subject,
+		"created_at": now,
+		"updated_at": now,
+	})
+
+	t.Id = id
+	t.UserId = userId
+	t.Provider = provider
+	t.Subject = subject
+	t.CreatedAt = now
+	t.UpdatedAt = now
+
+	return t, err
+}
+
+// Delete delete the user identity.
+func (t UserIdentityModel) Delete() error {
+	return t.WithTx(t.Tx).Table(t.TableName).
+		Where("id", "=", t.Id).
+		Delete()
+}
+
+// MapToModel get the user identity model from given map.
+func (t UserIdentityModel) MapToModel(m map[string]interface{}) UserIdentityModel {
+	t.Id = m["id"].(int64)
+	t.UserId, _ = m["user_id"].(int64)
+	t.Provider, _ = m["provider"].(string)
+	t.Subject, _ = m["subject"].(string)
+	t.CreatedAt, _ = m["created_at"].(string)
+	t.UpdatedAt, _ = m["updated_at"].(string)
+	return t
+}
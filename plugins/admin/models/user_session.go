@@ -0,0 +1,166 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/db/dialect"
+)
+
+// UserSessionModel is user session model structure, a row of it is a login
+// session of the user. The session is ended when the row is deleted.
+type UserSessionModel struct {
+	Base
+
+	Id        int64
+	UserId    int64
+	SessionId string
+	Ip        string
+	UserAgent string
+	// LastSeenAt and ExpiresAt are unix seconds.
+	LastSeenAt int64
+	ExpiresAt  int64
+
+	CreatedAt string
+	UpdatedAt string
+}
+
+// UserSession return a default user session model.
+func UserSession() UserSessionModel {
+	return UserSessionModel{Base: Base{TableName: "goadmin_user_sessions"}}
+}
+
+func (t UserSessionModel) SetConn(con db.Connection) UserSessionModel {
+	t.Conn = con
+	return t
+}
+
+func (t UserSessionModel) WithTx(tx *sql.Tx) UserSessionModel {
+	t.Tx = tx
+	return t
+}
+
+// Find return a default user session model of given id.
+func (t UserSessionModel) Find(id interface{}) UserSessionModel {
+	item, _ := t.Table(t.TableName).Find(id)
+	if item == nil {
+		return t
+	}
+	return t.MapToModel(item)
+}
+
+// FindBySessionId return a default user session model of given session id.
+func (t UserSessionModel) FindBySessionId(sessionId string) UserSessionModel {
+	item, _ := t.Table(t.TableName).Where("session_id", "=", sessionId).First()
+	if item == nil {
+		return t
+	}
+	return t.MapToModel(item)
+}
+
+// ListByUserId return the sessions of the user not expired at given time,
+// the last seen first.
+func (t UserSessionModel) ListByUserId(userId int64, now time.Time) []UserSessionModel {
+	items, _ := t.Table(t.TableName).
+		Where("user_id", "=", userId).
+		Where("expires_at", ">", now.Unix()).
+		OrderBy("last_seen_at", "desc").
+		All()
+
+	sessions := make([]UserSessionModel, len(items))
+	for i, item := range items {
+		sessions[i] = UserSession().MapToModel(item)
+	}
+	return sessions
+}
+
+// IsEmpty check the user session model is empty or not.
+func (t UserSessionModel) IsEmpty() bool {
+	return t.Id == int64(0)
+}
+
+// IsExpired check the session is expired at given time.
+func (t UserSessionModel) IsExpired(now time.Time) bool {
+	return t.ExpiresAt <= now.Unix()
+}
+
+// New add a session of the user.
+func (t UserSessionModel) New(userId int64, sessionId, ip, userAgent string, expiresAt int64) (UserSessionModel, error) {
+
+	now := time.Now()
+	nowStr := now.Format("2006-01-02 15:04:05")
+
+	id, err := t.WithTx(t.Tx).Table(t.TableName).Insert(dialect.H{
+		"user_id":      userId,
+		"session_id":   sessionId,
+		"ip":           ip,
+		"user_agent":   userAgent,
+		"last_seen_at": now.Unix(),
+		"expires_at":   expiresAt,
+		"created_at":   nowStr,
+		"updated_at":   nowStr,
+	})
+
+	t.Id = id
+	t.UserId = userId
+	t.SessionId = sessionId
+	t.Ip = ip
+	t.UserAgent = userAgent
+	t.LastSeenAt = now.Unix()
+	t.ExpiresAt = expiresAt
+	t.CreatedAt = nowStr
+	t.UpdatedAt = nowStr
+
+	return t, err
+}
+
+// Touch record the last request of the session.
+func (t UserSessionModel) Touch(at int64, ip string) (int64, error) {
+	t.LastSeenAt = at
+	t.Ip = ip
+	return t.WithTx(t.Tx).Table(t.TableName).
+		Where("id", "=", t.Id).
+		Update(dialect.H{
+			"last_seen_at": at,
+			"ip":           ip,
+		})
+}
+
+// Delete delete the session.
+func (t UserSessionModel) Delete() error {
+	return t.WithTx(t.Tx).Table(t.TableName).
+		Where("id", "=", t.Id).
+		Delete()
+}
+
+// DeleteByUserId delete the sessions of the user, except the one of given
+// session id when it is not empty.
+func (t UserSessionModel) DeleteByUserId(userId int64, exceptSessionId string) error {
+	stmt := t.WithTx(t.Tx).Table(t.TableName).Where("user_id", "=", userId)
+	if exceptSessionId != "" {
+		stmt = stmt.Where("session_id", "!=", exceptSessionId)
+	}
+	return stmt.Delete()
+}
+
+// DeleteExpired delete the sessions expired at given time.
+func (t UserSessionModel) DeleteExpired(now time.Time) error {
+	return t.WithTx(t.Tx).Table(t.TableName).
+		Where("expires_at", "<=", now.Unix()).
+		Delete()
+}
+
+// MapToModel get the user session model from given map.
+func (t UserSessionModel) MapToModel(m map[string]interface{}) UserSessionModel {
+	t.Id = m["id"].(int64)
+	t.UserId, _ = m["user_id"].(int64)
+	t.SessionId, _ = m["session_id"].(string)
+	t.Ip, _ = m["ip"].(string)
+	t.UserAgent, _ = m["user_agent"].(string)
+	t.LastSeenAt, _ = m["last_seen_at"].(int64)
+	t.ExpiresAt, _ = m["expires_at"].(int64)
+	t.CreatedAt, _ = m["created_at"].(string)
+	t.UpdatedAt, _ = m["updated_at"].(string)
+	return t
+}
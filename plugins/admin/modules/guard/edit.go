@@ -88,7 +88,7 @@ func (g *Guard) EditForm(ctx *context.Context) {
 	}
 	token := ctx.FormValue(form.TokenKey)
 
-	if !auth.GetTokenService(g.services.Get(auth.TokenServiceKey)).CheckToken(token) {
+	if !auth.IsAPITokenRequest(ctx) && !auth.GetTokenService(g.services.Get(auth.TokenServiceKey)).CheckSessionToken(ctx, token) {
 		alert(ctx, panel, errors.EditFailWrongToken, g.conn)
 		ctx.Abort()
 		return
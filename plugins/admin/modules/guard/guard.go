@@ -5,6 +5,7 @@ import (
 	"github.com/wowucco/go-admin/modules/db"
 	"github.com/wowucco/go-admin/modules/errors"
 	"github.com/wowucco/go-admin/modules/service"
+	"github.com/wowucco/go-admin/plugins/admin/models"
 	"github.com/wowucco/go-admin/plugins/admin/modules/constant"
 	"github.com/wowucco/go-admin/plugins/admin/modules/response"
 	"github.com/wowucco/go-admin/plugins/admin/modules/table"
@@ -25,13 +26,17 @@ func New(s service.List, c db.Connection, t table.GeneratorList) *Guard {
 }
 
 func (g *Guard) table(ctx *context.Context) (table.Table, string) {
-	prefix := ctx.Query(constant.PrefixKey)
-	return g.tableList[prefix](ctx), prefix
+	prefix := ctx.Param(constant.PrefixKey)
+	t := g.tableList[prefix](ctx)
+	if user, ok := ctx.User().(models.UserModel); ok {
+		t = table.ForRequest(t, prefix, user)
+	}
+	return t, prefix
 }
 
 func (g *Guard) CheckPrefix(ctx *context.Context) {
 
-	prefix := ctx.Query(constant.PrefixKey)
+	prefix := ctx.Param(constant.PrefixKey)
 
 	if _, ok := g.tableList[prefix]; !ok {
 		if ctx.Headers(constant.PjaxHeader) == "" && ctx.Method() != "GET" {
@@ -37,7 +37,7 @@ func (g *Guard) MenuEdit(ctx *context.Context) {
 		alert          template.HTML
 	)
 
-	if !auth.GetTokenService(g.services.Get(auth.TokenServiceKey)).CheckToken(token) {
+	if !auth.GetTokenService(g.services.Get(auth.TokenServiceKey)).CheckSessionToken(ctx, token) {
 		alert = getAlert(errors.EditFailWrongToken)
 	}
 
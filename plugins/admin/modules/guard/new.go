@@ -73,7 +73,7 @@ func (g *Guard) NewForm(ctx *context.Context) {
 
 	token := ctx.FormValue(form.TokenKey)
 
-	if !auth.GetTokenService(g.services.Get(auth.TokenServiceKey)).CheckToken(token) {
+	if !auth.IsAPITokenRequest(ctx) && !auth.GetTokenService(g.services.Get(auth.TokenServiceKey)).CheckSessionToken(ctx, token) {
 		alert(ctx, panel, errors.CreateFailWrongToken, conn)
 		ctx.Abort()
 		return
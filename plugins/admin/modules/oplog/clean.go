@@ -0,0 +1,151 @@
+package oplog
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+)
+
+// cleanBatchSize is the number of the logs archived and deleted at a time.
+const cleanBatchSize = 1000
+
+var startCleaner sync.Once
+
+// StartCleaner start the clean of the expired logs every clean interval of
+// the config, it does nothing when the logs are kept forever. Only the first
+// call starts it.
+func StartCleaner(conn db.Connection) {
+	cfg := config.GetOperationLog()
+	if cfg.RetentionDays <= 0 {
+		return
+	}
+	startCleaner.Do(func() {
+		go clean(conn, cfg, time.Duration(cfg.CleanInterval)*time.Second)
+	})
+}
+
+func clean(conn db.Connection, cfg config.OperationLog, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := Clean(conn, cfg, time.Now()); err != nil {
+			logger.Error("clean operation logs error: ", err)
+		} else if n > 0 {
+			logger.Info("clean operation logs: ", n, " deleted")
+		}
+		<-ticker.C
+	}
+}
+
+// Clean delete the logs older than the retention days, they are written to
+// a gzipped json lines file of the archive path first when it is set. It
+// returns the number of the deleted logs.
+func Clean(conn db.Connection, cfg config.OperationLog, now time.Time) (int, error) {
+	if cfg.RetentionDays <= 0 {
+		return 0, nil
+	}
+
+	var (
+		before  = now.AddDate(0, 0, -cfg.RetentionDays)
+		model   = models.OperationLog().SetConn(conn)
+		archive *archiveWriter
+		deleted = 0
+	)
+
+	defer func() {
+		if archive != nil {
+			if err := archive.Close(); err != nil {
+				logger.Error("close operation log archive error: ", err)
+			}
+		}
+	}()
+
+	for {
+		rows, err := model.Expired(before, cleanBatchSize)
+		if db.CheckError(err, db.QUERY) {
+			return deleted, err
+		}
+		if len(rows) == 0 {
+			return deleted, nil
+		}
+
+		if cfg.ArchivePath != "" {
+			if archive == nil {
+				if archive, err = newArchiveWriter(cfg.ArchivePath, now); err != nil {
+					return deleted, err
+				}
+			}
+			if err := archive.Write(rows); err != nil {
+				return deleted, err
+			}
+		}
+
+		lastId, _ := rows[len(rows)-1]["id"].(int64)
+		if err := model.DeleteExpired(before, lastId); db.CheckError(err, db.DELETE) {
+			return deleted, err
+		}
+		deleted += len(rows)
+
+		if len(rows) < cleanBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+type archiveWriter struct {
+	file *os.File
+	gz   *gzip.Writer
+	enc  *json.Encoder
+}
+
+// newArchiveWriter create the archive file of the clean in the directory.
+func newArchiveWriter(dir string, now time.Time) (*archiveWriter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	name := filepath.Join(dir, "operation_log_"+now.Format("20060102150405")+".jsonl.gz")
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return nil, err
+	}
+	gz := gzip.NewWriter(file)
+	return &archiveWriter{file: file, gz: gz, enc: json.NewEncoder(gz)}, nil
+}
+
+// Write write the rows as json lines, the bytes are written as strings.
+func (w *archiveWriter) Write(rows []map[string]interface{}) error {
+	for _, row := range rows {
+		for k, v := range row {
+			if b, ok := v.([]byte); ok {
+				row[k] = string(b)
+			}
+		}
+		if err := w.enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	// the archived rows are flushed before they are deleted
+	if err := w.gz.Flush(); err != nil {
+		return err
+	}
+	return w.file.Sync()
+}
+
+func (w *archiveWriter) Close() error {
+	if err := w.gz.Close(); err != nil {
+		_ = w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
@@ -0,0 +1,184 @@
+// Package oplog records the operation logs of the requests, the sensitive
+// form values are masked, and cleans the expired ones.
+package oplog
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+	"github.com/wowucco/go-admin/plugins/admin/modules/constant"
+)
+
+// MaskedValue replaces the values of the masked form fields.
+const MaskedValue = "******"
+
+// maxRecordIdLength is the size of the record_id column.
+const maxRecordIdLength = 255
+
+// sensitiveWords are masked in the names of any form fields.
+var sensitiveWords = []string{"password", "secret", "token"}
+
+// Record store the operation log of the request of the logged in user, it
+// is called after the request is handled to get the status of the response.
+func Record(ctx *context.Context, conn db.Connection) {
+	user, ok := ctx.UserValue["user"].(models.UserModel)
+	if !ok {
+		return
+	}
+
+	var input []byte
+	if form := ctx.Request.MultipartForm; form != nil {
+		input, _ = json.Marshal(Mask(form.Value, config.GetOperationLog().MaskFields))
+	}
+
+	id := recordId(ctx)
+	if len(id) > maxRecordIdLength {
+		id = id[:maxRecordIdLength]
+	}
+
+	status := 0
+	if ctx.Response != nil {
+		status = ctx.Response.StatusCode
+	}
+
+	models.OperationLog().SetConn(conn).NewEntry(models.OperationLogEntry{
+		UserId:         user.Id,
+		ImpersonatorId: user.ImpersonatorId,
+		Path:           ctx.Path(),
+		Method:         ctx.Method(),
+		Ip:             ctx.LocalIP(),
+		Input:          string(input),
+		Prefix:         ctx.Param(constant.PrefixKey),
+		RecordId:       id,
+		Action:         Action(config.URLRemovePrefix(ctx.Path()), ctx.Method()),
+		Status:         status,
+	})
+}
+
+// Mask return a copy of the form values whose sensitive values are masked.
+// A field is sensitive if its name is one of the fields or contains a
+// sensitive word.
+func Mask(values map[string][]string, fields []string) map[string][]string {
+	masked := make(map[string][]string, len(values))
+	for key, value := range values {
+		if !sensitive(key, fields) {
+			masked[key] = value
+			continue
+		}
+		list := make([]string, len(value))
+		for i, v := range value {
+			if v != "" {
+				list[i] = MaskedValue
+			}
+		}
+		masked[key] = list
+	}
+	return masked
+}
+
+func sensitive(key string, fields []string) bool {
+	name := strings.ToLower(strings.TrimSuffix(key, "[]"))
+	for _, field := range fields {
+		if strings.ToLower(field) == name {
+			return true
+		}
+	}
+	for _, word := range sensitiveWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Action return the action of the request of the path without the url
+// prefix, such as models.OperationLogEdit for "/edit/users".
+func Action(path, method string) string {
+	if strings.HasPrefix(path, "/api/") {
+		path = path[len("/api"):]
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch segments[0] {
+	case "info":
+		if len(segments) > 2 {
+			switch segments[2] {
+			case "detail":
+				return models.OperationLogDetail
+			case "edit":
+				return models.OperationLogShowEdit
+			case "new":
+				return models.OperationLogShowCreate
+			}
+		}
+		return models.OperationLogQuery
+	case "list":
+		return models.OperationLogQuery
+	case "detail":
+		return models.OperationLogDetail
+	case "edit", "update":
+		if len(segments) > 2 && segments[1] == "form" {
+			return models.OperationLogShowEdit
+		}
+		return models.OperationLogEdit
+	case "new", "create":
+		if len(segments) > 2 && segments[1] == "form" {
+			return models.OperationLogShowCreate
+		}
+		return models.OperationLogCreate
+	case "delete":
+		return models.OperationLogDelete
+	case "export":
+		return models.OperationLogExport
+	case "signin":
+		return models.OperationLogLogin
+	case "logout":
+		return models.OperationLogLogout
+	case "menu":
+		if len(segments) > 1 && method == "POST" {
+			switch segments[1] {
+			case "new":
+				return models.OperationLogCreate
+			case "edit", "order":
+				return models.OperationLogEdit
+			case "delete":
+				return models.OperationLogDelete
+			}
+		}
+		return models.OperationLogQuery
+	}
+
+	if method == "GET" {
+		return models.OperationLogQuery
+	}
+	return models.OperationLogOther
+}
+
+// recordId return the primary key of the record of the request, such as the
+// ids of the deleted records.
+func recordId(ctx *context.Context) string {
+	for _, key := range []string{constant.EditPKKey, constant.DetailPKKey} {
+		if id := ctx.Query(key); id != "" {
+			return id
+		}
+	}
+	if form := ctx.Request.MultipartForm; form != nil {
+		for _, key := range []string{"pk", "id"} {
+			if id := form.Value[key]; len(id) > 0 && id[0] != "" {
+				return strings.Join(id, ",")
+			}
+		}
+	}
+	if ctx.Request.PostForm != nil {
+		for _, key := range []string{"pk", "id"} {
+			if id := ctx.Request.PostForm.Get(key); id != "" {
+				return id
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package oplog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+)
+
+func TestMask(t *testing.T) {
+	values := map[string][]string{
+		"name":           {"admin"},
+		"password":       {"secret1"},
+		"password_again": {"secret1"},
+		"api_token[]":    {"a", ""},
+		"card_number":    {"4111"},
+		"Client_Secret":  {"x"},
+	}
+
+	masked := Mask(values, []string{"CARD_NUMBER"})
+
+	assert.Equal(t, []string{"admin"}, masked["name"])
+	assert.Equal(t, []string{MaskedValue}, masked["password"])
+	assert.Equal(t, []string{MaskedValue}, masked["password_again"])
+	assert.Equal(t, []string{MaskedValue, ""}, masked["api_token[]"])
+	assert.Equal(t, []string{MaskedValue}, masked["card_number"])
+	assert.Equal(t, []string{MaskedValue}, masked["Client_Secret"])
+
+	// the values are copied
+	assert.Equal(t, []string{"secret1"}, values["password"])
+}
+
+func TestAction(t *testing.T) {
+	cases := []struct {
+		path   string
+		method string
+		action string
+	}{
+		{"/info/users", "GET", models.OperationLogQuery},
+		{"/info/users/detail", "GET", models.OperationLogDetail},
+		{"/info/users/edit", "GET", models.OperationLogShowEdit},
+		{"/info/users/new", "GET", models.OperationLogShowCreate},
+		{"/edit/users", "POST", models.OperationLogEdit},
+		{"/update/users", "POST", models.OperationLogEdit},
+		{"/new/users", "POST", models.OperationLogCreate},
+		{"/delete/users", "POST", models.OperationLogDelete},
+		{"/export/users", "POST", models.OperationLogExport},
+		{"/api/list/users", "GET", models.OperationLogQuery},
+		{"/api/update/form/users", "GET", models.OperationLogShowEdit},
+		{"/api/update/users", "POST", models.OperationLogEdit},
+		{"/api/create/form/users", "GET", models.OperationLogShowCreate},
+		{"/api/create/users", "POST", models.OperationLogCreate},
+		{"/edit/form", "POST", models.OperationLogEdit},
+		{"/menu/delete", "POST", models.OperationLogDelete},
+		{"/menu", "GET", models.OperationLogQuery},
+		{"/logout", "GET", models.OperationLogLogout},
+		{"/", "GET", models.OperationLogQuery},
+		{"/sessions/revoke", "POST", models.OperationLogOther},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.action, Action(c.path, c.method), c.path)
+	}
+}
@@ -35,6 +35,14 @@ func OkWithData(ctx *context.Context, data map[string]interface{}) {
 	})
 }
 
+func OkWithMsgAndData(ctx *context.Context, msg string, data map[string]interface{}) {
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"code": http.StatusOK,
+		"msg":  msg,
+		"data": data,
+	})
+}
+
 func BadRequest(ctx *context.Context, msg string) {
 	ctx.JSON(http.StatusBadRequest, map[string]interface{}{
 		"code": http.StatusBadRequest,
@@ -42,6 +50,13 @@ func BadRequest(ctx *context.Context, msg string) {
 	})
 }
 
+func TooManyRequests(ctx *context.Context, msg string) {
+	ctx.JSON(http.StatusTooManyRequests, map[string]interface{}{
+		"code": http.StatusTooManyRequests,
+		"msg":  language.Get(msg),
+	})
+}
+
 func Alert(ctx *context.Context, desc, title, msg string, conn db.Connection) {
 	user := auth.Auth(ctx)
 
@@ -0,0 +1,521 @@
+package table
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	tmpl "html/template"
+	"sort"
+	"strings"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/auth"
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/db"
+	errs "github.com/wowucco/go-admin/modules/errors"
+	"github.com/wowucco/go-admin/modules/language"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/modules/notify"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+	"github.com/wowucco/go-admin/plugins/admin/modules"
+	form2 "github.com/wowucco/go-admin/plugins/admin/modules/form"
+	"github.com/wowucco/go-admin/template/types/form"
+)
+
+// ErrChangeRequested is returned instead of writing the change of a table
+// which needs approvals, the change waits for the approval of another user.
+var ErrChangeRequested = errors.New("the change is waiting for approval")
+
+// ErrChangeOutdated is returned when the approved change is of a record
+// which has been changed since the change was requested.
+var ErrChangeOutdated = errors.New("the record has been changed since the change was requested")
+
+// ChangeField is a changed field of a change request, the record is the
+// primary key of the deleted rows.
+type ChangeField struct {
+	Record string `json:"record,omitempty"`
+	Field  string `json:"field"`
+	Old    string `json:"old"`
+	New    string `json:"new"`
+}
+
+type tableRequest struct {
+	prefix string
+	user   models.UserModel
+}
+
+// ForRequest set the prefix and the user of the request to the table, the
+// changes of a table which needs approvals are requested by the user.
+func ForRequest(t Table, prefix string, user models.UserModel) Table {
+	if tb, ok := t.(DefaultTable); ok {
+		tb.request = &tableRequest{prefix: prefix, user: user}
+		return tb
+	}
+	return t
+}
+
+// NeedsApproval report if the changes of the table are requested and
+// applied only after they are approved.
+func NeedsApproval(t Table) bool {
+	if tb, ok := t.(DefaultTable); ok {
+		return tb.needsApproval()
+	}
+	return false
+}
+
+func (tb DefaultTable) needsApproval() bool {
+	return tb.approval != "" && !tb.applying
+}
+
+// requestChange store the form values of the insert or the update as a
+// change request. The values are validated now, but processed when the
+// change is approved.
+func (tb DefaultTable) requestChange(kind string, dataList form2.Values) error {
+
+	if tb.Form.Validator != nil {
+		if err := tb.Form.Validator(dataList); err != nil {
+			return err
+		}
+	}
+
+	values := make(form2.Values, len(dataList))
+	for k, v := range dataList {
+		if k != form2.TokenKey {
+			values[k] = v
+		}
+	}
+
+	recordId := ""
+	if kind == models.ChangeRequestUpdate {
+		recordId = dataList.Get(tb.PrimaryKey.Name)
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	return tb.newChangeRequest(kind, recordId, string(data), tb.formDiff(recordId, values))
+}
+
+// requestDelete store the delete of the rows as a change request.
+func (tb DefaultTable) requestDelete(id string) error {
+	return tb.newChangeRequest(models.ChangeRequestDelete, id, "", tb.rowsDiff(strings.Split(id, ",")))
+}
+
+func (tb DefaultTable) newChangeRequest(kind, recordId, data string, diff []ChangeField) error {
+	if tb.request == nil {
+		return errors.New("the table needs approvals, but the request is unknown")
+	}
+	// the impersonator could approve the change requested as another user
+	if tb.request.user.IsImpersonated() {
+		return auth.ErrImpersonated
+	}
+
+	diffJSON, err := json.Marshal(diff)
+	if err != nil {
+		return err
+	}
+
+	conn := db.GetConnection(services)
+	cr, err := models.ChangeRequest().SetConn(conn).
+		New(tb.request.prefix, kind, recordId, tb.approval, data, string(diffJSON), tb.request.user.Id)
+	if err != nil {
+		return err
+	}
+
+	go notifyApprovers(conn, cr)
+
+	return ErrChangeRequested
+}
+
+// formDiff compare the form values with the stored row of the update, the
+// values of an insert are all new.
+func (tb DefaultTable) formDiff(id string, values form2.Values) []ChangeField {
+
+	var (
+		columns []string
+		row     map[string]interface{}
+	)
+
+	if tb.getDataFromDB() && tb.Form.Table != "" {
+		columns, _ = tb.getColumns(tb.Form.Table)
+		if id != "" {
+			row, _ = tb.sql().Table(tb.Form.Table).Where(tb.PrimaryKey.Name, "=", id).First()
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	diff := make([]ChangeField, 0)
+	for _, k := range keys {
+		name := strings.Replace(k, "[]", "", -1)
+		if name == tb.PrimaryKey.Name || (columns != nil && !modules.InArray(columns, name)) ||
+			(columns == nil && strings.HasPrefix(name, "__")) {
+			continue
+		}
+
+		field := tb.Form.FieldList.FindByFieldName(name)
+		delimiter := modules.SetDefault(field.DefaultOptionDelimiter, ",")
+		newValue := strings.Join(modules.RemoveBlankFromArray(values[k]), delimiter)
+		oldValue := changeValue(row[name])
+
+		if newValue == oldValue {
+			continue
+		}
+		if field.FormType == form.Password {
+			oldValue, newValue = maskValue(oldValue), maskValue(newValue)
+		}
+		diff = append(diff, ChangeField{Field: name, Old: oldValue, New: newValue})
+	}
+
+	return diff
+}
+
+// rowsDiff list the fields of the rows to be deleted.
+func (tb DefaultTable) rowsDiff(ids []string) []ChangeField {
+	diff := make([]ChangeField, 0)
+	if !tb.getDataFromDB() || tb.Info.Table == "" {
+		return diff
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, _ := tb.sql().Table(tb.Info.Table).WhereIn(tb.PrimaryKey.Name, args).All()
+
+	for _, row := range rows {
+		keys := make([]string, 0, len(row))
+		for k := range row {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		record := changeValue(row[tb.PrimaryKey.Name])
+		for _, k := range keys {
+			if k == tb.PrimaryKey.Name {
+				continue
+			}
+			oldValue := changeValue(row[k])
+			if tb.Form.FieldList.FindByFieldName(k).FormType == form.Password {
+				oldValue = maskValue(oldValue)
+			}
+			diff = append(diff, ChangeField{Record: record, Field: k, Old: oldValue})
+		}
+	}
+
+	return diff
+}
+
+// changeDiffHTML render the stored diff of a change request as a table.
+func changeDiffHTML(diff string) tmpl.HTML {
+	var fields []ChangeField
+	if err := json.Unmarshal([]byte(diff), &fields); err != nil || len(fields) == 0 {
+		return "-"
+	}
+
+	withRecord := false
+	for _, f := range fields {
+		if f.Record != "" {
+			withRecord = true
+			break
+		}
+	}
+
+	html := `<table class="table table-bordered table-condensed"><tr>`
+	if withRecord {
+		html += "<th>" + tmpl.HTMLEscapeString(lg("record")) + "</th>"
+	}
+	for _, th := range []string{"field", "old value", "new value"} {
+		html += "<th>" + tmpl.HTMLEscapeString(lg(th)) + "</th>"
+	}
+	html += "</tr>"
+	for _, f := range fields {
+		html += "<tr>"
+		if withRecord {
+			html += "<td>" + tmpl.HTMLEscapeString(f.Record) + "</td>"
+		}
+		html += "<td>" + tmpl.HTMLEscapeString(f.Field) + "</td><td>" + tmpl.HTMLEscapeString(f.Old) +
+			"</td><td>" + tmpl.HTMLEscapeString(f.New) + "</td></tr>"
+	}
+	return tmpl.HTML(html + "</table>")
+}
+
+func changeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func maskValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "******"
+}
+
+// ApplyChange write the change of the change request with the table, the
+// approvals of the table are skipped.
+func ApplyChange(t Table, cr models.ChangeRequestModel) error {
+	tb, ok := t.(DefaultTable)
+	if !ok {
+		return errors.New("the table does not support the change requests")
+	}
+	tb.applying = true
+
+	if cr.Kind == models.ChangeRequestDelete {
+		return tb.DeleteData(cr.RecordId)
+	}
+
+	values := make(form2.Values)
+	if err := json.Unmarshal([]byte(cr.Data), &values); err != nil {
+		return err
+	}
+
+	switch cr.Kind {
+	case models.ChangeRequestInsert:
+		return tb.InsertData(values)
+	case models.ChangeRequestUpdate:
+		return tb.UpdateData(values)
+	}
+	return errors.New("wrong change request kind: " + cr.Kind)
+}
+
+// CanApproveChange check if the user can approve the change request, which
+// needs its permission and can not be approved by its requester, nor by an
+// impersonated user.
+func CanApproveChange(user models.UserModel, cr models.ChangeRequestModel) bool {
+	if user.Id == cr.RequesterId || user.IsImpersonated() || user.ImpersonatorId == cr.RequesterId {
+		return false
+	}
+	return user.IsSuperAdmin() || user.CheckPermission(cr.Permission)
+}
+
+// ReviewChange approve or reject the change request by the user. An
+// approved change is decided and applied within a transaction, so that it
+// is applied once, and it is refused when the record has been changed since
+// the change was requested. The requester can withdraw the change request
+// by rejecting it.
+func ReviewChange(ctx *context.Context, conn db.Connection, list GeneratorList, cr models.ChangeRequestModel,
+	user models.UserModel, approve bool, comment string) error {
+
+	if approve && !CanApproveChange(user, cr) {
+		return errors.New(errs.NoPermission)
+	}
+	if !approve && user.Id != cr.RequesterId && !CanApproveChange(user, cr) {
+		return errors.New(errs.NoPermission)
+	}
+
+	if !approve {
+		cr, err := cr.SetConn(conn).Decide(models.ChangeRequestRejected, user.Id, comment)
+		if err != nil {
+			return err
+		}
+		go notifyRequester(conn, cr)
+		return nil
+	}
+
+	gen, ok := list[cr.Prefix]
+	if !ok {
+		return errors.New("the table of the change request is not found: " + cr.Prefix)
+	}
+	tb, ok := ForRequest(gen(ctx), cr.Prefix, user).(DefaultTable)
+	if !ok {
+		return errors.New("the table does not support the change requests")
+	}
+
+	var err error
+	if tb.canApplyWithTx(conn, cr) {
+		_, err = db.WithDriver(conn).WithTransaction(func(tx *sql.Tx) (error, map[string]interface{}) {
+			tb.tx = tx
+			decided, err := cr.SetConn(conn).WithTx(tx).Decide(models.ChangeRequestApproved, user.Id, comment)
+			if err != nil {
+				return err, nil
+			}
+			cr = decided
+			if err := tb.checkOutdated(cr); err != nil {
+				return err, nil
+			}
+			return ApplyChange(tb, cr), nil
+		})
+	} else {
+		err = tb.applyWithoutTx(conn, cr, user, comment)
+	}
+	if err != nil {
+		return err
+	}
+
+	cr.Status = models.ChangeRequestApproved
+	go notifyRequester(conn, cr)
+
+	return nil
+}
+
+// canApplyWithTx report if the change of the table can be written within
+// the transaction of the change request, which needs the same connection
+// and the default writes of the table.
+func (tb DefaultTable) canApplyWithTx(conn db.Connection, cr models.ChangeRequestModel) bool {
+	if !tb.getDataFromDB() || tb.connectionDriver != conn.Name() || tb.connection != DefaultConnectionName {
+		return false
+	}
+	switch cr.Kind {
+	case models.ChangeRequestInsert:
+		return tb.Form.InsertFn == nil
+	case models.ChangeRequestUpdate:
+		return tb.Form.UpdateFn == nil
+	case models.ChangeRequestDelete:
+		return tb.Info.DeleteFn == nil
+	}
+	return false
+}
+
+// applyWithoutTx apply the change of the tables writing with their own
+// functions or connections, which can not join the transaction. The change
+// request is decided first and it is pending again if the change fails.
+func (tb DefaultTable) applyWithoutTx(conn db.Connection, cr models.ChangeRequestModel,
+	user models.UserModel, comment string) error {
+
+	if err := tb.checkOutdated(cr); err != nil {
+		return err
+	}
+
+	cr, err := cr.SetConn(conn).Decide(models.ChangeRequestApproved, user.Id, comment)
+	if err != nil {
+		return err
+	}
+
+	if err := ApplyChange(tb, cr); err != nil {
+		if reopenErr := cr.Reopen("apply error: " + err.Error()); reopenErr != nil {
+			logger.Error("reopen change request error: ", reopenErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// checkOutdated compare the old values stored in the diff of the change
+// request with the current rows, the change of a record changed since it
+// was requested is refused.
+func (tb DefaultTable) checkOutdated(cr models.ChangeRequestModel) error {
+	if cr.Kind == models.ChangeRequestInsert || !tb.getDataFromDB() {
+		return nil
+	}
+
+	table := tb.Form.Table
+	if cr.Kind == models.ChangeRequestDelete {
+		table = tb.Info.Table
+	}
+	if table == "" {
+		return nil
+	}
+
+	var diff []ChangeField
+	if cr.Diff == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(cr.Diff), &diff); err != nil {
+		return err
+	}
+
+	rows := make(map[string]map[string]interface{})
+	for _, f := range diff {
+		record := f.Record
+		if record == "" {
+			record = cr.RecordId
+		}
+		row, ok := rows[record]
+		if !ok {
+			row, _ = tb.sql().Table(table).Where(tb.PrimaryKey.Name, "=", record).First()
+			rows[record] = row
+		}
+		if !sameOldValue(tb, row, f) {
+			return ErrChangeOutdated
+		}
+	}
+	return nil
+}
+
+// sameOldValue report if the current row has the old value of the field,
+// the passwords are compared masked as they are stored.
+func sameOldValue(tb DefaultTable, row map[string]interface{}, f ChangeField) bool {
+	if row == nil {
+		return false
+	}
+	value := changeValue(row[f.Field])
+	if tb.Form.FieldList.FindByFieldName(f.Field).FormType == form.Password {
+		value = maskValue(value)
+	}
+	return value == f.Old
+}
+
+func changeRequestURL(cr models.ChangeRequestModel) string {
+	return config.Url(fmt.Sprintf("/info/change_requests/detail?__goadmin_detail_pk=%d", cr.Id))
+}
+
+// notifyApprovers send the change request to the users who can approve it.
+func notifyApprovers(conn db.Connection, cr models.ChangeRequestModel) {
+	defer func() {
+		if err := recover(); err != nil {
+			logger.Error(err)
+		}
+	}()
+
+	users, err := db.WithDriver(conn).Table(config.GetAuthUserTable()).Select("id", "email").All()
+	if db.CheckError(err, db.QUERY) {
+		logger.Error("notify approvers error: ", err)
+		return
+	}
+
+	for _, item := range users {
+		if email, _ := item["email"].(string); email == "" {
+			continue
+		}
+		user := models.User().SetConn(conn).Find(item["id"]).WithRoles().WithPermissions()
+		if !CanApproveChange(user, cr) {
+			continue
+		}
+		if err := notify.Send(notify.Message{
+			To:      user.Email,
+			Subject: config.GetTitle() + " - " + language.Get("change request"),
+			Body: fmt.Sprintf("%s: %s %s %s\n\n%s", language.Get("a change is waiting for approval"),
+				language.Get(cr.Kind), cr.Prefix, cr.RecordId, changeRequestURL(cr)),
+		}); err != nil {
+			logger.Error("notify approver error: ", err)
+		}
+	}
+}
+
+// notifyRequester send the decision of the change request to its requester.
+func notifyRequester(conn db.Connection, cr models.ChangeRequestModel) {
+	requester := models.User().SetConn(conn).Find(cr.RequesterId)
+	if requester.IsEmpty() || requester.Email == "" || requester.Id == cr.ApproverId {
+		return
+	}
+
+	body := fmt.Sprintf("%s: %s %s %s", language.Get("the change request is "+cr.Status),
+		language.Get(cr.Kind), cr.Prefix, cr.RecordId)
+	if cr.Comment != "" {
+		body += "\n\n" + cr.Comment
+	}
+	body += "\n\n" + changeRequestURL(cr)
+
+	if err := notify.Send(notify.Message{
+		To:      requester.Email,
+		Subject: config.GetTitle() + " - " + language.Get("change request"),
+		Body:    body,
+	}); err != nil {
+		logger.Error("notify requester error: ", err)
+	}
+}
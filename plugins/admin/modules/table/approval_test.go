@@ -0,0 +1,117 @@
+package table
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wowucco/go-admin/modules/auth"
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+	form2 "github.com/wowucco/go-admin/plugins/admin/modules/form"
+	"github.com/wowucco/go-admin/template/types/form"
+)
+
+func TestNeedsApproval(t *testing.T) {
+	tb := NewDefaultTable(DefaultConfig()).(DefaultTable)
+	assert.False(t, tb.needsApproval())
+
+	tb = NewDefaultTable(DefaultConfig().SetApproval("payouts_approve")).(DefaultTable)
+	assert.True(t, tb.needsApproval())
+	assert.True(t, tb.Copy().(DefaultTable).needsApproval())
+	assert.True(t, NeedsApproval(tb))
+
+	tb.applying = true
+	assert.False(t, tb.needsApproval())
+}
+
+func TestRequestChangeValidates(t *testing.T) {
+	tb := NewDefaultTable(DefaultConfig().SetApproval("payouts_approve")).(DefaultTable)
+	tb.Form.SetPostValidator(func(values form2.Values) error {
+		return errors.New("amount is required")
+	})
+
+	err := tb.UpdateData(form2.Values{"id": {"1"}})
+	assert.EqualError(t, err, "amount is required")
+}
+
+func TestCanApproveChange(t *testing.T) {
+	cr := models.ChangeRequest()
+	cr.Permission = "payouts_approve"
+	cr.RequesterId = 1
+
+	approver := models.User()
+	approver.Id = 2
+	approver.Permissions = []models.PermissionModel{{Slug: "payouts_approve"}}
+	assert.True(t, CanApproveChange(approver, cr))
+
+	// the requester can not approve the own change
+	approver.Id = 1
+	assert.False(t, CanApproveChange(approver, cr))
+
+	other := models.User()
+	other.Id = 3
+	assert.False(t, CanApproveChange(other, cr))
+
+	// nor an impersonated user, or the requester impersonating another one
+	approver.Id = 2
+	approver.ImpersonatorId = 4
+	assert.False(t, CanApproveChange(approver, cr))
+	approver.ImpersonatorId = 1
+	assert.False(t, CanApproveChange(approver, cr))
+
+	super := models.User()
+	super.Id = 5
+	super.ImpersonatorId = 1
+	super.Roles = []models.RoleModel{{Slug: "administrator"}}
+	super.Permissions = []models.PermissionModel{{HttpPath: []string{"*"}}}
+	assert.False(t, CanApproveChange(super, cr))
+}
+
+func TestRequestChangeImpersonated(t *testing.T) {
+	tb := NewDefaultTable(DefaultConfig().SetApproval("payouts_approve")).(DefaultTable)
+	user := models.User()
+	user.Id = 2
+	user.ImpersonatorId = 1
+	tb = ForRequest(tb, "payouts", user).(DefaultTable)
+
+	err := tb.InsertData(form2.Values{"amount": {"10"}})
+	assert.Equal(t, auth.ErrImpersonated, err)
+}
+
+func TestChangeDiffHTML(t *testing.T) {
+	assert.Equal(t, "-", string(changeDiffHTML("")))
+	assert.Equal(t, "-", string(changeDiffHTML("[]")))
+
+	html := string(changeDiffHTML(`[{"field":"amount","old":"10","new":"<b>20</b>"}]`))
+	assert.True(t, strings.Contains(html, "<td>amount</td><td>10</td><td>&lt;b&gt;20&lt;/b&gt;</td>"))
+	assert.False(t, strings.Contains(html, "<td>1</td>"))
+
+	html = string(changeDiffHTML(`[{"record":"1","field":"amount","old":"10","new":""}]`))
+	assert.True(t, strings.Contains(html, "<td>1</td><td>amount</td>"))
+}
+
+func TestChangeValue(t *testing.T) {
+	assert.Equal(t, "", changeValue(nil))
+	assert.Equal(t, "abc", changeValue([]byte("abc")))
+	assert.Equal(t, "12", changeValue(int64(12)))
+	assert.Equal(t, "", maskValue(""))
+	assert.Equal(t, "******", maskValue("secret"))
+}
+
+func TestSameOldValue(t *testing.T) {
+	tb := NewDefaultTable(DefaultConfig().SetApproval("payouts_approve")).(DefaultTable)
+	tb.Form.AddField("Password", "password", db.Varchar, form.Password)
+
+	row := map[string]interface{}{"amount": int64(10), "note": nil, "password": "hash"}
+
+	assert.True(t, sameOldValue(tb, row, ChangeField{Field: "amount", Old: "10"}))
+	assert.False(t, sameOldValue(tb, row, ChangeField{Field: "amount", Old: "20"}))
+	assert.True(t, sameOldValue(tb, row, ChangeField{Field: "note", Old: ""}))
+	assert.True(t, sameOldValue(tb, row, ChangeField{Field: "password", Old: "******"}))
+	assert.False(t, sameOldValue(tb, row, ChangeField{Field: "password", Old: ""}))
+
+	// the record is deleted
+	assert.False(t, sameOldValue(tb, nil, ChangeField{Field: "amount", Old: "10"}))
+}
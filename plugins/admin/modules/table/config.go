@@ -14,6 +14,10 @@ type Config struct {
 	PrimaryKey PrimaryKey
 	SourceURL  string
 	GetDataFun GetDataFun
+	// Approval is the slug of the permission of the users who approve the
+	// changes of the table, the changes are written only when approved if
+	// it is set.
+	Approval string
 }
 
 func DefaultConfig() Config {
@@ -66,6 +70,13 @@ func (config Config) SetExportable(exportable bool) Config {
 	return config
 }
 
+// SetApproval require the changes of the table to be approved by another
+// user with the permission before they are written.
+func (config Config) SetApproval(permission string) Config {
+	config.Approval = permission
+	return config
+}
+
 func (config Config) SetConnection(connection string) Config {
 	config.Connection = connection
 	return config
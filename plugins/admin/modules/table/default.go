@@ -1,6 +1,7 @@
 package table
 
 import (
+	dbsql "database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	errs "github.com/wowucco/go-admin/modules/errors"
 	"github.com/wowucco/go-admin/modules/language"
 	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/plugins/admin/models"
 	"github.com/wowucco/go-admin/plugins/admin/modules"
 	"github.com/wowucco/go-admin/plugins/admin/modules/form"
 	"github.com/wowucco/go-admin/plugins/admin/modules/paginator"
@@ -28,6 +30,15 @@ type DefaultTable struct {
 	connection       string
 	sourceURL        string
 	getDataFun       GetDataFun
+
+	// approval is the permission of the approvers of the changes.
+	approval string
+	request  *tableRequest
+	applying bool
+
+	// tx is the transaction of the approval, which the change is
+	// applied within.
+	tx *dbsql.Tx
 }
 
 type GetDataFun func(params parameter.Parameters) ([]map[string]interface{}, int)
@@ -57,6 +68,7 @@ func NewDefaultTable(cfgs ...Config) Table {
 		connection:       cfg.Connection,
 		sourceURL:        cfg.SourceURL,
 		getDataFun:       cfg.GetDataFun,
+		approval:         cfg.Approval,
 	}
 }
 
@@ -84,6 +96,7 @@ func (tb DefaultTable) Copy() Table {
 		connection:       tb.connection,
 		sourceURL:        tb.sourceURL,
 		getDataFun:       tb.getDataFun,
+		approval:         tb.approval,
 	}
 }
 
@@ -695,6 +708,10 @@ func (tb DefaultTable) GetDataWithId(param parameter.Parameters) (FormInfo, erro
 // UpdateData update data.
 func (tb DefaultTable) UpdateData(dataList form.Values) error {
 
+	if tb.needsApproval() {
+		return tb.requestChange(models.ChangeRequestUpdate, dataList)
+	}
+
 	dataList.Add(form.PostTypeKey, "0")
 
 	var (
@@ -759,6 +776,10 @@ func (tb DefaultTable) UpdateData(dataList form.Values) error {
 // InsertData insert data.
 func (tb DefaultTable) InsertData(dataList form.Values) error {
 
+	if tb.needsApproval() {
+		return tb.requestChange(models.ChangeRequestInsert, dataList)
+	}
+
 	dataList.Add(form.PostTypeKey, "1")
 
 	var (
@@ -889,6 +910,10 @@ func (tb DefaultTable) getInjectValueFromFormValue(dataList form.Values) dialect
 // DeleteData delete data.
 func (tb DefaultTable) DeleteData(id string) error {
 
+	if tb.needsApproval() {
+		return tb.requestDelete(id)
+	}
+
 	var (
 		idArr = strings.Split(id, ",")
 		err   error
@@ -986,6 +1011,15 @@ func (tb DefaultTable) getTheadAndFilterForm(params parameter.Parameters, column
 	})
 }
 
+// Connection return the db connection and the connection name of the
+// table, the connection is nil if the data of the table is not from db.
+func Connection(t Table) (db.Connection, string) {
+	if tb, ok := t.(DefaultTable); ok {
+		return tb.db(), tb.connection
+	}
+	return nil, ""
+}
+
 // db is a helper function return raw db connection.
 func (tb DefaultTable) db() db.Connection {
 	if tb.connectionDriver != "" && tb.getDataFromDB() {
@@ -1008,7 +1042,8 @@ func (tb DefaultTable) getDataFromDB() bool {
 // sql is a helper function return db sql.
 func (tb DefaultTable) sql() *db.SQL {
 	if tb.connectionDriver != "" && tb.getDataFromDB() {
-		return db.WithDriverAndConnection(tb.connection, db.GetConnectionFromService(services.Get(tb.connectionDriver)))
+		return db.WithDriverAndConnection(tb.connection, db.GetConnectionFromService(services.Get(tb.connectionDriver))).
+			WithTx(tb.tx)
 	}
 	return nil
 }
@@ -12,17 +12,20 @@ import (
 	"time"
 
 	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/auth"
 	"github.com/wowucco/go-admin/modules/collection"
 	"github.com/wowucco/go-admin/modules/config"
 	"github.com/wowucco/go-admin/modules/db"
 	"github.com/wowucco/go-admin/modules/db/dialect"
 	errs "github.com/wowucco/go-admin/modules/errors"
+	"github.com/wowucco/go-admin/modules/file"
 	"github.com/wowucco/go-admin/modules/language"
 	"github.com/wowucco/go-admin/modules/utils"
 	"github.com/wowucco/go-admin/plugins/admin/models"
 	form2 "github.com/wowucco/go-admin/plugins/admin/modules/form"
 	"github.com/wowucco/go-admin/plugins/admin/modules/parameter"
 	"github.com/wowucco/go-admin/template"
+	"github.com/wowucco/go-admin/template/icon"
 	"github.com/wowucco/go-admin/template/types"
 	"github.com/wowucco/go-admin/template/types/action"
 	"github.com/wowucco/go-admin/template/types/form"
@@ -31,14 +34,22 @@ import (
 )
 
 type SystemTable struct {
-	conn db.Connection
-	c    *config.Config
+	conn       db.Connection
+	c          *config.Config
+	generators GeneratorList
 }
 
 func NewSystemTable(conn db.Connection, c *config.Config) *SystemTable {
 	return &SystemTable{conn: conn, c: c}
 }
 
+// SetGenerators set the generators whose tables are scanned for the
+// references of the media library.
+func (s *SystemTable) SetGenerators(list GeneratorList) *SystemTable {
+	s.generators = list
+	return s
+}
+
 func (s *SystemTable) GetManagerTable(ctx *context.Context) (managerTable Table) {
 	managerTable = NewDefaultTable(DefaultConfigWithDriver(config.GetDatabases().GetDefault().Driver))
 
@@ -81,6 +92,31 @@ func (s *SystemTable) GetManagerTable(ctx *context.Context) (managerTable Table)
 	info.AddField(lg("createdAt"), "created_at", db.Timestamp)
 	info.AddField(lg("updatedAt"), "updated_at", db.Timestamp)
 
+	info.AddActionButton(tmpl.HTML(lg("force logout everywhere")), action.Ajax("manager_force_logout",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			id, err := strconv.ParseInt(ctx.FormValue("id"), 10, 64)
+			if err != nil {
+				return false, lg("wrong id"), nil
+			}
+			if err := auth.RevokeUserSessions(id, "", s.conn); err != nil {
+				return false, err.Error(), nil
+			}
+			return true, lg("logged out everywhere"), nil
+		}).WithAlert())
+	info.AddActionButton(tmpl.HTML(lg("impersonate")), action.Ajax("manager_impersonate",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			id, err := strconv.ParseInt(ctx.FormValue("id"), 10, 64)
+			if err != nil {
+				return false, lg("wrong id"), nil
+			}
+			if err := auth.StartImpersonate(ctx, id, s.conn); err == auth.ErrCannotImpersonate {
+				return false, lg("the user cannot be impersonated"), nil
+			} else if err != nil {
+				return false, err.Error(), nil
+			}
+			return true, lg("impersonating"), map[string]interface{}{"url": config.GetIndexURL()}
+		}).WithAlert())
+
 	info.SetTable("goadmin_users").
 		SetTitle(lg("Managers")).
 		SetDescription(lg("Managers")).
@@ -108,6 +144,26 @@ func (s *SystemTable) GetManagerTable(ctx *context.Context) (managerTable Table)
 					return deleteUserPermissionErr, nil
 				}
 
+				deleteUserIdentityErr := s.connection().WithTx(tx).
+					Table("goadmin_user_identities").
+					WhereIn("user_id", ids).
+					Delete()
+
+				if db.CheckError(deleteUserIdentityErr, db.DELETE) {
+					return deleteUserIdentityErr, nil
+				}
+
+				for _, table := range []string{"goadmin_password_histories", "goadmin_password_resets", "goadmin_api_tokens", "goadmin_user_sessions", "goadmin_permission_denies"} {
+					deleteErr := s.connection().WithTx(tx).
+						Table(table).
+						WhereIn("user_id", ids).
+						Delete()
+
+					if db.CheckError(deleteErr, db.DELETE) {
+						return deleteErr, nil
+					}
+				}
+
 				deleteUserErr := s.connection().WithTx(tx).
 					Table("goadmin_users").
 					WhereIn("id", ids).
@@ -120,9 +176,30 @@ func (s *SystemTable) GetManagerTable(ctx *context.Context) (managerTable Table)
 				return nil, nil
 			})
 
+			if txErr == nil {
+				auth.InvalidateUserCache()
+			}
+
 			return txErr
 		})
 
+	if user, ok := ctx.User().(models.UserModel); ok && user.IsSuperAdmin() {
+		info.AddActionButton(tmpl.HTML(lg("reset two-factor")), action.Ajax("manager_reset_two_factor",
+			func(ctx *context.Context) (success bool, msg string, data interface{}) {
+				if user, ok := ctx.User().(models.UserModel); !ok || !user.IsSuperAdmin() {
+					return false, lg(errs.NoPermission), nil
+				}
+				tf := models.TwoFactor().SetConn(s.conn).FindByUserId(ctx.FormValue("id"))
+				if tf.IsEmpty() {
+					return false, lg("two-factor authentication is not enabled"), nil
+				}
+				if err := tf.Delete(); db.CheckError(err, db.DELETE) {
+					return false, err.Error(), nil
+				}
+				return true, lg("two-factor authentication is reset"), nil
+			}).WithAlert())
+	}
+
 	formList := managerTable.GetForm().AddXssJsFilter()
 
 	formList.AddField("ID", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
@@ -164,7 +241,13 @@ func (s *SystemTable) GetManagerTable(ctx *context.Context) (managerTable Table)
 			return permissions
 		}).FieldHelpMsg(template.HTML(lg("no corresponding options?")) +
 		link("/admin/info/permission/new", "Create here."))
+	formList.AddField(lg("denied permission"), "denied_permission_id", db.Varchar, form.Select).
+		FieldOptionsFromTable("goadmin_permissions", "slug", "id").
+		FieldDisplay(func(model types.FieldModel) interface{} {
+			return s.deniedPermissionIds("user_id", model.ID)
+		}).FieldHelpMsg(template.HTML(lg("override the permissions of the user and the roles")))
 
+	formList.AddField(lg("email"), "email", db.Varchar, form.Email).FieldHelpMsg(template.HTML(lg("use to reset the password")))
 	formList.AddField(lg("password"), "password", db.Varchar, form.Password).
 		FieldDisplay(func(value types.FieldModel) interface{} {
 			return ""
@@ -185,12 +268,23 @@ func (s *SystemTable) GetManagerTable(ctx *context.Context) (managerTable Table)
 
 		password := values.Get("password")
 
+		// the password and the email, which resets the password, of the
+		// impersonated user are changed only by the real user
+		if login := auth.Auth(ctx); login.IsImpersonated() && login.Id == user.Id &&
+			(password != "" || values.Get("email") != models.User().SetConn(s.conn).Find(user.Id).Email) {
+			return auth.ErrImpersonated
+		}
+
 		if password != "" {
 
 			if password != values.Get("password_again") {
 				return errors.New("password does not match")
 			}
 
+			if err := auth.CheckPassword(user.Id, values.Get("username"), password, s.conn); err != nil {
+				return err
+			}
+
 			password = encodePassword([]byte(values.Get("password")))
 		}
 
@@ -202,6 +296,12 @@ func (s *SystemTable) GetManagerTable(ctx *context.Context) (managerTable Table)
 				return updateUserErr, nil
 			}
 
+			_, updateEmailErr := user.WithTx(tx).UpdateEmail(values.Get("email"))
+
+			if db.CheckError(updateEmailErr, db.UPDATE) {
+				return updateEmailErr, nil
+			}
+
 			delRoleErr := user.WithTx(tx).DeleteRoles()
 
 			if db.CheckError(delRoleErr, db.DELETE) {
@@ -228,9 +328,34 @@ func (s *SystemTable) GetManagerTable(ctx *context.Context) (managerTable Table)
 				}
 			}
 
+			delDeniedPermissionErr := user.WithTx(tx).DeleteDeniedPermissions()
+
+			if db.CheckError(delDeniedPermissionErr, db.DELETE) {
+				return delDeniedPermissionErr, nil
+			}
+
+			for i := 0; i < len(values["denied_permission_id[]"]); i++ {
+				_, addDeniedPermissionErr := user.WithTx(tx).AddDeniedPermission(values["denied_permission_id[]"][i])
+				if db.CheckError(addDeniedPermissionErr, db.INSERT) {
+					return addDeniedPermissionErr, nil
+				}
+			}
+
 			return nil, nil
 		})
 
+		if txErr == nil {
+			auth.InvalidateUserCache(user.Id)
+		}
+
+		if txErr == nil && password != "" {
+			auth.RecordPassword(user.Id, password, s.conn)
+			// the other sessions of the user end with the old password
+			if err := auth.RevokeUserSessions(user.Id, auth.CurrentSessionId(ctx), s.conn); err != nil {
+				logger.Error("revoke user sessions error: ", err)
+			}
+		}
+
 		return txErr
 	})
 	formList.SetInsertFn(func(values form2.Values) error {
@@ -244,10 +369,18 @@ func (s *SystemTable) GetManagerTable(ctx *context.Context) (managerTable Table)
 			return errors.New("password does not match")
 		}
 
+		if err := auth.CheckPassword(0, values.Get("username"), password, s.conn); err != nil {
+			return err
+		}
+
+		password = encodePassword([]byte(password))
+
+		var userId int64
+
 		_, txErr := s.connection().WithTransaction(func(tx *sql.Tx) (e error, i map[string]interface{}) {
 
 			user, createUserErr := models.User().WithTx(tx).SetConn(s.conn).New(values.Get("username"),
-				encodePassword([]byte(values.Get("password"))),
+				password,
 				values.Get("name"),
 				values.Get("avatar"))
 
@@ -255,6 +388,14 @@ func (s *SystemTable) GetManagerTable(ctx *context.Context) (managerTable Table)
 				return createUserErr, nil
 			}
 
+			userId = user.Id
+
+			_, updateEmailErr := user.WithTx(tx).UpdateEmail(values.Get("email"))
+
+			if db.CheckError(updateEmailErr, db.UPDATE) {
+				return updateEmailErr, nil
+			}
+
 			for i := 0; i < len(values["role_id[]"]); i++ {
 				_, addRoleErr := user.WithTx(tx).AddRole(values["role_id[]"][i])
 				if db.CheckError(addRoleErr, db.INSERT) {
@@ -269,8 +410,20 @@ func (s *SystemTable) GetManagerTable(ctx *context.Context) (managerTable Table)
 				}
 			}
 
+			for i := 0; i < len(values["denied_permission_id[]"]); i++ {
+				_, addDeniedPermissionErr := user.WithTx(tx).AddDeniedPermission(values["denied_permission_id[]"][i])
+				if db.CheckError(addDeniedPermissionErr, db.INSERT) {
+					return addDeniedPermissionErr, nil
+				}
+			}
+
 			return nil, nil
 		})
+
+		if txErr == nil {
+			auth.RecordPassword(userId, password, s.conn)
+		}
+
 		return txErr
 	})
 
@@ -279,10 +432,8 @@ func (s *SystemTable) GetManagerTable(ctx *context.Context) (managerTable Table)
 	detail.AddField(lg("Name"), "username", db.Varchar)
 	detail.AddField(lg("Avatar"), "avatar", db.Varchar).
 		FieldDisplay(func(model types.FieldModel) interface{} {
-			if model.Value == "" || config.GetStore().Prefix == "" {
+			if model.Value = file.StoredURL(model.Value); model.Value == "" {
 				model.Value = config.Url("/assets/dist/img/avatar04.png")
-			} else {
-				model.Value = config.GetStore().URL(model.Value)
 			}
 			return template.Default().Image().
 				SetSrc(template.HTML(model.Value)).
@@ -335,6 +486,25 @@ func (s *SystemTable) GetManagerTable(ctx *context.Context) (managerTable Table)
 
 			return permissions
 		})
+	detail.AddField(lg("inherited roles"), "roles", db.Varchar).
+		FieldDisplay(func(model types.FieldModel) interface{} {
+			user := models.User().SetConn(s.conn).Find(model.ID).WithRoles()
+			names := make([]string, len(user.InheritedRoles))
+			for i, role := range user.InheritedRoles {
+				names[i] = role.Name
+			}
+			return labels(names, "info")
+		})
+	detail.AddField(lg("effective permissions"), "roles", db.Varchar).
+		FieldDisplay(func(model types.FieldModel) interface{} {
+			user := models.User().SetConn(s.conn).Find(model.ID).WithRoles().WithPermissions()
+			return labels(permissionNames(user.Permissions), "success")
+		})
+	detail.AddField(lg("denied permissions"), "roles", db.Varchar).
+		FieldDisplay(func(model types.FieldModel) interface{} {
+			user := models.User().SetConn(s.conn).Find(model.ID).WithRoles().WithPermissions()
+			return labels(permissionNames(user.DeniedPermissions), "danger")
+		})
 	detail.AddField(lg("createdAt"), "created_at", db.Timestamp)
 	detail.AddField(lg("updatedAt"), "updated_at", db.Timestamp)
 
@@ -410,6 +580,26 @@ func (s *SystemTable) GetNormalManagerTable(ctx *context.Context) (managerTable
 					return deleteUserPermissionErr, nil
 				}
 
+				deleteUserIdentityErr := s.connection().WithTx(tx).
+					Table("goadmin_user_identities").
+					WhereIn("user_id", ids).
+					Delete()
+
+				if db.CheckError(deleteUserIdentityErr, db.DELETE) {
+					return deleteUserIdentityErr, nil
+				}
+
+				for _, table := range []string{"goadmin_password_histories", "goadmin_password_resets", "goadmin_api_tokens", "goadmin_user_sessions", "goadmin_permission_denies"} {
+					deleteErr := s.connection().WithTx(tx).
+						Table(table).
+						WhereIn("user_id", ids).
+						Delete()
+
+					if db.CheckError(deleteErr, db.DELETE) {
+						return deleteErr, nil
+					}
+				}
+
 				deleteUserErr := s.connection().WithTx(tx).
 					Table("goadmin_users").
 					WhereIn("id", ids).
@@ -422,6 +612,10 @@ func (s *SystemTable) GetNormalManagerTable(ctx *context.Context) (managerTable
 				return nil, nil
 			})
 
+			if txErr == nil {
+				auth.InvalidateUserCache()
+			}
+
 			return txErr
 		})
 
@@ -431,14 +625,19 @@ func (s *SystemTable) GetNormalManagerTable(ctx *context.Context) (managerTable
 	formList.AddField(lg("Name"), "username", db.Varchar, form.Text).FieldHelpMsg(template.HTML(lg("use for login"))).FieldMust()
 	formList.AddField(lg("Nickname"), "name", db.Varchar, form.Text).FieldHelpMsg(template.HTML(lg("use to display"))).FieldMust()
 	formList.AddField(lg("Avatar"), "avatar", db.Varchar, form.File)
+	formList.AddField(lg("email"), "email", db.Varchar, form.Email).FieldHelpMsg(template.HTML(lg("use to reset the password")))
 	formList.AddField(lg("password"), "password", db.Varchar, form.Password).
 		FieldDisplay(func(value types.FieldModel) interface{} {
 			return ""
 		})
+	settingLinks := link(config.Url("/2fa"), "two-factor authentication") + " | " + link(config.Url("/sessions"), "my sessions")
+	if config.GetOpenAdminApi() {
+		settingLinks += " | " + link(config.Url("/tokens"), "api tokens")
+	}
 	formList.AddField(lg("confirm password"), "password_again", db.Varchar, form.Password).
 		FieldDisplay(func(value types.FieldModel) interface{} {
 			return ""
-		})
+		}).FieldHelpMsg(settingLinks)
 
 	formList.SetTable("goadmin_users").SetTitle(lg("Managers")).SetDescription(lg("Managers"))
 	formList.SetUpdateFn(func(values form2.Values) error {
@@ -455,12 +654,23 @@ func (s *SystemTable) GetNormalManagerTable(ctx *context.Context) (managerTable
 
 		password := values.Get("password")
 
+		// the password and the email, which resets the password, are
+		// changed only by the real user
+		if auth.Auth(ctx).IsImpersonated() &&
+			(password != "" || values.Get("email") != models.User().SetConn(s.conn).Find(user.Id).Email) {
+			return auth.ErrImpersonated
+		}
+
 		if password != "" {
 
 			if password != values.Get("password_again") {
 				return errors.New("password does not match")
 			}
 
+			if err := auth.CheckPassword(user.Id, values.Get("username"), password, s.conn); err != nil {
+				return err
+			}
+
 			password = encodePassword([]byte(values.Get("password")))
 		}
 
@@ -470,6 +680,21 @@ func (s *SystemTable) GetNormalManagerTable(ctx *context.Context) (managerTable
 			return updateUserErr
 		}
 
+		_, updateEmailErr := user.UpdateEmail(values.Get("email"))
+
+		if db.CheckError(updateEmailErr, db.UPDATE) {
+			return updateEmailErr
+		}
+
+		auth.InvalidateUserCache(user.Id)
+
+		if password != "" {
+			auth.RecordPassword(user.Id, password, s.conn)
+			if err := auth.RevokeUserSessions(user.Id, auth.CurrentSessionId(ctx), s.conn); err != nil {
+				logger.Error("revoke user sessions error: ", err)
+			}
+		}
+
 		return nil
 	})
 	formList.SetInsertFn(func(values form2.Values) error {
@@ -487,8 +712,14 @@ func (s *SystemTable) GetNormalManagerTable(ctx *context.Context) (managerTable
 			return errors.New(errs.NoPermission)
 		}
 
-		_, createUserErr := models.User().SetConn(s.conn).New(values.Get("username"),
-			encodePassword([]byte(values.Get("password"))),
+		if err := auth.CheckPassword(0, values.Get("username"), password, s.conn); err != nil {
+			return err
+		}
+
+		password = encodePassword([]byte(password))
+
+		user, createUserErr := models.User().SetConn(s.conn).New(values.Get("username"),
+			password,
 			values.Get("name"),
 			values.Get("avatar"))
 
@@ -496,6 +727,14 @@ func (s *SystemTable) GetNormalManagerTable(ctx *context.Context) (managerTable
 			return createUserErr
 		}
 
+		_, updateEmailErr := user.UpdateEmail(values.Get("email"))
+
+		if db.CheckError(updateEmailErr, db.UPDATE) {
+			return updateEmailErr
+		}
+
+		auth.RecordPassword(user.Id, password, s.conn)
+
 		return nil
 	})
 
@@ -559,6 +798,15 @@ func (s *SystemTable) GetPermissionTable(ctx *context.Context) (permissionTable
 					return deleteUserPermissionErr, nil
 				}
 
+				deletePermissionDenyErr := s.connection().WithTx(tx).
+					Table("goadmin_permission_denies").
+					WhereIn("permission_id", ids).
+					Delete()
+
+				if db.CheckError(deletePermissionDenyErr, db.DELETE) {
+					return deletePermissionDenyErr, nil
+				}
+
 				deletePermissionsErr := s.connection().WithTx(tx).
 					Table("goadmin_permissions").
 					WhereIn("id", ids).
@@ -571,6 +819,10 @@ func (s *SystemTable) GetPermissionTable(ctx *context.Context) (permissionTable
 				return nil, nil
 			})
 
+			if txErr == nil {
+				auth.InvalidateUserCache()
+			}
+
 			return txErr
 		})
 
@@ -619,6 +871,7 @@ func (s *SystemTable) GetPermissionTable(ctx *context.Context) (permissionTable
 			}
 			return nil
 		}).SetPostHook(func(values form2.Values) error {
+		auth.InvalidateUserCache()
 		_, err := s.connection().Table("goadmin_permissions").
 			Where("id", "=", values.Get("id")).Update(dialect.H{
 			"updated_at": time.Now().Format("2006-01-02 15:04:05"),
@@ -676,6 +929,25 @@ func (s *SystemTable) GetRolesTable(ctx *context.Context) (roleTable Table) {
 					return deleteRolePermissionErr, nil
 				}
 
+				deleteRoleDenyErr := s.connection().WithTx(tx).
+					Table("goadmin_permission_denies").
+					WhereIn("role_id", ids).
+					Delete()
+
+				if db.CheckError(deleteRoleDenyErr, db.DELETE) {
+					return deleteRoleDenyErr, nil
+				}
+
+				// the children of the deleted roles have no parent
+				_, updateChildrenErr := s.connection().WithTx(tx).
+					Table("goadmin_roles").
+					WhereIn("parent_id", ids).
+					Update(dialect.H{"parent_id": 0})
+
+				if db.CheckError(updateChildrenErr, db.UPDATE) {
+					return updateChildrenErr, nil
+				}
+
 				deleteRolesErr := s.connection().WithTx(tx).
 					Table("goadmin_roles").
 					WhereIn("id", ids).
@@ -688,6 +960,10 @@ func (s *SystemTable) GetRolesTable(ctx *context.Context) (roleTable Table) {
 				return nil, nil
 			})
 
+			if txErr == nil {
+				auth.InvalidateUserCache()
+			}
+
 			return txErr
 		})
 
@@ -714,6 +990,22 @@ func (s *SystemTable) GetRolesTable(ctx *context.Context) (roleTable Table) {
 			return permissions
 		}).FieldHelpMsg(template.HTML(lg("no corresponding options?")) +
 		link("/admin/info/permission/new", "Create here."))
+	formList.AddField(lg("denied permission"), "denied_permission_id", db.Varchar, form.SelectBox).
+		FieldOptionsFromTable("goadmin_permissions", "name", "id").
+		FieldDisplay(func(model types.FieldModel) interface{} {
+			return s.deniedPermissionIds("role_id", model.ID)
+		}).FieldHelpMsg(template.HTML(lg("override the permissions of the role and the parent roles")))
+	formList.AddField(lg("parent role"), "parent_id", db.Int, form.SelectSingle).
+		FieldOptionsFromTable("goadmin_roles", "name", "id").
+		FieldOptionExt(map[string]interface{}{"allowClear": true}).
+		FieldHelpMsg(template.HTML(lg("the role has the permissions and the menus of the parent role")))
+	formList.AddField(lg("two-factor authentication"), "two_factor", db.Tinyint, form.Switch).
+		FieldOptions(types.FieldOptions{
+			{Text: lg("required"), Value: "1"},
+			{Text: lg("optional"), Value: "0"},
+		}).
+		FieldDefault("0").
+		FieldHelpMsg(template.HTML(lg("the users of the role and its child roles must enroll the two-factor authentication")))
 
 	formList.AddField(lg("updatedAt"), "updated_at", db.Timestamp, form.Default).FieldNotAllowAdd()
 	formList.AddField(lg("createdAt"), "created_at", db.Timestamp, form.Default).FieldNotAllowAdd()
@@ -730,6 +1022,14 @@ func (s *SystemTable) GetRolesTable(ctx *context.Context) (roleTable Table) {
 
 		role := models.RoleWithId(values.Get("id")).SetConn(s.conn)
 
+		parentId, _ := strconv.ParseInt(values.Get("parent_id"), 10, 64)
+		if !role.CanSetParent(parentId) {
+			return errors.New(lg("the parent role makes a cycle"))
+		}
+		wasParentId := models.Role().SetConn(s.conn).Find(role.Id).ParentId
+
+		required, wasRequired := values.Get("two_factor") == "1", role.IsTwoFactorRequired()
+
 		_, txErr := s.connection().WithTransaction(func(tx *sql.Tx) (e error, i map[string]interface{}) {
 
 			_, updateRoleErr := role.WithTx(tx).Update(values.Get("name"), values.Get("slug"))
@@ -751,9 +1051,40 @@ func (s *SystemTable) GetRolesTable(ctx *context.Context) (roleTable Table) {
 				}
 			}
 
+			delDeniedPermissionErr := role.WithTx(tx).DeleteDeniedPermissions()
+
+			if db.CheckError(delDeniedPermissionErr, db.DELETE) {
+				return delDeniedPermissionErr, nil
+			}
+
+			for i := 0; i < len(values["denied_permission_id[]"]); i++ {
+				_, addDeniedPermissionErr := role.WithTx(tx).AddDeniedPermission(values["denied_permission_id[]"][i])
+				if db.CheckError(addDeniedPermissionErr, db.INSERT) {
+					return addDeniedPermissionErr, nil
+				}
+			}
+
+			if parentId != wasParentId {
+				if err := role.WithTx(tx).UpdateParent(parentId); err != nil {
+					return err, nil
+				}
+			}
+
+			// the column may be missing in the tables before the two-factor
+			// authentication, so it is written only when turned on or off
+			if required != wasRequired {
+				if err := role.WithTx(tx).UpdateTwoFactor(required); err != nil {
+					return err, nil
+				}
+			}
+
 			return nil, nil
 		})
 
+		if txErr == nil {
+			auth.InvalidateUserCache()
+		}
+
 		return txErr
 	})
 
@@ -777,9 +1108,33 @@ func (s *SystemTable) GetRolesTable(ctx *context.Context) (roleTable Table) {
 				}
 			}
 
+			for i := 0; i < len(values["denied_permission_id[]"]); i++ {
+				_, addDeniedPermissionErr := role.WithTx(tx).AddDeniedPermission(values["denied_permission_id[]"][i])
+				if db.CheckError(addDeniedPermissionErr, db.INSERT) {
+					return addDeniedPermissionErr, nil
+				}
+			}
+
+			// a new role cannot be an ancestor of its parent
+			if parentId, _ := strconv.ParseInt(values.Get("parent_id"), 10, 64); parentId != 0 {
+				if err := role.WithTx(tx).UpdateParent(parentId); err != nil {
+					return err, nil
+				}
+			}
+
+			if values.Get("two_factor") == "1" {
+				if err := role.WithTx(tx).UpdateTwoFactor(true); err != nil {
+					return err, nil
+				}
+			}
+
 			return nil, nil
 		})
 
+		if txErr == nil {
+			auth.InvalidateUserCache()
+		}
+
 		return txErr
 	})
 
@@ -801,7 +1156,20 @@ func (s *SystemTable) GetOpTable(ctx *context.Context) (opTable Table) {
 	})
 
 	info := opTable.GetInfo().AddXssJsFilter().
-		HideFilterArea().HideDeleteButton().HideDetailButton().HideEditButton().HideNewButton()
+		HideDeleteButton().HideDetailButton().HideEditButton().HideNewButton().
+		SetSortField("id").SetSortDesc()
+
+	actions := []string{
+		models.OperationLogQuery, models.OperationLogDetail, models.OperationLogShowEdit,
+		models.OperationLogShowCreate, models.OperationLogEdit, models.OperationLogCreate,
+		models.OperationLogDelete, models.OperationLogExport, models.OperationLogLogin,
+		models.OperationLogLogout, models.OperationLogOther,
+	}
+	actionOptions := make(types.FieldOptions, len(actions))
+	for k, a := range actions {
+		actionOptions[k].Value = a
+		actionOptions[k].Text = lg(a)
+	}
 
 	info.AddField("ID", "id", db.Int).FieldSortable()
 	info.AddField("userID", "user_id", db.Int).FieldHide()
@@ -818,11 +1186,43 @@ func (s *SystemTable) GetOpTable(ctx *context.Context) (opTable Table) {
 			SetTabTitle("Manager Detail").
 			GetContent()
 	}).FieldFilterable()
-	info.AddField(lg("path"), "path", db.Varchar).FieldFilterable()
+	info.AddField(lg("impersonator"), "impersonator_id", db.Int).FieldDisplay(func(value types.FieldModel) interface{} {
+		if value.Value == "" || value.Value == "0" {
+			return "-"
+		}
+		return template.Default().
+			Link().
+			SetURL(config.Url("/info/manager/detail?__goadmin_detail_pk=") + value.Value).
+			SetContent(template.HTML(value.Value)).
+			OpenInNewTab().
+			SetTabTitle("Manager Detail").
+			GetContent()
+	})
+	info.AddField(lg("table"), "prefix", db.Varchar).FieldFilterable()
+	info.AddField(lg("record"), "record_id", db.Varchar).FieldFilterable()
+	info.AddField(lg("action"), "action", db.Varchar).FieldDisplay(func(value types.FieldModel) interface{} {
+		if value.Value == "" {
+			return "-"
+		}
+		return lg(value.Value)
+	}).FieldFilterable(types.FilterType{FormType: form.SelectSingle}).FieldFilterOptions(actionOptions)
+	info.AddField(lg("path"), "path", db.Varchar).FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
 	info.AddField(lg("method"), "method", db.Varchar).FieldFilterable()
 	info.AddField(lg("ip"), "ip", db.Varchar).FieldFilterable()
+	info.AddField(lg("response status"), "status", db.Int).FieldDisplay(func(value types.FieldModel) interface{} {
+		status, _ := strconv.Atoi(value.Value)
+		if status == 0 {
+			return "-"
+		}
+		typ := "success"
+		if status >= 400 {
+			typ = "danger"
+		}
+		return label().SetType(typ).SetContent(tmpl.HTML(value.Value)).GetContent()
+	}).FieldFilterable()
 	info.AddField(lg("content"), "input", db.Text).FieldWidth(230)
-	info.AddField(lg("createdAt"), "created_at", db.Timestamp)
+	info.AddField(lg("createdAt"), "created_at", db.Timestamp).FieldSortable().
+		FieldFilterable(types.FilterType{FormType: form.DatetimeRange})
 
 	users, _ := s.table(config.GetAuthUserTable()).Select("id", "name").All()
 	options := make(types.FieldOptions, len(users))
@@ -839,6 +1239,7 @@ func (s *SystemTable) GetOpTable(ctx *context.Context) (opTable Table) {
 		{Value: "HEAD", Text: "HEAD"},
 		{Value: "DELETE", Text: "DELETE"},
 	}, action.FieldFilter("method"))
+	info.AddSelectBox(lg("action"), actionOptions, action.FieldFilter("action"))
 
 	info.SetTable("goadmin_operation_log").
 		SetTitle(lg("operation log")).
@@ -848,10 +1249,14 @@ func (s *SystemTable) GetOpTable(ctx *context.Context) (opTable Table) {
 
 	formList.AddField("ID", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
 	formList.AddField(lg("userID"), "user_id", db.Int, form.Text)
+	formList.AddField(lg("table"), "prefix", db.Varchar, form.Text)
+	formList.AddField(lg("record"), "record_id", db.Varchar, form.Text)
+	formList.AddField(lg("action"), "action", db.Varchar, form.Text)
 	formList.AddField(lg("path"), "path", db.Varchar, form.Text)
 	formList.AddField(lg("method"), "method", db.Varchar, form.Text)
 	formList.AddField(lg("ip"), "ip", db.Varchar, form.Text)
 	formList.AddField(lg("content"), "input", db.Varchar, form.Text)
+	formList.AddField(lg("response status"), "status", db.Int, form.Number)
 	formList.AddField(lg("updatedAt"), "updated_at", db.Timestamp, form.Default).FieldNotAllowAdd()
 	formList.AddField(lg("createdAt"), "created_at", db.Timestamp, form.Default).FieldNotAllowAdd()
 
@@ -862,6 +1267,338 @@ func (s *SystemTable) GetOpTable(ctx *context.Context) (opTable Table) {
 	return
 }
 
+func (s *SystemTable) GetMediaTable(ctx *context.Context) (mediaTable Table) {
+	mediaTable = NewDefaultTable(Config{
+		Driver:     config.GetDatabases().GetDefault().Driver,
+		CanAdd:     false,
+		Editable:   false,
+		Deletable:  true,
+		Exportable: false,
+		Connection: "default",
+		PrimaryKey: PrimaryKey{
+			Type: db.Int,
+			Name: DefaultPrimaryKeyName,
+		},
+	})
+
+	info := mediaTable.GetInfo().AddXssJsFilter().HideEditButton().HideNewButton()
+
+	info.AddField("ID", "id", db.Int).FieldSortable()
+	info.AddField(lg("preview"), "path", db.Varchar).FieldDisplay(func(value types.FieldModel) interface{} {
+		mime, _ := value.Row["mime"].(string)
+		if strings.HasPrefix(mime, "image/") {
+			return template.Default().Image().SetWidth("60").SetHeight("60").
+				SetSrc(template.HTML(file.URL(file.VariantPath(value.Value, file.ThumbnailVariant)))).
+				GetContent()
+		}
+		return template.Default().Link().SetURL(file.URL(value.Value)).
+			SetContent(template.HTML(tmpl.HTMLEscapeString(value.Value))).
+			OpenInNewTab().GetContent()
+	})
+	info.AddField(lg("name"), "name", db.Varchar).FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+	info.AddField(lg("mime"), "mime", db.Varchar).FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+	info.AddField(lg("size"), "size", db.Int).FieldSortable().FieldDisplay(func(value types.FieldModel) interface{} {
+		size, _ := strconv.ParseInt(value.Value, 10, 64)
+		return file.FormatSize(size)
+	})
+	info.AddField("userID", "user_id", db.Int).FieldHide()
+	info.AddField(lg("uploader"), "name", db.Varchar).FieldJoin(types.Join{
+		Table:     config.GetAuthUserTable(),
+		JoinField: "id",
+		Field:     "user_id",
+	})
+	info.AddField(lg("references"), "refs", db.Int).FieldSortable()
+	info.AddField(lg("createdAt"), "created_at", db.Timestamp).FieldSortable()
+
+	users, _ := s.table(config.GetAuthUserTable()).Select("id", "name").All()
+	options := make(types.FieldOptions, len(users))
+	for k, user := range users {
+		options[k].Value = fmt.Sprintf("%v", user["id"])
+		options[k].Text = fmt.Sprintf("%v", user["name"])
+	}
+	info.AddSelectBox(lg("uploader"), options, action.FieldFilter("user_id"))
+
+	info.AddButton(tmpl.HTML(lg("clean up")), icon.Eraser, action.Ajax("media_clean",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			deleted, err := CleanMedia(ctx, s.conn, s.generators, DefaultMediaCleanGrace)
+			if err != nil {
+				return false, err.Error(), nil
+			}
+			return true, fmt.Sprintf("%s: %d", lg("orphaned files deleted"), len(deleted)), nil
+		}).WithAlert())
+
+	// deleting a record deletes the stored file, which must not be referenced
+	info.SetDeleteFn(func(ids []string) error {
+		scan, err := ScanMediaReferences(ctx, s.generators)
+		if err != nil {
+			return err
+		}
+		media := models.Media().SetConn(s.conn)
+		for _, id := range ids {
+			m := media.Find(id)
+			if m.IsEmpty() {
+				continue
+			}
+			if scan.Refs[m.Path] > 0 {
+				return errors.New(m.Name + ": " + lg("file is referenced"))
+			}
+			if err := DeleteMediaFile(m); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	info.SetTable("goadmin_media").
+		SetTitle(lg("media library")).
+		SetDescription(lg("media library"))
+
+	formList := mediaTable.GetForm().AddXssJsFilter()
+
+	formList.AddField("ID", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+	formList.AddField(lg("name"), "name", db.Varchar, form.Text)
+	formList.AddField(lg("path"), "path", db.Varchar, form.Text).FieldNotAllowEdit()
+	formList.AddField(lg("mime"), "mime", db.Varchar, form.Text).FieldNotAllowEdit()
+	formList.AddField(lg("size"), "size", db.Int, form.Number).FieldNotAllowEdit()
+	formList.AddField(lg("updatedAt"), "updated_at", db.Timestamp, form.Default).FieldNotAllowAdd()
+	formList.AddField(lg("createdAt"), "created_at", db.Timestamp, form.Default).FieldNotAllowAdd()
+
+	formList.SetTable("goadmin_media").
+		SetTitle(lg("media library")).
+		SetDescription(lg("media library"))
+
+	return
+}
+
+func (s *SystemTable) GetLoginThrottleTable(ctx *context.Context) (throttleTable Table) {
+	throttleTable = NewDefaultTable(Config{
+		Driver:     config.GetDatabases().GetDefault().Driver,
+		CanAdd:     false,
+		Editable:   false,
+		Deletable:  true,
+		Exportable: false,
+		Connection: "default",
+		PrimaryKey: PrimaryKey{
+			Type: db.Int,
+			Name: DefaultPrimaryKeyName,
+		},
+	})
+
+	info := throttleTable.GetInfo().AddXssJsFilter().HideEditButton().HideNewButton().
+		SetSortField("locked_until").SetSortDesc()
+
+	formatUnix := func(value types.FieldModel) interface{} {
+		sec, _ := strconv.ParseInt(value.Value, 10, 64)
+		if sec == 0 {
+			return "-"
+		}
+		return time.Unix(sec, 0).Format("2006-01-02 15:04:05")
+	}
+
+	info.AddField("ID", "id", db.Int).FieldSortable()
+	info.AddField(lg("kind"), "kind", db.Varchar).FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(types.FieldOptions{
+			{Value: models.LoginThrottleUsername, Text: lg("username")},
+			{Value: models.LoginThrottleIP, Text: "IP"},
+		})
+	info.AddField(lg("value"), "value", db.Varchar).FieldFilterable(types.FilterType{Operator: types.FilterOperatorLike})
+	info.AddField(lg("failures"), "failures", db.Int).FieldSortable()
+	info.AddField(lg("locked until"), "locked_until", db.Int).FieldSortable().FieldDisplay(func(value types.FieldModel) interface{} {
+		sec, _ := strconv.ParseInt(value.Value, 10, 64)
+		if sec <= time.Now().Unix() {
+			return formatUnix(value)
+		}
+		return fmt.Sprintf("%s %s", label().SetType("danger").SetContent(tmpl.HTML(lg("locked"))).GetContent(),
+			formatUnix(value))
+	})
+	info.AddField(lg("last failed at"), "last_failed_at", db.Int).FieldSortable().FieldDisplay(formatUnix)
+	info.AddField(lg("updatedAt"), "updated_at", db.Timestamp)
+
+	info.AddActionButton(tmpl.HTML(lg("unlock")), action.Ajax("login_throttle_unlock",
+		func(ctx *context.Context) (success bool, msg string, data interface{}) {
+			t := models.LoginThrottle().SetConn(s.conn).FindById(ctx.FormValue("id"))
+			if t.IsEmpty() {
+				return true, lg("unlocked"), nil
+			}
+			if err := t.Delete(); db.CheckError(err, db.DELETE) {
+				return false, err.Error(), nil
+			}
+			return true, lg("unlocked"), nil
+		}).WithAlert())
+
+	info.SetTable("goadmin_login_throttles").
+		SetTitle(lg("login locks")).
+		SetDescription(lg("login locks"))
+
+	formList := throttleTable.GetForm().AddXssJsFilter()
+
+	formList.AddField("ID", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+	formList.AddField(lg("kind"), "kind", db.Varchar, form.Default).FieldNotAllowEdit()
+	formList.AddField(lg("value"), "value", db.Varchar, form.Default).FieldNotAllowEdit()
+	formList.AddField(lg("failures"), "failures", db.Int, form.Default).FieldNotAllowEdit()
+
+	formList.SetTable("goadmin_login_throttles").
+		SetTitle(lg("login locks")).
+		SetDescription(lg("login locks"))
+
+	return
+}
+
+func (s *SystemTable) GetChangeRequestTable(ctx *context.Context) (changeTable Table) {
+	changeTable = NewDefaultTable(Config{
+		Driver:     config.GetDatabases().GetDefault().Driver,
+		CanAdd:     false,
+		Editable:   true,
+		Deletable:  false,
+		Exportable: false,
+		Connection: "default",
+		PrimaryKey: PrimaryKey{
+			Type: db.Int,
+			Name: DefaultPrimaryKeyName,
+		},
+	})
+
+	users, _ := s.table(config.GetAuthUserTable()).Select("id", "name").All()
+	names := make(map[string]string, len(users))
+	options := make(types.FieldOptions, len(users))
+	for k, user := range users {
+		options[k].Value = fmt.Sprintf("%v", user["id"])
+		options[k].Text = fmt.Sprintf("%v", user["name"])
+		names[options[k].Value] = options[k].Text
+	}
+	userName := func(value types.FieldModel) interface{} {
+		if name, ok := names[value.Value]; ok {
+			return name
+		}
+		return "-"
+	}
+
+	statusTypes := map[string]string{
+		models.ChangeRequestPending:  "warning",
+		models.ChangeRequestApproved: "success",
+		models.ChangeRequestRejected: "danger",
+	}
+	status := func(value types.FieldModel) interface{} {
+		return label().SetType(statusTypes[value.Value]).SetContent(tmpl.HTML(lg(value.Value))).GetContent()
+	}
+	kind := func(value types.FieldModel) interface{} {
+		return lg(value.Value)
+	}
+
+	info := changeTable.GetInfo().AddXssJsFilter().HideNewButton().HideDeleteButton().
+		SetSortField("id").SetSortDesc()
+
+	// the users see the changes they requested and the ones they can approve
+	if user, ok := ctxUser(ctx); ok && !user.IsSuperAdmin() {
+		raw, args := "(requester_id = ?", []interface{}{user.Id}
+		for _, per := range user.Permissions {
+			if user.CheckPermission(per.Slug) {
+				raw += " or permission = ?"
+				args = append(args, per.Slug)
+			}
+		}
+		info.WhereRaw(raw+")", args...)
+	}
+
+	info.AddField("ID", "id", db.Int).FieldSortable()
+	info.AddField(lg("table"), "prefix", db.Varchar).FieldFilterable()
+	info.AddField(lg("kind"), "kind", db.Varchar).FieldDisplay(kind).
+		FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(types.FieldOptions{
+			{Value: models.ChangeRequestInsert, Text: lg(models.ChangeRequestInsert)},
+			{Value: models.ChangeRequestUpdate, Text: lg(models.ChangeRequestUpdate)},
+			{Value: models.ChangeRequestDelete, Text: lg(models.ChangeRequestDelete)},
+		})
+	info.AddField(lg("record"), "record_id", db.Varchar)
+	info.AddField(lg("requester"), "requester_id", db.Int).FieldDisplay(userName)
+	info.AddField(lg("status"), "status", db.Varchar).FieldDisplay(status).
+		FieldFilterable(types.FilterType{FormType: form.SelectSingle}).
+		FieldFilterOptions(types.FieldOptions{
+			{Value: models.ChangeRequestPending, Text: lg(models.ChangeRequestPending)},
+			{Value: models.ChangeRequestApproved, Text: lg(models.ChangeRequestApproved)},
+			{Value: models.ChangeRequestRejected, Text: lg(models.ChangeRequestRejected)},
+		})
+	info.AddField(lg("approver"), "approver_id", db.Int).FieldDisplay(userName)
+	info.AddField(lg("comment"), "comment", db.Varchar)
+	info.AddField(lg("createdAt"), "created_at", db.Timestamp).FieldSortable()
+	info.AddField(lg("updatedAt"), "updated_at", db.Timestamp)
+
+	info.AddSelectBox(lg("requester"), options, action.FieldFilter("requester_id"))
+
+	info.SetTable("goadmin_change_requests").
+		SetTitle(lg("change requests")).
+		SetDescription(lg("change requests"))
+
+	detail := changeTable.GetDetail()
+
+	detail.AddField("ID", "id", db.Int)
+	detail.AddField(lg("table"), "prefix", db.Varchar)
+	detail.AddField(lg("kind"), "kind", db.Varchar).FieldDisplay(kind)
+	detail.AddField(lg("record"), "record_id", db.Varchar)
+	detail.AddField(lg("changes"), "diff", db.Text).FieldDisplay(func(value types.FieldModel) interface{} {
+		return changeDiffHTML(value.Value)
+	})
+	detail.AddField(lg("requester"), "requester_id", db.Int).FieldDisplay(userName)
+	detail.AddField(lg("status"), "status", db.Varchar).FieldDisplay(status)
+	detail.AddField(lg("approver"), "approver_id", db.Int).FieldDisplay(userName)
+	detail.AddField(lg("comment"), "comment", db.Varchar)
+	detail.AddField(lg("createdAt"), "created_at", db.Timestamp)
+	detail.AddField(lg("updatedAt"), "updated_at", db.Timestamp)
+
+	detail.SetTable("goadmin_change_requests").
+		SetTitle(lg("change requests")).
+		SetDescription(lg("change requests"))
+
+	formList := changeTable.GetForm().AddXssJsFilter()
+
+	formList.AddField("ID", "id", db.Int, form.Default).FieldNotAllowEdit().FieldNotAllowAdd()
+	formList.AddField(lg("table"), "prefix", db.Varchar, form.Default).FieldNotAllowEdit()
+	formList.AddField(lg("kind"), "kind", db.Varchar, form.Default).FieldNotAllowEdit().FieldDisplay(kind)
+	formList.AddField(lg("record"), "record_id", db.Varchar, form.Default).FieldNotAllowEdit()
+	formList.AddField(lg("changes"), "diff", db.Text, form.Default).FieldNotAllowEdit().
+		FieldDisplay(func(value types.FieldModel) interface{} {
+			return changeDiffHTML(value.Value)
+		})
+	formList.AddField(lg("requester"), "requester_id", db.Int, form.Default).FieldNotAllowEdit().
+		FieldDisplay(userName)
+	formList.AddField(lg("decision"), "status", db.Varchar, form.SelectSingle).
+		FieldOptions(types.FieldOptions{
+			{Value: models.ChangeRequestApproved, Text: lg("approve")},
+			{Value: models.ChangeRequestRejected, Text: lg("reject")},
+		}).FieldMust()
+	formList.AddField(lg("comment"), "comment", db.Varchar, form.TextArea)
+
+	formList.SetUpdateFn(func(values form2.Values) error {
+
+		user, ok := ctxUser(ctx)
+		if !ok {
+			return errors.New(errs.NoPermission)
+		}
+
+		cr := models.ChangeRequest().SetConn(s.conn).Find(values.Get("id"))
+		if cr.IsEmpty() {
+			return errors.New(errs.WrongID)
+		}
+		if !cr.IsPending() {
+			return models.ErrChangeRequestDecided
+		}
+
+		decision := values.Get("status")
+		if decision != models.ChangeRequestApproved && decision != models.ChangeRequestRejected {
+			return errors.New("choose to approve or reject the change")
+		}
+
+		return ReviewChange(ctx, s.conn, s.generators, cr, user, decision == models.ChangeRequestApproved,
+			values.Get("comment"))
+	})
+
+	formList.SetTable("goadmin_change_requests").
+		SetTitle(lg("change requests")).
+		SetDescription(lg("change requests"))
+
+	return
+}
+
 func (s *SystemTable) GetMenuTable(ctx *context.Context) (menuTable Table) {
 	menuTable = NewDefaultTable(DefaultConfigWithDriver(config.GetDatabases().GetDefault().Driver))
 
@@ -907,6 +1644,10 @@ func (s *SystemTable) GetMenuTable(ctx *context.Context) (menuTable Table) {
 				return nil, map[string]interface{}{}
 			})
 
+			if txErr == nil {
+				auth.InvalidateUserCache()
+			}
+
 			return txErr
 		})
 
@@ -1294,6 +2035,16 @@ func label() types.LabelAttribute {
 	return template.Get(config.GetTheme()).Label().SetType("success")
 }
 
+// ctxUser return the user of the request, which is unknown when the
+// generator is called outside of a request.
+func ctxUser(ctx *context.Context) (models.UserModel, bool) {
+	if ctx == nil {
+		return models.User(), false
+	}
+	user, ok := ctx.User().(models.UserModel)
+	return user, ok
+}
+
 func lg(v string) string {
 	return language.Get(v)
 }
@@ -1344,6 +2095,46 @@ func checkJSON(values form2.Values, key string) error {
 	return nil
 }
 
+// deniedPermissionIds return the ids of the permissions denied to the user
+// or the role of given id.
+func (s *SystemTable) deniedPermissionIds(field, id string) []string {
+	var permissions = make([]string, 0)
+
+	if id == "" {
+		return permissions
+	}
+	denyModel, _ := s.table("goadmin_permission_denies").
+		Select("permission_id").
+		Where(field, "=", id).
+		All()
+	for _, v := range denyModel {
+		permissions = append(permissions, strconv.FormatInt(v["permission_id"].(int64), 10))
+	}
+	return permissions
+}
+
+func permissionNames(permissions []models.PermissionModel) []string {
+	names := make([]string, len(permissions))
+	for i, permission := range permissions {
+		names[i] = permission.Name
+	}
+	return names
+}
+
+func labels(contents []string, typ string) tmpl.HTML {
+	var (
+		content = tmpl.HTML("")
+		tpl     = label().SetType(typ)
+	)
+	for key, c := range contents {
+		content += tpl.SetContent(template.HTML(tmpl.HTMLEscapeString(c))).GetContent()
+		if key != len(contents)-1 {
+			content += "<br><br>"
+		}
+	}
+	return content
+}
+
 func (s *SystemTable) table(table string) *db.SQL {
 	return s.connection().Table(table)
 }
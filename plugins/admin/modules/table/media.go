@@ -0,0 +1,228 @@
+package table
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/file"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+	"github.com/wowucco/go-admin/plugins/admin/modules"
+)
+
+// DefaultMediaCleanGrace is how long a new upload is kept before the cleanup
+// may delete it, the form which references it may not be saved yet.
+const DefaultMediaCleanGrace = 24 * time.Hour
+
+// MediaReferencer is implemented by the Table which can list the stored file
+// paths referenced by its file fields. The paths are keyed by the field name,
+// and every scanned field has a key even if nothing is referenced. A nil map
+// means the table can not be scanned.
+type MediaReferencer interface {
+	MediaReferences() (map[string][]string, error)
+}
+
+// MediaReferences implements the MediaReferencer.MediaReferences.
+func (tb DefaultTable) MediaReferences() (map[string][]string, error) {
+	if !tb.getDataFromDB() || tb.Form.Table == "" {
+		return nil, nil
+	}
+
+	columns, _ := tb.getColumns(tb.Form.Table)
+
+	fields := make([]string, 0)
+	for _, field := range tb.Form.FieldList {
+		if field.FormType.IsFile() && modules.InArray(columns, field.Field) && !modules.InArray(fields, field.Field) {
+			fields = append(fields, field.Field)
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	rows, err := tb.sql().Table(tb.Form.Table).Select(fields...).All()
+	if db.CheckError(err, db.QUERY) {
+		return nil, err
+	}
+
+	refs := make(map[string][]string, len(fields))
+	for _, name := range fields {
+		refs[name] = make([]string, 0)
+	}
+	for _, row := range rows {
+		for _, name := range fields {
+			delimiter := modules.SetDefault(tb.Form.FieldList.FindByFieldName(name).DefaultOptionDelimiter, ",")
+			refs[name] = append(refs[name], splitMediaPaths(row[name], delimiter)...)
+		}
+	}
+
+	return refs, nil
+}
+
+func splitMediaPaths(value interface{}, delimiter string) []string {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		s = fmt.Sprintf("%v", v)
+	}
+
+	paths := make([]string, 0)
+	for _, p := range strings.Split(s, delimiter) {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// MediaScan is the result of ScanMediaReferences.
+type MediaScan struct {
+	// Refs is the number of references of every stored file path.
+	Refs map[string]int64
+
+	// fields is the scanned file fields keyed by "<prefix>.<field>".
+	fields map[string]bool
+}
+
+// Scanned check if the file field of the table of the prefix was scanned. A
+// file uploaded for a field which was not scanned, such as the fields of the
+// tables with custom data or the rich text fields, may be referenced even if
+// no references were found.
+func (s MediaScan) Scanned(prefix, field string) bool {
+	return prefix != "" && field != "" && s.fields[prefix+"."+field]
+}
+
+// ScanMediaReferences count the references of every stored file path in the
+// tables of the generators. A table field shared by several generators is
+// counted once. A generator which panics is logged and skipped, its fields
+// are not scanned.
+func ScanMediaReferences(ctx *context.Context, list GeneratorList) (MediaScan, error) {
+	var (
+		scan = MediaScan{
+			Refs:   make(map[string]int64),
+			fields: make(map[string]bool),
+		}
+		counted = make(map[string]bool)
+	)
+
+	for prefix, gen := range list {
+		panel, ok := generatorPanel(ctx, prefix, gen)
+		if !ok {
+			continue
+		}
+		referencer, ok := panel.(MediaReferencer)
+		if !ok {
+			continue
+		}
+		fields, err := referencer.MediaReferences()
+		if err != nil {
+			return MediaScan{}, err
+		}
+		for field, paths := range fields {
+			scan.fields[prefix+"."+field] = true
+			key := panel.GetForm().Table + "." + field
+			if counted[key] {
+				continue
+			}
+			counted[key] = true
+			for _, p := range paths {
+				scan.Refs[p]++
+			}
+		}
+	}
+
+	return scan, nil
+}
+
+// generatorPanel return the table of the generator, or false if it panics.
+func generatorPanel(ctx *context.Context, prefix string, gen Generator) (panel Table, ok bool) {
+	defer func() {
+		if err := recover(); err != nil {
+			logger.Warn("scan media references: can not get the table of ", prefix, ": ", err)
+			panel, ok = nil, false
+		}
+	}()
+	return gen(ctx), true
+}
+
+// CleanMedia update the references of the recorded uploads and delete the
+// files which no record references and are older than the grace period,
+// together with their media records. Only the files uploaded for a scanned
+// field are deleted, the others can not be known to be orphans. It returns
+// the deleted paths, a file which can not be deleted is logged and kept, and
+// the first error returned.
+func CleanMedia(ctx *context.Context, conn db.Connection, list GeneratorList, grace time.Duration) ([]string, error) {
+
+	scan, err := ScanMediaReferences(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+
+	media := models.Media().SetConn(conn)
+
+	items, err := db.WithDriver(conn).Table(media.TableName).All()
+	if db.CheckError(err, db.QUERY) {
+		return nil, err
+	}
+
+	for _, item := range items {
+		m := media.MapToModel(item)
+		if err := m.UpdateRefs(scan.Refs[m.Path]); err != nil {
+			return nil, err
+		}
+	}
+
+	orphans, err := db.WithDriver(conn).Table(media.TableName).
+		Where("refs", "=", 0).
+		Where("created_at", "<", time.Now().Add(-grace).Format("2006-01-02 15:04:05")).
+		All()
+	if db.CheckError(err, db.QUERY) {
+		return nil, err
+	}
+
+	var (
+		deleted  = make([]string, 0)
+		firstErr error
+	)
+
+	for _, item := range orphans {
+		m := media.MapToModel(item)
+		if !scan.Scanned(m.Prefix, m.Field) {
+			continue
+		}
+		if err := DeleteMediaFile(m); err != nil {
+			logger.Error("clean media error: ", m.Path, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		deleted = append(deleted, m.Path)
+	}
+
+	return deleted, firstErr
+}
+
+// DeleteMediaFile delete the stored file of the media and its record.
+func DeleteMediaFile(m models.MediaModel) error {
+	engine := modules.SetDefault(m.Engine, config.GetFileUploadEngine().Name)
+	if err := file.Delete(engine, m.Path); err != nil {
+		return err
+	}
+	err := m.Delete()
+	if db.CheckError(err, db.DELETE) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package table
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/wowucco/go-admin/context"
+)
+
+func TestSplitMediaPaths(t *testing.T) {
+	assert.Equal(t, splitMediaPaths(nil, ","), []string(nil))
+	assert.Equal(t, splitMediaPaths("", ","), []string{})
+	assert.Equal(t, splitMediaPaths("a.png", ","), []string{"a.png"})
+	assert.Equal(t, splitMediaPaths("a.png, b.pdf,", ","), []string{"a.png", "b.pdf"})
+	assert.Equal(t, splitMediaPaths([]byte("a.png|b.pdf"), "|"), []string{"a.png", "b.pdf"})
+}
+
+type stubMediaTable struct {
+	DefaultTable
+	refs map[string][]string
+}
+
+func (s stubMediaTable) MediaReferences() (map[string][]string, error) {
+	return s.refs, nil
+}
+
+func TestScanMediaReferences(t *testing.T) {
+	ctx := context.NewContext(&http.Request{URL: &url.URL{}, Header: make(http.Header)})
+
+	posts := NewDefaultTable(DefaultConfig()).(DefaultTable)
+	posts.Form.SetTable("posts")
+
+	list := GeneratorList{
+		"posts": func(ctx *context.Context) Table {
+			return stubMediaTable{DefaultTable: posts, refs: map[string][]string{
+				"cover":      {"a.png", "b.png"},
+				"attachment": {},
+			}}
+		},
+		// the same table of another prefix is counted once
+		"drafts": func(ctx *context.Context) Table {
+			return stubMediaTable{DefaultTable: posts, refs: map[string][]string{
+				"cover": {"a.png", "b.png"},
+			}}
+		},
+		"custom": func(ctx *context.Context) Table {
+			return stubMediaTable{DefaultTable: NewDefaultTable(DefaultConfig()).(DefaultTable)}
+		},
+		"broken": func(ctx *context.Context) Table {
+			panic("no table")
+		},
+	}
+
+	scan, err := ScanMediaReferences(ctx, list)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, scan.Refs["a.png"], int64(1))
+	assert.Equal(t, scan.Refs["b.png"], int64(1))
+
+	assert.Equal(t, scan.Scanned("posts", "cover"), true)
+	assert.Equal(t, scan.Scanned("posts", "attachment"), true)
+	assert.Equal(t, scan.Scanned("drafts", "cover"), true)
+	assert.Equal(t, scan.Scanned("drafts", "attachment"), false)
+	assert.Equal(t, scan.Scanned("custom", "cover"), false)
+	assert.Equal(t, scan.Scanned("broken", "cover"), false)
+	assert.Equal(t, scan.Scanned("", ""), false)
+}
@@ -0,0 +1,311 @@
+package table
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/auth"
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/db/dialect"
+	"github.com/wowucco/go-admin/modules/logger"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+	"github.com/wowucco/go-admin/plugins/admin/modules"
+	"github.com/wowucco/go-admin/template/icon"
+)
+
+// SyncConfig is the config of the sync of the generators with the
+// permissions and the menus.
+type SyncConfig struct {
+	// MenuParent is the title of the top menu which the menus of the
+	// generators are put under, it is created when not found. The menus of
+	// the generators are top menus when it is empty.
+	MenuParent string
+	// MenuIcon is the icon of the menus, it is icon.Table by default.
+	MenuIcon string
+	// Exclude are the prefixes of the generators which are not synced.
+	Exclude []string
+}
+
+// SyncReport lists the permissions and the menus found by a sync.
+type SyncReport struct {
+	Created []string
+	Updated []string
+	// Drifted are the records which differ from the generators and have
+	// been edited by hand or created by others, they are kept as they are.
+	Drifted []string
+	// Failed are the records which could not be synced, the others are
+	// synced anyway.
+	Failed []SyncError
+}
+
+// SyncError is the error of the sync of a permission or a menu.
+type SyncError struct {
+	Item string
+	Err  error
+}
+
+func (e SyncError) Error() string {
+	return e.Item + ": " + e.Err.Error()
+}
+
+// HasChanges check if the sync created or updated any record.
+func (r SyncReport) HasChanges() bool {
+	return len(r.Created) > 0 || len(r.Updated) > 0
+}
+
+// syncNameLength is the length of the names and the slugs of the permissions
+// and the titles of the menus.
+const syncNameLength = 50
+
+// syncedPermissions are the permissions created for every generator, the
+// same as the ones of the adm add permission command.
+var syncedPermissions = []struct {
+	slug   string
+	name   string
+	method string
+	path   string
+}{
+	{"query", "Query", "GET", "/info/%s"},
+	{"show_detail", "Show Detail Page", "GET", "/info/%s/detail"},
+	{"show_edit", "Show Edit Form Page", "GET", "/info/%s/edit"},
+	{"show_create", "Show Create Form Page", "GET", "/info/%s/new"},
+	{"edit", "Edit", "POST", "/edit/%s"},
+	{"create", "Create", "POST", "/new/%s"},
+	{"delete", "Delete", "POST", "/delete/%s"},
+	{"export", "Export", "POST", "/export/%s"},
+}
+
+// syncItem is a permission or a menu which a generator should have. The
+// checksum of its fields tells if the stored record was written by the sync.
+type syncItem struct {
+	kind   string
+	label  string
+	table  string
+	where  [][2]interface{}
+	fields []string
+	values dialect.H
+	// insert are the values only set when the record is created.
+	insert func() dialect.H
+}
+
+type generatorSyncer struct {
+	conn   db.Connection
+	report SyncReport
+}
+
+// SyncGenerators create the standard permissions and a menu of every
+// generator. A record is updated only when it is as the last sync left it,
+// a record edited by hand is reported as drifted and never touched. The
+// generators are called with the given context to get their titles.
+func SyncGenerators(ctx *context.Context, conn db.Connection, list GeneratorList, cfg SyncConfig) SyncReport {
+
+	s := &generatorSyncer{conn: conn}
+
+	menuIcon := modules.SetDefault(cfg.MenuIcon, icon.Table)
+
+	prefixes := make([]string, 0, len(list))
+	for prefix := range list {
+		if !modules.InArray(cfg.Exclude, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	sort.Strings(prefixes)
+
+	var (
+		parentId int64
+		menus    = true
+	)
+	if cfg.MenuParent != "" {
+		title := fitSyncName(cfg.MenuParent)
+		// the menus are not moved to the top when their parent fails
+		parentId, menus = s.sync(syncItem{
+			kind:   models.SyncRecordMenu,
+			label:  "menu " + title,
+			table:  "goadmin_menu",
+			where:  [][2]interface{}{{"title", title}, {"parent_id", 0}},
+			fields: []string{"title", "parent_id", "uri"},
+			values: dialect.H{"title": title, "parent_id": int64(0), "uri": ""},
+			insert: s.menuInsert(menuIcon),
+		})
+	}
+
+	for _, prefix := range prefixes {
+		// the names are unique, as the prefixes are, while the titles of
+		// the generators may be the same
+		for _, p := range syncedPermissions {
+			slug := fitSyncName(prefix + "_" + p.slug)
+			s.sync(syncItem{
+				kind:   models.SyncRecordPermission,
+				label:  "permission " + slug,
+				table:  "goadmin_permissions",
+				where:  [][2]interface{}{{"slug", slug}},
+				fields: []string{"name", "slug", "http_method", "http_path"},
+				values: dialect.H{
+					"name":        fitSyncName(prefix + " " + p.name),
+					"slug":        slug,
+					"http_method": p.method,
+					"http_path":   fmt.Sprintf(p.path, prefix),
+				},
+			})
+		}
+
+		if !menus {
+			continue
+		}
+
+		uri := "/info/" + prefix
+		s.sync(syncItem{
+			kind:   models.SyncRecordMenu,
+			label:  "menu " + uri,
+			table:  "goadmin_menu",
+			where:  [][2]interface{}{{"uri", uri}},
+			fields: []string{"title", "parent_id", "uri", "icon"},
+			values: dialect.H{
+				"title":     fitSyncName(generatorTitle(ctx, prefix, list[prefix])),
+				"parent_id": parentId,
+				"uri":       uri,
+				"icon":      menuIcon,
+			},
+			insert: s.menuInsert(""),
+		})
+	}
+
+	if s.report.HasChanges() {
+		auth.InvalidateUserCache()
+	}
+
+	return s.report
+}
+
+// fitSyncName cut the name to the length of the columns. A cut name ends
+// with a hash of the whole name, so the cut names stay unique.
+func fitSyncName(name string) string {
+	runes := []rune(name)
+	if len(runes) <= syncNameLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	return string(runes[:syncNameLength-9]) + "~" + hex.EncodeToString(sum[:4])
+}
+
+// sync create or update the record of the item, it returns the id of it
+// and false if it failed, the error is added to the report.
+func (s *generatorSyncer) sync(item syncItem) (int64, bool) {
+	id, err := s.syncItem(item)
+	if err != nil {
+		s.report.Failed = append(s.report.Failed, SyncError{Item: item.label, Err: err})
+		return 0, false
+	}
+	return id, true
+}
+
+func (s *generatorSyncer) syncItem(item syncItem) (int64, error) {
+
+	query := db.WithDriver(s.conn).Table(item.table)
+	for _, w := range item.where {
+		query = query.Where(w[0].(string), "=", w[1])
+	}
+	row, err := query.OrderBy("id", "asc").First()
+	if db.CheckError(err, db.QUERY) {
+		return 0, err
+	}
+
+	want := syncChecksum(item.values, item.fields)
+
+	if row == nil {
+		values := dialect.H{}
+		if item.insert != nil {
+			values = item.insert()
+		}
+		for k, v := range item.values {
+			values[k] = v
+		}
+		id, err := db.WithDriver(s.conn).Table(item.table).Insert(values)
+		if db.CheckError(err, db.INSERT) {
+			return 0, err
+		}
+		if _, err := models.SyncRecord().SetConn(s.conn).Find(item.kind, id).Save(want); err != nil {
+			return 0, err
+		}
+		s.report.Created = append(s.report.Created, item.label)
+		return id, nil
+	}
+
+	id, _ := row["id"].(int64)
+	record := models.SyncRecord().SetConn(s.conn).Find(item.kind, id)
+	current := syncChecksum(row, item.fields)
+
+	switch {
+	case current == want:
+		if record.Checksum != want {
+			if _, err := record.Save(want); err != nil {
+				return 0, err
+			}
+		}
+	case !record.IsEmpty() && record.Checksum == current:
+		values := dialect.H{"updated_at": time.Now().Format("2006-01-02 15:04:05")}
+		for k, v := range item.values {
+			values[k] = v
+		}
+		_, err := db.WithDriver(s.conn).Table(item.table).Where("id", "=", id).Update(values)
+		if db.CheckError(err, db.UPDATE) {
+			return 0, err
+		}
+		if _, err := record.Save(want); err != nil {
+			return 0, err
+		}
+		s.report.Updated = append(s.report.Updated, item.label)
+	default:
+		s.report.Drifted = append(s.report.Drifted, item.label)
+	}
+
+	return id, nil
+}
+
+// menuInsert return the values of a new menu, which is put at the end.
+func (s *generatorSyncer) menuInsert(menuIcon string) func() dialect.H {
+	return func() dialect.H {
+		var order int64
+		last, _ := db.WithDriver(s.conn).Table("goadmin_menu").OrderBy("order", "desc").First()
+		if last != nil {
+			order, _ = last["order"].(int64)
+		}
+		values := dialect.H{"order": order + 1, "header": ""}
+		if menuIcon != "" {
+			values["icon"] = menuIcon
+		}
+		return values
+	}
+}
+
+func syncChecksum(values map[string]interface{}, fields []string) string {
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		switch v := values[field].(type) {
+		case nil:
+		case []byte:
+			parts[i] = string(v)
+		default:
+			parts[i] = fmt.Sprintf("%v", v)
+		}
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// generatorTitle return the title of the info panel of the generator, or the
+// prefix if it has none.
+func generatorTitle(ctx *context.Context, prefix string, gen Generator) (title string) {
+	defer func() {
+		if err := recover(); err != nil {
+			logger.Warn("sync generators: can not get the title of ", prefix, ": ", err)
+			title = prefix
+		}
+	}()
+	return modules.SetDefault(gen(ctx).GetInfo().Title, prefix)
+}
@@ -0,0 +1,119 @@
+package table
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wowucco/go-admin/context"
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/db"
+	"github.com/wowucco/go-admin/modules/db/dialect"
+	_ "github.com/wowucco/go-admin/modules/db/drivers/sqlite"
+	"github.com/wowucco/go-admin/plugins/admin/models"
+)
+
+func TestSyncChecksum(t *testing.T) {
+	fields := []string{"title", "parent_id", "uri"}
+
+	stored := map[string]interface{}{"id": int64(3), "title": []byte("Users"), "parent_id": int64(0), "uri": "/info/users"}
+	want := map[string]interface{}{"title": "Users", "parent_id": int64(0), "uri": "/info/users"}
+	assert.Equal(t, syncChecksum(want, fields), syncChecksum(stored, fields))
+
+	stored["parent_id"] = int64(1)
+	assert.NotEqual(t, syncChecksum(want, fields), syncChecksum(stored, fields))
+
+	// the fields are separated, so that the values can not shift
+	assert.NotEqual(t,
+		syncChecksum(map[string]interface{}{"title": "ab", "uri": ""}, []string{"title", "uri"}),
+		syncChecksum(map[string]interface{}{"title": "a", "uri": "b"}, []string{"title", "uri"}))
+}
+
+func TestGeneratorTitle(t *testing.T) {
+	panics := func(ctx *context.Context) Table {
+		panic("request required")
+	}
+	assert.Equal(t, "users", generatorTitle(nil, "users", panics))
+}
+
+func TestFitSyncName(t *testing.T) {
+	assert.Equal(t, "users_query", fitSyncName("users_query"))
+
+	long := strings.Repeat("a", 45) + "_show_detail"
+	fit := fitSyncName(long)
+	assert.Len(t, []rune(fit), syncNameLength)
+	assert.NotEqual(t, fit, fitSyncName(strings.Repeat("a", 45)+"_show_edit"))
+	assert.Equal(t, fit, fitSyncName(long))
+
+	assert.Len(t, []rune(fitSyncName(strings.Repeat("用户", 40))), syncNameLength)
+}
+
+func TestGeneratorSyncerSync(t *testing.T) {
+	data, err := ioutil.ReadFile("../../../../data/admin.db")
+	assert.NoError(t, err)
+	dir, err := ioutil.TempDir("", "goadmin-sync")
+	assert.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	file := filepath.Join(dir, "admin.db")
+	assert.NoError(t, ioutil.WriteFile(file, data, 0644))
+
+	conn := db.GetConnectionByDriver(db.DriverSqlite).InitDB(config.DatabaseList{
+		"default": {Driver: db.DriverSqlite, File: file},
+	})
+
+	item := func(name string) syncItem {
+		return syncItem{
+			kind:   models.SyncRecordPermission,
+			label:  "permission posts_query",
+			table:  "goadmin_permissions",
+			where:  [][2]interface{}{{"slug", "posts_query"}},
+			fields: []string{"name", "slug", "http_method", "http_path"},
+			values: dialect.H{"name": name, "slug": "posts_query", "http_method": "GET", "http_path": "/info/posts"},
+		}
+	}
+	stored := func(id int64) string {
+		row, _ := db.WithDriver(conn).Table("goadmin_permissions").Where("id", "=", id).First()
+		return row["name"].(string)
+	}
+
+	s := &generatorSyncer{conn: conn}
+
+	// a missing record is created
+	id, ok := s.sync(item("posts Query"))
+	assert.True(t, ok)
+	assert.Equal(t, []string{"permission posts_query"}, s.report.Created)
+
+	// a record as the sync left it is updated
+	id2, ok := s.sync(item("posts List"))
+	assert.True(t, ok)
+	assert.Equal(t, id, id2)
+	assert.Equal(t, []string{"permission posts_query"}, s.report.Updated)
+	assert.Equal(t, "posts List", stored(id))
+
+	// a record edited by hand is reported and kept
+	_, err = db.WithDriver(conn).Table("goadmin_permissions").Where("id", "=", id).
+		Update(dialect.H{"name": "Posts by hand"})
+	assert.NoError(t, err)
+	_, ok = s.sync(item("posts Query"))
+	assert.True(t, ok)
+	assert.Equal(t, []string{"permission posts_query"}, s.report.Drifted)
+	assert.Equal(t, "Posts by hand", stored(id))
+
+	// a failed item is reported and the sync goes on, the names are unique
+	// in the other databases
+	_, err = conn.Exec("CREATE UNIQUE INDEX permissions_name_unique ON goadmin_permissions (name)")
+	assert.NoError(t, err)
+	failing := item("Posts by hand")
+	failing.label = "permission pages_query"
+	failing.where = [][2]interface{}{{"slug", "pages_query"}}
+	failing.values["slug"] = "pages_query"
+	_, ok = s.sync(failing)
+	assert.False(t, ok)
+	assert.Len(t, s.report.Failed, 1)
+	assert.Equal(t, "permission pages_query", s.report.Failed[0].Item)
+}
@@ -17,6 +17,11 @@ func (admin *Admin) initRouter() *Admin {
 	// auth
 	route.GET(config.GetLoginUrl(), admin.handler.ShowLogin)
 	route.POST("/signin", admin.handler.Auth)
+	route.POST("/signin/2fa", admin.handler.AuthTwoFactor)
+	route.POST("/password/forgot", admin.handler.ForgotPassword)
+	route.POST("/password/reset", admin.handler.ResetPassword)
+	route.GET("/oidc/:__provider/login", admin.handler.OIDCLogin)
+	route.GET("/oidc/:__provider/callback", admin.handler.OIDCCallback)
 
 	// auto install
 	route.GET("/install", admin.handler.ShowInstall)
@@ -35,6 +40,20 @@ func (admin *Admin) initRouter() *Admin {
 	// auth
 	authRoute.GET("/logout", admin.handler.Logout)
 
+	// two-factor authentication of the login user
+	authRoute.GET("/2fa", admin.handler.ShowTwoFactor).Name("two_factor")
+	authRoute.POST("/2fa/enable", admin.handler.EnableTwoFactor).Name("two_factor_enable")
+	authRoute.POST("/2fa/recovery_codes", admin.handler.RegenerateRecoveryCodes).Name("two_factor_recovery_codes")
+	authRoute.POST("/2fa/disable", admin.handler.DisableTwoFactor).Name("two_factor_disable")
+
+	// active sessions of the login user
+	authRoute.GET("/sessions", admin.handler.ShowUserSessions).Name("user_sessions")
+	authRoute.POST("/sessions/revoke", admin.handler.RevokeUserSession).Name("user_session_revoke")
+	authRoute.POST("/sessions/revoke_others", admin.handler.RevokeOtherUserSessions).Name("user_session_revoke_others")
+
+	// the impersonation is started by an action of the managers
+	authRoute.POST("/impersonate/stop", admin.handler.StopImpersonate).Name("impersonate_stop")
+
 	authPrefixRoute := route.Group("/", auth.Middleware(admin.Conn), admin.guardian.CheckPrefix)
 
 	// menus
@@ -57,6 +76,16 @@ func (admin *Admin) initRouter() *Admin {
 	authPrefixRoute.GET("/info/:__prefix", admin.handler.ShowInfo).Name("info")
 
 	authPrefixRoute.POST("/update/:__prefix", admin.guardian.Update, admin.handler.Update).Name("update")
+	authPrefixRoute.POST("/order/:__prefix", admin.handler.NestedSetOrder).Name("nestedset_order")
+
+	// resumable chunked uploads
+	authPrefixRoute.POST("/upload/chunk/:__prefix/init", admin.handler.ChunkUploadInit).Name("chunk_upload_init")
+	authPrefixRoute.GET("/upload/chunk/:__prefix/status", admin.handler.ChunkUploadStatus).Name("chunk_upload_status")
+	authPrefixRoute.POST("/upload/chunk/:__prefix/complete", admin.handler.ChunkUploadComplete).Name("chunk_upload_complete")
+	authPrefixRoute.POST("/upload/chunk/:__prefix", admin.handler.ChunkUpload).Name("chunk_upload")
+
+	// media library picker of the file fields
+	authRoute.GET("/media/library", admin.handler.MediaLibrary).Name("media_library")
 
 	authRoute.GET("/application/info", admin.handler.SystemInfo)
 
@@ -64,18 +93,21 @@ func (admin *Admin) initRouter() *Admin {
 
 	if config.GetOpenAdminApi() {
 
+		// personal api tokens of the login user
+		authRoute.GET("/tokens", admin.handler.ShowAPITokens).Name("api_tokens")
+		authRoute.POST("/tokens/new", admin.handler.NewAPIToken).Name("api_token_new")
+		authRoute.POST("/tokens/revoke", admin.handler.RevokeAPIToken).Name("api_token_revoke")
+
 		// crud json apis
-		apiRoute := route.Group("/api", auth.Middleware(admin.Conn), admin.guardian.CheckPrefix)
+		apiRoute := route.Group("/api", auth.APIMiddleware(admin.Conn), admin.guardian.CheckPrefix)
 		apiRoute.GET("/list/:__prefix", admin.handler.ApiList).Name("api_info")
 		apiRoute.GET("/detail/:__prefix", admin.handler.ApiDetail).Name("api_detail")
 		apiRoute.POST("/delete/:__prefix", admin.guardian.Delete, admin.handler.Delete).Name("api_delete")
-		apiRoute.POST("/update/:__prefix", admin.guardian.EditForm, admin.handler.ApiUpdate).Name("api_edit")
 		apiRoute.GET("/update/form/:__prefix", admin.guardian.ShowForm, admin.handler.ApiUpdateForm).Name("api_show_edit")
 		apiRoute.POST("/create/:__prefix", admin.guardian.NewForm, admin.handler.ApiCreate).Name("api_new")
 		apiRoute.GET("/create/form/:__prefix", admin.guardian.ShowNewForm, admin.handler.ApiCreateForm).Name("api_show_new")
-		apiRoute.POST("/export/:__prefix", admin.guardian.Export, admin.handler.Export).Name("api_export")
-
 		apiRoute.POST("/update/:__prefix", admin.guardian.Update, admin.handler.Update).Name("api_update")
+		apiRoute.POST("/export/:__prefix", admin.guardian.Export, admin.handler.Export).Name("api_export")
 	}
 
 	admin.App = app
@@ -48,6 +48,66 @@ var List = map[string]string{"login/theme1": `{{define "login_theme1"}}
                     <div class="form-group">
                         <button class="btn btn-primary" onclick="submitData()">{{lang "login"}}</button>
                     </div>
+                    {{if .PasswordReset}}
+                        <div class="form-group">
+                            <a href="javascript:void(0)" onclick="showForm('#forgot-form')">{{lang "forgot password?"}}</a>
+                        </div>
+                    {{end}}
+                    {{if .SSOError}}
+                        <p class="text-danger">{{lang "single sign-on fail"}}</p>
+                    {{end}}
+                    {{range .Providers}}
+                        <div class="form-group">
+                            <a class="btn btn-default btn-block" href="{{.URL}}">{{lang "sign in with"}} {{.Title}}</a>
+                        </div>
+                    {{end}}
+                </form>
+                <form action="##" onsubmit="return false" method="post" id="two-factor-form" class="fh5co-form"
+                      style="display: none;">
+                    <h2>{{.Title}}</h2>
+                    <div class="form-group">
+                        <label for="code" class="sr-only">Code</label>
+                        <input type="text" class="form-control" id="code" placeholder="{{lang "two-factor code or recovery code"}}"
+                               autocomplete="one-time-code">
+                    </div>
+                    <div class="form-group">
+                        <button class="btn btn-primary" onclick="submitCode()">{{lang "login"}}</button>
+                    </div>
+                </form>
+                {{if .PasswordReset}}
+                    <form action="##" onsubmit="return false" method="post" id="forgot-form" class="fh5co-form"
+                          style="display: none;">
+                        <h2>{{lang "reset password"}}</h2>
+                        <div class="form-group">
+                            <label for="account" class="sr-only">Account</label>
+                            <input type="text" class="form-control" id="account" placeholder="{{lang "username or email"}}"
+                                   autocomplete="off">
+                        </div>
+                        <div class="form-group">
+                            <button class="btn btn-primary" onclick="submitForgot()">{{lang "send reset link"}}</button>
+                        </div>
+                        <div class="form-group">
+                            <a href="javascript:void(0)" onclick="showForm('#sign-up-form')">{{lang "back to login"}}</a>
+                        </div>
+                    </form>
+                {{end}}
+                <form action="##" onsubmit="return false" method="post" id="reset-form" class="fh5co-form"
+                      style="display: none;">
+                    <h2>{{lang "reset password"}}</h2>
+                    <p class="text-warning" id="password-expired" style="display: none;">{{lang "your password has expired, please set a new one"}}</p>
+                    <div class="form-group">
+                        <label for="new-password" class="sr-only">Password</label>
+                        <input type="password" class="form-control" id="new-password" placeholder="{{lang "password"}}"
+                               autocomplete="new-password">
+                    </div>
+                    <div class="form-group">
+                        <label for="new-password-again" class="sr-only">Confirm password</label>
+                        <input type="password" class="form-control" id="new-password-again"
+                               placeholder="{{lang "confirm password"}}" autocomplete="new-password">
+                    </div>
+                    <div class="form-group">
+                        <button class="btn btn-primary" onclick="submitReset()">{{lang "reset password"}}</button>
+                    </div>
                 </form>
             </div>
         </div>
@@ -67,6 +127,32 @@ var List = map[string]string{"login/theme1": `{{define "login_theme1"}}
     <script src="{{link .CdnUrl .UrlPrefix "/assets/login/dist/all.min.js"}}"></script>
 
     <script>
+        let twoFactorToken = '';
+        let resetToken = '';
+
+        function showForm(id) {
+            $(".fh5co-form").hide();
+            $(id).show();
+            $(id).find("input:first").focus();
+        }
+
+        // the single sign-on of a user with the two-factor authentication
+        // comes back with the token of the challenge
+        if (location.hash.indexOf('#two_factor=') === 0) {
+            twoFactorToken = location.hash.substr('#two_factor='.length);
+            history.replaceState(null, '', location.pathname + location.search);
+            $("#sign-up-form").hide();
+            $("#two-factor-form").show();
+            $("#code").focus();
+        }
+
+        // the link of the password reset email
+        if (location.hash.indexOf('#reset=') === 0) {
+            resetToken = location.hash.substr('#reset='.length);
+            history.replaceState(null, '', location.pathname + location.search);
+            showForm("#reset-form");
+        }
+
         function submitData() {
             $.ajax({
                 dataType: 'json',
@@ -78,13 +164,98 @@ var List = map[string]string{"login/theme1": `{{define "login_theme1"}}
                     'password': $("#password").val()
                 },
                 success: function (data) {
+                    if (data.data.password_expired) {
+                        resetToken = data.data.token;
+                        $("#password-expired").show();
+                        showForm("#reset-form");
+                        return
+                    }
+                    if (data.data.two_factor) {
+                        twoFactorToken = data.data.token;
+                        $("#sign-up-form").hide();
+                        $("#two-factor-form").show();
+                        $("#code").focus();
+                        return
+                    }
                     location.href = data.data.url
                 },
                 error: function (data) {
+                    if (data.status === 429 && data.responseJSON) {
+                        alert(data.responseJSON.msg);
+                        return
+                    }
                     alert('{{lang "login fail"}}');
                 }
             });
         }
+
+        function submitCode() {
+            $.ajax({
+                dataType: 'json',
+                type: 'POST',
+                url: '{{.UrlPrefix}}/signin/2fa',
+                async: 'true',
+                data: {
+                    'token': twoFactorToken,
+                    'code': $("#code").val()
+                },
+                success: function (data) {
+                    if (data.data.password_expired) {
+                        resetToken = data.data.token;
+                        $("#two-factor-form").hide();
+                        $("#password-expired").show();
+                        showForm("#reset-form");
+                        return
+                    }
+                    location.href = data.data.url
+                },
+                error: function (data) {
+                    alert('{{lang "wrong two-factor code"}}');
+                }
+            });
+        }
+
+        function submitForgot() {
+            $.ajax({
+                dataType: 'json',
+                type: 'POST',
+                url: '{{.UrlPrefix}}/password/forgot',
+                async: 'true',
+                data: {
+                    'account': $("#account").val()
+                },
+                success: function (data) {
+                    alert(data.msg);
+                    showForm("#sign-up-form");
+                },
+                error: function (data) {
+                    alert(data.responseJSON ? data.responseJSON.msg : '{{lang "request fail"}}');
+                }
+            });
+        }
+
+        function submitReset() {
+            $.ajax({
+                dataType: 'json',
+                type: 'POST',
+                url: '{{.UrlPrefix}}/password/reset',
+                async: 'true',
+                data: {
+                    'token': resetToken,
+                    'password': $("#new-password").val(),
+                    'password_again': $("#new-password-again").val()
+                },
+                success: function (data) {
+                    alert(data.msg);
+                    resetToken = '';
+                    $("#password").val('');
+                    showForm("#sign-up-form");
+                },
+                error: function (data) {
+                    alert(data.responseJSON ? data.responseJSON.msg : '{{lang "request fail"}}');
+                }
+            });
+        }
     </script>
 
     </body>
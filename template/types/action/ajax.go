@@ -90,7 +90,9 @@ func (ajax *AjaxAction) Js() template.JS {
                             url: "` + ajax.Url + `",
                             data: data,
                             success: function (data) { 
-                                if (data.code === 0) {
+                                if (data.code === 0 && data.data && data.data.url) {
+                                    location.href = data.data.url;
+                                } else if (data.code === 0) {
                                     swal(data.msg, '', 'success');
                                 } else {
                                     swal(data.msg, '', 'error');
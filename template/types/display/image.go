@@ -1,6 +1,7 @@
 package display
 
 import (
+	"github.com/wowucco/go-admin/modules/file"
 	"github.com/wowucco/go-admin/template"
 	"github.com/wowucco/go-admin/template/types"
 )
@@ -17,14 +18,17 @@ func (image *Image) Get(args ...interface{}) types.FieldFilterFn {
 
 	param := args[2].([]string)
 	return func(value types.FieldModel) interface{} {
+		// use the thumbnail generated by the image pipeline, if there is one
+		src := file.VariantPath(value.Value, file.ThumbnailVariant)
+		// the prefix given to the field overrides the links of the file
+		// upload engine
 		if len(param) > 0 {
-			return template.Default().Image().SetWidth(args[0].(string)).SetHeight(args[1].(string)).
-				SetSrc(template.HTML(param[0] + value.Value)).GetContent()
-
+			src = param[0] + src
 		} else {
-			return template.Default().Image().SetWidth(args[0].(string)).SetHeight(args[1].(string)).
-				SetSrc(template.HTML(value.Value)).GetContent()
+			src = file.URL(src)
 		}
+		return template.Default().Image().SetWidth(args[0].(string)).SetHeight(args[1].(string)).
+			SetSrc(template.HTML(src)).GetContent()
 	}
 
 }
@@ -3,6 +3,7 @@ package types
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/wowucco/go-admin/context"
 	"github.com/wowucco/go-admin/modules/config"
@@ -16,6 +17,7 @@ import (
 	form2 "github.com/wowucco/go-admin/template/types/form"
 	"html"
 	"html/template"
+	"mime/multipart"
 	"net/http"
 	"strconv"
 	"strings"
@@ -143,6 +145,8 @@ type FormField struct {
 
 	FieldDisplay `json:"-"`
 	PostFilterFn PostFieldFilterFn `json:"-"`
+
+	UploadRule file.Rule `json:"-"`
 }
 
 func (f FormField) UpdateValue(id, val string, res map[string]interface{}, sqls ...*db.SQL) FormField {
@@ -540,7 +544,7 @@ func (f *FormPanel) FieldEnableFileUpload(data ...interface{}) *FormPanel {
 
 			var imgPath = make([]string, len(ctx.Request.MultipartForm.Value["file"]))
 			for i, path := range ctx.Request.MultipartForm.Value["file"] {
-				imgPath[i] = config.GetStore().URL(path)
+				imgPath[i] = file.URL(path)
 			}
 
 			ctx.JSON(http.StatusOK, map[string]interface{}{
@@ -560,6 +564,270 @@ func (f *FormPanel) FieldEnableFileUpload(data ...interface{}) *FormPanel {
 	return f
 }
 
+// FieldUploadRule set the constraints of the files uploaded to the field.
+func (f *FormPanel) FieldUploadRule(rule file.Rule) *FormPanel {
+	f.FieldList[f.curFieldListIndex].UploadRule = rule
+	return f
+}
+
+// FieldMaxFileSize limit the size in bytes of each uploaded file.
+func (f *FormPanel) FieldMaxFileSize(size int64) *FormPanel {
+	f.FieldList[f.curFieldListIndex].UploadRule.MaxSize = size
+	return f.FieldOptionExt(map[string]interface{}{"maxFileSize": size / 1024})
+}
+
+// FieldAllowedExtensions limit the extensions of the uploaded files, like "jpg" or "pdf".
+func (f *FormPanel) FieldAllowedExtensions(ext ...string) *FormPanel {
+	f.FieldList[f.curFieldListIndex].UploadRule.Extensions = ext
+	exts := make([]string, len(ext))
+	for k, e := range ext {
+		exts[k] = strings.TrimPrefix(e, ".")
+	}
+	return f.FieldOptionExt(map[string]interface{}{"allowedFileExtensions": exts})
+}
+
+// FieldAllowedMimeTypes limit the mime types sniffed from the content of the uploaded
+// files, like "application/pdf" or "image/*".
+func (f *FormPanel) FieldAllowedMimeTypes(mimeTypes ...string) *FormPanel {
+	f.FieldList[f.curFieldListIndex].UploadRule.MimeTypes = mimeTypes
+	return f
+}
+
+// FieldImageSize limit the dimensions of the uploaded images, zero means no limit.
+func (f *FormPanel) FieldImageSize(minWidth, minHeight, maxWidth, maxHeight int) *FormPanel {
+	rule := &f.FieldList[f.curFieldListIndex].UploadRule
+	rule.MinWidth, rule.MinHeight, rule.MaxWidth, rule.MaxHeight = minWidth, minHeight, maxWidth, maxHeight
+	return f
+}
+
+// FieldMaxFileCount limit the number of files uploaded to a Multifile field.
+func (f *FormPanel) FieldMaxFileCount(count int) *FormPanel {
+	f.FieldList[f.curFieldListIndex].UploadRule.MaxCount = count
+	return f.FieldOptionExt(map[string]interface{}{"maxFileCount": count})
+}
+
+// FieldMediaLibrary add a button to the file field to pick files from the
+// media library, so a file uploaded before can be reused without uploading
+// it again.
+func (f *FormPanel) FieldMediaLibrary() *FormPanel {
+	field := f.FieldList[f.curFieldListIndex]
+	id := "media-picker-" + utils.Uuid(10)
+	f.FieldList[f.curFieldListIndex].HelpMsg += template.HTML(fmt.Sprintf(`<div id="%s" class="media-picker">
+	<a href="javascript:;" class="btn btn-sm btn-default media-picker-open"><i class="fa fa-folder-open"></i> %s</a>
+	<div class="media-picker-picked" style="margin-top:5px"></div>
+	<div class="modal fade" tabindex="-1" role="dialog">
+		<div class="modal-dialog modal-lg" role="document">
+			<div class="modal-content">
+				<div class="modal-header">
+					<button type="button" class="close" data-dismiss="modal">&times;</button>
+					<input type="text" class="form-control input-sm media-picker-search" style="width:50%%" placeholder="%s">
+				</div>
+				<div class="modal-body media-picker-list" style="max-height:480px;overflow-y:auto"></div>
+				<div class="modal-footer">
+					<a href="javascript:;" class="btn btn-sm btn-default media-picker-prev">&laquo;</a>
+					<a href="javascript:;" class="btn btn-sm btn-default media-picker-next">&raquo;</a>
+				</div>
+			</div>
+		</div>
+	</div>
+</div>
+<script>
+(function () {
+	let box = $('#%s'), multiple = %v, name = '%s', url = '%s';
+	function pick(item) {
+		let picked = box.find('.media-picker-picked');
+		if (!multiple) {
+			picked.empty();
+		}
+		let tag = $('<span class="label label-primary" style="display:inline-block;margin:0 4px 4px 0;cursor:pointer"></span>').
+			text(item.name + ' \u00d7');
+		tag.append($('<input type="hidden">').attr('name', name).val(item.path));
+		tag.on('click', function () {
+			$(this).remove();
+		});
+		picked.append(tag);
+		if (!multiple) {
+			box.find('.modal').modal('hide');
+		}
+	}
+	function load(page) {
+		$.get(url, {page: page, search: box.find('.media-picker-search').val()}, function (res) {
+			if (res.code !== 0) {
+				swal(res.msg, '', 'error');
+				return;
+			}
+			let list = box.find('.media-picker-list').empty();
+			$.each(res.data.list, function (i, item) {
+				let cell = $('<a href="javascript:;" style="display:inline-block;width:110px;margin:4px;text-align:center;word-break:break-all;vertical-align:top"></a>');
+				if (item.mime.indexOf('image/') === 0) {
+					cell.append($('<img style="width:100px;height:100px;object-fit:cover">').attr('src', item.thumb));
+				} else {
+					cell.append('<i class="fa fa-file-o fa-5x"></i>');
+				}
+				cell.append($('<div style="font-size:12px"></div>').text(item.name + ' (' + item.size + ')'));
+				cell.on('click', function () {
+					pick(item);
+				});
+				list.append(cell);
+			});
+			box.find('.media-picker-prev').toggle(page > 1).off('click').on('click', function () {
+				load(page - 1);
+			});
+			box.find('.media-picker-next').toggle(page * res.data.page_size < res.data.total).off('click').on('click', function () {
+				load(page + 1);
+			});
+		});
+	}
+	box.find('.media-picker-open').on('click', function () {
+		box.find('.modal').modal('show');
+		load(1);
+	});
+	box.find('.media-picker-search').on('keydown', function (e) {
+		// do not submit the form
+		if (e.keyCode === 13) {
+			e.preventDefault();
+			load(1);
+		}
+	});
+})();
+</script>`, id, language.Get("choose from media library"), language.Get("search"),
+		id, field.FormType == form2.Multifile, field.Field+file.MediaPathSuffix, config.Url("/media/library")))
+	return f
+}
+
+// FieldChunkedUpload add a button to the file field to upload large files in
+// chunks of given size before the form is posted. An upload resumes from the
+// missing chunks after a failure. The chunk checksums are computed by the web
+// crypto of the browser, which is only available over https or on localhost.
+func (f *FormPanel) FieldChunkedUpload(chunkSize int64) *FormPanel {
+	if chunkSize <= 0 {
+		chunkSize = file.DefaultMaxChunkSize
+	}
+	field := f.FieldList[f.curFieldListIndex]
+	id := "chunk-upload-" + utils.Uuid(10)
+	f.FieldList[f.curFieldListIndex].HelpMsg += template.HTML(fmt.Sprintf(`<div id="%s" class="chunk-upload">
+	<input type="file" class="chunk-upload-file" style="display:none"%s>
+	<a href="javascript:;" class="btn btn-sm btn-default chunk-upload-open"><i class="fa fa-upload"></i> %s</a>
+	<div class="chunk-upload-list" style="margin-top:5px"></div>
+</div>
+<script>
+(function () {
+	let box = $('#%s'), field = '%s', name = '%s', multiple = %v, chunkSize = %d, base = '%s', retry = '%s';
+	function url(action) {
+		// the forms are posted to the url which ends with the prefix
+		let prefix = box.closest('form').attr('action').split('?')[0].split('/').pop();
+		return base + '/' + prefix + (action ? '/' + action : '');
+	}
+	function hex(buf) {
+		return Array.prototype.map.call(new Uint8Array(buf), function (b) {
+			return ('0' + b.toString(16)).slice(-2);
+		}).join('');
+	}
+	function errorMsg(xhr) {
+		return xhr.responseJSON && xhr.responseJSON.msg ? xhr.responseJSON.msg : xhr.statusText;
+	}
+	function upload(file, row) {
+		let status = row.find('.chunk-upload-status');
+		function fail(msg) {
+			status.text(msg + ' ');
+			status.append($('<a href="javascript:;"></a>').text(retry).on('click', function () {
+				resume();
+			}));
+		}
+		function complete() {
+			$.post(url('complete'), {upload_id: row.data('id')}).done(function (res) {
+				row.find('.chunk-upload-remove').before($('<input type="hidden">').attr('name', name).val(res.data.path));
+				status.text('100%%');
+			}).fail(function (xhr) {
+				fail(errorMsg(xhr));
+			});
+		}
+		function send(index, count, received) {
+			if (index === count) {
+				complete();
+				return;
+			}
+			if (received.indexOf(index) !== -1) {
+				send(index + 1, count, received);
+				return;
+			}
+			let chunk = file.slice(index * chunkSize, (index + 1) * chunkSize);
+			chunk.arrayBuffer().then(function (buf) {
+				return crypto.subtle.digest('SHA-256', buf);
+			}).then(function (sum) {
+				$.ajax({
+					url: url('') + '?' + $.param({upload_id: row.data('id'), index: index, checksum: hex(sum)}),
+					type: 'POST',
+					data: chunk,
+					processData: false,
+					contentType: 'application/octet-stream'
+				}).done(function () {
+					status.text(Math.floor((index + 1) * 100 / (count + 1)) + '%%');
+					send(index + 1, count, received);
+				}).fail(function (xhr) {
+					fail(errorMsg(xhr));
+				});
+			}, function (err) {
+				fail(err.message);
+			});
+		}
+		function resume() {
+			$.get(url('status'), {upload_id: row.data('id')}).done(function (res) {
+				send(0, row.data('count'), res.data.received);
+			}).fail(function (xhr) {
+				fail(errorMsg(xhr));
+			});
+		}
+		$.post(url('init'), {field: field, filename: file.name, size: file.size, chunk_size: chunkSize}).done(function (res) {
+			row.data('id', res.data.upload_id).data('count', res.data.chunk_count);
+			send(0, res.data.chunk_count, []);
+		}).fail(function (xhr) {
+			fail(errorMsg(xhr));
+		});
+	}
+	box.find('.chunk-upload-open').on('click', function () {
+		box.find('.chunk-upload-file').click();
+	});
+	box.find('.chunk-upload-file').on('change', function () {
+		if (!multiple) {
+			box.find('.chunk-upload-list').empty();
+		}
+		$.each(this.files, function (i, file) {
+			let row = $('<div></div>').text(file.name + ' ');
+			row.append('<span class="chunk-upload-status">0%%</span> ');
+			row.append($('<a href="javascript:;" class="chunk-upload-remove">&times;</a>').on('click', function () {
+				row.remove();
+			}));
+			box.find('.chunk-upload-list').append(row);
+			upload(file, row);
+		});
+		// the chosen files are uploaded in chunks, not with the form
+		$(this).val('');
+	});
+})();
+</script>`, id, modules.AorEmpty(field.FormType == form2.Multifile, " multiple"), language.Get("upload in chunks"),
+		id, field.Field, field.Field+file.ChunkPathSuffix, field.FormType == form2.Multifile, chunkSize,
+		config.Url("/upload/chunk"), language.Get("retry")))
+	return f
+}
+
+// ValidateUpload check the uploaded files of the form against the upload
+// rules of the fields. It should be called before the files are uploaded.
+func (f *FormPanel) ValidateUpload(multiForm *multipart.Form) error {
+	if multiForm == nil {
+		return nil
+	}
+	for _, field := range f.FieldList {
+		if !field.FormType.IsFile() || field.UploadRule.IsEmpty() {
+			continue
+		}
+		if err := field.UploadRule.Validate(multiForm.File[field.Field]); err != nil {
+			return errors.New(field.Head + ": " + err.Error())
+		}
+	}
+	return nil
+}
+
 func (f *FormPanel) FieldDefault(def string) *FormPanel {
 	f.FieldList[f.curFieldListIndex].Default = template.HTML(def)
 	return f
@@ -1106,7 +1374,7 @@ func (f *FormPanel) GroupFieldWithValue(pk, id string, columns []string, res map
 							list[j] = field.UpdateValue(id, rowValue, res)
 						}
 						if list[j].FormType == form2.File && list[j].Value != template.HTML("") {
-							list[j].Value2 = config.GetStore().URL(string(list[j].Value))
+							list[j].Value2 = file.URL(string(list[j].Value))
 						}
 						break
 					}
@@ -1174,7 +1442,7 @@ func (f *FormPanel) FieldsWithValue(pk, id string, columns []string, res map[str
 		}
 
 		if formList[key].FormType == form2.File && formList[key].Value != template.HTML("") {
-			formList[key].Value2 = config.GetStore().URL(string(formList[key].Value))
+			formList[key].Value2 = file.URL(string(formList[key].Value))
 		}
 
 		if field.Field == pk {
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"github.com/wowucco/go-admin/context"
 	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/language"
 	"github.com/wowucco/go-admin/modules/menu"
 	"github.com/wowucco/go-admin/modules/system"
 	"github.com/wowucco/go-admin/modules/utils"
@@ -119,7 +120,7 @@ func NewPage(param NewPageParam) *Page {
 		IndexUrl:       config.GetIndexURL(),
 		CdnUrl:         config.GetAssetUrl(),
 		CustomHeadHtml: config.GetCustomHeadHtml(),
-		CustomFootHtml: config.GetCustomFootHtml() + btnJS,
+		CustomFootHtml: config.GetCustomFootHtml() + btnJS + impersonateBanner(param.User),
 		FooterInfo:     config.GetFooterInfo(),
 		AssetsList:     param.Assets,
 		navButtons:     param.Buttons,
@@ -127,6 +128,32 @@ func NewPage(param NewPageParam) *Page {
 	}
 }
 
+// impersonateBanner return a banner to stop the impersonation when the user
+// is impersonated.
+func impersonateBanner(user models.UserModel) template.HTML {
+	if !user.IsImpersonated() {
+		return ""
+	}
+	return template.HTML(fmt.Sprintf(`<div id="impersonate-banner" style="position:fixed;bottom:0;left:0;right:0;z-index:2000;padding:8px 15px;background:#f39c12;color:#fff;text-align:center;">
+	%s <button type="button" class="btn btn-xs btn-default" style="margin-left:10px;">%s</button>
+</div>
+<script>
+$("#impersonate-banner button").on("click", function () {
+	$.ajax({
+		method: "post",
+		url: "%s",
+		success: function () {
+			location.href = "%s";
+		}
+	});
+});
+</script>`,
+		template.HTMLEscapeString(fmt.Sprintf(language.Get("%s is impersonating %s"), user.ImpersonatorName, user.Name)),
+		language.Get("stop impersonating"),
+		config.Url("/impersonate/stop"),
+		config.GetIndexURL()))
+}
+
 func (page *Page) AddButton(title template.HTML, icon string, action Action) *Page {
 	page.navButtons = append(page.navButtons, GetNavButton(title, icon, action))
 	page.CustomFootHtml += action.FooterContent()
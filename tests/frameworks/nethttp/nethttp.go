@@ -0,0 +1,77 @@
+package nethttp
+
+import (
+	// add net/http adapter
+	_ "github.com/wowucco/go-admin/adapter/nethttp"
+	// add mysql driver
+	_ "github.com/wowucco/go-admin/modules/db/drivers/mysql"
+	// add postgresql driver
+	_ "github.com/wowucco/go-admin/modules/db/drivers/postgres"
+	// add sqlite driver
+	_ "github.com/wowucco/go-admin/modules/db/drivers/sqlite"
+	// add mssql driver
+	_ "github.com/wowucco/go-admin/modules/db/drivers/mssql"
+	// add adminlte ui theme
+	_ "github.com/GoAdminGroup/themes/adminlte"
+
+	"net/http"
+	"os"
+
+	"github.com/GoAdminGroup/themes/adminlte"
+	"github.com/wowucco/go-admin/engine"
+	"github.com/wowucco/go-admin/modules/config"
+	"github.com/wowucco/go-admin/modules/language"
+	"github.com/wowucco/go-admin/plugins/admin"
+	"github.com/wowucco/go-admin/plugins/admin/modules/table"
+	"github.com/wowucco/go-admin/plugins/example"
+	"github.com/wowucco/go-admin/template"
+	"github.com/wowucco/go-admin/template/chartjs"
+	"github.com/wowucco/go-admin/tests/tables"
+)
+
+func newHandler() http.Handler {
+	eng := engine.Default()
+
+	adminPlugin := admin.NewAdmin(tables.Generators)
+	adminPlugin.AddGenerator("user", tables.GetUserTable)
+	examplePlugin := example.NewExample()
+	template.AddComp(chartjs.NewChart())
+
+	r, err := eng.AddConfigFromJSON(os.Args[len(os.Args)-1]).
+		AddPlugins(adminPlugin, examplePlugin).Handler()
+	if err != nil {
+		panic(err)
+	}
+
+	eng.HTML("GET", "/admin", tables.GetContent)
+
+	return r
+}
+
+func NewHandler(dbs config.DatabaseList, gens table.GeneratorList) http.Handler {
+	eng := engine.Default()
+
+	adminPlugin := admin.NewAdmin(gens)
+	template.AddComp(chartjs.NewChart())
+
+	r, err := eng.AddConfig(config.Config{
+		Databases: dbs,
+		UrlPrefix: "admin",
+		Store: config.Store{
+			Path:   "./uploads",
+			Prefix: "uploads",
+		},
+		Language:    language.EN,
+		IndexUrl:    "/",
+		Debug:       true,
+		ColorScheme: adminlte.ColorschemeSkinBlack,
+	}).
+		AddPlugins(adminPlugin).Handler()
+	if err != nil {
+		panic(err)
+	}
+
+	eng.HTML("GET", "/admin", tables.GetContent)
+
+	return r
+}
@@ -0,0 +1,18 @@
+package nethttp
+
+import (
+	"github.com/gavv/httpexpect"
+	"github.com/wowucco/go-admin/tests/common"
+	"net/http"
+	"testing"
+)
+
+func TestNetHTTP(t *testing.T) {
+	common.ExtraTest(httpexpect.WithConfig(httpexpect.Config{
+		Client: &http.Client{
+			Transport: httpexpect.NewBinder(newHandler()),
+			Jar:       httpexpect.NewJar(),
+		},
+		Reporter: httpexpect.NewAssertReporter(t),
+	}))
+}